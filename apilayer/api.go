@@ -14,6 +14,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -73,6 +74,9 @@ func main() {
 	// summary
 	router.Handle("/api/v1/summary", CustomRequestHandler(handler.GetAssetsAndSummary)).Methods(http.MethodGet)
 
+	// search
+	router.Handle("/api/v1/search", CustomRequestHandler(handler.Search)).Methods(http.MethodGet)
+
 	// categories
 	router.Handle("/api/v1/category/items", CustomRequestHandler(handler.GetAllCategoryItems)).Methods(http.MethodGet)
 	router.Handle("/api/v1/category/items", CustomRequestHandler(handler.AddItemsInCategory)).Methods(http.MethodPost)
@@ -84,6 +88,11 @@ func main() {
 	router.Handle("/api/v1/category/{id}", CustomRequestHandler(handler.UpdateCategory)).Methods(http.MethodPut)
 	router.Handle("/api/v1/category/{id}", CustomRequestHandler(handler.RemoveCategory)).Methods(http.MethodDelete)
 
+	router.Handle("/api/v1/category/{catID}/attributes", CustomRequestHandler(handler.GetCategoryAttributes)).Methods(http.MethodGet)
+	router.Handle("/api/v1/category/{catID}/attributes", CustomRequestHandler(handler.AddCategoryAttribute)).Methods(http.MethodPost)
+	router.Handle("/api/v1/category/{catID}/attributes/{attributeID}", CustomRequestHandler(handler.UpdateCategoryAttribute)).Methods(http.MethodPut)
+	router.Handle("/api/v1/category/{catID}/attributes/{attributeID}", CustomRequestHandler(handler.RemoveCategoryAttribute)).Methods(http.MethodDelete)
+
 	// maintenance plans
 	router.Handle("/api/v1/plans/items", CustomRequestHandler(handler.GetAllMaintenancePlanItems)).Methods(http.MethodGet)
 	router.Handle("/api/v1/plans/items", CustomRequestHandler(handler.AddItemsInMaintenancePlan)).Methods(http.MethodPost)
@@ -104,27 +113,123 @@ func main() {
 	router.Handle("/api/v1/profile/{id}/recent-activities", CustomRequestHandler(handler.GetRecentActivities)).Methods(http.MethodGet)
 	router.Handle("/api/v1/profile/{id}", CustomRequestHandler(handler.UpdateProfile)).Methods(http.MethodPut)
 	router.Handle("/api/v1/profile/{id}/username", CustomRequestHandler(handler.GetUsername)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/backup", CustomRequestHandler(handler.ExportAccountBackup)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/backup/restore", CustomRequestHandler(handler.RestoreAccountBackup)).Methods(http.MethodPost)
 
 	// inventories
 	router.Handle("/api/v1/profile/{id}/inventories", CustomRequestHandler(handler.GetAllInventories)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/lookup", CustomRequestHandler(handler.LookupInventory)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/duplicates", CustomRequestHandler(handler.GetInventoryDuplicates)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/expiring", CustomRequestHandler(handler.GetExpiringInventories)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/imports", CustomRequestHandler(handler.GetInventoryImports)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/mappers", CustomRequestHandler(handler.GetInventoryImportMappers)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/profiles", CustomRequestHandler(handler.GetInventoryImportProfiles)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/export", CustomRequestHandler(handler.ExportInventories)).Methods(http.MethodGet)
 	router.Handle("/api/v1/profile/{id}/inventories/{invID}", CustomRequestHandler(handler.GetInventoryByID)).Methods(http.MethodGet)
 	router.Handle("/api/v1/profile/{id}/inventories/{asssetID}", CustomRequestHandler(handler.UpdateAssetColumn)).Methods(http.MethodPut)
 
 	router.Handle("/api/v1/profile/{id}/inventories", CustomRequestHandler(handler.AddNewInventory)).Methods(http.MethodPost)
 	router.Handle("/api/v1/profile/{id}/inventories/bulk", CustomRequestHandler(handler.AddInventoryInBulk)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/bulk/edit", CustomRequestHandler(handler.BulkEditInventories)).Methods(http.MethodPost)
 	router.Handle("/api/v1/profile/{id}/inventories", CustomRequestHandler(handler.UpdateSelectedInventory)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}", CustomRequestHandler(handler.PatchSelectedInventory)).Methods(http.MethodPatch)
 	router.Handle("/api/v1/profile/{id}/inventories/prune", CustomRequestHandler(handler.RemoveSelectedInventory)).Methods(http.MethodPost)
 
 	router.Handle("/api/v1/profile/{id}/fav", CustomRequestHandler(handler.GetFavouriteItems)).Methods(http.MethodGet)
 	router.Handle("/api/v1/profile/{id}/fav", CustomRequestHandler(handler.SaveFavItem)).Methods(http.MethodPost)
 	router.Handle("/api/v1/profile/{id}/fav", CustomRequestHandler(handler.RemoveFavItem)).Methods(http.MethodDelete)
 
+	// trash
+	router.Handle("/api/v1/profile/{id}/trash", CustomRequestHandler(handler.GetTrash)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/trash/{type}/{itemID}/restore", CustomRequestHandler(handler.RestoreTrashItem)).Methods(http.MethodPost)
+
+	// labels
+	router.Handle("/api/v1/profile/{id}/labels/sheet", CustomRequestHandler(handler.GetLabelSheet)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/labels/{labelID}", CustomRequestHandler(handler.GetLabelImage)).Methods(http.MethodGet)
+
+	// loans
+	router.Handle("/api/v1/profile/{id}/loans", CustomRequestHandler(handler.GetLoans)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/loans", CustomRequestHandler(handler.CheckOutAssets)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/loans/{loanID}/checkin", CustomRequestHandler(handler.CheckInAssets)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/loans", CustomRequestHandler(handler.GetAssetLoanHistory)).Methods(http.MethodGet)
+
+	// inventory history
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/history", CustomRequestHandler(handler.GetInventoryHistory)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/history/{revisionID}/revert", CustomRequestHandler(handler.RevertInventoryRevision)).Methods(http.MethodPost)
+
+	// inventory tree
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/tree", CustomRequestHandler(handler.GetInventoryTree)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/parent", CustomRequestHandler(handler.UpdateInventoryParent)).Methods(http.MethodPut)
+
+	// inventory duplicates
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/merge", CustomRequestHandler(handler.MergeInventory)).Methods(http.MethodPost)
+
+	// inventory attachments
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/attachments", CustomRequestHandler(handler.GetInventoryAttachments)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/attachments", CustomRequestHandler(handler.AddInventoryAttachment)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/attachments/{attachmentID}", CustomRequestHandler(handler.DownloadInventoryAttachment)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/attachments/{attachmentID}", CustomRequestHandler(handler.RemoveInventoryAttachment)).Methods(http.MethodDelete)
+
+	// depreciation
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/depreciation", CustomRequestHandler(handler.GetInventoryDepreciation)).Methods(http.MethodGet)
+
+	// stock movements
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/stock", CustomRequestHandler(handler.GetStockMovements)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/stock", CustomRequestHandler(handler.AddStockMovement)).Methods(http.MethodPost)
+
+	// inventory batches
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/batches", CustomRequestHandler(handler.GetInventoryBatches)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/batches", CustomRequestHandler(handler.AddInventoryBatch)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/batches/{batchID}", CustomRequestHandler(handler.UpdateInventoryBatch)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/batches/{batchID}", CustomRequestHandler(handler.RemoveInventoryBatch)).Methods(http.MethodDelete)
+
+	// inventory units
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/units", CustomRequestHandler(handler.GetInventoryUnits)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/units", CustomRequestHandler(handler.AddInventoryUnits)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/units/{unitID}", CustomRequestHandler(handler.UpdateInventoryUnit)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/inventories/{invID}/units/{unitID}", CustomRequestHandler(handler.RemoveInventoryUnit)).Methods(http.MethodDelete)
+
+	// inventory imports
+	router.Handle("/api/v1/profile/{id}/inventories/imports", CustomRequestHandler(handler.AddInventoryImport)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/profiles", CustomRequestHandler(handler.AddInventoryImportProfile)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/profiles/{profileID}", CustomRequestHandler(handler.UpdateInventoryImportProfile)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/profiles/{profileID}", CustomRequestHandler(handler.RemoveInventoryImportProfile)).Methods(http.MethodDelete)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/{importID}", CustomRequestHandler(handler.GetInventoryImport)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/{importID}", CustomRequestHandler(handler.UpdateInventoryImportMapping)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/{importID}", CustomRequestHandler(handler.RemoveInventoryImport)).Methods(http.MethodDelete)
+	router.Handle("/api/v1/profile/{id}/inventories/imports/{importID}/commit", CustomRequestHandler(handler.CommitInventoryImport)).Methods(http.MethodPost)
+
 	// notes
 	router.Handle("/api/v1/profile/{id}/notes", CustomRequestHandler(handler.GetNotes)).Methods(http.MethodGet)
 	router.Handle("/api/v1/profile/{id}/notes", CustomRequestHandler(handler.AddNewNote)).Methods(http.MethodPost)
 	router.Handle("/api/v1/profile/{id}/notes", CustomRequestHandler(handler.UpdateNote)).Methods(http.MethodPut)
 	router.Handle("/api/v1/profile/{id}/notes/{noteID}", CustomRequestHandler(handler.RemoveNote)).Methods(http.MethodDelete)
 
+	// tags
+	router.Handle("/api/v1/profile/{id}/tags", CustomRequestHandler(handler.GetTags)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/tags", CustomRequestHandler(handler.AddTag)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/tags/autocomplete", CustomRequestHandler(handler.GetTagSuggestions)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/tags/counts", CustomRequestHandler(handler.GetTagCounts)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/tags/{tagID}", CustomRequestHandler(handler.UpdateTag)).Methods(http.MethodPut)
+	router.Handle("/api/v1/profile/{id}/tags/{tagID}", CustomRequestHandler(handler.RemoveTag)).Methods(http.MethodDelete)
+	router.Handle("/api/v1/profile/{id}/tags/{entityType}/{entityID}", CustomRequestHandler(handler.GetEntityTags)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/tags/{entityType}/{entityID}", CustomRequestHandler(handler.UpdateEntityTags)).Methods(http.MethodPut)
+
+	// exchange rates
+	router.Handle("/api/v1/profile/{id}/exchange-rates", CustomRequestHandler(handler.GetExchangeRates)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/exchange-rates", CustomRequestHandler(handler.AddExchangeRates)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/exchange-rates/upload", CustomRequestHandler(handler.UploadExchangeRates)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/exchange-rates/{rateID}", CustomRequestHandler(handler.RemoveExchangeRate)).Methods(http.MethodDelete)
+
+	// jobs
+	router.Handle("/api/v1/profile/{id}/jobs", CustomRequestHandler(handler.GetJobs)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/jobs", CustomRequestHandler(handler.AddJob)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/jobs/{jobID}", CustomRequestHandler(handler.GetJob)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/jobs/{jobID}", CustomRequestHandler(handler.RemoveJob)).Methods(http.MethodDelete)
+	router.Handle("/api/v1/profile/{id}/jobs/{jobID}/progress", CustomRequestHandler(handler.GetJobProgress)).Methods(http.MethodGet)
+	router.Handle("/api/v1/profile/{id}/jobs/{jobID}/cancel", CustomRequestHandler(handler.CancelJob)).Methods(http.MethodPost)
+	router.Handle("/api/v1/profile/{id}/jobs/{jobID}/download", CustomRequestHandler(handler.DownloadJobFile)).Methods(http.MethodGet)
+
 	// reports
 	router.Handle("/api/v1/reports/{id}", CustomRequestHandler(handler.GetReports)).Methods(http.MethodGet)
 
@@ -133,15 +238,22 @@ func main() {
 	router.Handle("/api/v1/{id}/fetchImage", CustomRequestHandler(handler.FetchImage)).Methods(http.MethodGet)
 
 	cors := handlers.CORS(
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "Role2"}),
-		handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "Role2", "If-Match"}),
+		handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}),
 		handlers.AllowCredentials(),
 		handlers.AllowedOrigins([]string{"http://localhost", "http://localhost:5173", "http://localhost:5173", "http://localhost:8081"}),
-		handlers.ExposedHeaders([]string{"Role2"}),
+		handlers.ExposedHeaders([]string{"Role2", "X-Total-Count", "X-Next-Cursor", "ETag"}),
 	)
 
 	http.Handle("/", cors(router))
 
+	// long running operations are queued as jobs and run in the background by the workers
+	handler.RegisterJobRunners()
+	service.StartJobWorkers(context.Background(), validateCurrentUser())
+
+	// items in the trash are purged along with their images and attachments in the bucket
+	service.StartTrashPurge(context.Background(), validateCurrentUser())
+
 	config.Log("Api is up and running ...", nil)
 	err := http.ListenAndServe(":8087", nil)
 	if err != nil {
@@ -73,3 +73,100 @@ func RetrieveDocumentFromBucket(documentID string) ([]byte, string, string, erro
 
 	return content, objectStat.ContentType, objectStat.Key, nil
 }
+
+// RetrieveDocumentStreamFromBucket ...
+//
+// returns a reader of the selected document along with the size and content type of the document, so that large
+// documents can be streamed without holding them in memory. The reader must be closed by the caller.
+func RetrieveDocumentStreamFromBucket(documentID string) (io.ReadCloser, int64, string, error) {
+	client, err := initializeStorage()
+	if err != nil {
+		config.Log("unable to initialize minio client storage", err)
+		return nil, 0, "", err
+	}
+	bucketName := os.Getenv("MINIO_APP_BUCKET_NAME")
+
+	object, err := client.GetObject(bucketName, documentID, minio.GetObjectOptions{})
+	if err != nil {
+		config.Log("unable to retrieve object from the bucket", err)
+		return nil, 0, "", err
+	}
+
+	objectStat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			config.Log("Object metadata not found: %s", err, documentID)
+			return nil, 0, "", errors.New("NoSuchKey")
+		}
+		config.Log("unable to retrieve object metadata", err)
+		return nil, 0, "", err
+	}
+
+	return object, objectStat.Size, objectStat.ContentType, nil
+}
+
+// UploadStreamInBucket ...
+//
+// uploads the content of the reader in the bucket under the selected object name
+func UploadStreamInBucket(objectName string, reader io.Reader, size int64, contentType string) error {
+
+	client, err := initializeStorage()
+	if err != nil {
+		config.Log("unable to initialize minio client storage", err)
+		return err
+	}
+	bucketName := os.Getenv("MINIO_APP_BUCKET_NAME")
+
+	_, err = client.PutObject(bucketName, objectName, reader, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		config.Log("unable to add object to the selected bucket", err)
+		return err
+	}
+	config.Log("upload successful", nil)
+	return nil
+}
+
+// RemoveDocumentFromBucket ...
+//
+// Removes the selected document from the bucket storage
+func RemoveDocumentFromBucket(documentID string) error {
+	client, err := initializeStorage()
+	if err != nil {
+		config.Log("unable to initialize minio client storage", err)
+		return err
+	}
+	bucketName := os.Getenv("MINIO_APP_BUCKET_NAME")
+
+	err = client.RemoveObject(bucketName, documentID)
+	if err != nil {
+		config.Log("unable to remove object from the bucket", err)
+		return err
+	}
+	return nil
+}
+
+// CopyDocumentInBucket ...
+//
+// Copies the selected document to the destination object name within the bucket storage
+func CopyDocumentInBucket(sourceDocumentID string, destinationDocumentID string) error {
+	client, err := initializeStorage()
+	if err != nil {
+		config.Log("unable to initialize minio client storage", err)
+		return err
+	}
+	bucketName := os.Getenv("MINIO_APP_BUCKET_NAME")
+
+	destination, err := minio.NewDestinationInfo(bucketName, destinationDocumentID, nil, nil)
+	if err != nil {
+		config.Log("unable to build destination of the copied object", err)
+		return err
+	}
+
+	err = client.CopyObject(destination, minio.NewSourceInfo(bucketName, sourceDocumentID, nil))
+	if err != nil {
+		config.Log("unable to copy object within the bucket", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,887 @@
+package db
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// AccountBackupVersion is the format of the archive. Increment when the layout of the archive changes.
+	// Version 2 adds loans, the stock ledger, batches and revisions of the assets.
+	AccountBackupVersion = 2
+
+	InvalidAccountBackup            = "invalid account backup"
+	UnsupportedAccountBackupVersion = "unsupported account backup version"
+
+	accountBackupManifestFileName = "manifest.json"
+	accountBackupProfileFileName  = "profile.json"
+	accountBackupTablesDir        = "tables/"
+	accountBackupObjectsDir       = "objects/"
+
+	// maxAccountBackupPartSize is the max uncompressed size of a single json file of the archive
+	maxAccountBackupPartSize = 256 << 20
+)
+
+// sub queries used to select the rows that belong to the user. $1 is the userID.
+const (
+	accountBackupInventoriesSqlStr = `SELECT inv.id FROM community.inventory inv WHERE inv.created_by = $1::UUID`
+	accountBackupCategoriesSqlStr  = `SELECT c.id FROM community.category c WHERE c.created_by = $1::UUID`
+	accountBackupPlansSqlStr       = `SELECT mp.id FROM community.maintenance_plan mp WHERE mp.created_by = $1::UUID`
+	accountBackupNotesSqlStr       = `SELECT n.id FROM community.notes n WHERE n.created_by = $1::UUID`
+	accountBackupTagsSqlStr        = `SELECT tg.id FROM community.tags tg WHERE tg.created_by = $1::UUID`
+	accountBackupUnitsSqlStr       = `SELECT iu.id FROM community.inventory_units iu WHERE iu.item_id IN (` + accountBackupInventoriesSqlStr + `)`
+	accountBackupLoansSqlStr       = `SELECT l.id FROM community.loans l WHERE l.created_by = $1::UUID`
+)
+
+// accountBackupTable ...
+//
+// accountBackupTable is a single table of the account backup. references are the columns that hold the id of a row
+// of another table in the archive; the ids are replaced with the ids of the restored rows.
+type accountBackupTable struct {
+	name        string
+	whereClause string
+	references  map[string]string
+	// hasStatus is true when the status column references the statuses table. Statuses are exported by name since
+	// the ids of the statuses differ between instances.
+	hasStatus bool
+	// hasImage is true when the image of each row is stored in the bucket under the id of the row
+	hasImage bool
+	// objectKeyColumn is the column that holds the name of the document of each row in the bucket
+	objectKeyColumn string
+	// mergeBy is the unique column of the user; an existing row with the same value is reused instead of a new row
+	mergeBy string
+	// omitColumns are the columns that are assigned by the db when the row is restored
+	omitColumns []string
+	// changesReferences are the references within the before and after values of the changes column of each row
+	changesReferences map[string]string
+	// sinceVersion is the version of the archive that added the table; older archives are restored without it
+	sinceVersion int
+}
+
+// accountBackupTables are the tables of the account backup in the order that they are restored. Rows are only
+// exported when every row that they reference is exported as well.
+var accountBackupTables = []accountBackupTable{
+	{
+		name: "storage_locations",
+		whereClause: `t.created_by = $1::UUID
+			OR t.id IN (SELECT inv.storage_location_id FROM community.inventory inv WHERE inv.created_by = $1::UUID)
+			OR t.id IN (SELECT iu.storage_location_id FROM community.inventory_units iu WHERE iu.item_id IN (` + accountBackupInventoriesSqlStr + `))
+			OR t.id IN (SELECT sm.from_storage_location_id FROM community.stock_movements sm WHERE sm.item_id IN (` + accountBackupInventoriesSqlStr + `))
+			OR t.id IN (SELECT sm.to_storage_location_id FROM community.stock_movements sm WHERE sm.item_id IN (` + accountBackupInventoriesSqlStr + `))`,
+	},
+	{
+		name:        "inventory",
+		whereClause: `t.created_by = $1::UUID`,
+		references:  map[string]string{"storage_location_id": "storage_locations", "parent_id": "inventory"},
+		hasImage:    true,
+	},
+	{
+		name:        "inventory_units",
+		whereClause: `t.item_id IN (` + accountBackupInventoriesSqlStr + `)`,
+		references:  map[string]string{"item_id": "inventory", "storage_location_id": "storage_locations"},
+	},
+	{
+		name:            "inventory_attachments",
+		whereClause:     `t.item_id IN (` + accountBackupInventoriesSqlStr + `)`,
+		references:      map[string]string{"item_id": "inventory"},
+		objectKeyColumn: "object_key",
+	},
+	{
+		name:         "inventory_batches",
+		whereClause:  `t.item_id IN (` + accountBackupInventoriesSqlStr + `)`,
+		references:   map[string]string{"item_id": "inventory"},
+		sinceVersion: 2,
+	},
+	{
+		name:         "stock_movements",
+		whereClause:  `t.item_id IN (` + accountBackupInventoriesSqlStr + `)`,
+		references:   map[string]string{"item_id": "inventory", "from_storage_location_id": "storage_locations", "to_storage_location_id": "storage_locations"},
+		sinceVersion: 2,
+	},
+	{
+		name:              "inventory_revisions",
+		whereClause:       `t.item_id IN (` + accountBackupInventoriesSqlStr + `)`,
+		references:        map[string]string{"item_id": "inventory"},
+		omitColumns:       []string{"revision_number"},
+		changesReferences: map[string]string{"storage_location_id": "storage_locations", "parent_id": "inventory"},
+		sinceVersion:      2,
+	},
+	{
+		name:         "loans",
+		whereClause:  `t.created_by = $1::UUID`,
+		sinceVersion: 2,
+	},
+	{
+		name: "loan_items",
+		whereClause: `t.loan_id IN (` + accountBackupLoansSqlStr + `) AND t.item_id IN (` + accountBackupInventoriesSqlStr + `)
+			AND (t.unit_id IS NULL OR t.unit_id IN (` + accountBackupUnitsSqlStr + `))`,
+		references:   map[string]string{"loan_id": "loans", "item_id": "inventory", "unit_id": "inventory_units"},
+		sinceVersion: 2,
+	},
+	{
+		name:        "category",
+		whereClause: `t.created_by = $1::UUID`,
+		hasStatus:   true,
+		hasImage:    true,
+	},
+	{
+		name:        "category_attributes",
+		whereClause: `t.category_id IN (` + accountBackupCategoriesSqlStr + `)`,
+		references:  map[string]string{"category_id": "category"},
+	},
+	{
+		name:        "maintenance_plan",
+		whereClause: `t.created_by = $1::UUID`,
+		hasStatus:   true,
+		hasImage:    true,
+	},
+	{
+		name:        "category_item",
+		whereClause: `t.item_id IN (` + accountBackupInventoriesSqlStr + `) AND t.category_id IN (` + accountBackupCategoriesSqlStr + `)`,
+		references:  map[string]string{"category_id": "category", "item_id": "inventory"},
+	},
+	{
+		name: "maintenance_item",
+		whereClause: `t.item_id IN (` + accountBackupInventoriesSqlStr + `) AND t.maintenance_plan_id IN (` + accountBackupPlansSqlStr + `)
+			AND (t.unit_id IS NULL OR t.unit_id IN (` + accountBackupUnitsSqlStr + `))`,
+		references: map[string]string{"maintenance_plan_id": "maintenance_plan", "item_id": "inventory", "unit_id": "inventory_units"},
+	},
+	{
+		name: "favourite_items",
+		whereClause: `t.created_by = $1::UUID
+			AND (t.category_id IS NULL OR t.category_id IN (` + accountBackupCategoriesSqlStr + `))
+			AND (t.maintenance_plan_id IS NULL OR t.maintenance_plan_id IN (` + accountBackupPlansSqlStr + `))`,
+		references: map[string]string{"category_id": "category", "maintenance_plan_id": "maintenance_plan"},
+	},
+	{
+		name:        "notes",
+		whereClause: `t.created_by = $1::UUID`,
+		hasStatus:   true,
+	},
+	{
+		name:        "tags",
+		whereClause: `t.created_by = $1::UUID`,
+		mergeBy:     "name",
+	},
+	{
+		name: "tag_items",
+		whereClause: `t.tag_id IN (` + accountBackupTagsSqlStr + `)
+			AND (t.item_id IS NULL OR t.item_id IN (` + accountBackupInventoriesSqlStr + `))
+			AND (t.note_id IS NULL OR t.note_id IN (` + accountBackupNotesSqlStr + `))
+			AND (t.maintenance_plan_id IS NULL OR t.maintenance_plan_id IN (` + accountBackupPlansSqlStr + `))`,
+		references: map[string]string{"tag_id": "tags", "item_id": "inventory", "note_id": "notes", "maintenance_plan_id": "maintenance_plan"},
+	},
+}
+
+// accountBackupProfileColumns are the columns of the profile that are restored. The email address, username and
+// role belong to the account that the backup is restored into and are left as is.
+var accountBackupProfileColumns = []string{"full_name", "avatar_url", "phone_number", "about_me", "appearance", "grid_view", "unit_system", "base_currency"}
+
+// ExportAccountBackup ...
+//
+// writes the profile, the rows of every table of the account backup and every document from the bucket of the
+// selected user into a zip archive. Rows are stored as json in tables/{table}.json and documents are stored under
+// objects/{key}. The manifest is written last and is returned once the archive is complete.
+func ExportAccountBackup(user string, userID string, w io.Writer) (*model.AccountBackup, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		config.Log("unable to validate selected user", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	// a single snapshot is used for every table so that the rows of the archive are consistent with each other
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	manifest := model.AccountBackup{
+		Version:   AccountBackupVersion,
+		CreatedAt: time.Now(),
+		ProfileID: userID,
+		Tables:    make(map[string]int),
+		Objects:   make([]model.AccountBackupObject, 0),
+	}
+	archive := zip.NewWriter(w)
+
+	var profile []byte
+	sqlStr := `SELECT to_jsonb(p) FROM community.profiles p WHERE p.id = $1::UUID;`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, userID).Scan(&profile)
+	if err != nil {
+		config.Log("unable to retrieve selected profile", err)
+		return nil, err
+	}
+
+	part, err := archive.Create(accountBackupProfileFileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(profile); err != nil {
+		return nil, err
+	}
+
+	objectKeys := []string{userID}
+	for _, table := range accountBackupTables {
+		rowCount, tableObjectKeys, err := exportAccountBackupTable(tx, archive, table, userID)
+		if err != nil {
+			config.Log("unable to export rows of %s", err, table.name)
+			return nil, err
+		}
+		manifest.Tables[table.name] = rowCount
+		objectKeys = append(objectKeys, tableObjectKeys...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	// documents are retrieved once the rows are exported so that the db connection is not held up by the bucket
+	for _, objectKey := range objectKeys {
+		content, contentType, _, err := bucket.RetrieveDocumentFromBucket(objectKey)
+		if err != nil && err.Error() != "NoSuchKey" {
+			config.Log("unable to retrieve selected document", err)
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+
+		part, err := archive.Create(accountBackupObjectsDir + objectKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, err
+		}
+		manifest.Objects = append(manifest.Objects, model.AccountBackupObject{
+			Key:         objectKey,
+			ContentType: contentType,
+			SizeInBytes: int64(len(content)),
+		})
+	}
+
+	part, err = archive.Create(accountBackupManifestFileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(part).Encode(manifest); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		config.Log("unable to complete account backup", err)
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// exportAccountBackupTable ...
+//
+// streams the rows of the selected table that belong to the user into tables/{table}.json. Returns the count of
+// exported rows and the keys of the documents of the rows in the bucket.
+func exportAccountBackupTable(tx *sql.Tx, archive *zip.Writer, table accountBackupTable, userID string) (int, []string, error) {
+	objectKeySqlStr := "NULL::TEXT"
+	if table.hasImage {
+		objectKeySqlStr = "t.id::TEXT"
+	} else if len(table.objectKeyColumn) > 0 {
+		objectKeySqlStr = "t." + table.objectKeyColumn
+	}
+
+	rowSqlStr := "to_jsonb(t) - 'search_vector'"
+	joinSqlStr := ""
+	if table.hasStatus {
+		rowSqlStr = "(" + rowSqlStr + ") || jsonb_build_object('status', s.name)"
+		joinSqlStr = "LEFT JOIN community.statuses s ON s.id = t.status"
+	}
+
+	sqlStr := fmt.Sprintf(`SELECT %s, %s FROM community.%s t %s WHERE %s ORDER BY t.created_at, t.id;`,
+		objectKeySqlStr, rowSqlStr, table.name, joinSqlStr, table.whereClause)
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	part, err := archive.Create(accountBackupTablesDir + table.name + ".json")
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := io.WriteString(part, "["); err != nil {
+		return 0, nil, err
+	}
+
+	var rowCount int
+	var objectKeys []string
+	for rows.Next() {
+		var objectKey sql.NullString
+		var row []byte
+		if err := rows.Scan(&objectKey, &row); err != nil {
+			return 0, nil, err
+		}
+
+		separator := "\n"
+		if rowCount > 0 {
+			separator = ",\n"
+		}
+		if _, err := io.WriteString(part, separator); err != nil {
+			return 0, nil, err
+		}
+		if _, err := part.Write(row); err != nil {
+			return 0, nil, err
+		}
+
+		rowCount++
+		if objectKey.Valid {
+			objectKeys = append(objectKeys, objectKey.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := io.WriteString(part, "\n]\n"); err != nil {
+		return 0, nil, err
+	}
+	return rowCount, objectKeys, nil
+}
+
+// RestoreAccountBackup ...
+//
+// restores the selected account backup archive into the account of the selected user. Every row is added with a new
+// id and the ids that reference other rows of the archive are replaced with the new ids. Storage locations and tags
+// with the same name as an existing one are reused. Documents are uploaded under the key of the restored row.
+// Nothing is restored if a single row cannot be restored. Returns the count of restored rows of each table.
+func RestoreAccountBackup(user string, userID string, content io.ReaderAt, size int64) (*model.AccountBackup, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		config.Log("unable to validate selected user", err)
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(content, size)
+	if err != nil {
+		config.Log("unable to read selected account backup", err)
+		return nil, errors.New(InvalidAccountBackup)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var manifest model.AccountBackup
+	if err := readAccountBackupPart(files, accountBackupManifestFileName, &manifest); err != nil {
+		config.Log("unable to read manifest of selected account backup", err)
+		return nil, err
+	}
+	if manifest.Version <= 0 {
+		config.Log("unable to validate version of selected account backup", errors.New(InvalidAccountBackup))
+		return nil, errors.New(InvalidAccountBackup)
+	}
+	if manifest.Version > AccountBackupVersion {
+		config.Log("unable to restore account backup of version %d", errors.New(UnsupportedAccountBackupVersion), manifest.Version)
+		return nil, errors.New(UnsupportedAccountBackupVersion)
+	}
+
+	var profile json.RawMessage
+	if err := readAccountBackupPart(files, accountBackupProfileFileName, &profile); err != nil {
+		config.Log("unable to read profile of selected account backup", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the restored assets already hold the quantity and location of the restored stock ledger
+	sqlStr := `SELECT set_config('community.restoring_account_backup', 'on', true);`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	if _, err := tx.Exec(sqlStr); err != nil {
+		config.Log("unable to start restore of selected account backup", err)
+		return nil, err
+	}
+
+	if err := restoreAccountBackupProfile(tx, userID, profile); err != nil {
+		config.Log("unable to restore profile of selected account backup", err)
+		return nil, err
+	}
+
+	statuses, err := retrieveAccountBackupStatuses(tx)
+	if err != nil {
+		config.Log("unable to retrieve statuses", err)
+		return nil, err
+	}
+
+	draftRestore := accountBackupRestore{
+		tx:         tx,
+		userID:     userID,
+		statuses:   statuses,
+		ids:        make(map[string]map[string]string),
+		columns:    make(map[string]map[string]string),
+		objectKeys: map[string]string{manifest.ProfileID: userID},
+	}
+
+	restoredBackup := model.AccountBackup{
+		Version:   manifest.Version,
+		CreatedAt: manifest.CreatedAt,
+		ProfileID: userID,
+		Tables:    make(map[string]int),
+		Objects:   make([]model.AccountBackupObject, 0),
+	}
+
+	for _, table := range accountBackupTables {
+		if manifest.Version < table.sinceVersion {
+			continue
+		}
+
+		var rows []map[string]interface{}
+		if err := readAccountBackupPart(files, accountBackupTablesDir+table.name+".json", &rows); err != nil {
+			config.Log("unable to read rows of %s", err, table.name)
+			return nil, err
+		}
+
+		rowCount, err := draftRestore.restoreTable(table, rows)
+		if err != nil {
+			config.Log("unable to restore rows of %s", err, table.name)
+			return nil, err
+		}
+		restoredBackup.Tables[table.name] = rowCount
+	}
+
+	// documents are uploaded last so that a failed row does not leave documents behind in the bucket
+	var uploadedObjectKeys []string
+	removeUploadedObjects := func() {
+		for _, v := range uploadedObjectKeys {
+			bucket.RemoveDocumentFromBucket(v)
+		}
+	}
+
+	for _, v := range manifest.Objects {
+		objectKey, ok := draftRestore.objectKeys[v.Key]
+		if !ok {
+			config.Log("skipping document %s without a restored row", nil, v.Key)
+			continue
+		}
+
+		f, ok := files[accountBackupObjectsDir+v.Key]
+		if !ok {
+			config.Log("unable to find document %s", errors.New(InvalidAccountBackup), v.Key)
+			removeUploadedObjects()
+			return nil, errors.New(InvalidAccountBackup)
+		}
+
+		err := uploadAccountBackupObject(f, objectKey, v.ContentType)
+		if err != nil {
+			config.Log("unable to upload selected document", err)
+			removeUploadedObjects()
+			return nil, err
+		}
+		uploadedObjectKeys = append(uploadedObjectKeys, objectKey)
+		restoredBackup.Objects = append(restoredBackup.Objects, model.AccountBackupObject{
+			Key:         objectKey,
+			ContentType: v.ContentType,
+			SizeInBytes: int64(f.UncompressedSize64),
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		removeUploadedObjects()
+		return nil, err
+	}
+	return &restoredBackup, nil
+}
+
+// accountBackupRestore ...
+//
+// accountBackupRestore holds the state of a single restore. ids are the new ids of the restored rows of each table
+// by the id of the row in the archive, objectKeys are the new keys of the documents by the key in the archive.
+type accountBackupRestore struct {
+	tx         *sql.Tx
+	userID     string
+	statuses   map[string]string
+	ids        map[string]map[string]string
+	columns    map[string]map[string]string
+	objectKeys map[string]string
+}
+
+// restoreTable ...
+//
+// adds every row of the selected table. References to rows of the same table are set once every row is added
+// since the referenced row can appear later in the archive.
+func (r *accountBackupRestore) restoreTable(table accountBackupTable, rows []map[string]interface{}) (int, error) {
+	r.ids[table.name] = make(map[string]string)
+
+	// deferredReferences are the references to rows of the same table by the new id of the row
+	deferredReferences := make(map[string]map[string]string)
+
+	for _, row := range rows {
+		oldID, ok := row["id"].(string)
+		if !ok {
+			return 0, errors.New(InvalidAccountBackup)
+		}
+
+		if table.name == "storage_locations" {
+			location, ok := row["location"].(string)
+			if !ok || len(strings.TrimSpace(location)) == 0 {
+				return 0, errors.New(InvalidAccountBackup)
+			}
+			locationID, err := retrieveOrAddStorageLocationInTx(r.tx, location, r.userID)
+			if err != nil {
+				return 0, err
+			}
+			r.ids[table.name][oldID] = locationID
+			continue
+		}
+
+		newID := uuid.New().String()
+		for column, referencedTable := range table.references {
+			value, ok := row[column]
+			if !ok || value == nil {
+				continue
+			}
+			referencedID, ok := value.(string)
+			if !ok {
+				return 0, errors.New(InvalidAccountBackup)
+			}
+
+			if referencedTable == table.name {
+				if deferredReferences[newID] == nil {
+					deferredReferences[newID] = make(map[string]string)
+				}
+				deferredReferences[newID][column] = referencedID
+				row[column] = nil
+				continue
+			}
+
+			newReferencedID, ok := r.ids[referencedTable][referencedID]
+			if !ok {
+				config.Log("unable to find %s %s referenced by %s", nil, referencedTable, referencedID, table.name)
+				return 0, errors.New(InvalidAccountBackup)
+			}
+			row[column] = newReferencedID
+		}
+
+		if table.hasStatus {
+			statusName, _ := row["status"].(string)
+			statusID, ok := r.statuses[statusName]
+			if ok {
+				row["status"] = statusID
+			} else {
+				row["status"] = nil
+			}
+		}
+
+		for _, column := range table.omitColumns {
+			delete(row, column)
+		}
+
+		if len(table.changesReferences) > 0 {
+			if err := r.remapChanges(table, row); err != nil {
+				return 0, err
+			}
+		}
+
+		if len(table.objectKeyColumn) > 0 {
+			objectKey, ok := row[table.objectKeyColumn].(string)
+			if !ok {
+				return 0, errors.New(InvalidAccountBackup)
+			}
+			// documents of attachments are stored under the id of the asset and the id of the attachment
+			newObjectKey := fmt.Sprintf("attachments/%s/%s", row["item_id"], newID)
+			row[table.objectKeyColumn] = newObjectKey
+			r.objectKeys[objectKey] = newObjectKey
+		}
+
+		insertedID, err := r.addRow(table, newID, row)
+		if err != nil {
+			if table.name == "inventory" {
+				return 0, toDuplicateBarcodeOrSKUError(err)
+			}
+			return 0, err
+		}
+		r.ids[table.name][oldID] = insertedID
+		if table.hasImage {
+			r.objectKeys[oldID] = insertedID
+		}
+	}
+
+	for id, references := range deferredReferences {
+		for column, referencedID := range references {
+			newReferencedID, ok := r.ids[table.name][referencedID]
+			if !ok {
+				config.Log("unable to find %s %s referenced by %s", nil, table.name, referencedID, table.name)
+				return 0, errors.New(InvalidAccountBackup)
+			}
+
+			sqlStr := fmt.Sprintf(`UPDATE community.%s SET %s = $2 WHERE id = $1;`, table.name, pq.QuoteIdentifier(column))
+			config.Log("SqlStr: %s", nil, sqlStr)
+			if _, err := r.tx.Exec(sqlStr, id, newReferencedID); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(rows), nil
+}
+
+// remapChanges ...
+//
+// replaces the ids in the before and after values of the changes of the selected row with the ids of the restored
+// rows. Ids of rows that are not restored are dropped so that a revert cannot reference the rows of another account.
+func (r *accountBackupRestore) remapChanges(table accountBackupTable, row map[string]interface{}) error {
+	if row["changes"] == nil {
+		return nil
+	}
+	changes, ok := row["changes"].(map[string]interface{})
+	if !ok {
+		return errors.New(InvalidAccountBackup)
+	}
+
+	for column, referencedTable := range table.changesReferences {
+		change, ok := changes[column].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, v := range []string{"before", "after"} {
+			referencedID, ok := change[v].(string)
+			if !ok {
+				continue
+			}
+			if newReferencedID, ok := r.ids[referencedTable][referencedID]; ok {
+				change[v] = newReferencedID
+			} else {
+				change[v] = nil
+			}
+		}
+	}
+	return nil
+}
+
+// addRow ...
+//
+// adds the selected row with the selected id for the user. Only the columns that exist in the table are added so
+// that archives of an older schema leave the new columns with their default values. Ids that are not replaced with
+// the id of a restored row are dropped so that the row cannot reference the rows of another account.
+func (r *accountBackupRestore) addRow(table accountBackupTable, id string, row map[string]interface{}) (string, error) {
+	columns, err := r.retrieveColumns(table.name)
+	if err != nil {
+		return "", err
+	}
+
+	row["id"] = id
+	row["created_by"] = r.userID
+	row["updated_by"] = r.userID
+	row["sharable_groups"] = []string{r.userID}
+
+	var selectedColumns []string
+	for column := range row {
+		dataType, ok := columns[column]
+		if !ok {
+			continue
+		}
+		_, isReference := table.references[column]
+		isRemapped := isReference || column == "id" || column == "created_by" || column == "updated_by" || column == "sharable_groups" || (column == "status" && table.hasStatus)
+		if (dataType == "uuid" || dataType == "_uuid") && !isRemapped {
+			continue
+		}
+		selectedColumns = append(selectedColumns, pq.QuoteIdentifier(column))
+	}
+	sort.Strings(selectedColumns)
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
+
+	columnsSqlStr := strings.Join(selectedColumns, ", ")
+	sqlStr := fmt.Sprintf(`INSERT INTO community.%s (%s) SELECT %s FROM jsonb_populate_record(NULL::community.%s, $1::JSONB)`,
+		table.name, columnsSqlStr, columnsSqlStr, table.name)
+	if len(table.mergeBy) > 0 {
+		sqlStr += " ON CONFLICT DO NOTHING"
+	}
+	sqlStr += " RETURNING id;"
+
+	var insertedID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = r.tx.QueryRow(sqlStr, data).Scan(&insertedID)
+	if errors.Is(err, sql.ErrNoRows) && len(table.mergeBy) > 0 {
+		mergeSqlStr := fmt.Sprintf(`SELECT t.id FROM community.%s t WHERE t.created_by = $1::UUID AND LOWER(t.%s) = LOWER($2);`,
+			table.name, pq.QuoteIdentifier(table.mergeBy))
+		config.Log("SqlStr: %s", nil, mergeSqlStr)
+		err = r.tx.QueryRow(mergeSqlStr, r.userID, fmt.Sprint(row[table.mergeBy])).Scan(&insertedID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return insertedID, nil
+}
+
+// retrieveColumns ...
+//
+// returns the data type of each column of the selected table that can be written to
+func (r *accountBackupRestore) retrieveColumns(tableName string) (map[string]string, error) {
+	if columns, ok := r.columns[tableName]; ok {
+		return columns, nil
+	}
+
+	sqlStr := `SELECT c.column_name, c.udt_name FROM information_schema.columns c
+		WHERE c.table_schema = 'community' AND c.table_name = $1 AND c.is_generated = 'NEVER';`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := r.tx.Query(sqlStr, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var columnName, dataType string
+		if err := rows.Scan(&columnName, &dataType); err != nil {
+			return nil, err
+		}
+		columns[columnName] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	r.columns[tableName] = columns
+	return columns, nil
+}
+
+// restoreAccountBackupProfile ...
+//
+// updates the profile of the user with the restored columns of the profile in the archive. Columns that are missing
+// from the archive are left as is.
+func restoreAccountBackupProfile(tx *sql.Tx, userID string, profile json.RawMessage) error {
+	var setSqlStr []string
+	for _, v := range accountBackupProfileColumns {
+		setSqlStr = append(setSqlStr, fmt.Sprintf("%s = COALESCE(r.%s, p.%s)", v, v, v))
+	}
+
+	sqlStr := `UPDATE community.profiles p SET ` + strings.Join(setSqlStr, ", ") + `, updated_at = $3
+		FROM jsonb_populate_record(NULL::community.profiles, $2::JSONB) r
+		WHERE p.id = $1::UUID;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	result, err := tx.Exec(sqlStr, userID, []byte(profile), time.Now())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// retrieveAccountBackupStatuses ...
+//
+// returns the id of each status by the name of the status
+func retrieveAccountBackupStatuses(tx *sql.Tx) (map[string]string, error) {
+	sqlStr := `SELECT s.name, s.id FROM community.statuses s ORDER BY s.name, s.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[string]string)
+	for rows.Next() {
+		var name, id string
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		if _, ok := statuses[name]; !ok {
+			statuses[name] = id
+		}
+	}
+	return statuses, rows.Err()
+}
+
+// readAccountBackupPart ...
+//
+// decodes the selected json file of the archive. Numbers are kept as is so that prices are not rounded.
+func readAccountBackupPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok || f.UncompressedSize64 > maxAccountBackupPartSize {
+		return errors.New(InvalidAccountBackup)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return errors.New(InvalidAccountBackup)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxAccountBackupPartSize+1))
+	if err != nil || len(content) > maxAccountBackupPartSize {
+		return errors.New(InvalidAccountBackup)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return errors.New(InvalidAccountBackup)
+	}
+	return nil
+}
+
+// uploadAccountBackupObject ...
+//
+// uploads the selected document of the archive in the bucket under the selected key
+func uploadAccountBackupObject(f *zip.File, objectKey string, contentType string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errors.New(InvalidAccountBackup)
+	}
+	defer rc.Close()
+
+	if len(contentType) == 0 {
+		contentType = defaultAttachmentContentType
+	}
+	return bucket.UploadStreamInBucket(objectKey, rc, int64(f.UncompressedSize64), contentType)
+}
@@ -7,6 +7,10 @@ import (
 )
 
 // RetrieveAssetsAndSummary...
+//
+// RetrieveAssetsAndSummary returns the summary of categories, maintenance plans and overdue assets. The price of each
+// overdue asset is converted into the base currency of the user with the exchange rate on its purchase date, and is
+// left in its own currency when no exchange rate is found.
 func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummaryResponse, error) {
 	db, err := SetupDB(user)
 	if err != nil {
@@ -21,6 +25,7 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
     type, 
     returnTime, 
     price, 
+    currency, 
 	items,
     created_at,
     updated_at, 
@@ -32,13 +37,15 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 				'C' as type,
 				NULL::timestamp with time zone as returnTime,
 				0 as price,
+				'' as currency,
 				array_agg(COALESCE(i.name, '')) AS items,
 				c.created_at,
 				c.updated_at,
 				c.sharable_groups
 			FROM community.category c
 			LEFT JOIN community.category_item ci ON c.id = ci.category_id
-			LEFT JOIN community.inventory i ON i.id = ci.item_id
+			LEFT JOIN community.inventory i ON i.id = ci.item_id AND i.deleted_at IS NULL
+			WHERE c.deleted_at IS NULL
 			GROUP BY c.id
 			UNION
 			SELECT
@@ -47,13 +54,15 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 				'M' as type,
 				NULL::timestamp with time zone as returnTime,
 				0 as price,
+				'' as currency,
 				array_agg(COALESCE(i.name, '')) AS items,
 				mp.created_at,
 				mp.updated_at,
 				mp.sharable_groups
 			FROM community.maintenance_plan mp
 			LEFT JOIN community.maintenance_item mi ON mp.id = mi.maintenance_plan_id
-			LEFT JOIN community.inventory i ON i.id = mi.item_id
+			LEFT JOIN community.inventory i ON i.id = mi.item_id AND i.deleted_at IS NULL
+			WHERE mp.deleted_at IS NULL
 			GROUP BY mp.id
 			UNION
 			SELECT 
@@ -61,13 +70,17 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 				i.name,
 				'A' as type, 
 				i.return_datetime AS returnTime,
-				i.price,
+				COALESCE(i.price * er.rate, i.price) AS price,
+				CASE WHEN er.rate IS NULL THEN i.currency ELSE bp.base_currency END AS currency,
 				ARRAY[]::TEXT[] AS items,
 				i.created_at,
 				i.updated_at,
 				i.sharable_groups
 			FROM community.inventory i
+			LEFT JOIN community.profiles bp ON bp.id = $1::UUID
+			LEFT JOIN LATERAL (SELECT ` + exchangeRateSqlStr("i", "$1") + ` AS rate) er ON TRUE
 			WHERE i.is_returnable = TRUE AND i.return_datetime IS NOT NULL AND i.return_datetime < CURRENT_TIMESTAMP
+			AND i.deleted_at IS NULL
 		) AS combined
 		WHERE $1::UUID = ANY(sharable_groups)
 		ORDER BY type, updated_at DESC;`
@@ -88,7 +101,7 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 		var returnDateTime pq.NullTime
 		var items pq.StringArray
 		var sharableGroups pq.StringArray
-		if err := rows.Scan(&as.ID, &as.Name, &as.Type, &returnDateTime, &as.Price, &items, &as.CreatedAt, &as.UpdatedAt, &sharableGroups); err != nil {
+		if err := rows.Scan(&as.ID, &as.Name, &as.Type, &returnDateTime, &as.Price, &as.Currency, &items, &as.CreatedAt, &as.UpdatedAt, &sharableGroups); err != nil {
 			config.Log("unable to retrieve asset summary details", err)
 			return model.AssetsAndSummaryResponse{AssetSummaryList: []model.AssetSummary{}, AssetList: []model.Inventory{}}, err
 		}
@@ -97,6 +110,8 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 			as.ReturnTime = returnDateTime.Time
 		}
 
+		as.Price = roundCurrency(as.Price)
+
 		as.Items = items
 
 		as.SharableGroups = sharableGroups
@@ -112,11 +127,12 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 	i.name, 
 	i.description, 
 	i.price, 
+	i.currency, 
 	i.quantity, 
 	i.bought_at
 		FROM community.inventory i 
 	WHERE 
-		$1::UUID = ANY(sharable_groups) ORDER BY updated_at DESC;`
+		$1::UUID = ANY(sharable_groups) AND i.deleted_at IS NULL ORDER BY updated_at DESC;`
 
 	var inventories []model.Inventory
 
@@ -129,7 +145,7 @@ func RetrieveAssetsAndSummary(user string, userID string) (model.AssetsAndSummar
 
 	for rows.Next() {
 		var i model.Inventory
-		if err := rows.Scan(&i.Name, &i.Description, &i.Price, &i.Quantity, &i.BoughtAt); err != nil {
+		if err := rows.Scan(&i.Name, &i.Description, &i.Price, &i.Currency, &i.Quantity, &i.BoughtAt); err != nil {
 			config.Log("unable to retrieve asset details in summary", err)
 			return model.AssetsAndSummaryResponse{AssetSummaryList: []model.AssetSummary{}, AssetList: []model.Inventory{}}, err
 		}
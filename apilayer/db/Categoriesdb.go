@@ -42,6 +42,7 @@ func RetrieveAllCategories(user string, userID string, limit int) ([]model.Categ
 	LEFT JOIN community.profiles cp on cp.id = c.created_by
 	LEFT JOIN community.profiles up on up.id = c.updated_by
 	WHERE $1::UUID = ANY(c.sharable_groups)
+	AND c.deleted_at IS NULL
 	ORDER BY c.updated_at DESC
 	LIMIT $2;`
 
@@ -124,7 +125,7 @@ func RetrieveAllCategoryItems(user string, userID string, categoryID string, lim
 	LEFT JOIN community.inventory i ON ci.item_id = i.id
 	LEFT JOIN community.profiles cp ON ci.created_by = cp.id
 	LEFT JOIN community.profiles up ON ci.updated_by = up.id
-	WHERE $1::UUID = ANY(ci.sharable_groups) AND ci.category_id = $2
+	WHERE $1::UUID = ANY(ci.sharable_groups) AND ci.category_id = $2 AND i.deleted_at IS NULL
 	ORDER BY ci.updated_at DESC FETCH FIRST $3 ROWS ONLY;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -332,6 +333,8 @@ func UpdateCategory(user string, draftCategory *model.Category) (*model.Category
 }
 
 // RemoveCategory ...
+//
+// RemoveCategory moves the selected category to the trash
 func RemoveCategory(user string, categoryID string) error {
 	db, err := SetupDB(user)
 	if err != nil {
@@ -339,7 +342,7 @@ func RemoveCategory(user string, categoryID string) error {
 	}
 	defer db.Close()
 
-	sqlStr := `DELETE FROM community.category WHERE id=$1;`
+	sqlStr := `UPDATE community.category SET deleted_at = NOW() WHERE id=$1 AND deleted_at IS NULL;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
 	_, err = db.Exec(sqlStr, categoryID)
@@ -380,7 +383,7 @@ func retrieveCategoryByID(user string, userID string, categoryID string) (model.
 	LEFT JOIN community.statuses s on s.id = c.status
 	LEFT JOIN community.profiles cp on cp.id = c.created_by
 	LEFT JOIN community.profiles up on up.id = c.updated_by
-	WHERE c.id = $2 AND $1::UUID = ANY(c.sharable_groups)
+	WHERE c.id = $2 AND $1::UUID = ANY(c.sharable_groups) AND c.deleted_at IS NULL
 	ORDER BY c.updated_at DESC;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -487,7 +490,7 @@ func AddAssetToCategory(user string, draftCategory *model.CategoryItemRequest) (
 	LEFT JOIN community.inventory i ON ci.item_id = i.id
 	LEFT JOIN community.profiles cp ON ci.created_by = cp.id
 	LEFT JOIN community.profiles up ON ci.updated_by = up.id
-	WHERE $1::UUID = ANY(ci.sharable_groups) AND ci.category_id = $2
+	WHERE $1::UUID = ANY(ci.sharable_groups) AND ci.category_id = $2 AND i.deleted_at IS NULL
 	ORDER BY ci.updated_at DESC;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
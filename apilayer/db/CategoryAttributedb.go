@@ -0,0 +1,536 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	CategoryAttributeTypeText    = "text"
+	CategoryAttributeTypeNumber  = "number"
+	CategoryAttributeTypeDate    = "date"
+	CategoryAttributeTypeEnum    = "enum"
+	CategoryAttributeTypeBoolean = "boolean"
+
+	InvalidCategoryAttribute   = "invalid category attribute"
+	DuplicateCategoryAttribute = "category attribute already exists"
+	InvalidCustomAttributes    = "invalid custom attributes"
+	InvalidAttributeFilter     = "invalid attribute filter"
+
+	AttributeFilterOperatorEqual = "eq"
+	AttributeFilterOperatorMin   = "min"
+	AttributeFilterOperatorMax   = "max"
+)
+
+// categoryAttributeNamePattern is the pattern that the name of each attribute must match. Names are used as keys
+// in the custom attributes of an asset and in the list filters, so they are limited to lowercase snake case.
+var categoryAttributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// RetrieveCategoryAttributes ...
+func RetrieveCategoryAttributes(user string, userID string, categoryID string) ([]model.CategoryAttribute, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveCategoryAttributes(tx, " AND ca.category_id = $2", userID, categoryID)
+	if err != nil {
+		config.Log("unable to retrieve attributes for selected category", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return make([]model.CategoryAttribute, 0), nil
+	}
+	return data, nil
+}
+
+// AddCategoryAttribute ...
+//
+// AddCategoryAttribute adds a new custom field to the selected category. The attribute is shared with the same
+// groups as the category.
+func AddCategoryAttribute(user string, userID string, categoryID string, draftAttribute model.CategoryAttribute) (*model.CategoryAttribute, error) {
+	if err := ValidateCategoryAttribute(draftAttribute); err != nil {
+		config.Log("unable to validate category attribute", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.category_attributes (category_id, name, label, field_type, is_required, options, sort_order, created_at, created_by, updated_at, updated_by, sharable_groups)
+		SELECT c.id, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $1, $9, $1, c.sharable_groups
+		FROM community.category c
+		WHERE c.id = $2
+		AND $1::UUID = ANY(c.sharable_groups)
+		AND c.deleted_at IS NULL
+		RETURNING id;`
+
+	var attributeID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(
+		sqlStr,
+		userID,
+		categoryID,
+		draftAttribute.Name,
+		draftAttribute.Label,
+		draftAttribute.FieldType,
+		draftAttribute.IsRequired,
+		pq.Array(categoryAttributeOptions(draftAttribute)),
+		draftAttribute.SortOrder,
+		time.Now(),
+	).Scan(&attributeID)
+	if err != nil {
+		config.Log("unable to add attribute for selected category", err)
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolationErrorCode {
+			return nil, errors.New(DuplicateCategoryAttribute)
+		}
+		return nil, err
+	}
+
+	data, err := retrieveCategoryAttributes(tx, " AND ca.id = $2", userID, attributeID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected attribute", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// UpdateCategoryAttribute ...
+//
+// UpdateCategoryAttribute updates the rules of the selected attribute. The name of the attribute cannot be changed
+// since the values stored in the assets are keyed by the name.
+func UpdateCategoryAttribute(user string, userID string, categoryID string, attributeID string, draftAttribute model.CategoryAttribute) (*model.CategoryAttribute, error) {
+	if err := validateCategoryAttributeRules(draftAttribute); err != nil {
+		config.Log("unable to validate category attribute", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.category_attributes ca
+		SET label = NULLIF($4, ''),
+			field_type = $5,
+			is_required = $6,
+			options = $7,
+			sort_order = $8,
+			updated_at = $9,
+			updated_by = $1
+		WHERE ca.id = $3
+		AND ca.category_id = $2
+		AND $1::UUID = ANY(ca.sharable_groups)
+		RETURNING ca.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(
+		sqlStr,
+		userID,
+		categoryID,
+		attributeID,
+		draftAttribute.Label,
+		draftAttribute.FieldType,
+		draftAttribute.IsRequired,
+		pq.Array(categoryAttributeOptions(draftAttribute)),
+		draftAttribute.SortOrder,
+		time.Now(),
+	).Scan(&attributeID)
+	if err != nil {
+		config.Log("unable to update selected attribute", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveCategoryAttributes(tx, " AND ca.id = $2", userID, attributeID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected attribute", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// RemoveCategoryAttribute ...
+//
+// RemoveCategoryAttribute removes the selected attribute from the category. Values already stored in the assets are retained.
+func RemoveCategoryAttribute(user string, userID string, categoryID string, attributeID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.category_attributes ca
+		WHERE ca.id = $3
+		AND ca.category_id = $2
+		AND $1::UUID = ANY(ca.sharable_groups)
+		RETURNING ca.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, userID, categoryID, attributeID).Scan(&attributeID)
+	if err != nil {
+		config.Log("unable to remove selected attribute", err)
+		return err
+	}
+	return nil
+}
+
+// ValidateCategoryAttribute ...
+//
+// ValidateCategoryAttribute ensures that the selected attribute has a valid name and type. Enum attributes
+// require at least one option.
+func ValidateCategoryAttribute(draftAttribute model.CategoryAttribute) error {
+	if !categoryAttributeNamePattern.MatchString(draftAttribute.Name) {
+		return errors.New(InvalidCategoryAttribute)
+	}
+	return validateCategoryAttributeRules(draftAttribute)
+}
+
+// validateCategoryAttributeRules ...
+//
+// validateCategoryAttributeRules ensures that the type of the selected attribute is supported and that enum
+// attributes hold at least one option
+func validateCategoryAttributeRules(draftAttribute model.CategoryAttribute) error {
+	switch draftAttribute.FieldType {
+	case CategoryAttributeTypeText, CategoryAttributeTypeNumber, CategoryAttributeTypeDate, CategoryAttributeTypeBoolean:
+		return nil
+	case CategoryAttributeTypeEnum:
+		if len(draftAttribute.Options) == 0 {
+			return errors.New(InvalidCategoryAttribute)
+		}
+		for _, v := range draftAttribute.Options {
+			if len(v) <= 0 {
+				return errors.New(InvalidCategoryAttribute)
+			}
+		}
+		return nil
+	}
+	return errors.New(InvalidCategoryAttribute)
+}
+
+// ValidateCustomAttributes ...
+//
+// ValidateCustomAttributes ensures that the custom attributes of an asset satisfy the attributes of every category
+// that the asset belongs to. Required attributes must be present and every value must match the type of the attribute.
+// Values without a matching attribute are rejected unless they are already stored in the asset, since removed
+// attributes and categories that the asset left retain their values.
+func ValidateCustomAttributes(attributes []model.CategoryAttribute, customAttributes map[string]interface{}, storedAttributes map[string]interface{}) error {
+	knownAttributes := make(map[string]bool)
+	for _, attribute := range attributes {
+		knownAttributes[attribute.Name] = true
+
+		value, ok := customAttributes[attribute.Name]
+		if !ok || value == nil {
+			if attribute.IsRequired {
+				config.Log("missing required attribute %s", nil, attribute.Name)
+				return errors.New(InvalidCustomAttributes)
+			}
+			continue
+		}
+
+		if !isValidCustomAttributeValue(attribute, value) {
+			config.Log("invalid value for attribute %s", nil, attribute.Name)
+			return errors.New(InvalidCustomAttributes)
+		}
+	}
+
+	for name, value := range customAttributes {
+		storedValue, isStored := storedAttributes[name]
+		if !knownAttributes[name] && !(isStored && reflect.DeepEqual(storedValue, value)) {
+			config.Log("unknown attribute %s", nil, name)
+			return errors.New(InvalidCustomAttributes)
+		}
+	}
+	return nil
+}
+
+// validateInventoryCustomAttributes ...
+//
+// validateInventoryCustomAttributes validates the custom attributes of the selected asset against the attributes of the
+// categories that the asset belongs to along with the selected categories. invID is empty for new assets and
+// storedAttributes are the custom attributes already stored in the asset.
+func validateInventoryCustomAttributes(tx *sql.Tx, userID string, invID string, categoryIDs []string, customAttributes map[string]interface{}, storedAttributes map[string]interface{}) error {
+	additionalWhereClause := ` AND ca.category_id IN (
+		SELECT c.id FROM community.category c
+		WHERE c.deleted_at IS NULL
+		AND (c.id = ANY($2::UUID[]) OR c.id IN (SELECT ci.category_id FROM community.category_item ci WHERE ci.item_id = NULLIF($3, '')::UUID))
+	)`
+
+	attributes, err := retrieveCategoryAttributes(tx, additionalWhereClause, userID, pq.Array(categoryIDs), invID)
+	if err != nil {
+		config.Log("unable to retrieve attributes for selected asset", err)
+		return err
+	}
+	return ValidateCustomAttributes(attributes, customAttributes, storedAttributes)
+}
+
+// ValidateAttributeFilter ...
+//
+// ValidateAttributeFilter ensures that the selected filter can be applied to the list of inventories. min and max
+// filters require a number or a date in YYYY-MM-DD format.
+func ValidateAttributeFilter(filter model.InventoryAttributeFilter) error {
+	if !categoryAttributeNamePattern.MatchString(filter.Name) {
+		return errors.New(InvalidAttributeFilter)
+	}
+
+	switch filter.Operator {
+	case AttributeFilterOperatorEqual:
+		return nil
+	case AttributeFilterOperatorMin, AttributeFilterOperatorMax:
+		if _, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+			return nil
+		}
+		if _, err := time.Parse(time.DateOnly, filter.Value); err == nil {
+			return nil
+		}
+	}
+	return errors.New(InvalidAttributeFilter)
+}
+
+// buildInventoryAttributeWhereClause ...
+//
+// builds the additional where clause for the selected attribute filter. Numbers are compared numerically and
+// dates are compared as YYYY-MM-DD strings. Values of a different json type never match a min or max filter.
+func buildInventoryAttributeWhereClause(filter model.InventoryAttributeFilter, params *[]interface{}) (string, error) {
+	if err := ValidateAttributeFilter(filter); err != nil {
+		return "", err
+	}
+
+	*params = append(*params, filter.Name, filter.Value)
+	namePlaceholder, valuePlaceholder := len(*params)-1, len(*params)
+
+	if filter.Operator == AttributeFilterOperatorEqual {
+		return fmt.Sprintf(" AND inv.custom_attributes ->> $%d::TEXT = $%d", namePlaceholder, valuePlaceholder), nil
+	}
+
+	comparator := ">="
+	if filter.Operator == AttributeFilterOperatorMax {
+		comparator = "<="
+	}
+
+	if _, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+		return fmt.Sprintf(" AND (CASE WHEN jsonb_typeof(inv.custom_attributes -> $%d::TEXT) = 'number' THEN (inv.custom_attributes ->> $%d::TEXT)::NUMERIC END) %s $%d::NUMERIC", namePlaceholder, namePlaceholder, comparator, valuePlaceholder), nil
+	}
+	return fmt.Sprintf(" AND (CASE WHEN jsonb_typeof(inv.custom_attributes -> $%d::TEXT) = 'string' THEN inv.custom_attributes ->> $%d::TEXT END) %s $%d", namePlaceholder, namePlaceholder, comparator, valuePlaceholder), nil
+}
+
+// addInventoryToCategories ...
+//
+// addInventoryToCategories associates the selected asset with the selected categories. Categories that the asset
+// already belongs to are skipped. The association is shared with the same groups as the category.
+func addInventoryToCategories(tx *sql.Tx, userID string, invID string, categoryIDs []string) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	sqlStr := `INSERT INTO community.category_item (category_id, item_id, created_by, created_at, updated_by, updated_at, sharable_groups)
+		SELECT c.id, $2, $3, $4, $3, $4, c.sharable_groups
+		FROM community.category c
+		WHERE c.id = ANY($1::UUID[])
+		AND $3::UUID = ANY(c.sharable_groups)
+		AND c.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM community.category_item ci WHERE ci.category_id = c.id AND ci.item_id = $2);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err := tx.Exec(sqlStr, pq.Array(categoryIDs), invID, userID, time.Now())
+	if err != nil {
+		config.Log("unable to add selected asset to categories", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveCategoryAttributes ...
+//
+// retrieveCategoryAttributes returns the attributes that are visible to the selected user sorted by the sort order.
+// userID is always $1.
+func retrieveCategoryAttributes(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.CategoryAttribute, error) {
+	sqlStr := `SELECT
+		ca.id,
+		ca.category_id,
+		ca.name,
+		COALESCE(ca.label, ''),
+		ca.field_type,
+		ca.is_required,
+		ca.options,
+		ca.sort_order,
+		ca.created_at,
+		COALESCE(ca.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		ca.updated_at,
+		COALESCE(ca.updated_by::TEXT, ''),
+		COALESCE(up.username, up.full_name, up.email_address, '') AS updator,
+		ca.sharable_groups
+	FROM community.category_attributes ca
+	LEFT JOIN community.profiles cp ON cp.id = ca.created_by
+	LEFT JOIN community.profiles up ON up.id = ca.updated_by
+	WHERE $1::UUID = ANY(ca.sharable_groups)` + additionalWhereClause + `
+	ORDER BY ca.sort_order, ca.name;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []model.CategoryAttribute
+	for rows.Next() {
+		var attribute model.CategoryAttribute
+		if err := rows.Scan(
+			&attribute.ID,
+			&attribute.CategoryID,
+			&attribute.Name,
+			&attribute.Label,
+			&attribute.FieldType,
+			&attribute.IsRequired,
+			pq.Array(&attribute.Options),
+			&attribute.SortOrder,
+			&attribute.CreatedAt,
+			&attribute.CreatedBy,
+			&attribute.Creator,
+			&attribute.UpdatedAt,
+			&attribute.UpdatedBy,
+			&attribute.Updator,
+			pq.Array(&attribute.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+		data = append(data, attribute)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// isValidCustomAttributeValue ...
+//
+// isValidCustomAttributeValue function is used to determine if the decoded json value matches the type of the attribute.
+// Dates are expected in YYYY-MM-DD format.
+func isValidCustomAttributeValue(attribute model.CategoryAttribute, value interface{}) bool {
+	switch attribute.FieldType {
+	case CategoryAttributeTypeText:
+		_, ok := value.(string)
+		return ok
+	case CategoryAttributeTypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case CategoryAttributeTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case CategoryAttributeTypeDate:
+		draftDate, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse(time.DateOnly, draftDate)
+		return err == nil
+	case CategoryAttributeTypeEnum:
+		draftOption, ok := value.(string)
+		if !ok {
+			return false
+		}
+		for _, v := range attribute.Options {
+			if v == draftOption {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// categoryAttributeOptions ...
+//
+// categoryAttributeOptions returns the options of the selected attribute. Only enum attributes hold options.
+func categoryAttributeOptions(draftAttribute model.CategoryAttribute) []string {
+	if draftAttribute.FieldType != CategoryAttributeTypeEnum {
+		return []string{}
+	}
+	return draftAttribute.Options
+}
+
+// formatCustomAttributes ...
+//
+// formatCustomAttributes returns the json representation of the custom attributes stored against the asset
+func formatCustomAttributes(customAttributes map[string]interface{}) ([]byte, error) {
+	if customAttributes == nil {
+		customAttributes = make(map[string]interface{})
+	}
+	return json.Marshal(customAttributes)
+}
+
+// parseCustomAttributes ...
+//
+// parseCustomAttributes returns the custom attributes stored against the asset. Malformed values are logged and skipped.
+func parseCustomAttributes(data []byte) map[string]interface{} {
+	customAttributes := make(map[string]interface{})
+	if len(data) == 0 {
+		return customAttributes
+	}
+	if err := json.Unmarshal(data, &customAttributes); err != nil {
+		config.Log("unable to parse custom attributes", err)
+	}
+	return customAttributes
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+)
+
+const (
+	DepreciationMethodStraightLine     = "straight_line"
+	DepreciationMethodDecliningBalance = "declining_balance"
+
+	InvalidDepreciation = "invalid depreciation details"
+
+	// decliningBalanceFactor is applied to the straight line rate. 2 is the double declining balance method.
+	decliningBalanceFactor = 2.0
+)
+
+// RetrieveInventoryDepreciation ...
+//
+// RetrieveInventoryDepreciation returns the book value of the selected asset at the selected time along with
+// the full depreciation schedule of the asset.
+func RetrieveInventoryDepreciation(user string, userID string, invID string, asOf time.Time) (*model.Depreciation, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inventory, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	depreciation := BuildDepreciation(*inventory, asOf)
+	return &depreciation, nil
+}
+
+// ValidateDepreciation ...
+//
+// ValidateDepreciation ensures that the depreciation details of the selected asset are complete. Assets without
+// a depreciation method are not depreciated and are always valid.
+func ValidateDepreciation(draftInventory model.Inventory) error {
+	if draftInventory.SalvageValue < 0 || draftInventory.UsefulLifeYears < 0 {
+		return errors.New(InvalidDepreciation)
+	}
+	if len(draftInventory.DepreciationMethod) <= 0 {
+		return nil
+	}
+	if draftInventory.DepreciationMethod != DepreciationMethodStraightLine && draftInventory.DepreciationMethod != DepreciationMethodDecliningBalance {
+		return errors.New(InvalidDepreciation)
+	}
+	if draftInventory.UsefulLifeYears <= 0 || draftInventory.PurchaseDate == nil || draftInventory.SalvageValue > draftInventory.Price {
+		return errors.New(InvalidDepreciation)
+	}
+	return nil
+}
+
+// BuildDepreciation ...
+//
+// BuildDepreciation returns the book value of the selected asset at the selected time along with the full depreciation schedule
+func BuildDepreciation(inventory model.Inventory, asOf time.Time) model.Depreciation {
+	bookValue := ComputeBookValue(inventory, asOf)
+	return model.Depreciation{
+		ItemID:                  inventory.ID,
+		Method:                  inventory.DepreciationMethod,
+		PurchaseCost:            inventory.Price,
+		SalvageValue:            inventory.SalvageValue,
+		UsefulLifeYears:         inventory.UsefulLifeYears,
+		PurchaseDate:            inventory.PurchaseDate,
+		AsOf:                    asOf,
+		BookValue:               bookValue,
+		AccumulatedDepreciation: roundCurrency(inventory.Price - bookValue),
+		Schedule:                BuildDepreciationSchedule(inventory),
+	}
+}
+
+// BuildDepreciationSchedule ...
+//
+// BuildDepreciationSchedule returns the yearly depreciation of the selected asset starting from the purchase date.
+// Declining balance never depreciates below the salvage value and the final year depreciates the remaining amount
+// so that every schedule closes at the salvage value.
+func BuildDepreciationSchedule(inventory model.Inventory) []model.DepreciationPeriod {
+	schedule := []model.DepreciationPeriod{}
+	if !isDepreciable(inventory) {
+		return schedule
+	}
+
+	salvageValue := math.Min(inventory.SalvageValue, inventory.Price)
+	openingBookValue := inventory.Price
+	accumulatedDepreciation := 0.0
+
+	for year := 1; year <= inventory.UsefulLifeYears; year++ {
+		var depreciation float64
+		switch inventory.DepreciationMethod {
+		case DepreciationMethodStraightLine:
+			depreciation = (inventory.Price - salvageValue) / float64(inventory.UsefulLifeYears)
+		case DepreciationMethodDecliningBalance:
+			depreciation = openingBookValue * decliningBalanceFactor / float64(inventory.UsefulLifeYears)
+		}
+
+		if year == inventory.UsefulLifeYears || openingBookValue-depreciation < salvageValue {
+			depreciation = openingBookValue - salvageValue
+		}
+		depreciation = roundCurrency(math.Max(depreciation, 0))
+		accumulatedDepreciation = roundCurrency(accumulatedDepreciation + depreciation)
+		closingBookValue := roundCurrency(openingBookValue - depreciation)
+
+		schedule = append(schedule, model.DepreciationPeriod{
+			Year:                    year,
+			StartDate:               inventory.PurchaseDate.AddDate(year-1, 0, 0),
+			EndDate:                 inventory.PurchaseDate.AddDate(year, 0, 0),
+			OpeningBookValue:        roundCurrency(openingBookValue),
+			Depreciation:            depreciation,
+			AccumulatedDepreciation: accumulatedDepreciation,
+			ClosingBookValue:        closingBookValue,
+		})
+		openingBookValue = closingBookValue
+	}
+
+	return schedule
+}
+
+// ComputeBookValue ...
+//
+// ComputeBookValue returns the book value of the selected asset at the selected time. Depreciation within
+// a year of the schedule is prorated by the time elapsed in that year.
+func ComputeBookValue(inventory model.Inventory, asOf time.Time) float64 {
+	if !isDepreciable(inventory) || asOf.Before(*inventory.PurchaseDate) {
+		return roundCurrency(inventory.Price)
+	}
+
+	schedule := BuildDepreciationSchedule(inventory)
+	for _, period := range schedule {
+		if asOf.Before(period.EndDate) {
+			elapsed := asOf.Sub(period.StartDate).Hours() / period.EndDate.Sub(period.StartDate).Hours()
+			return roundCurrency(period.OpeningBookValue - period.Depreciation*elapsed)
+		}
+	}
+	return schedule[len(schedule)-1].ClosingBookValue
+}
+
+// isDepreciable ...
+//
+// isDepreciable function is used to determine if the selected asset has enough details to be depreciated
+func isDepreciable(inventory model.Inventory) bool {
+	return len(inventory.DepreciationMethod) > 0 && inventory.UsefulLifeYears > 0 && inventory.PurchaseDate != nil
+}
+
+// roundCurrency ...
+//
+// roundCurrency rounds the selected amount to the nearest cent
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// applyDepreciation ...
+//
+// applyDepreciation sets the nullable depreciation details of the selected asset and computes the current book value
+func applyDepreciation(inventory *model.Inventory, purchaseDate sql.NullTime, usefulLifeYears sql.NullInt64, depreciationMethod sql.NullString) {
+	if purchaseDate.Valid {
+		inventory.PurchaseDate = &purchaseDate.Time
+	}
+	if usefulLifeYears.Valid {
+		inventory.UsefulLifeYears = int(usefulLifeYears.Int64)
+	}
+	if depreciationMethod.Valid {
+		inventory.DepreciationMethod = depreciationMethod.String
+	}
+	inventory.BookValue = ComputeBookValue(*inventory, time.Now())
+}
+
+// retainDepreciation ...
+//
+// retainDepreciation keeps the stored depreciation details of the selected asset that are not sent by the client
+func retainDepreciation(draftInventory *model.Inventory, currentInventory model.Inventory) {
+	if draftInventory.PurchaseDate == nil {
+		draftInventory.PurchaseDate = currentInventory.PurchaseDate
+	}
+	if draftInventory.UsefulLifeYears == 0 {
+		draftInventory.UsefulLifeYears = currentInventory.UsefulLifeYears
+	}
+	if draftInventory.SalvageValue == 0 {
+		draftInventory.SalvageValue = currentInventory.SalvageValue
+	}
+	if len(draftInventory.DepreciationMethod) == 0 {
+		draftInventory.DepreciationMethod = currentInventory.DepreciationMethod
+	}
+}
@@ -0,0 +1,372 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidCurrency      = "invalid currency"
+	InvalidExchangeRate  = "invalid exchange rate"
+	TooManyExchangeRates = "too many exchange rates selected"
+
+	// MaxExchangeRatesPerRequest is the maximum number of exchange rates that can be loaded in a single request
+	MaxExchangeRatesPerRequest = 5000
+
+	exchangeRateDateLayout = "2006-01-02"
+)
+
+// currencyRegex matches the three letter ISO 4217 code of the currency, eg USD
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// exchangeRateCSVColumns are the accepted headers of each column in the exchange rate csv file
+var exchangeRateCSVColumns = map[string][]string{
+	"from_currency":  {"from_currency", "from", "base"},
+	"to_currency":    {"to_currency", "to", "quote"},
+	"rate":           {"rate"},
+	"effective_date": {"effective_date", "date"},
+}
+
+// RetrieveExchangeRates ...
+//
+// RetrieveExchangeRates returns the exchange rates visible to the user with the most recent rate first. The rates
+// can be narrowed down to the selected from and to currency.
+func RetrieveExchangeRates(user string, userID string, fromCurrency string, toCurrency string) ([]model.ExchangeRate, error) {
+	fromCurrency, err := normalizeCurrency(fromCurrency)
+	if err != nil {
+		config.Log("unable to validate from currency", err)
+		return nil, err
+	}
+	toCurrency, err = normalizeCurrency(toCurrency)
+	if err != nil {
+		config.Log("unable to validate to currency", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT
+			er.id,
+			er.from_currency,
+			er.to_currency,
+			er.rate,
+			er.effective_date,
+			er.created_at,
+			COALESCE(er.created_by::TEXT, ''),
+			er.updated_at,
+			COALESCE(er.updated_by::TEXT, ''),
+			er.sharable_groups
+		FROM community.exchange_rates er
+		WHERE $1::UUID = ANY(er.sharable_groups)
+		AND ($2 = '' OR er.from_currency = $2)
+		AND ($3 = '' OR er.to_currency = $3)
+		ORDER BY er.effective_date DESC, er.from_currency, er.to_currency;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, fromCurrency, toCurrency)
+	if err != nil {
+		config.Log("unable to query selected exchange rates", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.ExchangeRate, 0)
+	for rows.Next() {
+		var exchangeRate model.ExchangeRate
+		if err := rows.Scan(
+			&exchangeRate.ID,
+			&exchangeRate.FromCurrency,
+			&exchangeRate.ToCurrency,
+			&exchangeRate.Rate,
+			&exchangeRate.EffectiveDate,
+			&exchangeRate.CreatedAt,
+			&exchangeRate.CreatedBy,
+			&exchangeRate.UpdatedAt,
+			&exchangeRate.UpdatedBy,
+			pq.Array(&exchangeRate.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected exchange rates", err)
+			return nil, err
+		}
+		data = append(data, exchangeRate)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddExchangeRates ...
+//
+// AddExchangeRates saves the selected exchange rates in a single transaction. A rate for the same currencies on the
+// same effective date replaces the existing rate, so the same file can be loaded more than once.
+func AddExchangeRates(user string, userID string, draftExchangeRates []model.ExchangeRate) ([]model.ExchangeRate, error) {
+	if len(draftExchangeRates) == 0 {
+		config.Log("unable to add empty exchange rates", errors.New(InvalidExchangeRate))
+		return nil, errors.New(InvalidExchangeRate)
+	}
+	if len(draftExchangeRates) > MaxExchangeRatesPerRequest {
+		config.Log("unable to add selected exchange rates", errors.New(TooManyExchangeRates))
+		return nil, errors.New(TooManyExchangeRates)
+	}
+
+	for i := range draftExchangeRates {
+		if err := validateExchangeRate(&draftExchangeRates[i]); err != nil {
+			config.Log("unable to validate exchange rate", err)
+			return nil, err
+		}
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.exchange_rates (from_currency, to_currency, rate, effective_date, created_at, created_by, updated_at, updated_by, sharable_groups)
+		VALUES ($1, $2, $3, $4, $5, $6, $5, $6, $7)
+		ON CONFLICT (created_by, from_currency, to_currency, effective_date)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by
+		RETURNING id, created_at, COALESCE(created_by::TEXT, ''), updated_at, COALESCE(updated_by::TEXT, ''), sharable_groups;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	stmt, err := tx.Prepare(sqlStr)
+	if err != nil {
+		config.Log("unable to prepare selected exchange rates", err)
+		tx.Rollback()
+		return nil, err
+	}
+	defer stmt.Close()
+
+	currentTimestamp := time.Now()
+	for i := range draftExchangeRates {
+		draftExchangeRate := &draftExchangeRates[i]
+		err := stmt.QueryRow(
+			draftExchangeRate.FromCurrency,
+			draftExchangeRate.ToCurrency,
+			draftExchangeRate.Rate,
+			draftExchangeRate.EffectiveDate,
+			currentTimestamp,
+			userID,
+			pq.Array([]string{userID}),
+		).Scan(
+			&draftExchangeRate.ID,
+			&draftExchangeRate.CreatedAt,
+			&draftExchangeRate.CreatedBy,
+			&draftExchangeRate.UpdatedAt,
+			&draftExchangeRate.UpdatedBy,
+			pq.Array(&draftExchangeRate.SharableGroups),
+		)
+		if err != nil {
+			config.Log("unable to add selected exchange rate", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return draftExchangeRates, nil
+}
+
+// RemoveExchangeRate ...
+func RemoveExchangeRate(user string, userID string, exchangeRateID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.exchange_rates er WHERE er.id = $1 AND $2::UUID = ANY(er.sharable_groups);`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	result, err := db.Exec(sqlStr, exchangeRateID, userID)
+	if err != nil {
+		config.Log("unable to remove selected exchange rate", err)
+		return err
+	}
+
+	removedRows, err := result.RowsAffected()
+	if err != nil {
+		config.Log("unable to retrieve removed exchange rates", err)
+		return err
+	}
+	if removedRows == 0 {
+		config.Log("unable to find selected exchange rate", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ParseExchangeRatesCSV ...
+//
+// ParseExchangeRatesCSV reads the exchange rates from the selected csv file. The first row is the header and must
+// contain the from_currency, to_currency, rate and effective_date columns in any order. The effective date is
+// either a date, eg 2024-01-31, or a timestamp in RFC3339 format.
+func ParseExchangeRatesCSV(reader io.Reader) ([]model.ExchangeRate, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		config.Log("unable to read header of exchange rates", err)
+		return nil, errors.New(InvalidExchangeRate)
+	}
+
+	columnIndexes := make(map[string]int)
+	for index, draftColumn := range header {
+		draftColumn = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(draftColumn, "\ufeff")))
+		for column, aliases := range exchangeRateCSVColumns {
+			for _, alias := range aliases {
+				if draftColumn == alias {
+					columnIndexes[column] = index
+				}
+			}
+		}
+	}
+	for column := range exchangeRateCSVColumns {
+		if _, ok := columnIndexes[column]; !ok {
+			return nil, fmt.Errorf("%s: missing %s column", InvalidExchangeRate, column)
+		}
+	}
+
+	data := make([]model.ExchangeRate, 0)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			config.Log("unable to read exchange rates", err)
+			return nil, fmt.Errorf("%s: %v", InvalidExchangeRate, err)
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[columnIndexes["rate"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s on line %d", InvalidExchangeRate, line)
+		}
+
+		effectiveDate, err := parseExchangeRateDate(record[columnIndexes["effective_date"]])
+		if err != nil {
+			return nil, fmt.Errorf("%s on line %d", InvalidExchangeRate, line)
+		}
+
+		draftExchangeRate := model.ExchangeRate{
+			FromCurrency:  record[columnIndexes["from_currency"]],
+			ToCurrency:    record[columnIndexes["to_currency"]],
+			Rate:          rate,
+			EffectiveDate: effectiveDate,
+		}
+		if err := validateExchangeRate(&draftExchangeRate); err != nil {
+			return nil, fmt.Errorf("%s on line %d", err.Error(), line)
+		}
+		data = append(data, draftExchangeRate)
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New(InvalidExchangeRate)
+	}
+	return data, nil
+}
+
+// validateExchangeRate ...
+//
+// validateExchangeRate validates the selected exchange rate and normalizes the currencies into upper case
+func validateExchangeRate(draftExchangeRate *model.ExchangeRate) error {
+	fromCurrency, err := normalizeCurrency(draftExchangeRate.FromCurrency)
+	if err != nil || len(fromCurrency) == 0 {
+		return errors.New(InvalidCurrency)
+	}
+	toCurrency, err := normalizeCurrency(draftExchangeRate.ToCurrency)
+	if err != nil || len(toCurrency) == 0 {
+		return errors.New(InvalidCurrency)
+	}
+	if fromCurrency == toCurrency || draftExchangeRate.Rate <= 0 || draftExchangeRate.EffectiveDate.IsZero() {
+		return errors.New(InvalidExchangeRate)
+	}
+
+	draftExchangeRate.FromCurrency = fromCurrency
+	draftExchangeRate.ToCurrency = toCurrency
+	return nil
+}
+
+// parseExchangeRateDate ...
+//
+// parseExchangeRateDate parses the effective date of the exchange rate as a date or as a timestamp
+func parseExchangeRateDate(draftDate string) (time.Time, error) {
+	draftDate = strings.TrimSpace(draftDate)
+	if parsedDate, err := time.Parse(exchangeRateDateLayout, draftDate); err == nil {
+		return parsedDate, nil
+	}
+	return time.Parse(time.RFC3339, draftDate)
+}
+
+// normalizeCurrency ...
+//
+// normalizeCurrency returns the three letter code of the selected currency in upper case. An empty currency is
+// returned as is so that the caller can fall back to the base currency of the user.
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) == 0 {
+		return "", nil
+	}
+	if !currencyRegex.MatchString(currency) {
+		return "", errors.New(InvalidCurrency)
+	}
+	return currency, nil
+}
+
+// exchangeRateSqlStr ...
+//
+// exchangeRateSqlStr returns the sql expression for the rate that converts the price of the selected inventory into
+// the base currency of the user. The rate is the most recent rate that is effective on the purchase date of the
+// inventory, or on the day it was added when the purchase date is unknown. When only the reverse rate is known, the
+// inverse of the reverse rate is used. The expression is NULL when no rate is found.
+func exchangeRateSqlStr(alias string, userIDPlaceholder string) string {
+	return fmt.Sprintf(`(SELECT CASE
+			WHEN %[1]s.currency = bp.base_currency THEN 1
+			ELSE COALESCE(
+				(SELECT er.rate FROM community.exchange_rates er
+					WHERE %[2]s::UUID = ANY(er.sharable_groups)
+					AND er.from_currency = %[1]s.currency
+					AND er.to_currency = bp.base_currency
+					AND er.effective_date <= COALESCE(%[1]s.purchase_date, %[1]s.created_at::DATE)
+					ORDER BY er.effective_date DESC, er.updated_at DESC LIMIT 1),
+				(SELECT 1 / er.rate FROM community.exchange_rates er
+					WHERE %[2]s::UUID = ANY(er.sharable_groups)
+					AND er.from_currency = bp.base_currency
+					AND er.to_currency = %[1]s.currency
+					AND er.effective_date <= COALESCE(%[1]s.purchase_date, %[1]s.created_at::DATE)
+					ORDER BY er.effective_date DESC, er.updated_at DESC LIMIT 1)
+			)
+		END
+		FROM community.profiles bp WHERE bp.id = %[2]s::UUID)`, alias, userIDPlaceholder)
+}
@@ -0,0 +1,305 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	InventoryAttachmentTypeReceipt  = "receipt"
+	InventoryAttachmentTypeManual   = "manual"
+	InventoryAttachmentTypeWarranty = "warranty"
+	InventoryAttachmentTypeInvoice  = "invoice"
+	InventoryAttachmentTypeOther    = "other"
+
+	InvalidAttachmentType = "invalid attachment type"
+
+	// defaultAttachmentContentType is used when the uploaded document does not declare a content type
+	defaultAttachmentContentType = "application/octet-stream"
+)
+
+// InventoryAttachmentTypes ...
+//
+// list of document types that can be attached to an asset
+var InventoryAttachmentTypes = []string{
+	InventoryAttachmentTypeReceipt,
+	InventoryAttachmentTypeManual,
+	InventoryAttachmentTypeWarranty,
+	InventoryAttachmentTypeInvoice,
+	InventoryAttachmentTypeOther,
+}
+
+// RetrieveInventoryAttachments ...
+func RetrieveInventoryAttachments(user string, userID string, invID string) ([]model.InventoryAttachment, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveInventoryAttachments(tx, "", userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve attachments for selected asset", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return make([]model.InventoryAttachment, 0), nil
+	}
+	return data, nil
+}
+
+// AddInventoryAttachment ...
+//
+// AddInventoryAttachment stores the metadata of the selected document and uploads the document in the bucket.
+// The metadata is only persisted if the upload is successful.
+func AddInventoryAttachment(user string, userID string, invID string, attachmentType string, file multipart.File, header *multipart.FileHeader) (*model.InventoryAttachment, error) {
+	if !isValidAttachmentType(attachmentType) {
+		config.Log("unable to validate attachment type", errors.New(InvalidAttachmentType))
+		return nil, errors.New(InvalidAttachmentType)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if len(contentType) <= 0 {
+		contentType = defaultAttachmentContentType
+	}
+
+	attachmentID := uuid.New().String()
+	objectKey := fmt.Sprintf("attachments/%s/%s", invID, attachmentID)
+
+	sqlStr := `INSERT INTO community.inventory_attachments (id, item_id, attachment_type, file_name, content_type, size_in_bytes, object_key, created_at, created_by, sharable_groups)
+		SELECT $1, inv.id, $3, $4, $5, $6, $7, $8, $9, inv.sharable_groups
+		FROM community.inventory inv
+		WHERE inv.id = $2
+		AND $9::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, attachmentID, invID, attachmentType, header.Filename, contentType, header.Size, objectKey, time.Now(), userID).Scan(&attachmentID)
+	if err != nil {
+		config.Log("unable to add attachment for selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = bucket.UploadStreamInBucket(objectKey, file, header.Size, contentType)
+	if err != nil {
+		config.Log("unable to upload selected attachment", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveInventoryAttachments(tx, " AND ia.id = $3", userID, invID, attachmentID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected attachment", err)
+		tx.Rollback()
+		bucket.RemoveDocumentFromBucket(objectKey)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		bucket.RemoveDocumentFromBucket(objectKey)
+		return nil, err
+	}
+
+	return &data[0], nil
+}
+
+// RetrieveInventoryAttachmentContent ...
+//
+// RetrieveInventoryAttachmentContent returns the metadata of the selected attachment along with the document from the bucket
+func RetrieveInventoryAttachmentContent(user string, userID string, invID string, attachmentID string) (*model.InventoryAttachment, []byte, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveInventoryAttachments(tx, " AND ia.id = $3", userID, invID, attachmentID)
+	if err != nil {
+		config.Log("unable to retrieve selected attachment", err)
+		return nil, nil, err
+	}
+	if len(data) == 0 {
+		config.Log("unable to find selected attachment", sql.ErrNoRows)
+		return nil, nil, sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, nil, err
+	}
+
+	content, _, _, err := bucket.RetrieveDocumentFromBucket(data[0].ObjectKey)
+	if err != nil {
+		config.Log("unable to retrieve the selected document", err)
+		return nil, nil, err
+	}
+	return &data[0], content, nil
+}
+
+// RemoveInventoryAttachment ...
+//
+// RemoveInventoryAttachment removes the metadata of the selected attachment and the document from the bucket.
+// The metadata is retained if the document cannot be removed.
+func RemoveInventoryAttachment(user string, userID string, invID string, attachmentID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return err
+	}
+
+	sqlStr := `DELETE FROM community.inventory_attachments ia
+		WHERE ia.id = $1
+		AND ia.item_id = $2
+		AND $3::UUID = ANY(ia.sharable_groups)
+		RETURNING ia.object_key;`
+
+	var objectKey string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, attachmentID, invID, userID).Scan(&objectKey)
+	if err != nil {
+		config.Log("unable to remove selected attachment", err)
+		tx.Rollback()
+		return err
+	}
+
+	err = bucket.RemoveDocumentFromBucket(objectKey)
+	if err != nil {
+		config.Log("unable to remove selected document", err)
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveInventoryAttachments ...
+//
+// retrieveInventoryAttachments returns the attachments of the selected asset with the most recent attachment first.
+// userID is always $1 and invID is always $2. Attachments of assets in the trash are not returned.
+func retrieveInventoryAttachments(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryAttachment, error) {
+	sqlStr := `SELECT
+		ia.id,
+		ia.item_id,
+		ia.attachment_type,
+		ia.file_name,
+		ia.content_type,
+		ia.size_in_bytes,
+		ia.object_key,
+		ia.created_at,
+		COALESCE(ia.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		ia.sharable_groups
+	FROM community.inventory_attachments ia
+	JOIN community.inventory inv ON inv.id = ia.item_id AND inv.deleted_at IS NULL
+	LEFT JOIN community.profiles cp ON cp.id = ia.created_by
+	WHERE $1::UUID = ANY(ia.sharable_groups)
+	AND ia.item_id = $2` + additionalWhereClause + `
+	ORDER BY ia.created_at DESC;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []model.InventoryAttachment
+	for rows.Next() {
+		var attachment model.InventoryAttachment
+		if err := rows.Scan(
+			&attachment.ID,
+			&attachment.ItemID,
+			&attachment.AttachmentType,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.SizeInBytes,
+			&attachment.ObjectKey,
+			&attachment.CreatedAt,
+			&attachment.CreatedBy,
+			&attachment.Creator,
+			pq.Array(&attachment.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+		data = append(data, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// isValidAttachmentType ...
+//
+// isValidAttachmentType function is used to determine if the selected attachment type is supported
+func isValidAttachmentType(attachmentType string) bool {
+	for _, v := range InventoryAttachmentTypes {
+		if v == attachmentType {
+			return true
+		}
+	}
+	return false
+}
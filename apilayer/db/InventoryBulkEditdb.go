@@ -0,0 +1,465 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidInventoryBulkEdit = "invalid inventory bulk edit"
+	TooManyInventoriesToEdit = "too many inventories selected for bulk edit"
+
+	InventoryBulkEditOperationSet              = "set"
+	InventoryBulkEditOperationIncrementPercent = "increment_percent"
+	InventoryBulkEditOperationClear            = "clear"
+
+	InventoryBulkEditStatusUpdated    = "updated"
+	InventoryBulkEditStatusFailed     = "failed"
+	InventoryBulkEditStatusRolledBack = "rolled_back"
+
+	// MaxInventoryBulkEditSize is the maximum number of inventories that can be edited in a single request
+	MaxInventoryBulkEditSize = 1000
+
+	inventoryBulkEditFieldText    = "text"
+	inventoryBulkEditFieldNumber  = "number"
+	inventoryBulkEditFieldInteger = "integer"
+	inventoryBulkEditFieldBoolean = "boolean"
+	inventoryBulkEditFieldUUID    = "uuid"
+)
+
+// inventoryBulkEditField ...
+//
+// inventoryBulkEditField describes a column of the inventory that can be bulk edited. clearSqlStr is the value
+// the column is set to when it is cleared, empty if the column cannot be cleared.
+type inventoryBulkEditField struct {
+	fieldType     string
+	maxLength     int
+	clearSqlStr   string
+	isIncremental bool
+}
+
+// inventoryBulkEditFields is the whitelist of columns that can be bulk edited
+var inventoryBulkEditFields = map[string]inventoryBulkEditField{
+	"name":                {fieldType: inventoryBulkEditFieldText, maxLength: 100},
+	"description":         {fieldType: inventoryBulkEditFieldText, maxLength: 500, clearSqlStr: "''"},
+	"status":              {fieldType: inventoryBulkEditFieldText, maxLength: 100, clearSqlStr: "''"},
+	"color":               {fieldType: inventoryBulkEditFieldText, maxLength: 10, clearSqlStr: "NULL"},
+	"bought_at":           {fieldType: inventoryBulkEditFieldText, maxLength: 500, clearSqlStr: "''"},
+	"return_location":     {fieldType: inventoryBulkEditFieldText, maxLength: 200, clearSqlStr: "NULL"},
+	"return_notes":        {fieldType: inventoryBulkEditFieldText, maxLength: 250, clearSqlStr: "NULL"},
+	"price":               {fieldType: inventoryBulkEditFieldNumber, clearSqlStr: "0", isIncremental: true},
+	"quantity":            {fieldType: inventoryBulkEditFieldInteger, clearSqlStr: "0", isIncremental: true},
+	"reorder_point":       {fieldType: inventoryBulkEditFieldInteger, clearSqlStr: "NULL"},
+	"is_returnable":       {fieldType: inventoryBulkEditFieldBoolean, clearSqlStr: "false"},
+	"storage_location_id": {fieldType: inventoryBulkEditFieldUUID},
+}
+
+// BulkEditInventory ...
+//
+// BulkEditInventory applies the selected operations to every selected asset in a single transaction. Assets are
+// selected either by ids or by filter and only assets that are shared with the user can be edited. Every asset is
+// attempted so that the result of each asset can be reported, but changes are applied only if every asset is updated.
+func BulkEditInventory(user string, userID string, draftBulkEdit model.InventoryBulkEditRequest) (*model.InventoryBulkEditResponse, error) {
+	if err := validateInventoryBulkEdit(draftBulkEdit); err != nil {
+		config.Log("unable to validate bulk edit", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	selectedIDs := draftBulkEdit.IDs
+	if draftBulkEdit.Filter != nil {
+		selectedIDs, err = retrieveInventoryIDsForBulkEdit(tx, userID, *draftBulkEdit.Filter)
+		if err != nil {
+			config.Log("unable to retrieve inventories for selected filter", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if len(selectedIDs) > MaxInventoryBulkEditSize {
+		config.Log("unable to bulk edit selected inventories", errors.New(TooManyInventoriesToEdit))
+		tx.Rollback()
+		return nil, errors.New(TooManyInventoriesToEdit)
+	}
+
+	setSqlStr, params, isStorageLocationChanged, err := buildInventoryBulkEditSetClause(draftBulkEdit.Operations)
+	if err != nil {
+		config.Log("unable to build selected operations", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if isStorageLocationChanged {
+		err = validateStorageLocationForBulkEdit(tx, draftBulkEdit.Operations)
+		if err != nil {
+			config.Log("unable to validate selected storage location", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// the quantity of serialized assets is derived from their units, so serialized assets fail the quantity operation
+	isQuantityChanged := false
+	for _, operation := range draftBulkEdit.Operations {
+		if operation.Field == "quantity" {
+			isQuantityChanged = true
+		}
+	}
+
+	params = append(params, userID, time.Now())
+	sqlStr := fmt.Sprintf(`UPDATE community.inventory inv
+		SET %s,
+			updated_by = $%d,
+			updated_at = $%d
+		WHERE inv.id = $1;`, setSqlStr, len(params), len(params)+1)
+
+	resp := model.InventoryBulkEditResponse{
+		Results: make([]model.InventoryBulkEditResult, 0, len(selectedIDs)),
+	}
+
+	for _, invID := range selectedIDs {
+		err := bulkEditSelectedInventory(tx, userID, invID, sqlStr, params, isStorageLocationChanged, isQuantityChanged)
+		if err != nil {
+			config.Log("unable to bulk edit selected inventory %s", err, invID)
+			resp.FailedCount++
+			resp.Results = append(resp.Results, model.InventoryBulkEditResult{
+				ID:     invID,
+				Status: InventoryBulkEditStatusFailed,
+				Error:  toInventoryBulkEditError(err),
+			})
+			continue
+		}
+		resp.UpdatedCount++
+		resp.Results = append(resp.Results, model.InventoryBulkEditResult{
+			ID:     invID,
+			Status: InventoryBulkEditStatusUpdated,
+		})
+	}
+
+	if resp.FailedCount > 0 {
+		tx.Rollback()
+		for i := range resp.Results {
+			if resp.Results[i].Status == InventoryBulkEditStatusUpdated {
+				resp.Results[i].Status = InventoryBulkEditStatusRolledBack
+			}
+		}
+		resp.UpdatedCount = 0
+		return &resp, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	resp.IsApplied = true
+	return &resp, nil
+}
+
+// validateInventoryBulkEdit ...
+//
+// validateInventoryBulkEdit validates the selection and the operations of the bulk edit. Assets are selected either
+// by ids or by filter, and each field can be part of a single operation.
+func validateInventoryBulkEdit(draftBulkEdit model.InventoryBulkEditRequest) error {
+	if (len(draftBulkEdit.IDs) > 0) == (draftBulkEdit.Filter != nil) {
+		return errors.New(InvalidInventoryBulkEdit)
+	}
+
+	if len(draftBulkEdit.IDs) > MaxInventoryBulkEditSize {
+		return errors.New(TooManyInventoriesToEdit)
+	}
+
+	selectedIDs := make(map[string]bool)
+	for _, v := range draftBulkEdit.IDs {
+		if _, err := uuid.Parse(v); err != nil || selectedIDs[v] {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		selectedIDs[v] = true
+	}
+
+	if len(draftBulkEdit.Operations) == 0 {
+		return errors.New(InvalidInventoryBulkEdit)
+	}
+
+	selectedFields := make(map[string]bool)
+	for _, operation := range draftBulkEdit.Operations {
+		if selectedFields[operation.Field] {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		selectedFields[operation.Field] = true
+
+		if err := validateInventoryBulkEditOperation(operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateInventoryBulkEditOperation ...
+//
+// validateInventoryBulkEditOperation validates that the selected operation can be applied to the selected field
+// and that the value matches the type of the field
+func validateInventoryBulkEditOperation(operation model.InventoryBulkEditOperation) error {
+	field, ok := inventoryBulkEditFields[operation.Field]
+	if !ok {
+		return errors.New(InvalidInventoryBulkEdit)
+	}
+
+	switch operation.Operation {
+	case InventoryBulkEditOperationClear:
+		if len(field.clearSqlStr) == 0 || operation.Value != nil {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		return nil
+	case InventoryBulkEditOperationIncrementPercent:
+		percent, ok := operation.Value.(float64)
+		if !field.isIncremental || !ok || percent <= -100 || math.IsInf(percent, 0) {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		return nil
+	case InventoryBulkEditOperationSet:
+	default:
+		return errors.New(InvalidInventoryBulkEdit)
+	}
+
+	switch field.fieldType {
+	case inventoryBulkEditFieldText:
+		value, ok := operation.Value.(string)
+		if !ok || utf8.RuneCountInString(value) > field.maxLength {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		if operation.Field == "name" && len(strings.TrimSpace(value)) == 0 {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+	case inventoryBulkEditFieldNumber:
+		value, ok := operation.Value.(float64)
+		if !ok || value < 0 {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+	case inventoryBulkEditFieldInteger:
+		value, ok := operation.Value.(float64)
+		if !ok || value < 0 || value != math.Trunc(value) || value > math.MaxInt32 {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+	case inventoryBulkEditFieldBoolean:
+		if _, ok := operation.Value.(bool); !ok {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+	case inventoryBulkEditFieldUUID:
+		value, ok := operation.Value.(string)
+		if !ok {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return errors.New(InvalidInventoryBulkEdit)
+		}
+	}
+	return nil
+}
+
+// buildInventoryBulkEditSetClause ...
+//
+// buildInventoryBulkEditSetClause builds the set clause of the selected operations. The id of each asset is
+// always $1, so each returned param is placed one after its position. Returns true if the storage location is changed.
+func buildInventoryBulkEditSetClause(operations []model.InventoryBulkEditOperation) (string, []interface{}, bool, error) {
+	var setClauses []string
+	var params []interface{}
+	isStorageLocationChanged := false
+
+	for _, operation := range operations {
+		field, ok := inventoryBulkEditFields[operation.Field]
+		if !ok {
+			return "", nil, false, errors.New(InvalidInventoryBulkEdit)
+		}
+
+		switch operation.Operation {
+		case InventoryBulkEditOperationClear:
+			setClauses = append(setClauses, fmt.Sprintf("%s = %s", operation.Field, field.clearSqlStr))
+		case InventoryBulkEditOperationIncrementPercent:
+			params = append(params, operation.Value)
+			expression := fmt.Sprintf("COALESCE(inv.%s, 0) * (1 + $%d::NUMERIC / 100)", operation.Field, len(params)+1)
+			if field.fieldType == inventoryBulkEditFieldInteger {
+				setClauses = append(setClauses, fmt.Sprintf("%s = ROUND(%s)::INT", operation.Field, expression))
+			} else {
+				setClauses = append(setClauses, fmt.Sprintf("%s = ROUND(%s, 4)", operation.Field, expression))
+			}
+		case InventoryBulkEditOperationSet:
+			params = append(params, operation.Value)
+			if field.fieldType == inventoryBulkEditFieldUUID {
+				isStorageLocationChanged = true
+				setClauses = append(setClauses, fmt.Sprintf("storage_location_id = $%d::UUID", len(params)+1))
+				setClauses = append(setClauses, fmt.Sprintf("location = (SELECT sl.location FROM community.storage_locations sl WHERE sl.id = $%d::UUID)", len(params)+1))
+				continue
+			}
+			if field.fieldType == inventoryBulkEditFieldInteger {
+				setClauses = append(setClauses, fmt.Sprintf("%s = $%d::INT", operation.Field, len(params)+1))
+				continue
+			}
+			setClauses = append(setClauses, fmt.Sprintf("%s = $%d", operation.Field, len(params)+1))
+		default:
+			return "", nil, false, errors.New(InvalidInventoryBulkEdit)
+		}
+	}
+
+	return strings.Join(setClauses, ",\n\t\t\t"), params, isStorageLocationChanged, nil
+}
+
+// validateStorageLocationForBulkEdit ...
+//
+// validateStorageLocationForBulkEdit ensures that the storage location selected in the operations exists
+func validateStorageLocationForBulkEdit(tx *sql.Tx, operations []model.InventoryBulkEditOperation) error {
+	for _, operation := range operations {
+		if operation.Field != "storage_location_id" {
+			continue
+		}
+
+		sqlStr := `SELECT sl.id FROM community.storage_locations sl WHERE sl.id = $1;`
+		var storageLocationID string
+		config.Log("SqlStr: %s", nil, sqlStr)
+		err := tx.QueryRow(sqlStr, operation.Value).Scan(&storageLocationID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New(InvalidInventoryBulkEdit)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// retrieveInventoryIDsForBulkEdit ...
+//
+// retrieveInventoryIDsForBulkEdit returns the ids of every asset shared with the user that matches the selected filter.
+// Pagination and sort of the filter are not applied as the bulk edit is applied to every matching asset.
+func retrieveInventoryIDsForBulkEdit(tx *sql.Tx, userID string, filter model.InventoryListParams) ([]string, error) {
+	var params []interface{}
+	params = append(params, userID)
+
+	additionalWhereClause, err := buildInventoryListWhereClause(filter, &params)
+	if err != nil {
+		config.Log("unable to build filters for selected inventories", err)
+		return nil, err
+	}
+
+	params = append(params, MaxInventoryBulkEditSize+1)
+	sqlStr := fmt.Sprintf(`SELECT inv.id
+		FROM community.inventory inv
+		WHERE $1::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL%s
+		ORDER BY inv.id
+		LIMIT $%d;`, additionalWhereClause, len(params))
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected inventories", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []string
+	for rows.Next() {
+		var invID string
+		if err := rows.Scan(&invID); err != nil {
+			config.Log("unable to scan selected inventories", err)
+			return nil, err
+		}
+		data = append(data, invID)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// bulkEditSelectedInventory ...
+//
+// bulkEditSelectedInventory applies the bulk edit to a single asset within a savepoint, so that a failure of the
+// asset does not abort the transaction and the remaining assets can still be attempted.
+func bulkEditSelectedInventory(tx *sql.Tx, userID string, invID string, sqlStr string, params []interface{}, isStorageLocationChanged bool, isQuantityChanged bool) error {
+	config.Log("SqlStr: %s", nil, "SAVEPOINT bulk_edit_inventory;")
+	if _, err := tx.Exec(`SAVEPOINT bulk_edit_inventory;`); err != nil {
+		return err
+	}
+
+	err := func() error {
+		before, err := snapshotInventory(tx, userID, invID)
+		if err != nil {
+			return err
+		}
+
+		if isQuantityChanged {
+			isSerialized, err := isSerializedInventory(tx, invID)
+			if err != nil {
+				return err
+			}
+			if isSerialized {
+				return errors.New(SerializedInventoryQuantity)
+			}
+		}
+
+		itemParams := append([]interface{}{invID}, params...)
+		config.Log("SqlStr: %s", nil, sqlStr)
+		if _, err := tx.Exec(sqlStr, itemParams...); err != nil {
+			return err
+		}
+
+		if isStorageLocationChanged {
+			if err := moveInventoryDescendants(tx, userID, invID); err != nil {
+				return err
+			}
+		}
+
+		return recordInventoryRevision(tx, userID, invID, before, InventoryRevisionActionUpdate)
+	}()
+
+	if err != nil {
+		config.Log("SqlStr: %s", nil, "ROLLBACK TO SAVEPOINT bulk_edit_inventory;")
+		if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT bulk_edit_inventory;`); rollbackErr != nil {
+			config.Log("unable to rollback to savepoint", rollbackErr)
+		}
+		return err
+	}
+
+	config.Log("SqlStr: %s", nil, "RELEASE SAVEPOINT bulk_edit_inventory;")
+	_, err = tx.Exec(`RELEASE SAVEPOINT bulk_edit_inventory;`)
+	return err
+}
+
+// toInventoryBulkEditError ...
+//
+// toInventoryBulkEditError replaces the error of a single asset with a readable error
+func toInventoryBulkEditError(err error) string {
+	if errors.Is(err, sql.ErrNoRows) {
+		return "inventory not found"
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,522 @@
+package db
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidInventoryMerge = "invalid inventory merge"
+	InventoryMergeOnLoan  = "unable to merge assets that are both on loan"
+
+	InventoryDuplicateReasonBarcode = "barcode"
+	InventoryDuplicateReasonSKU     = "sku"
+	InventoryDuplicateReasonName    = "name"
+
+	// DefaultInventoryDuplicateMinScore is the minimum score of a candidate when the score is not selected
+	DefaultInventoryDuplicateMinScore = 0.6
+	// DefaultInventoryDuplicateLimit is the number of candidates returned when the limit is not selected
+	DefaultInventoryDuplicateLimit = 50
+	// MaxInventoryDuplicateLimit is the maximum number of candidates returned
+	MaxInventoryDuplicateLimit = 500
+
+	// skuMatchWeight is lower than the barcode as the sku is often reused across variants of the same asset
+	skuMatchWeight = 0.9
+	// nameMatchThreshold is the minimum name similarity that is reported as a reason
+	nameMatchThreshold = 0.5
+)
+
+// RetrieveInventoryDuplicates ...
+//
+// RetrieveInventoryDuplicates returns pairs of assets that are likely to be the same asset. Pairs are found and scored
+// in the db by matching barcode, matching sku and the pg_trgm similarity of the names, so that only candidate pairs
+// are returned. Pairs are sorted with the highest score first.
+func RetrieveInventoryDuplicates(user string, userID string, minScore float64, limit int) ([]model.InventoryDuplicateCandidate, error) {
+	if minScore <= 0 {
+		minScore = DefaultInventoryDuplicateMinScore
+	}
+	if limit <= 0 {
+		limit = DefaultInventoryDuplicateLimit
+	}
+	if limit > MaxInventoryDuplicateLimit {
+		limit = MaxInventoryDuplicateLimit
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	// pairs that only match by name must be at least as similar as the selected minimum score
+	sqlStr := `SELECT set_config('pg_trgm.similarity_threshold', $1, true);`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, strconv.FormatFloat(minScore, 'f', -1, 64))
+	if err != nil {
+		config.Log("unable to set similarity threshold", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the asset that was created first is the survivor of each pair
+	sqlStr = `SELECT
+			pairs.score,
+			pairs.barcode_match,
+			pairs.sku_match,
+			pairs.name_similarity,
+			pairs.inventory_id,
+			pairs.inventory_name,
+			pairs.inventory_barcode,
+			pairs.inventory_sku,
+			pairs.inventory_quantity,
+			pairs.inventory_location,
+			pairs.inventory_created_at,
+			pairs.inventory_updated_at,
+			pairs.duplicate_id,
+			pairs.duplicate_name,
+			pairs.duplicate_barcode,
+			pairs.duplicate_sku,
+			pairs.duplicate_quantity,
+			pairs.duplicate_location,
+			pairs.duplicate_created_at,
+			pairs.duplicate_updated_at
+		FROM (
+			SELECT
+				candidates.*,
+				ROUND((1 - CASE WHEN candidates.barcode_match THEN 0 ELSE 1 END
+					* CASE WHEN candidates.sku_match THEN 1 - $2 ELSE 1 END
+					* (1 - candidates.name_similarity))::NUMERIC, 2)::FLOAT8 AS score
+			FROM (
+				SELECT
+					COALESCE(LOWER(TRIM(a.barcode)) <> '' AND LOWER(TRIM(a.barcode)) = LOWER(TRIM(b.barcode)), false) AS barcode_match,
+					COALESCE(LOWER(TRIM(a.sku)) <> '' AND LOWER(TRIM(a.sku)) = LOWER(TRIM(b.sku)), false) AS sku_match,
+					similarity(a.name, b.name)::FLOAT8 AS name_similarity,
+					a.id AS inventory_id,
+					a.name AS inventory_name,
+					COALESCE(a.barcode, '') AS inventory_barcode,
+					COALESCE(a.sku, '') AS inventory_sku,
+					COALESCE(a.quantity, 0) AS inventory_quantity,
+					COALESCE(a.location, '') AS inventory_location,
+					a.created_at AS inventory_created_at,
+					a.updated_at AS inventory_updated_at,
+					b.id AS duplicate_id,
+					b.name AS duplicate_name,
+					COALESCE(b.barcode, '') AS duplicate_barcode,
+					COALESCE(b.sku, '') AS duplicate_sku,
+					COALESCE(b.quantity, 0) AS duplicate_quantity,
+					COALESCE(b.location, '') AS duplicate_location,
+					b.created_at AS duplicate_created_at,
+					b.updated_at AS duplicate_updated_at
+				FROM community.inventory a
+				JOIN community.inventory b ON (a.created_at, a.id) < (b.created_at, b.id)
+				AND $1::UUID = ANY(b.sharable_groups)
+				AND b.deleted_at IS NULL
+				AND (
+					a.name % b.name
+					OR (LOWER(TRIM(a.barcode)) <> '' AND LOWER(TRIM(a.barcode)) = LOWER(TRIM(b.barcode)))
+					OR (LOWER(TRIM(a.sku)) <> '' AND LOWER(TRIM(a.sku)) = LOWER(TRIM(b.sku)))
+				)
+				WHERE $1::UUID = ANY(a.sharable_groups)
+				AND a.deleted_at IS NULL
+			) candidates
+		) pairs
+		WHERE pairs.score >= $3
+		ORDER BY pairs.score DESC, pairs.inventory_created_at, pairs.duplicate_created_at
+		LIMIT $4;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, userID, skuMatchWeight, minScore, limit)
+	if err != nil {
+		config.Log("unable to query duplicate assets", err)
+		tx.Rollback()
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]model.InventoryDuplicateCandidate, 0)
+	for rows.Next() {
+		var candidate model.InventoryDuplicateCandidate
+		var barcodeMatch, skuMatch bool
+		var nameSimilarity float64
+		if err := rows.Scan(
+			&candidate.Score,
+			&barcodeMatch,
+			&skuMatch,
+			&nameSimilarity,
+			&candidate.Inventory.ID,
+			&candidate.Inventory.Name,
+			&candidate.Inventory.Barcode,
+			&candidate.Inventory.SKU,
+			&candidate.Inventory.Quantity,
+			&candidate.Inventory.Location,
+			&candidate.Inventory.CreatedAt,
+			&candidate.Inventory.UpdatedAt,
+			&candidate.Duplicate.ID,
+			&candidate.Duplicate.Name,
+			&candidate.Duplicate.Barcode,
+			&candidate.Duplicate.SKU,
+			&candidate.Duplicate.Quantity,
+			&candidate.Duplicate.Location,
+			&candidate.Duplicate.CreatedAt,
+			&candidate.Duplicate.UpdatedAt,
+		); err != nil {
+			config.Log("unable to scan duplicate assets", err)
+			tx.Rollback()
+			return nil, err
+		}
+		candidate.Reasons = inventoryDuplicateReasons(barcodeMatch, skuMatch, nameSimilarity)
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit", err)
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// MergeInventory ...
+//
+// MergeInventory merges the selected duplicate into the surviving asset in a single transaction. The quantity of the
+// duplicate is added to the survivor through the stock ledger, and categories, maintenance plans, tags, attachments,
+// loans, units and child assets of the duplicate are moved to the survivor. When either asset is serialized, the
+// quantity of the survivor is derived from the combined units instead. Barcode, sku and image of the duplicate are kept only
+// when the survivor does not have one. Favourites are held against categories and maintenance plans, so they follow
+// the survivor through the moved associations. The duplicate is removed once it is merged.
+func MergeInventory(user string, userID string, invID string, duplicateID string) (*model.Inventory, error) {
+	if invID == duplicateID {
+		config.Log("unable to merge asset into itself", errors.New(InvalidInventoryMerge))
+		return nil, errors.New(InvalidInventoryMerge)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	before, err := snapshotInventory(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `SELECT
+			inv.name,
+			COALESCE(inv.quantity, 0),
+			COALESCE(inv.barcode, ''),
+			COALESCE(inv.sku, ''),
+			COALESCE(inv.associated_image_url, '')
+		FROM community.inventory inv
+		WHERE inv.id = $1
+		AND $2::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		FOR UPDATE;`
+
+	var duplicateName, duplicateBarcode, duplicateSKU, duplicateImageURL string
+	var duplicateQuantity int
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, duplicateID, userID).Scan(&duplicateName, &duplicateQuantity, &duplicateBarcode, &duplicateSKU, &duplicateImageURL)
+	if err != nil {
+		config.Log("unable to find selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// child assets of the duplicate are moved to the survivor, so the survivor cannot be inside the duplicate
+	isCycle, err := isInventoryAncestor(tx, invID, duplicateID)
+	if err != nil {
+		config.Log("unable to validate selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+	if isCycle {
+		config.Log("unable to merge asset into its descendant", errors.New(InventoryParentCycle))
+		tx.Rollback()
+		return nil, errors.New(InventoryParentCycle)
+	}
+
+	currentTime := time.Now()
+
+	// associations that the survivor already holds are left behind and removed along with the duplicate
+	sqlStr = `UPDATE community.category_item ci
+		SET item_id = $1,
+			updated_by = $3,
+			updated_at = $4
+		WHERE ci.item_id = $2
+		AND NOT EXISTS (
+			SELECT 1 FROM community.category_item s WHERE s.item_id = $1 AND s.category_id = ci.category_id
+		);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID, userID, currentTime)
+	if err != nil {
+		config.Log("unable to move categories of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.maintenance_item mi
+		SET item_id = $1,
+			updated_by = $3,
+			updated_at = $4
+		WHERE mi.item_id = $2
+		AND NOT EXISTS (
+			SELECT 1 FROM community.maintenance_item s
+			WHERE s.item_id = $1
+			AND s.maintenance_plan_id = mi.maintenance_plan_id
+			AND s.unit_id IS NOT DISTINCT FROM mi.unit_id
+		);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID, userID, currentTime)
+	if err != nil {
+		config.Log("unable to move maintenance plans of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.tag_items ti
+		SET item_id = $1
+		WHERE ti.item_id = $2
+		AND NOT EXISTS (
+			SELECT 1 FROM community.tag_items s WHERE s.item_id = $1 AND s.tag_id = ti.tag_id
+		);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID)
+	if err != nil {
+		config.Log("unable to move tags of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.inventory_attachments SET item_id = $1 WHERE item_id = $2;`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID)
+	if err != nil {
+		config.Log("unable to move attachments of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.loan_items
+		SET item_id = $1,
+			updated_by = $3,
+			updated_at = $4
+		WHERE item_id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID, userID, currentTime)
+	if err != nil {
+		config.Log("unable to move loans of selected duplicate", err)
+		tx.Rollback()
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationErrorCode {
+			return nil, errors.New(InventoryMergeOnLoan)
+		}
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.inventory_units
+		SET item_id = $1,
+			updated_by = $3,
+			updated_at = $4
+		WHERE item_id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID, userID, currentTime)
+	if err != nil {
+		config.Log("unable to move units of selected duplicate", err)
+		tx.Rollback()
+		return nil, toDuplicateInventoryUnitError(err)
+	}
+
+	isSerialized, err := isSerializedInventory(tx, invID)
+	if err != nil {
+		config.Log("unable to retrieve units of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.inventory
+		SET parent_id = $1,
+			updated_by = $3,
+			updated_at = $4
+		WHERE parent_id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateID, userID, currentTime)
+	if err != nil {
+		config.Log("unable to move child assets of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the duplicate is removed before the survivor takes over its barcode and sku to satisfy the unique indexes
+	sqlStr = `DELETE FROM community.inventory WHERE id = $1;`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, duplicateID)
+	if err != nil {
+		config.Log("unable to remove selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `UPDATE community.inventory inv
+		SET barcode = COALESCE(NULLIF(inv.barcode, ''), NULLIF($2, '')),
+			sku = COALESCE(NULLIF(inv.sku, ''), NULLIF($3, '')),
+			associated_image_url = COALESCE(NULLIF(inv.associated_image_url, ''), NULLIF($4, '')),
+			updated_by = $5,
+			updated_at = $6
+		WHERE inv.id = $1;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, duplicateBarcode, duplicateSKU, duplicateImageURL, userID, currentTime)
+	if err != nil {
+		config.Log("unable to update selected asset", err)
+		tx.Rollback()
+		return nil, toDuplicateBarcodeOrSKUError(err)
+	}
+
+	// serialized assets derive their quantity from the units that are moved to the survivor
+	if duplicateQuantity != 0 && !isSerialized {
+		sqlStr = `INSERT INTO community.stock_movements (item_id, movement_type, quantity_change, reason, created_at, created_by, sharable_groups)
+			SELECT inv.id, 'adjustment', $2, $3, $4, $5, inv.sharable_groups
+			FROM community.inventory inv
+			WHERE inv.id = $1;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		_, err = tx.Exec(sqlStr, invID, duplicateQuantity, "merged from "+duplicateName, currentTime, userID)
+		if err != nil {
+			config.Log("unable to add quantity of selected duplicate", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = moveInventoryDescendants(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to move child assets with selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordInventoryRevision(tx, userID, invID, before, InventoryRevisionActionMerge)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	isImageCopied, err := copyInventoryImage(duplicateID, invID)
+	if err != nil {
+		config.Log("unable to copy image of selected duplicate", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		if isImageCopied {
+			bucket.RemoveDocumentFromBucket(invID)
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		if isImageCopied {
+			bucket.RemoveDocumentFromBucket(invID)
+		}
+		return nil, err
+	}
+
+	// the image of the duplicate is no longer reachable once the duplicate is removed
+	if err := bucket.RemoveDocumentFromBucket(duplicateID); err != nil {
+		config.Log("unable to remove image of merged duplicate", err)
+	}
+
+	return data, nil
+}
+
+// copyInventoryImage ...
+//
+// copyInventoryImage copies the image of the source asset to the destination asset when only the source asset
+// has an image. Returns true if the image was copied.
+func copyInventoryImage(sourceInvID string, destinationInvID string) (bool, error) {
+	hasDestinationImage, err := hasInventoryImage(destinationInvID)
+	if err != nil || hasDestinationImage {
+		return false, err
+	}
+
+	hasSourceImage, err := hasInventoryImage(sourceInvID)
+	if err != nil || !hasSourceImage {
+		return false, err
+	}
+
+	if err := bucket.CopyDocumentInBucket(sourceInvID, destinationInvID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// hasInventoryImage ...
+//
+// hasInventoryImage returns true if the selected asset has an image in the bucket storage
+func hasInventoryImage(invID string) (bool, error) {
+	content, _, _, err := FetchImage(invID)
+	if err != nil {
+		if err.Error() == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(content) > 0, nil
+}
+
+// inventoryDuplicateReasons ...
+//
+// inventoryDuplicateReasons returns the signals that matched for a pair of assets
+func inventoryDuplicateReasons(barcodeMatch bool, skuMatch bool, nameSimilarity float64) []string {
+	reasons := make([]string, 0)
+	if barcodeMatch {
+		reasons = append(reasons, InventoryDuplicateReasonBarcode)
+	}
+	if skuMatch {
+		reasons = append(reasons, InventoryDuplicateReasonSKU)
+	}
+	if nameSimilarity >= nameMatchThreshold {
+		reasons = append(reasons, InventoryDuplicateReasonName)
+	}
+	return reasons
+}
@@ -0,0 +1,463 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidInventoryBatch   = "invalid inventory batch"
+	DuplicateInventoryBatch = "batch with the same lot number already exists"
+	InvalidExpiryWindow     = "invalid expiry window"
+
+	// ExpiryNotificationType is the type of the notification raised for inventories that are about to expire
+	ExpiryNotificationType = "expiry"
+
+	// DefaultExpiryWindowDays is the number of days used when the expiry window is not selected
+	DefaultExpiryWindowDays = 30
+	// MaxExpiryWindowDays is the largest expiry window that can be selected
+	MaxExpiryWindowDays = 3650
+	// ExpiryAlertThresholdDays is the number of days before the expiry date that an expiry alert is raised
+	ExpiryAlertThresholdDays = 30
+
+	maxLotNumberLength  = 100
+	maxBatchNotesLength = 250
+)
+
+// RetrieveExpiringInventories ...
+//
+// RetrieveExpiringInventories returns the inventories and batches that expire within the selected number of days
+// with the earliest expiry date first. Inventories and batches that have already expired are included. Batches that
+// are used up are left out.
+func RetrieveExpiringInventories(user string, userID string, days int) ([]model.ExpiringInventory, error) {
+	if days == 0 {
+		days = DefaultExpiryWindowDays
+	}
+	if days < 0 || days > MaxExpiryWindowDays {
+		config.Log("unable to validate expiry window", errors.New(InvalidExpiryWindow))
+		return nil, errors.New(InvalidExpiryWindow)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT
+			inv.id,
+			'' AS batch_id,
+			inv.name,
+			'' AS lot_number,
+			COALESCE(inv.quantity, 0),
+			COALESCE(inv.location, ''),
+			inv.expiry_date,
+			inv.expiry_date - CURRENT_DATE AS days_until_expiry
+		FROM community.inventory inv
+		WHERE $1::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		AND inv.expiry_date <= CURRENT_DATE + $2::INT
+		UNION ALL
+		SELECT
+			inv.id,
+			ib.id::TEXT,
+			inv.name,
+			ib.lot_number,
+			ib.quantity,
+			COALESCE(inv.location, ''),
+			ib.expiry_date,
+			ib.expiry_date - CURRENT_DATE AS days_until_expiry
+		FROM community.inventory_batches ib
+		JOIN community.inventory inv ON inv.id = ib.item_id AND inv.deleted_at IS NULL
+		WHERE $1::UUID = ANY(ib.sharable_groups)
+		AND ib.quantity > 0
+		AND ib.expiry_date <= CURRENT_DATE + $2::INT
+		ORDER BY expiry_date, name;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, days)
+	if err != nil {
+		config.Log("unable to query expiring inventories", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.ExpiringInventory, 0)
+	for rows.Next() {
+		var expiringInventory model.ExpiringInventory
+		if err := rows.Scan(
+			&expiringInventory.ItemID,
+			&expiringInventory.BatchID,
+			&expiringInventory.Name,
+			&expiringInventory.LotNumber,
+			&expiringInventory.Quantity,
+			&expiringInventory.Location,
+			&expiringInventory.ExpiryDate,
+			&expiringInventory.DaysUntilExpiry,
+		); err != nil {
+			config.Log("unable to scan expiring inventories", err)
+			return nil, err
+		}
+		expiringInventory.IsExpired = expiringInventory.DaysUntilExpiry < 0
+		data = append(data, expiringInventory)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RetrieveInventoryBatches ...
+func RetrieveInventoryBatches(user string, userID string, invID string) ([]model.InventoryBatch, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveInventoryBatches(tx, "", userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve batches for selected asset", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddInventoryBatch ...
+func AddInventoryBatch(user string, userID string, invID string, draftBatch model.InventoryBatch) (*model.InventoryBatch, error) {
+	if err := validateInventoryBatch(draftBatch); err != nil {
+		config.Log("unable to validate inventory batch", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.inventory_batches (item_id, lot_number, quantity, expiry_date, notes, created_at, created_by, updated_at, updated_by, sharable_groups)
+		SELECT inv.id, $3, $4, $5, NULLIF($6, ''), $7, $2, $7, $2, inv.sharable_groups
+		FROM community.inventory inv
+		WHERE inv.id = $1
+		AND $2::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		RETURNING id;`
+
+	var batchID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, invID, userID, strings.TrimSpace(draftBatch.LotNumber), draftBatch.Quantity, draftBatch.ExpiryDate, draftBatch.Notes, time.Now()).Scan(&batchID)
+	if err != nil {
+		config.Log("unable to add batch for selected asset", err)
+		tx.Rollback()
+		return nil, toDuplicateInventoryBatchError(err)
+	}
+
+	data, err := retrieveInventoryBatches(tx, " AND ib.id = $3", userID, invID, batchID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected batch", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// UpdateInventoryBatch ...
+func UpdateInventoryBatch(user string, userID string, invID string, batchID string, draftBatch model.InventoryBatch) (*model.InventoryBatch, error) {
+	if err := validateInventoryBatch(draftBatch); err != nil {
+		config.Log("unable to validate inventory batch", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory_batches ib
+		SET lot_number = $4,
+			quantity = $5,
+			expiry_date = $6,
+			notes = NULLIF($7, ''),
+			updated_at = $8,
+			updated_by = $3
+		FROM community.inventory inv
+		WHERE ib.id = $1
+		AND ib.item_id = $2
+		AND inv.id = ib.item_id
+		AND inv.deleted_at IS NULL
+		AND $3::UUID = ANY(ib.sharable_groups)
+		RETURNING ib.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, batchID, invID, userID, strings.TrimSpace(draftBatch.LotNumber), draftBatch.Quantity, draftBatch.ExpiryDate, draftBatch.Notes, time.Now()).Scan(&batchID)
+	if err != nil {
+		config.Log("unable to update selected batch", err)
+		tx.Rollback()
+		return nil, toDuplicateInventoryBatchError(err)
+	}
+
+	data, err := retrieveInventoryBatches(tx, " AND ib.id = $3", userID, invID, batchID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected batch", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// RemoveInventoryBatch ...
+func RemoveInventoryBatch(user string, userID string, invID string, batchID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.inventory_batches ib WHERE ib.id = $1 AND ib.item_id = $2 AND $3::UUID = ANY(ib.sharable_groups);`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	result, err := db.Exec(sqlStr, batchID, invID, userID)
+	if err != nil {
+		config.Log("unable to remove selected batch", err)
+		return err
+	}
+
+	removedRows, err := result.RowsAffected()
+	if err != nil {
+		config.Log("unable to retrieve removed batches", err)
+		return err
+	}
+	if removedRows == 0 {
+		config.Log("unable to find selected batch", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// refreshExpiryAlerts ...
+//
+// refreshExpiryAlerts raises an expiry alert for every inventory and batch visible to the user that expires within
+// the alert threshold, and clears the alerts that no longer apply. Unlike low stock alerts, an inventory becomes due
+// to expire without any change to its row, so the alerts cannot be maintained with a trigger. An alert is marked as
+// unread again when its expiry date changes.
+func refreshExpiryAlerts(tx *sql.Tx, userID string) error {
+	sqlStr := `DELETE FROM community.expiry_alert ea
+		WHERE $1::UUID = ANY(ea.sharable_groups)
+		AND NOT EXISTS (
+			SELECT 1 FROM community.inventory inv
+			WHERE ea.batch_id IS NULL
+			AND inv.id = ea.item_id
+			AND inv.deleted_at IS NULL
+			AND inv.expiry_date <= CURRENT_DATE + $2::INT
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM community.inventory_batches ib
+			JOIN community.inventory inv ON inv.id = ib.item_id AND inv.deleted_at IS NULL
+			WHERE ib.id = ea.batch_id
+			AND ib.quantity > 0
+			AND ib.expiry_date <= CURRENT_DATE + $2::INT
+		);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err := tx.Exec(sqlStr, userID, ExpiryAlertThresholdDays)
+	if err != nil {
+		config.Log("unable to clear expiry alerts", err)
+		return err
+	}
+
+	sqlStr = `INSERT INTO community.expiry_alert (item_id, name, expiry_date, is_read, updated_by, updated_at, sharable_groups)
+		SELECT inv.id, inv.name, inv.expiry_date, false, 'system', NOW(), inv.sharable_groups
+		FROM community.inventory inv
+		WHERE $1::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		AND inv.expiry_date <= CURRENT_DATE + $2::INT
+		ON CONFLICT (item_id) WHERE batch_id IS NULL DO UPDATE
+		SET
+			is_read = CASE
+				WHEN community.expiry_alert.expiry_date IS DISTINCT FROM EXCLUDED.expiry_date
+				THEN false
+				ELSE community.expiry_alert.is_read
+			END,
+			name = EXCLUDED.name,
+			expiry_date = EXCLUDED.expiry_date,
+			sharable_groups = EXCLUDED.sharable_groups,
+			updated_at = NOW()
+		WHERE community.expiry_alert.expiry_date IS DISTINCT FROM EXCLUDED.expiry_date
+		OR community.expiry_alert.name IS DISTINCT FROM EXCLUDED.name;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, userID, ExpiryAlertThresholdDays)
+	if err != nil {
+		config.Log("unable to raise expiry alerts for inventories", err)
+		return err
+	}
+
+	sqlStr = `INSERT INTO community.expiry_alert (item_id, batch_id, name, expiry_date, is_read, updated_by, updated_at, sharable_groups)
+		SELECT inv.id, ib.id, inv.name || ' - ' || ib.lot_number, ib.expiry_date, false, 'system', NOW(), ib.sharable_groups
+		FROM community.inventory_batches ib
+		JOIN community.inventory inv ON inv.id = ib.item_id AND inv.deleted_at IS NULL
+		WHERE $1::UUID = ANY(ib.sharable_groups)
+		AND ib.quantity > 0
+		AND ib.expiry_date <= CURRENT_DATE + $2::INT
+		ON CONFLICT (batch_id) WHERE batch_id IS NOT NULL DO UPDATE
+		SET
+			is_read = CASE
+				WHEN community.expiry_alert.expiry_date IS DISTINCT FROM EXCLUDED.expiry_date
+				THEN false
+				ELSE community.expiry_alert.is_read
+			END,
+			name = EXCLUDED.name,
+			expiry_date = EXCLUDED.expiry_date,
+			sharable_groups = EXCLUDED.sharable_groups,
+			updated_at = NOW()
+		WHERE community.expiry_alert.expiry_date IS DISTINCT FROM EXCLUDED.expiry_date
+		OR community.expiry_alert.name IS DISTINCT FROM EXCLUDED.name;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, userID, ExpiryAlertThresholdDays)
+	if err != nil {
+		config.Log("unable to raise expiry alerts for batches", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveInventoryBatches ...
+//
+// retrieveInventoryBatches returns the batches of the selected asset with the earliest expiry date first.
+// userID is always $1 and invID is always $2. Batches of assets in the trash are not returned.
+func retrieveInventoryBatches(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryBatch, error) {
+	sqlStr := `SELECT
+		ib.id,
+		ib.item_id,
+		ib.lot_number,
+		ib.quantity,
+		ib.expiry_date,
+		COALESCE(ib.notes, ''),
+		ib.created_at,
+		COALESCE(ib.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		ib.updated_at,
+		COALESCE(ib.updated_by::TEXT, ''),
+		ib.sharable_groups
+	FROM community.inventory_batches ib
+	JOIN community.inventory inv ON inv.id = ib.item_id AND inv.deleted_at IS NULL
+	LEFT JOIN community.profiles cp ON cp.id = ib.created_by
+	WHERE $1::UUID = ANY(ib.sharable_groups)
+	AND ib.item_id = $2` + additionalWhereClause + `
+	ORDER BY ib.expiry_date, LOWER(ib.lot_number);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.InventoryBatch, 0)
+	for rows.Next() {
+		var batch model.InventoryBatch
+		if err := rows.Scan(
+			&batch.ID,
+			&batch.ItemID,
+			&batch.LotNumber,
+			&batch.Quantity,
+			&batch.ExpiryDate,
+			&batch.Notes,
+			&batch.CreatedAt,
+			&batch.CreatedBy,
+			&batch.Creator,
+			&batch.UpdatedAt,
+			&batch.UpdatedBy,
+			pq.Array(&batch.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected batches", err)
+			return nil, err
+		}
+		data = append(data, batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateInventoryBatch ...
+//
+// validateInventoryBatch validates the lot number, quantity and expiry date of the selected batch
+func validateInventoryBatch(draftBatch model.InventoryBatch) error {
+	lotNumber := strings.TrimSpace(draftBatch.LotNumber)
+	if len(lotNumber) == 0 || utf8.RuneCountInString(lotNumber) > maxLotNumberLength {
+		return errors.New(InvalidInventoryBatch)
+	}
+	if utf8.RuneCountInString(draftBatch.Notes) > maxBatchNotesLength {
+		return errors.New(InvalidInventoryBatch)
+	}
+	if draftBatch.Quantity < 0 || draftBatch.ExpiryDate.IsZero() {
+		return errors.New(InvalidInventoryBatch)
+	}
+	return nil
+}
+
+// toDuplicateInventoryBatchError ...
+//
+// returns the duplicate batch error when the lot number is already used by another batch of the same asset
+func toDuplicateInventoryBatchError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationErrorCode {
+		return errors.New(DuplicateInventoryBatch)
+	}
+	return err
+}
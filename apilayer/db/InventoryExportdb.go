@@ -0,0 +1,180 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+// ExportInventories ...
+//
+// streams every asset of the user that matches the selected filters into exportInventory along with the total count
+// of matching assets. Rows are read one at a time so that large inventories are not held in memory; the limit, offset
+// and cursor of the filters are ignored. Dimensions and weights are exported in the unit they are stored in so that
+// the values are not rounded.
+func ExportInventories(user string, userID string, listParams model.InventoryListParams, exportInventory func(draftInventory model.InventoryExport, totalCount int) error) error {
+
+	sortColumn, ok := inventorySortColumns[listParams.SortBy]
+	if !ok {
+		config.Log("unable to validate sort column", errors.New(InvalidColumnName))
+		return errors.New(InvalidColumnName)
+	}
+
+	sortDirection := "DESC"
+	if strings.EqualFold(listParams.SortOrder, "asc") {
+		sortDirection = "ASC"
+	}
+
+	var params []interface{}
+	params = append(params, userID)
+
+	additionalWhereClause, err := buildInventoryListWhereClause(listParams, &params)
+	if err != nil {
+		config.Log("unable to build filters for selected inventories", err)
+		return err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	sqlStr := `SELECT
+		inv.name,
+		COALESCE(inv.description, ''),
+		COALESCE(inv.price, 0),
+		COALESCE(inv.currency, ''),
+		COALESCE(inv.quantity, 0),
+		COALESCE(sl.location, inv.location, ''),
+		COALESCE(inv.color, ''),
+		COALESCE(inv.sku, ''),
+		COALESCE(inv.barcode, ''),
+		COALESCE(inv.bought_at, ''),
+		inv.max_weight,
+		inv.min_weight,
+		inv.max_height,
+		inv.min_height,
+		inv.length,
+		inv.width,
+		inv.height,
+		inv.weight,
+		inv.dimension_unit,
+		inv.weight_unit,
+		ARRAY(
+			SELECT c.name FROM community.category_item ci
+			JOIN community.category c ON c.id = ci.category_id
+			WHERE ci.item_id = inv.id AND c.deleted_at IS NULL
+			ORDER BY LOWER(c.name)
+		),
+		ARRAY(
+			SELECT mp.name FROM community.maintenance_item mi
+			JOIN community.maintenance_plan mp ON mp.id = mi.maintenance_plan_id
+			WHERE mi.item_id = inv.id AND mp.deleted_at IS NULL
+			ORDER BY LOWER(mp.name)
+		),
+		COUNT(*) OVER ()
+	FROM community.inventory inv
+	LEFT JOIN community.storage_locations sl ON sl.id = inv.storage_location_id
+	WHERE inv.created_by = $1::UUID AND inv.deleted_at IS NULL` + additionalWhereClause +
+		fmt.Sprintf(" ORDER BY %s %s, inv.id %s;", sortColumn.expression, sortDirection, sortDirection)
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to retrieve inventories for export", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var draftInventory model.InventoryExport
+		var measurements inventoryMeasurements
+		var totalCount int
+
+		if err := rows.Scan(
+			&draftInventory.Name,
+			&draftInventory.Description,
+			&draftInventory.Price,
+			&draftInventory.Currency,
+			&draftInventory.Quantity,
+			&draftInventory.StorageLocation,
+			&draftInventory.Color,
+			&draftInventory.SKU,
+			&draftInventory.Barcode,
+			&draftInventory.PurchaseLocation,
+			&measurements.maxWeight,
+			&measurements.minWeight,
+			&measurements.maxHeight,
+			&measurements.minHeight,
+			&measurements.length,
+			&measurements.width,
+			&measurements.height,
+			&measurements.weight,
+			&measurements.dimensionUnit,
+			&measurements.weightUnit,
+			pq.Array(&draftInventory.Categories),
+			pq.Array(&draftInventory.MaintenancePlans),
+			&totalCount,
+		); err != nil {
+			config.Log("unable to parse exported asset values", err)
+			return err
+		}
+
+		dimensionUnit, weightUnit := measurements.dimensionUnit.String, measurements.weightUnit.String
+		draftInventory.MaximumWeight = formatRawMeasurement(measurements.maxWeight.Float64, weightUnit)
+		draftInventory.MinimumWeight = formatRawMeasurement(measurements.minWeight.Float64, weightUnit)
+		draftInventory.Weight = formatRawMeasurement(measurements.weight.Float64, weightUnit)
+		draftInventory.MaximumHeight = formatRawMeasurement(measurements.maxHeight.Float64, dimensionUnit)
+		draftInventory.MinimumHeight = formatRawMeasurement(measurements.minHeight.Float64, dimensionUnit)
+		draftInventory.Length = formatRawMeasurement(measurements.length.Float64, dimensionUnit)
+		draftInventory.Width = formatRawMeasurement(measurements.width.Float64, dimensionUnit)
+		draftInventory.Height = formatRawMeasurement(measurements.height.Float64, dimensionUnit)
+
+		if draftInventory.Categories == nil {
+			draftInventory.Categories = make([]string, 0)
+		}
+		if draftInventory.MaintenancePlans == nil {
+			draftInventory.MaintenancePlans = make([]string, 0)
+		}
+
+		if err := exportInventory(draftInventory, totalCount); err != nil {
+			config.Log("unable to export selected asset", err)
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to process all rows", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// formatRawMeasurement ...
+//
+// formats the selected dimension or weight the way it is accepted by the bulk upload, eg 12.5 kg. Empty measurements
+// are left empty.
+func formatRawMeasurement(value float64, unit string) model.RawMeasurement {
+	if value == 0 {
+		return ""
+	}
+	formattedValue := strconv.FormatFloat(value, 'f', -1, 64)
+	if len(unit) == 0 {
+		return model.RawMeasurement(formattedValue)
+	}
+	return model.RawMeasurement(formattedValue + " " + unit)
+}
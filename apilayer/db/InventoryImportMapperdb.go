@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/model"
+)
+
+const (
+	InventoryImportSourceFleetwise    = "fleetwise"
+	InventoryImportSourceSnipeIT      = "snipeit"
+	InventoryImportSourceHomebox      = "homebox"
+	InventoryImportSourceGoogleSheets = "google_sheets"
+
+	UnknownInventoryImportSource = "unknown import source"
+
+	// maxImportedCategoryNameLength is the max length of the name of each category in the uploaded file
+	maxImportedCategoryNameLength = 100
+)
+
+// inventoryImportMapper translates the columns of a csv or xlsx layout exported from another tool into the fields
+// of the asset. Columns are the accepted headers of each field and are compared in lower case without spaces and
+// punctuation. CategorySeparators are the characters used to split a single cell into many categories; the cell is
+// a single category when it is empty.
+type inventoryImportMapper struct {
+	name               string
+	description        string
+	columns            map[string][]string
+	categorySeparators string
+}
+
+// inventoryImportMappers are the supported layouts of the uploaded file selected by the name of the source
+var inventoryImportMappers = []inventoryImportMapper{
+	{
+		name:               InventoryImportSourceFleetwise,
+		description:        "Files exported from fleetwise and spreadsheets with the same headers",
+		columns:            inventoryImportColumns,
+		categorySeparators: ";",
+	},
+	{
+		name:        InventoryImportSourceSnipeIT,
+		description: "Assets exported from Snipe-IT",
+		columns: map[string][]string{
+			"name":        {"Asset Name", "Item Name", "Name"},
+			"description": {"Notes"},
+			"price":       {"Purchase Cost", "Unit Cost"},
+			"currency":    {"Currency"},
+			"quantity":    {"Qty", "Quantity"},
+			"location":    {"Location", "Default Location"},
+			"sku":         {"Asset Tag"},
+			"barcode":     {"Serial", "Serial Number"},
+			"bought_at":   {"Supplier", "Order Number"},
+			"categories":  {"Category"},
+		},
+	},
+	{
+		name:        InventoryImportSourceHomebox,
+		description: "Items exported from Homebox",
+		columns: map[string][]string{
+			"name":        {"HB.name"},
+			"description": {"HB.description", "HB.notes"},
+			"price":       {"HB.purchase_price"},
+			"quantity":    {"HB.quantity"},
+			"location":    {"HB.location"},
+			"sku":         {"HB.asset_id", "HB.import_ref"},
+			"barcode":     {"HB.serial_number"},
+			"bought_at":   {"HB.purchase_from"},
+			"categories":  {"HB.labels"},
+		},
+		categorySeparators: ";",
+	},
+	{
+		name:        InventoryImportSourceGoogleSheets,
+		description: "Inventory spreadsheets exported from Google Sheets",
+		columns: map[string][]string{
+			"name":        {"Item", "Item Name", "Name", "Product", "Title"},
+			"description": {"Description", "Notes", "Details", "Comments"},
+			"price":       {"Price", "Cost", "Unit Cost", "Unit Price"},
+			"currency":    {"Currency"},
+			"quantity":    {"Quantity", "Qty", "Count", "In Stock"},
+			"location":    {"Location", "Storage Location", "Room", "Shelf", "Bin"},
+			"color":       {"Color", "Colour"},
+			"sku":         {"SKU", "Item Code", "Item ID"},
+			"barcode":     {"Barcode", "UPC", "EAN", "Serial Number"},
+			"bought_at":   {"Supplier", "Vendor", "Store", "Purchased From"},
+			"weight":      {"Weight"},
+			"length":      {"Length"},
+			"width":       {"Width"},
+			"height":      {"Height"},
+			"categories":  {"Category", "Categories", "Tags"},
+		},
+		categorySeparators: ",;",
+	},
+}
+
+// RetrieveInventoryImportMappers ...
+//
+// RetrieveInventoryImportMappers returns the names of the supported sources of the uploaded file
+func RetrieveInventoryImportMappers() []model.InventoryImportMapper {
+	data := make([]model.InventoryImportMapper, 0, len(inventoryImportMappers))
+	for _, v := range inventoryImportMappers {
+		fields := make([]string, 0, len(v.columns))
+		for field := range v.columns {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		data = append(data, model.InventoryImportMapper{
+			Name:        v.name,
+			Description: v.description,
+			Fields:      fields,
+		})
+	}
+	return data
+}
+
+// retrieveInventoryImportMapper ...
+//
+// returns the mapper of the selected source. An empty source is the default fleetwise layout.
+func retrieveInventoryImportMapper(source string) (*inventoryImportMapper, error) {
+	source = strings.TrimSpace(source)
+	if len(source) == 0 {
+		source = InventoryImportSourceFleetwise
+	}
+	for i := range inventoryImportMappers {
+		if inventoryImportMappers[i].name == source {
+			return &inventoryImportMappers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %s", UnknownInventoryImportSource, source)
+}
+
+// detectMapping ...
+//
+// maps each field of the asset to the first header that matches one of the accepted headers of the field
+func (m inventoryImportMapper) detectMapping(headers []string) map[string]string {
+	aliases := make(map[string]string)
+	for field, columnAliases := range m.columns {
+		for _, alias := range columnAliases {
+			aliases[normalizeInventoryImportHeader(alias)] = field
+		}
+	}
+
+	mapping := make(map[string]string)
+	for _, header := range headers {
+		field, ok := aliases[normalizeInventoryImportHeader(header)]
+		if !ok {
+			continue
+		}
+		if _, isMapped := mapping[field]; !isMapped {
+			mapping[field] = header
+		}
+	}
+	return mapping
+}
+
+// splitCategories ...
+//
+// splits the selected cell into the names of the categories. Names are trimmed and duplicates are removed
+// regardless of case, keeping the first spelling.
+func (m inventoryImportMapper) splitCategories(cell string) []string {
+	draftCategories := []string{cell}
+	if len(m.categorySeparators) > 0 {
+		draftCategories = strings.FieldsFunc(cell, func(r rune) bool { return strings.ContainsRune(m.categorySeparators, r) })
+	}
+
+	categories := make([]string, 0, len(draftCategories))
+	isSelected := make(map[string]bool)
+	for _, v := range draftCategories {
+		v = strings.TrimSpace(v)
+		if len(v) == 0 || isSelected[strings.ToLower(v)] {
+			continue
+		}
+		isSelected[strings.ToLower(v)] = true
+		categories = append(categories, v)
+	}
+	return categories
+}
@@ -0,0 +1,313 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidInventoryImportProfile   = "invalid inventory import profile"
+	DuplicateInventoryImportProfile = "inventory import profile with the same name already exists"
+
+	maxInventoryImportProfileNameLength   = 100
+	maxInventoryImportProfileHeaderLength = 255
+)
+
+// RetrieveInventoryImportProfiles ...
+//
+// RetrieveInventoryImportProfiles returns the saved column mappings of the selected user sorted by name
+func RetrieveInventoryImportProfiles(user string, userID string) ([]model.InventoryImportProfile, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImportProfiles(tx, "", userID)
+	if err != nil {
+		config.Log("unable to retrieve import profiles", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddInventoryImportProfile ...
+//
+// AddInventoryImportProfile saves the selected column mapping under a name that is unique for the user. The
+// headers of the mapping are checked against the uploaded file when the profile is used.
+func AddInventoryImportProfile(user string, userID string, draftProfile model.InventoryImportProfile) (*model.InventoryImportProfile, error) {
+	profile, err := validateInventoryImportProfile(draftProfile)
+	if err != nil {
+		config.Log("unable to validate import profile", err)
+		return nil, err
+	}
+
+	mapping, err := json.Marshal(profile.Mapping)
+	if err != nil {
+		config.Log("unable to marshal column mapping", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.inventory_import_profiles (name, source, mapping, created_at, created_by, updated_at, updated_by, sharable_groups)
+		VALUES ($2, $3, $4, $5, $1, $5, $1, ARRAY[$1::UUID])
+		RETURNING id;`
+
+	var profileID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, userID, profile.Name, profile.Source, mapping, time.Now()).Scan(&profileID)
+	if err != nil {
+		config.Log("unable to add selected import profile", err)
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolationErrorCode {
+			return nil, errors.New(DuplicateInventoryImportProfile)
+		}
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImportProfile(tx, userID, profileID)
+	if err != nil {
+		config.Log("unable to retrieve selected import profile", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// UpdateInventoryImportProfile ...
+//
+// UpdateInventoryImportProfile updates the name, source and column mapping of the selected profile. Imports that
+// used the profile keep their own mapping.
+func UpdateInventoryImportProfile(user string, userID string, profileID string, draftProfile model.InventoryImportProfile) (*model.InventoryImportProfile, error) {
+	profile, err := validateInventoryImportProfile(draftProfile)
+	if err != nil {
+		config.Log("unable to validate import profile", err)
+		return nil, err
+	}
+
+	mapping, err := json.Marshal(profile.Mapping)
+	if err != nil {
+		config.Log("unable to marshal column mapping", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory_import_profiles iip
+		SET name = $3, source = $4, mapping = $5, updated_at = $6, updated_by = $1
+		WHERE $1::UUID = ANY(iip.sharable_groups) AND iip.id = $2
+		RETURNING iip.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, userID, profileID, profile.Name, profile.Source, mapping, time.Now()).Scan(&profileID)
+	if err != nil {
+		config.Log("unable to update selected import profile", err)
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolationErrorCode {
+			return nil, errors.New(DuplicateInventoryImportProfile)
+		}
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImportProfile(tx, userID, profileID)
+	if err != nil {
+		config.Log("unable to retrieve selected import profile", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RemoveInventoryImportProfile ...
+//
+// RemoveInventoryImportProfile removes the selected profile. Imports that used the profile are not changed.
+func RemoveInventoryImportProfile(user string, userID string, profileID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.inventory_import_profiles iip WHERE $1::UUID = ANY(iip.sharable_groups) AND iip.id = $2 RETURNING iip.id;`
+
+	var removedProfileID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, userID, profileID).Scan(&removedProfileID)
+	if err != nil {
+		config.Log("unable to remove selected import profile", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveInventoryImportProfile ...
+//
+// returns the selected profile. Returns sql.ErrNoRows when the profile is not found.
+func retrieveInventoryImportProfile(tx *sql.Tx, userID string, profileID string) (*model.InventoryImportProfile, error) {
+	data, err := retrieveInventoryImportProfiles(tx, " AND iip.id = $2", userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &data[0], nil
+}
+
+// retrieveInventoryImportProfiles ...
+//
+// returns the profiles visible to the selected user. The selected user is the first param.
+func retrieveInventoryImportProfiles(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryImportProfile, error) {
+	sqlStr := `SELECT
+		iip.id,
+		iip.name,
+		iip.source,
+		iip.mapping,
+		iip.created_at,
+		COALESCE(iip.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		iip.updated_at,
+		COALESCE(iip.updated_by::TEXT, ''),
+		iip.sharable_groups
+	FROM community.inventory_import_profiles iip
+	LEFT JOIN community.profiles cp ON cp.id = iip.created_by
+	WHERE $1::UUID = ANY(iip.sharable_groups)` + additionalWhereClause + `
+	ORDER BY LOWER(iip.name);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.InventoryImportProfile, 0)
+	for rows.Next() {
+		var profile model.InventoryImportProfile
+		var mapping []byte
+		if err := rows.Scan(
+			&profile.ID,
+			&profile.Name,
+			&profile.Source,
+			&mapping,
+			&profile.CreatedAt,
+			&profile.CreatedBy,
+			&profile.Creator,
+			&profile.UpdatedAt,
+			&profile.UpdatedBy,
+			pq.Array(&profile.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected import profiles", err)
+			return nil, err
+		}
+
+		if err := json.Unmarshal(mapping, &profile.Mapping); err != nil {
+			config.Log("unable to unmarshal column mapping", err)
+			return nil, err
+		}
+		data = append(data, profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateInventoryImportProfile ...
+//
+// returns the selected profile with the name and headers trimmed after ensuring that the source and each field of
+// the mapping exists. Fields mapped to an empty header are left out. An empty source is the default fleetwise layout.
+func validateInventoryImportProfile(draftProfile model.InventoryImportProfile) (*model.InventoryImportProfile, error) {
+	name := strings.TrimSpace(draftProfile.Name)
+	if len(name) == 0 || utf8.RuneCountInString(name) > maxInventoryImportProfileNameLength {
+		return nil, fmt.Errorf("%s: name must be between 1 and %d characters", InvalidInventoryImportProfile, maxInventoryImportProfileNameLength)
+	}
+
+	mapper, err := retrieveInventoryImportMapper(draftProfile.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	for field, header := range draftProfile.Mapping {
+		if _, ok := inventoryImportColumns[field]; !ok {
+			return nil, fmt.Errorf("%s: unknown field %s", InvalidInventoryImportMapping, field)
+		}
+		header = strings.TrimSpace(header)
+		if len(header) == 0 {
+			continue
+		}
+		if utf8.RuneCountInString(header) > maxInventoryImportProfileHeaderLength {
+			return nil, fmt.Errorf("%s: column cannot be longer than %d characters", InvalidInventoryImportMapping, maxInventoryImportProfileHeaderLength)
+		}
+		mapping[field] = header
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("%s: mapping must select at least one column", InvalidInventoryImportProfile)
+	}
+
+	return &model.InventoryImportProfile{
+		Name:    name,
+		Source:  mapper.name,
+		Mapping: mapping,
+	}, nil
+}
@@ -0,0 +1,1355 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	InventoryImportStatusPending   = "pending"
+	InventoryImportStatusCommitted = "committed"
+
+	InvalidInventoryImport          = "invalid inventory import"
+	InvalidInventoryImportMapping   = "invalid column mapping"
+	InventoryImportAlreadyCommitted = "inventory import is already committed"
+	TooManyInventoryImportRows      = "too many rows in the selected file"
+
+	// MaxInventoryImportRows is the maximum number of rows that can be imported from a single file
+	MaxInventoryImportRows = 5000
+
+	// importedInventoryStatus is the status of the imported assets. Imported assets are hidden like the assets
+	// uploaded in bulk.
+	importedInventoryStatus          = "HIDDEN"
+	importedCategoryStatus           = "general"
+	importedCategoryColor            = "#f7f7f7"
+	maxInventoryImportFileNameLength = 255
+	maxInventoryImportPrice          = 1000000
+)
+
+// inventoryImportFileTypes is the list of supported file types that can be imported
+var inventoryImportFileTypes = []string{"csv", "xlsx"}
+
+// inventoryImportColumns are the accepted headers of each field of the asset. Headers are compared in lower case
+// without spaces and punctuation, so that "Storage Location" matches storagelocation.
+var inventoryImportColumns = map[string][]string{
+	"name":        {"name", "assetname", "itemname", "title"},
+	"description": {"description", "desc", "details"},
+	"price":       {"price", "cost", "unitprice", "purchaseprice"},
+	"currency":    {"currency"},
+	"quantity":    {"quantity", "qty", "count"},
+	"location":    {"storagelocation", "location", "storage"},
+	"color":       {"color", "colour"},
+	"sku":         {"sku"},
+	"barcode":     {"barcode", "upc", "ean"},
+	"bought_at":   {"purchaselocation", "boughtat", "store", "vendor"},
+	"max_weight":  {"maximumweight", "maxweight"},
+	"min_weight":  {"minimumweight", "minweight"},
+	"max_height":  {"maximumheight", "maxheight"},
+	"min_height":  {"minimumheight", "minheight"},
+	"length":      {"length"},
+	"width":       {"width"},
+	"height":      {"height"},
+	"weight":      {"weight"},
+	"categories":  {"categories", "category"},
+}
+
+// inventoryImportTextFields are the text fields of the asset alongside the max length of each field
+var inventoryImportTextFields = []struct {
+	field     string
+	maxLength int
+}{
+	{"name", 100},
+	{"description", 500},
+	{"location", 100},
+	{"color", 10},
+	{"sku", 100},
+	{"barcode", 100},
+	{"bought_at", 500},
+}
+
+// inventoryImportMeasurementFields are the dimensions and weights of the asset alongside the kind of each field
+var inventoryImportMeasurementFields = []struct {
+	field string
+	kind  string
+}{
+	{"max_weight", MeasurementKindWeight},
+	{"min_weight", MeasurementKindWeight},
+	{"max_height", MeasurementKindDimension},
+	{"min_height", MeasurementKindDimension},
+	{"length", MeasurementKindDimension},
+	{"width", MeasurementKindDimension},
+	{"height", MeasurementKindDimension},
+	{"weight", MeasurementKindWeight},
+}
+
+// inventoryImportCells are the cells of the uploaded file that are stored until the import is committed
+type inventoryImportCells struct {
+	status   string
+	source   string
+	headers  []string
+	cells    [][]string
+	firstRow int
+	mapping  map[string]string
+}
+
+// inventoryImportDraft is a single row of the uploaded file alongside the asset parsed from it
+type inventoryImportDraft struct {
+	inventory  model.Inventory
+	categories []string
+	report     model.InventoryImportRow
+}
+
+// addError ...
+//
+// adds an error to the selected row. Rows with errors are not imported.
+func (d *inventoryImportDraft) addError(field string, column string, message string) {
+	d.report.Errors = append(d.report.Errors, model.InventoryImportIssue{Field: field, Column: column, Message: message})
+}
+
+// addWarning ...
+//
+// adds a warning to the selected row. Rows with warnings are imported.
+func (d *inventoryImportDraft) addWarning(field string, column string, message string) {
+	d.report.Warnings = append(d.report.Warnings, model.InventoryImportIssue{Field: field, Column: column, Message: message})
+}
+
+// RetrieveInventoryImports ...
+//
+// RetrieveInventoryImports returns the imports of the selected user with the most recent import first
+func RetrieveInventoryImports(user string, userID string) ([]model.InventoryImport, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImports(tx, "", userID)
+	if err != nil {
+		config.Log("unable to retrieve imports", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RetrieveInventoryImport ...
+//
+// RetrieveInventoryImport returns the selected import alongside the errors and warnings of each row
+func RetrieveInventoryImport(user string, userID string, importID string) (*model.InventoryImport, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImport(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddInventoryImport ...
+//
+// AddInventoryImport validates the rows of the uploaded file without adding any asset and saves the file as a
+// pending import. The first row that is not empty is the header. The source selects the layout of the file, eg
+// snipeit or homebox. When a saved profile is selected, its source and column mapping are used unless they are
+// selected as well; columns of the profile that are missing from the file are left out. When the column mapping is
+// not selected, it is derived from the header. The returned import lists the errors and warnings of each row.
+func AddInventoryImport(user string, userID string, fileName string, fileType string, source string, profileID string, rows [][]string, draftMapping map[string]string) (*model.InventoryImport, error) {
+	fileName = strings.TrimSpace(fileName)
+	if len(fileName) == 0 || utf8.RuneCountInString(fileName) > maxInventoryImportFileNameLength || !slices.Contains(inventoryImportFileTypes, fileType) {
+		config.Log("unable to validate selected file", errors.New(InvalidInventoryImport))
+		return nil, errors.New(InvalidInventoryImport)
+	}
+
+	if len(source) > 0 {
+		if _, err := retrieveInventoryImportMapper(source); err != nil {
+			config.Log("unable to validate selected source", err)
+			return nil, err
+		}
+	}
+
+	if len(profileID) > 0 {
+		if _, err := uuid.Parse(profileID); err != nil {
+			config.Log("unable to validate selected profile", err)
+			return nil, errors.New(InvalidInventoryImportProfile)
+		}
+	}
+
+	draftCells, err := splitInventoryImportRows(rows)
+	if err != nil {
+		config.Log("unable to read selected file", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	if len(profileID) > 0 {
+		profile, err := retrieveInventoryImportProfile(tx, userID, profileID)
+		if err != nil {
+			config.Log("unable to retrieve selected profile", err)
+			tx.Rollback()
+			return nil, err
+		}
+		if len(source) == 0 {
+			source = profile.Source
+		}
+		if len(draftMapping) == 0 {
+			draftMapping = make(map[string]string)
+			for field, header := range profile.Mapping {
+				if slices.Contains(draftCells.headers, header) {
+					draftMapping[field] = header
+				}
+			}
+		}
+	}
+
+	mapper, err := retrieveInventoryImportMapper(source)
+	if err != nil {
+		config.Log("unable to validate selected source", err)
+		tx.Rollback()
+		return nil, err
+	}
+	draftCells.source = mapper.name
+
+	draftCells.mapping, err = selectInventoryImportMapping(draftMapping, draftCells.headers, *mapper)
+	if err != nil {
+		config.Log("unable to validate column mapping", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	drafts, err := validateInventoryImportRows(tx, userID, *draftCells)
+	if err != nil {
+		config.Log("unable to validate selected rows", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	cells, err := json.Marshal(draftCells.cells)
+	if err != nil {
+		config.Log("unable to marshal selected cells", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	mapping, err := json.Marshal(draftCells.mapping)
+	if err != nil {
+		config.Log("unable to marshal column mapping", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	issues, err := json.Marshal(inventoryImportIssues(drafts))
+	if err != nil {
+		config.Log("unable to marshal selected issues", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.inventory_imports (
+		file_name,
+		file_type,
+		source,
+		headers,
+		cells,
+		first_row,
+		mapping,
+		issues,
+		total_rows,
+		valid_rows,
+		created_by,
+		updated_by,
+		sharable_groups
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11, $12)
+	RETURNING id;`
+
+	var importID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(
+		sqlStr,
+		fileName,
+		fileType,
+		draftCells.source,
+		pq.Array(draftCells.headers),
+		cells,
+		draftCells.firstRow,
+		mapping,
+		issues,
+		len(drafts),
+		countValidInventoryImportRows(drafts),
+		userID,
+		pq.Array([]string{userID}),
+	).Scan(&importID)
+	if err != nil {
+		config.Log("unable to add selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImport(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// UpdateInventoryImportMapping ...
+//
+// UpdateInventoryImportMapping selects the column mapping of the pending import and validates its rows again. An
+// empty mapping is derived from the header using the source of the import.
+func UpdateInventoryImportMapping(user string, userID string, importID string, draftMapping map[string]string) (*model.InventoryImport, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	draftCells, err := retrieveInventoryImportCellsForUpdate(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if draftCells.status == InventoryImportStatusCommitted {
+		config.Log("unable to update committed import", errors.New(InventoryImportAlreadyCommitted))
+		tx.Rollback()
+		return nil, errors.New(InventoryImportAlreadyCommitted)
+	}
+
+	mapper, err := retrieveInventoryImportMapper(draftCells.source)
+	if err != nil {
+		config.Log("unable to retrieve source of selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	draftCells.mapping, err = selectInventoryImportMapping(draftMapping, draftCells.headers, *mapper)
+	if err != nil {
+		config.Log("unable to validate column mapping", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	drafts, err := validateInventoryImportRows(tx, userID, *draftCells)
+	if err != nil {
+		config.Log("unable to validate selected rows", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	mapping, err := json.Marshal(draftCells.mapping)
+	if err != nil {
+		config.Log("unable to marshal column mapping", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	issues, err := json.Marshal(inventoryImportIssues(drafts))
+	if err != nil {
+		config.Log("unable to marshal selected issues", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory_imports
+		SET mapping = $3, issues = $4, total_rows = $5, valid_rows = $6, updated_by = $1, updated_at = $7
+		WHERE $1::UUID = ANY(sharable_groups) AND id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, userID, importID, mapping, issues, len(drafts), countValidInventoryImportRows(drafts), time.Now())
+	if err != nil {
+		config.Log("unable to update selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImport(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// CommitInventoryImport ...
+//
+// CommitInventoryImport validates the rows of the pending import again and adds an asset for each row without
+// errors. Unknown storage locations and categories are added. Rows that fail to be added, eg when the sku was added since the
+// import was uploaded, are reported as errors and do not prevent the remaining rows from being added.
+func CommitInventoryImport(user string, userID string, importID string) (*model.InventoryImport, error) {
+	return CommitInventoryImportWithProgress(user, userID, importID, nil)
+}
+
+// CommitInventoryImportWithProgress ...
+//
+// CommitInventoryImportWithProgress commits the pending import like CommitInventoryImport and calls progress with
+// the number of processed rows before each row. Nothing is added when progress returns an error.
+func CommitInventoryImportWithProgress(user string, userID string, importID string, progress func(processedRows int, totalRows int) error) (*model.InventoryImport, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		config.Log("unable to parse the creator id", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	draftCells, err := retrieveInventoryImportCellsForUpdate(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if draftCells.status == InventoryImportStatusCommitted {
+		config.Log("unable to commit import twice", errors.New(InventoryImportAlreadyCommitted))
+		tx.Rollback()
+		return nil, errors.New(InventoryImportAlreadyCommitted)
+	}
+
+	drafts, err := validateInventoryImportRows(tx, userID, *draftCells)
+	if err != nil {
+		config.Log("unable to validate selected rows", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	importedRows := 0
+	storageLocationIDs := make(map[string]string)
+	categoryIDs := make(map[string]string)
+	for i := range drafts {
+		if progress != nil {
+			if err := progress(i, len(drafts)); err != nil {
+				config.Log("unable to commit import", err)
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		if len(drafts[i].report.Errors) > 0 {
+			continue
+		}
+
+		// each row is added within its own savepoint so that a single failed row does not abort the import. storage
+		// locations and categories of the row are added within the savepoint as well, so that a failed row does not
+		// leave them behind; they are only reused by the remaining rows once the row is added.
+		if _, err := tx.Exec(`SAVEPOINT inventory_import_row;`); err != nil {
+			config.Log("unable to add savepoint", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowStorageLocationIDs := make(map[string]string)
+		rowCategoryIDs := make(map[string]string)
+		err := func() error {
+			// storage location is unique key in the database. rows without a storage location are not stored anywhere
+			var storageLocationID interface{}
+			if location := drafts[i].inventory.Location; len(location) > 0 {
+				locationID, ok := storageLocationIDs[location]
+				if !ok {
+					addedLocationID, err := retrieveOrAddStorageLocationInTx(tx, location, userID)
+					if err != nil {
+						return err
+					}
+					locationID = addedLocationID
+					rowStorageLocationIDs[location] = locationID
+				}
+				storageLocationID = locationID
+			}
+
+			// categories are matched regardless of case so that each category is only added once
+			selectedCategoryIDs := make(map[string]string)
+			for _, category := range drafts[i].categories {
+				categoryID, ok := categoryIDs[strings.ToLower(category)]
+				if !ok {
+					categoryID, ok = rowCategoryIDs[strings.ToLower(category)]
+				}
+				if !ok {
+					addedCategoryID, err := retrieveOrAddImportedCategoryInTx(tx, category, userID)
+					if err != nil {
+						return err
+					}
+					categoryID = addedCategoryID
+					rowCategoryIDs[strings.ToLower(category)] = categoryID
+				}
+				selectedCategoryIDs[strings.ToLower(category)] = categoryID
+			}
+
+			inventoryID, err := addInventoryInTx(tx, drafts[i].inventory, storageLocationID, parsedUserID)
+			if err != nil {
+				return err
+			}
+			return addImportedInventoryToCategoriesInTx(tx, inventoryID, drafts[i].categories, selectedCategoryIDs, userID)
+		}()
+		if err != nil {
+			config.Log("unable to add imported asset", err)
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT inventory_import_row;`); err != nil {
+				config.Log("unable to rollback to savepoint", err)
+				tx.Rollback()
+				return nil, err
+			}
+			message := "unable to add asset"
+			if toDuplicateBarcodeOrSKUError(err).Error() == DuplicateBarcodeOrSKU {
+				message = DuplicateBarcodeOrSKU
+			}
+			drafts[i].addError("", "", message)
+			continue
+		}
+
+		if _, err := tx.Exec(`RELEASE SAVEPOINT inventory_import_row;`); err != nil {
+			config.Log("unable to release savepoint", err)
+			tx.Rollback()
+			return nil, err
+		}
+		maps.Copy(storageLocationIDs, rowStorageLocationIDs)
+		maps.Copy(categoryIDs, rowCategoryIDs)
+		importedRows++
+	}
+
+	issues, err := json.Marshal(inventoryImportIssues(drafts))
+	if err != nil {
+		config.Log("unable to marshal selected issues", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory_imports
+		SET status = $3, cells = '[]'::JSONB, issues = $4, total_rows = $5, valid_rows = $6, imported_rows = $7,
+		committed_at = $8, updated_by = $1, updated_at = $8
+		WHERE $1::UUID = ANY(sharable_groups) AND id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(
+		sqlStr,
+		userID,
+		importID,
+		InventoryImportStatusCommitted,
+		issues,
+		len(drafts),
+		countValidInventoryImportRows(drafts),
+		importedRows,
+		time.Now(),
+	)
+	if err != nil {
+		config.Log("unable to update selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveInventoryImport(tx, userID, importID)
+	if err != nil {
+		config.Log("unable to retrieve selected import", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RemoveInventoryImport ...
+//
+// RemoveInventoryImport removes the selected import. Assets added by the import are not removed.
+func RemoveInventoryImport(user string, userID string, importID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.inventory_imports ii WHERE $1::UUID = ANY(ii.sharable_groups) AND ii.id = $2 RETURNING ii.id;`
+
+	var removedImportID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, userID, importID).Scan(&removedImportID)
+	if err != nil {
+		config.Log("unable to remove selected import", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveInventoryImport ...
+//
+// returns the selected import. Returns sql.ErrNoRows when the import is not found.
+func retrieveInventoryImport(tx *sql.Tx, userID string, importID string) (*model.InventoryImport, error) {
+	data, err := retrieveInventoryImports(tx, " AND ii.id = $2", userID, importID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &data[0], nil
+}
+
+// retrieveInventoryImports ...
+//
+// returns the imports visible to the selected user. The selected user is the first param.
+func retrieveInventoryImports(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryImport, error) {
+	sqlStr := `SELECT
+		ii.id,
+		ii.file_name,
+		ii.file_type,
+		ii.source,
+		ii.status,
+		ii.headers,
+		ii.mapping,
+		ii.issues,
+		ii.total_rows,
+		ii.valid_rows,
+		ii.imported_rows,
+		ii.committed_at,
+		ii.created_at,
+		COALESCE(ii.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		ii.updated_at,
+		COALESCE(ii.updated_by::TEXT, ''),
+		ii.sharable_groups
+	FROM community.inventory_imports ii
+	LEFT JOIN community.profiles cp ON cp.id = ii.created_by
+	WHERE $1::UUID = ANY(ii.sharable_groups)` + additionalWhereClause + `
+	ORDER BY ii.created_at DESC;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.InventoryImport, 0)
+	for rows.Next() {
+		var inventoryImport model.InventoryImport
+		var mapping, issues []byte
+		var committedAt sql.NullTime
+		if err := rows.Scan(
+			&inventoryImport.ID,
+			&inventoryImport.FileName,
+			&inventoryImport.FileType,
+			&inventoryImport.Source,
+			&inventoryImport.Status,
+			pq.Array(&inventoryImport.Headers),
+			&mapping,
+			&issues,
+			&inventoryImport.TotalRows,
+			&inventoryImport.ValidRows,
+			&inventoryImport.ImportedRows,
+			&committedAt,
+			&inventoryImport.CreatedAt,
+			&inventoryImport.CreatedBy,
+			&inventoryImport.Creator,
+			&inventoryImport.UpdatedAt,
+			&inventoryImport.UpdatedBy,
+			pq.Array(&inventoryImport.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected imports", err)
+			return nil, err
+		}
+
+		if err := json.Unmarshal(mapping, &inventoryImport.Mapping); err != nil {
+			config.Log("unable to unmarshal column mapping", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(issues, &inventoryImport.Issues); err != nil {
+			config.Log("unable to unmarshal selected issues", err)
+			return nil, err
+		}
+		if committedAt.Valid {
+			inventoryImport.CommittedAt = &committedAt.Time
+		}
+		inventoryImport.UnmappedHeaders = unmappedInventoryImportHeaders(inventoryImport.Headers, inventoryImport.Mapping)
+		data = append(data, inventoryImport)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// retrieveInventoryImportCellsForUpdate ...
+//
+// returns the stored cells of the selected import and locks the import until the transaction ends
+func retrieveInventoryImportCellsForUpdate(tx *sql.Tx, userID string, importID string) (*inventoryImportCells, error) {
+	sqlStr := `SELECT ii.status, ii.source, ii.headers, ii.cells, ii.first_row, ii.mapping
+		FROM community.inventory_imports ii
+		WHERE $1::UUID = ANY(ii.sharable_groups) AND ii.id = $2
+		FOR UPDATE;`
+
+	var draftCells inventoryImportCells
+	var cells, mapping []byte
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, userID, importID).Scan(
+		&draftCells.status,
+		&draftCells.source,
+		pq.Array(&draftCells.headers),
+		&cells,
+		&draftCells.firstRow,
+		&mapping,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(cells, &draftCells.cells); err != nil {
+		config.Log("unable to unmarshal selected cells", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(mapping, &draftCells.mapping); err != nil {
+		config.Log("unable to unmarshal column mapping", err)
+		return nil, err
+	}
+	return &draftCells, nil
+}
+
+// splitInventoryImportRows ...
+//
+// splits the rows of the uploaded file into the header and the cells below it. Empty rows before the header are
+// skipped and empty rows at the end of the file are removed.
+func splitInventoryImportRows(rows [][]string) (*inventoryImportCells, error) {
+	headerIndex := slices.IndexFunc(rows, func(row []string) bool { return !isEmptyInventoryImportRow(row) })
+	if headerIndex < 0 {
+		return nil, fmt.Errorf("%s: missing header row", InvalidInventoryImport)
+	}
+
+	headers := make([]string, len(rows[headerIndex]))
+	for i, v := range rows[headerIndex] {
+		headers[i] = strings.TrimSpace(v)
+	}
+
+	cells := rows[headerIndex+1:]
+	for len(cells) > 0 && isEmptyInventoryImportRow(cells[len(cells)-1]) {
+		cells = cells[:len(cells)-1]
+	}
+
+	totalRows := 0
+	for _, row := range cells {
+		if !isEmptyInventoryImportRow(row) {
+			totalRows++
+		}
+	}
+	if totalRows == 0 {
+		return nil, fmt.Errorf("%s: missing rows below the header", InvalidInventoryImport)
+	}
+	if totalRows > MaxInventoryImportRows {
+		return nil, errors.New(TooManyInventoryImportRows)
+	}
+
+	return &inventoryImportCells{
+		status:   InventoryImportStatusPending,
+		headers:  headers,
+		cells:    cells,
+		firstRow: headerIndex + 2,
+	}, nil
+}
+
+// selectInventoryImportMapping ...
+//
+// returns the selected column mapping after ensuring that each field and header exists. Fields mapped to an empty
+// header are left out. When no mapping is selected, the mapping is derived from the header by the selected mapper.
+func selectInventoryImportMapping(draftMapping map[string]string, headers []string, mapper inventoryImportMapper) (map[string]string, error) {
+	if len(draftMapping) == 0 {
+		return mapper.detectMapping(headers), nil
+	}
+
+	mapping := make(map[string]string)
+	for field, header := range draftMapping {
+		if _, ok := inventoryImportColumns[field]; !ok {
+			return nil, fmt.Errorf("%s: unknown field %s", InvalidInventoryImportMapping, field)
+		}
+		header = strings.TrimSpace(header)
+		if len(header) == 0 {
+			continue
+		}
+		if !slices.Contains(headers, header) {
+			return nil, fmt.Errorf("%s: unknown column %s", InvalidInventoryImportMapping, header)
+		}
+		mapping[field] = header
+	}
+	return mapping, nil
+}
+
+// normalizeInventoryImportHeader ...
+//
+// returns the header in lower case without spaces and punctuation
+func normalizeInventoryImportHeader(header string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// unmappedInventoryImportHeaders ...
+//
+// returns the headers that are not mapped to any field of the asset
+func unmappedInventoryImportHeaders(headers []string, mapping map[string]string) []string {
+	unmappedHeaders := make([]string, 0)
+	for _, header := range headers {
+		if len(header) == 0 {
+			continue
+		}
+		isMapped := false
+		for _, v := range mapping {
+			if v == header {
+				isMapped = true
+				break
+			}
+		}
+		if !isMapped {
+			unmappedHeaders = append(unmappedHeaders, header)
+		}
+	}
+	return unmappedHeaders
+}
+
+// validateInventoryImportRows ...
+//
+// parses each row of the selected cells into an asset and reports the errors and warnings of each row. Skus and
+// barcodes must be unique within the file and among the assets of the user that are not in the trash. Storage
+// locations and categories that do not exist are reported as a warning since they are added when the import is
+// committed.
+func validateInventoryImportRows(tx *sql.Tx, userID string, draftCells inventoryImportCells) ([]inventoryImportDraft, error) {
+	mapper, err := retrieveInventoryImportMapper(draftCells.source)
+	if err != nil {
+		config.Log("unable to retrieve source of selected import", err)
+		return nil, err
+	}
+
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		return nil, err
+	}
+
+	columnIndexes := make(map[string]int)
+	for field, header := range draftCells.mapping {
+		if index := slices.Index(draftCells.headers, header); index >= 0 {
+			columnIndexes[field] = index
+		}
+	}
+
+	drafts := make([]inventoryImportDraft, 0, len(draftCells.cells))
+	skuRows := make(map[string]int)
+	barcodeRows := make(map[string]int)
+	for i, row := range draftCells.cells {
+		if isEmptyInventoryImportRow(row) {
+			continue
+		}
+
+		draft := parseInventoryImportRow(row, columnIndexes, draftCells.mapping, *mapper, unitSystem, userID)
+		draft.report.Row = draftCells.firstRow + i
+
+		if sku := draft.inventory.SKU; len(sku) > 0 {
+			if duplicateRow, ok := skuRows[sku]; ok {
+				draft.addError("sku", draftCells.mapping["sku"], fmt.Sprintf("sku is a duplicate of row %d", duplicateRow))
+			} else {
+				skuRows[sku] = draft.report.Row
+			}
+		}
+		if barcode := draft.inventory.Barcode; len(barcode) > 0 {
+			if duplicateRow, ok := barcodeRows[barcode]; ok {
+				draft.addError("barcode", draftCells.mapping["barcode"], fmt.Sprintf("barcode is a duplicate of row %d", duplicateRow))
+			} else {
+				barcodeRows[barcode] = draft.report.Row
+			}
+		}
+		drafts = append(drafts, draft)
+	}
+
+	skus := make([]string, 0, len(drafts))
+	barcodes := make([]string, 0, len(drafts))
+	locations := make([]string, 0, len(drafts))
+	names := make([]string, 0, len(drafts))
+	categories := make([]string, 0, len(drafts))
+	for _, v := range drafts {
+		skus = append(skus, v.inventory.SKU)
+		barcodes = append(barcodes, v.inventory.Barcode)
+		locations = append(locations, v.inventory.Location)
+		names = append(names, strings.ToLower(v.inventory.Name))
+		for _, category := range v.categories {
+			categories = append(categories, strings.ToLower(category))
+		}
+	}
+
+	existingSKUs, existingBarcodes, err := retrieveExistingInventoryCodes(tx, userID, skus, barcodes)
+	if err != nil {
+		config.Log("unable to retrieve existing skus and barcodes", err)
+		return nil, err
+	}
+
+	existingLocations, err := retrieveExistingStorageLocations(tx, locations)
+	if err != nil {
+		config.Log("unable to retrieve existing storage locations", err)
+		return nil, err
+	}
+
+	existingNames, err := retrieveExistingInventoryNames(tx, userID, names)
+	if err != nil {
+		config.Log("unable to retrieve existing asset names", err)
+		return nil, err
+	}
+
+	existingCategories, err := retrieveExistingCategoryNames(tx, userID, categories)
+	if err != nil {
+		config.Log("unable to retrieve existing categories", err)
+		return nil, err
+	}
+
+	for i := range drafts {
+		inventory := drafts[i].inventory
+		if existingSKUs[inventory.SKU] {
+			drafts[i].addError("sku", draftCells.mapping["sku"], "sku already exists")
+		}
+		if existingBarcodes[inventory.Barcode] {
+			drafts[i].addError("barcode", draftCells.mapping["barcode"], "barcode already exists")
+		}
+		if len(inventory.Location) > 0 && !existingLocations[inventory.Location] {
+			drafts[i].addWarning("location", draftCells.mapping["location"], "storage location does not exist and will be added")
+		}
+		if len(inventory.Name) > 0 && existingNames[strings.ToLower(inventory.Name)] {
+			drafts[i].addWarning("name", draftCells.mapping["name"], "asset with the same name already exists")
+		}
+		for _, category := range drafts[i].categories {
+			if !existingCategories[strings.ToLower(category)] {
+				drafts[i].addWarning("categories", draftCells.mapping["categories"], fmt.Sprintf("category %s does not exist and will be added", category))
+			}
+		}
+	}
+	return drafts, nil
+}
+
+// parseInventoryImportRow ...
+//
+// parses the cells of a single row into an asset and its categories. Empty quantities default to one and empty
+// currencies default to the base currency of the user. Dimensions and weights can have a unit suffix, eg 12kg or
+// 3 ft. The categories cell is split by the selected mapper.
+func parseInventoryImportRow(row []string, columnIndexes map[string]int, mapping map[string]string, mapper inventoryImportMapper, unitSystem string, userID string) inventoryImportDraft {
+	var draft inventoryImportDraft
+
+	cells := make(map[string]string)
+	for field, index := range columnIndexes {
+		if index < len(row) {
+			cells[field] = strings.TrimSpace(row[index])
+		}
+	}
+
+	if len(cells["name"]) == 0 {
+		draft.addError("name", mapping["name"], "name is required")
+	}
+	for _, v := range inventoryImportTextFields {
+		if utf8.RuneCountInString(cells[v.field]) > v.maxLength {
+			draft.addError(v.field, mapping[v.field], fmt.Sprintf("%s cannot be longer than %d characters", v.field, v.maxLength))
+		}
+	}
+
+	draft.categories = mapper.splitCategories(cells["categories"])
+	for _, v := range draft.categories {
+		if utf8.RuneCountInString(v) > maxImportedCategoryNameLength {
+			draft.addError("categories", mapping["categories"], fmt.Sprintf("category cannot be longer than %d characters", maxImportedCategoryNameLength))
+			break
+		}
+	}
+
+	rawInventory := model.RawInventory{
+		Name:             cells["name"],
+		Description:      cells["description"],
+		Quantity:         1,
+		StorageLocation:  cells["location"],
+		Color:            cells["color"],
+		SKU:              cells["sku"],
+		Barcode:          cells["barcode"],
+		PurchaseLocation: cells["bought_at"],
+		MaximumWeight:    model.RawMeasurement(cells["max_weight"]),
+		MinimumWeight:    model.RawMeasurement(cells["min_weight"]),
+		MaximumHeight:    model.RawMeasurement(cells["max_height"]),
+		MinimumHeight:    model.RawMeasurement(cells["min_height"]),
+		Length:           model.RawMeasurement(cells["length"]),
+		Width:            model.RawMeasurement(cells["width"]),
+		Height:           model.RawMeasurement(cells["height"]),
+		Weight:           model.RawMeasurement(cells["weight"]),
+	}
+
+	if len(cells["price"]) > 0 {
+		price, err := parseInventoryImportPrice(cells["price"])
+		if err != nil {
+			draft.addError("price", mapping["price"], "price is not a number")
+		} else if price < 0 || price >= maxInventoryImportPrice {
+			draft.addError("price", mapping["price"], fmt.Sprintf("price must be between 0 and %d", maxInventoryImportPrice))
+		}
+		rawInventory.Price = price
+	}
+
+	currency, err := normalizeCurrency(cells["currency"])
+	if err != nil {
+		draft.addError("currency", mapping["currency"], "currency must be a three letter code, eg USD")
+	}
+	rawInventory.Currency = currency
+
+	if len(cells["quantity"]) > 0 {
+		quantity, err := strconv.ParseFloat(strings.ReplaceAll(cells["quantity"], ",", ""), 64)
+		if err != nil || quantity != math.Trunc(quantity) || quantity > math.MaxInt32 {
+			draft.addError("quantity", mapping["quantity"], "quantity is not a whole number")
+		} else if quantity < 0 {
+			draft.addError("quantity", mapping["quantity"], "quantity cannot be negative")
+		}
+		rawInventory.Quantity = int64(quantity)
+	}
+
+	draft.inventory = model.Inventory{
+		Name:           rawInventory.Name,
+		Description:    rawInventory.Description,
+		Price:          rawInventory.Price,
+		Currency:       rawInventory.Currency,
+		Status:         importedInventoryStatus,
+		Barcode:        rawInventory.Barcode,
+		SKU:            rawInventory.SKU,
+		Color:          rawInventory.Color,
+		Quantity:       int(rawInventory.Quantity),
+		Location:       rawInventory.StorageLocation,
+		BoughtAt:       rawInventory.PurchaseLocation,
+		SharableGroups: []string{userID},
+	}
+
+	isMeasurementValid := true
+	for _, v := range inventoryImportMeasurementFields {
+		if _, _, err := ParseMeasurement(cells[v.field], v.kind); err != nil {
+			draft.addError(v.field, mapping[v.field], fmt.Sprintf("%s must be a number with an optional unit, eg 12kg or 3 ft", v.field))
+			isMeasurementValid = false
+		}
+	}
+	if isMeasurementValid {
+		if err := ApplyRawInventoryMeasurements(rawInventory, &draft.inventory); err != nil {
+			draft.addError("", "", err.Error())
+		} else if err := ValidateInventoryMeasurements(draft.inventory); err != nil {
+			draft.addError("", "", "dimensions and weights cannot be negative")
+		}
+		applyDefaultUnits(&draft.inventory, unitSystem)
+	}
+	return draft
+}
+
+// parseInventoryImportPrice ...
+//
+// parses the selected price with an optional currency symbol and thousands separator, eg $1,200.50. Prices that
+// use a decimal comma, eg 12,50 or 1.200,50, are also supported.
+func parseInventoryImportPrice(draftPrice string) (float64, error) {
+	draftPrice = strings.ReplaceAll(strings.TrimLeft(draftPrice, "$€£¥₹ "), " ", "")
+
+	lastCommaIndex := strings.LastIndex(draftPrice, ",")
+	lastDotIndex := strings.LastIndex(draftPrice, ".")
+	if lastCommaIndex > lastDotIndex && (lastDotIndex >= 0 || len(draftPrice)-lastCommaIndex-1 != 3) {
+		draftPrice = strings.ReplaceAll(strings.ReplaceAll(draftPrice, ".", ""), ",", ".")
+	} else {
+		draftPrice = strings.ReplaceAll(draftPrice, ",", "")
+	}
+
+	price, err := strconv.ParseFloat(draftPrice, 64)
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0, errors.New(InvalidInventoryImport)
+	}
+	return price, nil
+}
+
+// retrieveExistingInventoryCodes ...
+//
+// returns the selected skus and barcodes that are already used by the assets of the user. Assets in the trash do
+// not hold on to their sku or barcode.
+func retrieveExistingInventoryCodes(tx *sql.Tx, userID string, skus []string, barcodes []string) (map[string]bool, map[string]bool, error) {
+	sqlStr := `SELECT COALESCE(inv.sku, ''), COALESCE(inv.barcode, '')
+		FROM community.inventory inv
+		WHERE inv.created_by = $1
+		AND inv.deleted_at IS NULL
+		AND (inv.sku = ANY($2) OR inv.barcode = ANY($3));`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, userID, pq.Array(skus), pq.Array(barcodes))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	existingSKUs := make(map[string]bool)
+	existingBarcodes := make(map[string]bool)
+	for rows.Next() {
+		var sku, barcode string
+		if err := rows.Scan(&sku, &barcode); err != nil {
+			return nil, nil, err
+		}
+		if len(sku) > 0 && slices.Contains(skus, sku) {
+			existingSKUs[sku] = true
+		}
+		if len(barcode) > 0 && slices.Contains(barcodes, barcode) {
+			existingBarcodes[barcode] = true
+		}
+	}
+	return existingSKUs, existingBarcodes, rows.Err()
+}
+
+// retrieveExistingStorageLocations ...
+//
+// returns the selected storage locations that already exist
+func retrieveExistingStorageLocations(tx *sql.Tx, locations []string) (map[string]bool, error) {
+	sqlStr := `SELECT sl.location FROM community.storage_locations sl WHERE sl.location = ANY($1);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, pq.Array(locations))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existingLocations := make(map[string]bool)
+	for rows.Next() {
+		var location string
+		if err := rows.Scan(&location); err != nil {
+			return nil, err
+		}
+		existingLocations[location] = true
+	}
+	return existingLocations, rows.Err()
+}
+
+// retrieveExistingInventoryNames ...
+//
+// returns the selected names in lower case that are already used by the assets visible to the user
+func retrieveExistingInventoryNames(tx *sql.Tx, userID string, names []string) (map[string]bool, error) {
+	sqlStr := `SELECT DISTINCT LOWER(inv.name)
+		FROM community.inventory inv
+		WHERE $1::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		AND LOWER(inv.name) = ANY($2);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, userID, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existingNames := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existingNames[name] = true
+	}
+	return existingNames, rows.Err()
+}
+
+// retrieveExistingCategoryNames ...
+//
+// returns the selected category names in lower case that are already used by the categories visible to the user
+func retrieveExistingCategoryNames(tx *sql.Tx, userID string, names []string) (map[string]bool, error) {
+	existingNames := make(map[string]bool)
+	if len(names) == 0 {
+		return existingNames, nil
+	}
+
+	sqlStr := `SELECT DISTINCT LOWER(c.name)
+		FROM community.category c
+		WHERE $1::UUID = ANY(c.sharable_groups)
+		AND c.deleted_at IS NULL
+		AND LOWER(c.name) = ANY($2);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, userID, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existingNames[name] = true
+	}
+	return existingNames, rows.Err()
+}
+
+// retrieveOrAddImportedCategoryInTx ...
+//
+// returns the id of the oldest category visible to the user with the selected name regardless of case. The
+// category is added with the general status and the default color when it does not exist.
+func retrieveOrAddImportedCategoryInTx(tx *sql.Tx, name string, userID string) (string, error) {
+	sqlStr := `SELECT c.id
+		FROM community.category c
+		WHERE $1::UUID = ANY(c.sharable_groups)
+		AND c.deleted_at IS NULL
+		AND LOWER(c.name) = LOWER($2)
+		ORDER BY c.created_at
+		LIMIT 1;`
+
+	var categoryID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, userID, name).Scan(&categoryID)
+	if err == nil {
+		return categoryID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	addSqlStr := `INSERT INTO community.category(name, description, color, status, created_by, created_at, updated_by, updated_at, sharable_groups)
+		VALUES ($2, '', $4, (SELECT s.id FROM community.statuses s WHERE s.name = $3 LIMIT 1), $1, $5, $1, $5, ARRAY[$1::UUID])
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, addSqlStr)
+	err = tx.QueryRow(addSqlStr, userID, name, importedCategoryStatus, importedCategoryColor, time.Now()).Scan(&categoryID)
+	if err != nil {
+		return "", err
+	}
+	return categoryID, nil
+}
+
+// addImportedInventoryToCategoriesInTx ...
+//
+// adds the imported asset to each of the selected categories. CategoryIDs are keyed by the name of the category in
+// lower case.
+func addImportedInventoryToCategoriesInTx(tx *sql.Tx, inventoryID string, categories []string, categoryIDs map[string]string, userID string) error {
+	sqlStr := `INSERT INTO community.category_item(category_id, item_id, created_by, created_at, updated_by, updated_at, sharable_groups)
+		VALUES ($1, $2, $3, $4, $3, $4, ARRAY[$3::UUID]);`
+
+	for _, category := range categories {
+		config.Log("SqlStr: %s", nil, sqlStr)
+		if _, err := tx.Exec(sqlStr, categoryIDs[strings.ToLower(category)], inventoryID, userID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inventoryImportIssues ...
+//
+// returns the rows with errors or warnings in the order of the file
+func inventoryImportIssues(drafts []inventoryImportDraft) []model.InventoryImportRow {
+	issues := make([]model.InventoryImportRow, 0)
+	for _, v := range drafts {
+		if len(v.report.Errors) > 0 || len(v.report.Warnings) > 0 {
+			issues = append(issues, v.report)
+		}
+	}
+	return issues
+}
+
+// countValidInventoryImportRows ...
+//
+// returns the number of rows without errors
+func countValidInventoryImportRows(drafts []inventoryImportDraft) int {
+	count := 0
+	for _, v := range drafts {
+		if len(v.report.Errors) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// isEmptyInventoryImportRow ...
+//
+// returns true when every cell of the selected row is blank
+func isEmptyInventoryImportRow(row []string) bool {
+	for _, v := range row {
+		if len(strings.TrimSpace(v)) > 0 {
+			return false
+		}
+	}
+	return true
+}
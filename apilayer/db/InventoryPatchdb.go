@@ -0,0 +1,464 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+)
+
+const (
+	InvalidInventoryPatch = "invalid inventory patch"
+	StaleInventory        = "inventory has been modified since it was last retrieved"
+)
+
+// inventoryPatchMaxLength is the maximum length of each text column that can be patched
+var inventoryPatchMaxLength = map[string]int{
+	"name":                100,
+	"description":         500,
+	"status":              100,
+	"barcode":             100,
+	"sku":                 100,
+	"color":               10,
+	"bought_at":           500,
+	"return_location":     200,
+	"return_notes":        250,
+	"depreciation_method": 20,
+}
+
+// inventoryPatchColumns are the columns of an inventory that can be changed with a merge patch
+var inventoryPatchColumns = map[string]bool{
+	"name":                true,
+	"description":         true,
+	"price":               true,
+	"currency":            true,
+	"status":              true,
+	"barcode":             true,
+	"sku":                 true,
+	"color":               true,
+	"quantity":            true,
+	"reorder_point":       true,
+	"storage_location_id": true,
+	"is_returnable":       true,
+	"return_location":     true,
+	"return_datetime":     true,
+	"return_notes":        true,
+	"max_weight":          true,
+	"min_weight":          true,
+	"max_height":          true,
+	"min_height":          true,
+	"bought_at":           true,
+	"purchase_date":       true,
+	"expiry_date":         true,
+	"useful_life_years":   true,
+	"salvage_value":       true,
+	"depreciation_method": true,
+	"custom_attributes":   true,
+	"length":              true,
+	"width":               true,
+	"height":              true,
+	"weight":              true,
+	"dimension_unit":      true,
+	"weight_unit":         true,
+}
+
+// InventoryETag ...
+//
+// InventoryETag returns the entity tag of the selected asset. The tag changes every time the asset is updated.
+func InventoryETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%d"`, updatedAt.UnixMicro())
+}
+
+// PatchInventory ...
+//
+// PatchInventory applies the selected json merge patch to the selected asset. Fields that are not part of the patch
+// are left untouched and null removes the value of the field. When ifMatch is not empty, the patch is applied only if
+// one of the entity tags matches the current state of the asset.
+func PatchInventory(user string, userID string, invID string, patch map[string]interface{}, ifMatch []string) (*model.Inventory, error) {
+	for column := range patch {
+		if !inventoryPatchColumns[column] {
+			config.Log("unable to patch selected column %s", errors.New(InvalidInventoryPatch), column)
+			return nil, errors.New(InvalidInventoryPatch)
+		}
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	before, err := snapshotInventory(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	current, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !isInventoryETagMatch(ifMatch, current.UpdatedAt) {
+		config.Log("unable to patch selected asset", errors.New(StaleInventory))
+		tx.Rollback()
+		return nil, errors.New(StaleInventory)
+	}
+
+	// the patch is applied to the asset as the user reads it, so dimensions and weights are in the unit system of the user
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := convertInventoryUnits(current, unitSystem); err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := applyPatchedUnits(current, patch); err != nil {
+		config.Log("unable to apply units of selected patch", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	draftInventory, err := applyInventoryPatch(*current, patch)
+	if err != nil {
+		config.Log("unable to apply patch to selected asset", err)
+		tx.Rollback()
+		return nil, errors.New(InvalidInventoryPatch)
+	}
+
+	if err := validateInventoryPatch(draftInventory); err != nil {
+		config.Log("unable to validate patch of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+	applyDefaultUnits(&draftInventory, unitSystem)
+
+	// removing the currency resets the price to the base currency of the user
+	draftInventory.Currency, err = normalizeCurrency(draftInventory.Currency)
+	if err != nil {
+		config.Log("unable to validate currency", err)
+		tx.Rollback()
+		return nil, errors.New(InvalidInventoryPatch)
+	}
+
+	if err := ValidateDepreciation(draftInventory); err != nil {
+		config.Log("unable to validate depreciation", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = validateSerializedInventoryQuantity(tx, invID, current.Quantity, draftInventory.Quantity)
+	if err != nil {
+		config.Log("unable to validate quantity of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = validateInventoryCustomAttributes(tx, userID, invID, nil, draftInventory.CustomAttributes, current.CustomAttributes)
+	if err != nil {
+		config.Log("unable to validate custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	draftCustomAttributes, err := formatCustomAttributes(draftInventory.CustomAttributes)
+	if err != nil {
+		config.Log("unable to format custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !draftInventory.IsReturnable {
+		draftInventory.ReturnLocation = ""
+		draftInventory.ReturnDateTime = nil
+	}
+
+	// the location is derived from the storage location so that both are always in sync
+	sqlStr := `UPDATE community.inventory inv
+		SET name = $2,
+			description = $3,
+			price = $4,
+			status = $5,
+			barcode = $6,
+			sku = $7,
+			color = NULLIF($8, ''),
+			quantity = $9,
+			reorder_point = NULLIF($10, 0),
+			storage_location_id = sl.id,
+			location = sl.location,
+			is_returnable = $12,
+			return_location = $13,
+			return_datetime = $14,
+			return_notes = $15,
+			max_weight = $16,
+			min_weight = $17,
+			max_height = $18,
+			min_height = $19,
+			bought_at = $20,
+			purchase_date = $21,
+			useful_life_years = NULLIF($22, 0),
+			salvage_value = $23,
+			depreciation_method = NULLIF($24, ''),
+			custom_attributes = $25,
+			updated_by = $26,
+			updated_at = $27,
+			length = $28,
+			width = $29,
+			height = $30,
+			weight = $31,
+			dimension_unit = $32,
+			weight_unit = $33,
+			currency = COALESCE(NULLIF($34, ''), (SELECT p.base_currency FROM community.profiles p WHERE p.id = $26)),
+			expiry_date = $35
+		FROM community.storage_locations sl
+		WHERE inv.id = $1
+		AND sl.id = $11;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	result, err := tx.Exec(
+		sqlStr,
+		invID,
+		draftInventory.Name,
+		draftInventory.Description,
+		draftInventory.Price,
+		draftInventory.Status,
+		draftInventory.Barcode,
+		draftInventory.SKU,
+		draftInventory.Color,
+		draftInventory.Quantity,
+		draftInventory.ReorderPoint,
+		draftInventory.StorageLocationID,
+		draftInventory.IsReturnable,
+		draftInventory.ReturnLocation,
+		draftInventory.ReturnDateTime,
+		draftInventory.ReturnNotes,
+		draftInventory.MaxWeight,
+		draftInventory.MinWeight,
+		draftInventory.MaxHeight,
+		draftInventory.MinHeight,
+		draftInventory.BoughtAt,
+		draftInventory.PurchaseDate,
+		draftInventory.UsefulLifeYears,
+		draftInventory.SalvageValue,
+		draftInventory.DepreciationMethod,
+		draftCustomAttributes,
+		userID,
+		time.Now(),
+		draftInventory.Length,
+		draftInventory.Width,
+		draftInventory.Height,
+		draftInventory.Weight,
+		draftInventory.DimensionUnit,
+		draftInventory.WeightUnit,
+		draftInventory.Currency,
+		draftInventory.ExpiryDate,
+	)
+	if err != nil {
+		config.Log("unable to patch selected asset", err)
+		tx.Rollback()
+		return nil, toDuplicateBarcodeOrSKUError(err)
+	}
+
+	updatedRows, err := result.RowsAffected()
+	if err != nil || updatedRows == 0 {
+		config.Log("unable to find selected storage location", err)
+		tx.Rollback()
+		return nil, errors.New(InvalidInventoryPatch)
+	}
+
+	if draftInventory.StorageLocationID != current.StorageLocationID {
+		err = moveInventoryDescendants(tx, userID, invID)
+		if err != nil {
+			config.Log("unable to move selected asset with parent", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = recordInventoryRevision(tx, userID, invID, before, InventoryRevisionActionUpdate)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := convertInventoryUnits(data, unitSystem); err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// isInventoryETagMatch ...
+//
+// isInventoryETagMatch returns true if any of the selected entity tags matches the current state of the asset.
+// Weak tags never match as the patch requires a strong comparison.
+func isInventoryETagMatch(ifMatch []string, updatedAt time.Time) bool {
+	if len(ifMatch) == 0 {
+		return true
+	}
+	currentETag := InventoryETag(updatedAt)
+	for _, v := range ifMatch {
+		if v == "*" || v == currentETag {
+			return true
+		}
+	}
+	return false
+}
+
+// applyInventoryPatch ...
+//
+// applyInventoryPatch merges the selected patch into the selected asset as described in RFC 7396. Values that
+// do not match the type of the field are rejected.
+func applyInventoryPatch(inventory model.Inventory, patch map[string]interface{}) (model.Inventory, error) {
+	currentData, err := json.Marshal(inventory)
+	if err != nil {
+		return model.Inventory{}, err
+	}
+
+	var current map[string]interface{}
+	if err := json.Unmarshal(currentData, &current); err != nil {
+		return model.Inventory{}, err
+	}
+
+	patchedData, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		return model.Inventory{}, err
+	}
+
+	var patched model.Inventory
+	if err := json.Unmarshal(patchedData, &patched); err != nil {
+		return model.Inventory{}, err
+	}
+	return patched, nil
+}
+
+// mergePatch ...
+//
+// mergePatch applies the patch to the target. Objects are merged recursively, null removes the key
+// and every other value replaces the value of the target.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// applyPatchedUnits ...
+//
+// applyPatchedUnits converts the dimensions or weights of the asset into the unit selected in the patch, so that
+// the values that are not part of the patch keep their meaning once the unit changes
+func applyPatchedUnits(inventory *model.Inventory, patch map[string]interface{}) error {
+	for kind, column := range map[string]string{
+		MeasurementKindDimension: "dimension_unit",
+		MeasurementKindWeight:    "weight_unit",
+	} {
+		unit, ok := patch[column].(string)
+		if !ok || len(unit) == 0 {
+			continue
+		}
+		if _, ok := measurementUnits[kind][unit]; !ok {
+			return errors.New(InvalidInventoryPatch)
+		}
+		if err := convertInventoryMeasurements(inventory, kind, unit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateInventoryPatch ...
+//
+// validateInventoryPatch validates the patched asset against the constraints of the inventory
+func validateInventoryPatch(draftInventory model.Inventory) error {
+	if len(strings.TrimSpace(draftInventory.Name)) <= 0 {
+		return errors.New(InvalidInventoryPatch)
+	}
+
+	for column, value := range map[string]string{
+		"name":                draftInventory.Name,
+		"description":         draftInventory.Description,
+		"status":              draftInventory.Status,
+		"barcode":             draftInventory.Barcode,
+		"sku":                 draftInventory.SKU,
+		"color":               draftInventory.Color,
+		"bought_at":           draftInventory.BoughtAt,
+		"return_location":     draftInventory.ReturnLocation,
+		"return_notes":        draftInventory.ReturnNotes,
+		"depreciation_method": draftInventory.DepreciationMethod,
+	} {
+		if utf8.RuneCountInString(value) > inventoryPatchMaxLength[column] {
+			return errors.New(InvalidInventoryPatch)
+		}
+	}
+
+	if draftInventory.Price < 0 || draftInventory.Quantity < 0 || draftInventory.ReorderPoint < 0 {
+		return errors.New(InvalidInventoryPatch)
+	}
+
+	if err := ValidateInventoryMeasurements(draftInventory); err != nil {
+		return errors.New(InvalidInventoryPatch)
+	}
+
+	if draftInventory.MaxWeight > 0 && draftInventory.MinWeight > draftInventory.MaxWeight {
+		return errors.New(InvalidInventoryPatch)
+	}
+
+	if draftInventory.MaxHeight > 0 && draftInventory.MinHeight > draftInventory.MaxHeight {
+		return errors.New(InvalidInventoryPatch)
+	}
+
+	if _, err := uuid.Parse(draftInventory.StorageLocationID); err != nil {
+		return errors.New(InvalidInventoryPatch)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InventoryRevisionActionUpdate = "update"
+	InventoryRevisionActionImage  = "image"
+	InventoryRevisionActionRevert = "revert"
+	InventoryRevisionActionMerge  = "merge"
+)
+
+// inventoryRevisionColumns are the columns of an inventory that are tracked in the revision history.
+// Only these columns are restored when a prior revision is reverted.
+var inventoryRevisionColumns = []string{
+	"name",
+	"description",
+	"price",
+	"currency",
+	"status",
+	"barcode",
+	"sku",
+	"color",
+	"quantity",
+	"reorder_point",
+	"bought_at",
+	"purchase_date",
+	"expiry_date",
+	"useful_life_years",
+	"salvage_value",
+	"depreciation_method",
+	"location",
+	"storage_location_id",
+	"is_returnable",
+	"return_location",
+	"return_datetime",
+	"return_notes",
+	"max_weight",
+	"min_weight",
+	"max_height",
+	"min_height",
+	"length",
+	"width",
+	"height",
+	"weight",
+	"dimension_unit",
+	"weight_unit",
+	"associated_image_url",
+	"custom_attributes",
+}
+
+// RetrieveInventoryRevisions ...
+func RetrieveInventoryRevisions(user string, userID string, invID string) ([]model.InventoryRevision, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveInventoryRevisions(tx, "", userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve revisions for selected asset", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// RevertInventoryRevision ...
+//
+// RevertInventoryRevision restores the selected asset to the state right after the selected revision. Every change made
+// after the selected revision is undone in a single transaction and the revert itself is recorded as a new revision.
+func RevertInventoryRevision(user string, userID string, invID string, revisionID string) (*model.Inventory, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `SELECT ir.revision_number
+		FROM community.inventory_revisions ir
+		WHERE ir.id = $1
+		AND ir.item_id = $2
+		AND $3::UUID = ANY(ir.sharable_groups);`
+
+	var revisionNumber int64
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, revisionID, invID, userID).Scan(&revisionNumber)
+	if err != nil {
+		config.Log("unable to find selected revision", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	before, err := snapshotInventory(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the earliest change of each column after the selected revision holds the value at the selected revision
+	sqlStr = `SELECT COALESCE(jsonb_object_agg(earliest.key, earliest.before), '{}'::JSONB)
+		FROM (
+			SELECT DISTINCT ON (c.key) c.key, c.value -> 'before' AS before
+			FROM community.inventory_revisions ir, jsonb_each(ir.changes) c
+			WHERE ir.item_id = $1
+			AND ir.revision_number > $2
+			ORDER BY c.key, ir.revision_number ASC
+		) earliest;`
+
+	var patch []byte
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, invID, revisionNumber).Scan(&patch)
+	if err != nil {
+		config.Log("unable to derive selected revision", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	var prefixedColumns []string
+	for _, column := range inventoryRevisionColumns {
+		prefixedColumns = append(prefixedColumns, "p."+column)
+	}
+
+	sqlStr = `UPDATE community.inventory inv
+		SET (` + strings.Join(inventoryRevisionColumns, ", ") + `) = (
+			SELECT ` + strings.Join(prefixedColumns, ", ") + `
+			FROM jsonb_populate_record(inv, $2::JSONB) p
+		),
+		updated_by = $3,
+		updated_at = $4
+		WHERE inv.id = $1;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, patch, userID, time.Now())
+	if err != nil {
+		config.Log("unable to revert selected asset", err)
+		tx.Rollback()
+		return nil, toDuplicateBarcodeOrSKUError(err)
+	}
+
+	err = recordInventoryRevision(tx, userID, invID, before, InventoryRevisionActionRevert)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// snapshotInventory ...
+//
+// snapshotInventory locks the selected asset and returns the current state of the asset as json.
+// Used to derive the diff once the asset is updated.
+func snapshotInventory(tx *sql.Tx, userID string, invID string) ([]byte, error) {
+	sqlStr := `SELECT to_jsonb(inv)
+		FROM community.inventory inv
+		WHERE inv.id = $1
+		AND $2::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		FOR UPDATE;`
+
+	var snapshot []byte
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, invID, userID).Scan(&snapshot)
+	if err != nil {
+		config.Log("unable to retrieve snapshot of selected asset", err)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// recordInventoryRevision ...
+//
+// recordInventoryRevision compares the snapshot taken before the change with the current state of the asset
+// and stores the changed columns as a new revision. Nothing is stored if no tracked column has changed.
+func recordInventoryRevision(tx *sql.Tx, userID string, invID string, before []byte, action string) error {
+	sqlStr := `INSERT INTO community.inventory_revisions (item_id, action, changes, created_at, created_by, sharable_groups)
+		SELECT inv.id, $3, diff.changes, $5, $4, inv.sharable_groups
+		FROM community.inventory inv,
+		LATERAL (
+			SELECT jsonb_object_agg(b.key, jsonb_build_object('before', b.value, 'after', a.value)) AS changes
+			FROM jsonb_each($2::JSONB) b
+			JOIN jsonb_each(to_jsonb(inv)) a ON a.key = b.key
+			WHERE b.key = ANY($6::TEXT[])
+			AND b.value IS DISTINCT FROM a.value
+		) diff
+		WHERE inv.id = $1
+		AND diff.changes IS NOT NULL;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err := tx.Exec(sqlStr, invID, before, action, userID, time.Now(), pq.Array(inventoryRevisionColumns))
+	if err != nil {
+		config.Log("unable to record revision", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveInventoryRevisions ...
+//
+// retrieveInventoryRevisions returns the revisions of the selected asset with the most recent revision first.
+// userID is always $1 and invID is always $2.
+func retrieveInventoryRevisions(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryRevision, error) {
+	sqlStr := `SELECT
+		ir.id,
+		ir.revision_number,
+		ir.item_id,
+		ir.action,
+		ir.changes,
+		ir.created_at,
+		COALESCE(ir.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		ir.sharable_groups
+	FROM community.inventory_revisions ir
+	LEFT JOIN community.profiles cp ON cp.id = ir.created_by
+	WHERE $1::UUID = ANY(ir.sharable_groups)
+	AND ir.item_id = $2` + additionalWhereClause + `
+	ORDER BY ir.revision_number DESC;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.InventoryRevision, 0)
+	for rows.Next() {
+		var revision model.InventoryRevision
+		var changes []byte
+
+		if err := rows.Scan(
+			&revision.ID,
+			&revision.RevisionNumber,
+			&revision.ItemID,
+			&revision.Action,
+			&changes,
+			&revision.CreatedAt,
+			&revision.CreatedBy,
+			&revision.Creator,
+			pq.Array(&revision.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+
+		if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+			config.Log("unable to parse selected changes", err)
+			return nil, err
+		}
+
+		data = append(data, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	return data, nil
+}
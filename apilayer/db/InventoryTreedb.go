@@ -0,0 +1,325 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidInventoryParent = "invalid inventory parent"
+	InventoryParentCycle   = "inventory parent creates a cycle"
+
+	// maxInventoryTreeDepth guards the recursive queries against rows that were linked outside of the api
+	maxInventoryTreeDepth = 100
+)
+
+// RetrieveInventoryTree ...
+//
+// RetrieveInventoryTree returns the selected asset along with every descendant of the asset. Assets in the trash
+// and their descendants are not part of the tree.
+func RetrieveInventoryTree(user string, userID string, invID string) (*model.InventoryTreeNode, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `WITH RECURSIVE tree AS (
+			SELECT inv.id, 0 AS depth
+			FROM community.inventory inv
+			WHERE inv.id = $2
+			AND $1::UUID = ANY(inv.sharable_groups)
+			AND inv.deleted_at IS NULL
+			UNION
+			SELECT c.id, t.depth + 1
+			FROM community.inventory c
+			JOIN tree t ON c.parent_id = t.id
+			WHERE c.deleted_at IS NULL
+			AND $1::UUID = ANY(c.sharable_groups)
+			AND t.depth < $3
+		)
+		SELECT
+			inv.id,
+			COALESCE(inv.parent_id::TEXT, ''),
+			inv.name,
+			inv.status,
+			COALESCE(inv.price, 0),
+			COALESCE(inv.quantity, 0),
+			COALESCE(inv.location, ''),
+			COALESCE(inv.storage_location_id::TEXT, '')
+		FROM tree t
+		JOIN community.inventory inv ON inv.id = t.id
+		ORDER BY t.depth, inv.name;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, invID, maxInventoryTreeDepth)
+	if err != nil {
+		config.Log("unable to query selected tree", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []model.InventoryTreeNode
+	for rows.Next() {
+		var node model.InventoryTreeNode
+		if err := rows.Scan(
+			&node.ID,
+			&node.ParentID,
+			&node.Name,
+			&node.Status,
+			&node.Price,
+			&node.Quantity,
+			&node.Location,
+			&node.StorageLocationID,
+		); err != nil {
+			config.Log("unable to scan selected tree", err)
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		config.Log("unable to find selected asset", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+
+	tree := buildInventoryTree(nodes, invID)
+	return &tree, nil
+}
+
+// UpdateInventoryParent ...
+//
+// UpdateInventoryParent places the selected asset inside the selected parent. The asset along with every descendant
+// of the asset is moved to the storage location of the parent. Empty parentID detaches the asset from its parent.
+func UpdateInventoryParent(user string, userID string, invID string, parentID string) (*model.Inventory, error) {
+	if invID == parentID {
+		config.Log("unable to place asset inside itself", errors.New(InvalidInventoryParent))
+		return nil, errors.New(InvalidInventoryParent)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	if len(parentID) > 0 {
+		// the parent is locked so that two concurrent requests cannot link the assets in a loop
+		sqlStr := `SELECT inv.id
+			FROM community.inventory inv
+			WHERE inv.id = $1
+			AND $2::UUID = ANY(inv.sharable_groups)
+			AND inv.deleted_at IS NULL
+			FOR UPDATE;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		err = tx.QueryRow(sqlStr, parentID, userID).Scan(&parentID)
+		if err != nil {
+			config.Log("unable to find selected parent", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		isCycle, err := isInventoryAncestor(tx, parentID, invID)
+		if err != nil {
+			config.Log("unable to validate selected parent", err)
+			tx.Rollback()
+			return nil, err
+		}
+		if isCycle {
+			config.Log("unable to place asset inside its descendant", errors.New(InventoryParentCycle))
+			tx.Rollback()
+			return nil, errors.New(InventoryParentCycle)
+		}
+	}
+
+	before, err := snapshotInventory(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory inv
+		SET parent_id = NULLIF($2, '')::UUID,
+			updated_by = $3,
+			updated_at = $4
+		WHERE inv.id = $1;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, invID, parentID, userID, time.Now())
+	if err != nil {
+		config.Log("unable to update parent of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(parentID) > 0 {
+		err = moveInventoryDescendants(tx, userID, parentID)
+		if err != nil {
+			config.Log("unable to move selected asset with parent", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = recordInventoryRevision(tx, userID, invID, before, InventoryRevisionActionUpdate)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveSelectedInv(tx, userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// isInventoryAncestor ...
+//
+// isInventoryAncestor returns true if the selected ancestor is the selected asset itself or any of its ancestors.
+func isInventoryAncestor(tx *sql.Tx, invID string, ancestorID string) (bool, error) {
+	sqlStr := `WITH RECURSIVE ancestors AS (
+			SELECT inv.id, inv.parent_id, 0 AS depth
+			FROM community.inventory inv
+			WHERE inv.id = $1
+			UNION
+			SELECT p.id, p.parent_id, a.depth + 1
+			FROM community.inventory p
+			JOIN ancestors a ON p.id = a.parent_id
+			WHERE a.depth < $3
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2);`
+
+	var isAncestor bool
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, invID, ancestorID, maxInventoryTreeDepth).Scan(&isAncestor)
+	if err != nil {
+		config.Log("unable to retrieve ancestors of selected asset", err)
+		return false, err
+	}
+	return isAncestor, nil
+}
+
+// moveInventoryDescendants ...
+//
+// moveInventoryDescendants moves every descendant of the selected asset to the storage location of the selected asset.
+// Only descendants that are visible to the selected user and not in the trash are moved, and a revision is recorded
+// for each moved descendant the same way as for the selected asset.
+func moveInventoryDescendants(tx *sql.Tx, userID string, invID string) error {
+	sqlStr := `WITH RECURSIVE descendants AS (
+			SELECT c.id, 1 AS depth
+			FROM community.inventory c
+			WHERE c.parent_id = $1
+			AND $2::UUID = ANY(c.sharable_groups)
+			AND c.deleted_at IS NULL
+			UNION
+			SELECT c.id, d.depth + 1
+			FROM community.inventory c
+			JOIN descendants d ON c.parent_id = d.id
+			WHERE d.depth < $4
+			AND $2::UUID = ANY(c.sharable_groups)
+			AND c.deleted_at IS NULL
+		),
+		before AS (
+			SELECT inv.id, to_jsonb(inv) AS snapshot
+			FROM community.inventory inv
+			JOIN descendants d ON d.id = inv.id
+		),
+		moved AS (
+			UPDATE community.inventory inv
+			SET location = parent.location,
+				storage_location_id = parent.storage_location_id,
+				updated_by = $2,
+				updated_at = $3
+			FROM descendants d, community.inventory parent
+			WHERE inv.id = d.id
+			AND parent.id = $1
+			AND inv.storage_location_id IS DISTINCT FROM parent.storage_location_id
+			RETURNING inv.id, to_jsonb(inv) AS snapshot, inv.sharable_groups
+		)
+		INSERT INTO community.inventory_revisions (item_id, action, changes, created_at, created_by, sharable_groups)
+		SELECT m.id, $5, diff.changes, $3, $2, m.sharable_groups
+		FROM moved m
+		JOIN before b ON b.id = m.id,
+		LATERAL (
+			SELECT jsonb_object_agg(bv.key, jsonb_build_object('before', bv.value, 'after', av.value)) AS changes
+			FROM jsonb_each(b.snapshot) bv
+			JOIN jsonb_each(m.snapshot) av ON av.key = bv.key
+			WHERE bv.key = ANY($6::TEXT[])
+			AND bv.value IS DISTINCT FROM av.value
+		) diff
+		WHERE diff.changes IS NOT NULL;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err := tx.Exec(sqlStr, invID, userID, time.Now(), maxInventoryTreeDepth, InventoryRevisionActionUpdate, pq.Array(inventoryRevisionColumns))
+	if err != nil {
+		config.Log("unable to move descendants of selected asset", err)
+		return err
+	}
+	return nil
+}
+
+// buildInventoryTree ...
+//
+// buildInventoryTree links the flat list of nodes into a tree starting from the selected root and rolls up the
+// value and quantity of every descendant into its ancestors
+func buildInventoryTree(nodes []model.InventoryTreeNode, rootID string) model.InventoryTreeNode {
+	childrenOf := make(map[string][]model.InventoryTreeNode)
+	var root model.InventoryTreeNode
+	for _, node := range nodes {
+		if node.ID == rootID {
+			root = node
+			continue
+		}
+		childrenOf[node.ParentID] = append(childrenOf[node.ParentID], node)
+	}
+
+	visited := make(map[string]bool)
+	var rollUp func(node model.InventoryTreeNode) model.InventoryTreeNode
+	rollUp = func(node model.InventoryTreeNode) model.InventoryTreeNode {
+		visited[node.ID] = true
+		node.RolledUpValue = node.Price
+		node.RolledUpQuantity = node.Quantity
+		node.Children = make([]model.InventoryTreeNode, 0)
+		for _, child := range childrenOf[node.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			child = rollUp(child)
+			node.RolledUpValue += child.RolledUpValue
+			node.RolledUpQuantity += child.RolledUpQuantity
+			node.Children = append(node.Children, child)
+		}
+		node.RolledUpValue = roundCurrency(node.RolledUpValue)
+		return node
+	}
+	return rollUp(root)
+}
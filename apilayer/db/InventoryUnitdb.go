@@ -0,0 +1,451 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	InventoryUnitStatusAvailable = "available"
+	InventoryUnitStatusInUse     = "in_use"
+	InventoryUnitStatusInRepair  = "in_repair"
+	InventoryUnitStatusBroken    = "broken"
+	InventoryUnitStatusLost      = "lost"
+	InventoryUnitStatusRetired   = "retired"
+
+	InvalidInventoryUnit        = "invalid inventory unit"
+	DuplicateInventoryUnit      = "unit with the same serial number already exists"
+	TooManyInventoryUnits       = "too many units in a single request"
+	InventoryUnitOnLoan         = "unable to remove unit that is checked out"
+	SerializedInventoryQuantity = "quantity of serialized assets is derived from their units"
+	MaxInventoryUnitsPerRequest = 500
+	maxSerialNumberLength       = 100
+	maxInventoryUnitNotesLength = 500
+)
+
+// inventoryUnitStatuses is the list of supported statuses of a unit
+var inventoryUnitStatuses = []string{
+	InventoryUnitStatusAvailable,
+	InventoryUnitStatusInUse,
+	InventoryUnitStatusInRepair,
+	InventoryUnitStatusBroken,
+	InventoryUnitStatusLost,
+	InventoryUnitStatusRetired,
+}
+
+// RetrieveInventoryUnits ...
+//
+// RetrieveInventoryUnits returns the units of the selected asset ordered by serial number. Units can be
+// filtered by status.
+func RetrieveInventoryUnits(user string, userID string, invID string, status string) ([]model.InventoryUnit, error) {
+	var additionalWhereClause string
+	params := []interface{}{userID, invID}
+	if len(status) > 0 {
+		if !isValidInventoryUnitStatus(status) {
+			config.Log("unable to validate unit status", errors.New(InvalidInventoryUnit))
+			return nil, errors.New(InvalidInventoryUnit)
+		}
+		additionalWhereClause = " AND iu.status = $3"
+		params = append(params, status)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveInventoryUnits(tx, additionalWhereClause, params...)
+	if err != nil {
+		config.Log("unable to retrieve units for selected asset", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddInventoryUnits ...
+//
+// AddInventoryUnits adds the selected units to the asset in a single transaction. The quantity of the asset
+// is derived from its units by the database trigger once the units are added.
+func AddInventoryUnits(user string, userID string, invID string, draftUnits []model.InventoryUnit) ([]model.InventoryUnit, error) {
+	if len(draftUnits) == 0 {
+		config.Log("unable to add empty units", errors.New(InvalidInventoryUnit))
+		return nil, errors.New(InvalidInventoryUnit)
+	}
+	if len(draftUnits) > MaxInventoryUnitsPerRequest {
+		config.Log("unable to add selected units", errors.New(TooManyInventoryUnits))
+		return nil, errors.New(TooManyInventoryUnits)
+	}
+	for i := range draftUnits {
+		normalizeInventoryUnit(&draftUnits[i])
+		if err := validateInventoryUnit(draftUnits[i]); err != nil {
+			config.Log("unable to validate inventory unit", err)
+			return nil, err
+		}
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	// lock the selected asset so that the derived quantity is refreshed one unit after the other
+	sqlStr := `SELECT inv.id FROM community.inventory inv
+		WHERE inv.id = $1
+		AND $2::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		FOR UPDATE;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, invID, userID).Scan(&invID)
+	if err != nil {
+		config.Log("unable to find selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = `INSERT INTO community.inventory_units (item_id, serial_number, status, storage_location_id, notes, created_at, created_by, updated_at, updated_by, sharable_groups)
+		SELECT inv.id, $3, $4, NULLIF($5, '')::UUID, NULLIF($6, ''), $7, $2, $7, $2, inv.sharable_groups
+		FROM community.inventory inv
+		WHERE inv.id = $1
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	stmt, err := tx.Prepare(sqlStr)
+	if err != nil {
+		config.Log("unable to prepare selected units", err)
+		tx.Rollback()
+		return nil, err
+	}
+	defer stmt.Close()
+
+	currentTime := time.Now()
+	unitIDs := make([]string, 0, len(draftUnits))
+	for _, v := range draftUnits {
+		var unitID string
+		err = stmt.QueryRow(invID, userID, v.SerialNumber, v.Status, v.StorageLocationID, v.Notes, currentTime).Scan(&unitID)
+		if err != nil {
+			config.Log("unable to add unit for selected asset", err)
+			tx.Rollback()
+			return nil, toDuplicateInventoryUnitError(err)
+		}
+		unitIDs = append(unitIDs, unitID)
+	}
+
+	data, err := retrieveInventoryUnits(tx, " AND iu.id = ANY($3::UUID[])", userID, invID, pq.Array(unitIDs))
+	if err != nil {
+		config.Log("unable to retrieve selected units", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// UpdateInventoryUnit ...
+//
+// UpdateInventoryUnit updates the serial number, status, storage location and notes of the selected unit.
+func UpdateInventoryUnit(user string, userID string, invID string, unitID string, draftUnit model.InventoryUnit) (*model.InventoryUnit, error) {
+	normalizeInventoryUnit(&draftUnit)
+	if err := validateInventoryUnit(draftUnit); err != nil {
+		config.Log("unable to validate inventory unit", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.inventory_units iu
+		SET serial_number = $4,
+			status = $5,
+			storage_location_id = NULLIF($6, '')::UUID,
+			notes = NULLIF($7, ''),
+			updated_at = $8,
+			updated_by = $3
+		FROM community.inventory inv
+		WHERE iu.id = $1
+		AND iu.item_id = $2
+		AND inv.id = iu.item_id
+		AND inv.deleted_at IS NULL
+		AND $3::UUID = ANY(iu.sharable_groups)
+		RETURNING iu.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, unitID, invID, userID, draftUnit.SerialNumber, draftUnit.Status, draftUnit.StorageLocationID, draftUnit.Notes, time.Now()).Scan(&unitID)
+	if err != nil {
+		config.Log("unable to update selected unit", err)
+		tx.Rollback()
+		return nil, toDuplicateInventoryUnitError(err)
+	}
+
+	data, err := retrieveInventoryUnits(tx, " AND iu.id = $3", userID, invID, unitID)
+	if err == nil && len(data) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		config.Log("unable to retrieve selected unit", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// RemoveInventoryUnit ...
+//
+// RemoveInventoryUnit removes the selected unit alongside its loan and maintenance history. Units that are
+// checked out cannot be removed. The asset keeps the quantity of zero when its last unit is removed.
+func RemoveInventoryUnit(user string, userID string, invID string, unitID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return err
+	}
+
+	sqlStr := `SELECT EXISTS (
+			SELECT 1 FROM community.loan_items li WHERE li.unit_id = iu.id AND li.checked_in_at IS NULL
+		)
+		FROM community.inventory_units iu
+		WHERE iu.id = $1
+		AND iu.item_id = $2
+		AND $3::UUID = ANY(iu.sharable_groups)
+		FOR UPDATE OF iu;`
+
+	var isCheckedOut bool
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, unitID, invID, userID).Scan(&isCheckedOut)
+	if err != nil {
+		config.Log("unable to find selected unit", err)
+		tx.Rollback()
+		return err
+	}
+	if isCheckedOut {
+		config.Log("unable to remove selected unit", errors.New(InventoryUnitOnLoan))
+		tx.Rollback()
+		return errors.New(InventoryUnitOnLoan)
+	}
+
+	sqlStr = `DELETE FROM community.inventory_units iu WHERE iu.id = $1;`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, unitID)
+	if err != nil {
+		config.Log("unable to remove selected unit", err)
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return err
+	}
+	return nil
+}
+
+// isSerializedInventory ...
+//
+// isSerializedInventory returns true when the selected asset has at least one unit
+func isSerializedInventory(tx *sql.Tx, invID string) (bool, error) {
+	sqlStr := `SELECT EXISTS (SELECT 1 FROM community.inventory_units iu WHERE iu.item_id = $1);`
+
+	var isSerialized bool
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, invID).Scan(&isSerialized)
+	if err != nil {
+		config.Log("unable to retrieve units of selected asset", err)
+		return false, err
+	}
+	return isSerialized, nil
+}
+
+// validateSerializedInventoryQuantity ...
+//
+// validateSerializedInventoryQuantity returns an error when the quantity of a serialized asset is changed,
+// as the quantity of serialized assets is derived from their units
+func validateSerializedInventoryQuantity(tx *sql.Tx, invID string, currentQuantity int, quantity int) error {
+	if currentQuantity == quantity {
+		return nil
+	}
+	isSerialized, err := isSerializedInventory(tx, invID)
+	if err != nil {
+		return err
+	}
+	if isSerialized {
+		return errors.New(SerializedInventoryQuantity)
+	}
+	return nil
+}
+
+// retrieveInventoryUnits ...
+//
+// retrieveInventoryUnits returns the units of the selected asset ordered by serial number.
+// userID is always $1 and invID is always $2. Units of assets in the trash are not returned.
+func retrieveInventoryUnits(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.InventoryUnit, error) {
+	sqlStr := `SELECT
+		iu.id,
+		iu.item_id,
+		iu.serial_number,
+		iu.status,
+		COALESCE(iu.storage_location_id::TEXT, ''),
+		COALESCE(sl.location, ''),
+		COALESCE(iu.notes, ''),
+		EXISTS (
+			SELECT 1 FROM community.loan_items li WHERE li.unit_id = iu.id AND li.checked_in_at IS NULL
+		) AS is_checked_out,
+		iu.created_at,
+		COALESCE(iu.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		iu.updated_at,
+		COALESCE(iu.updated_by::TEXT, ''),
+		iu.sharable_groups
+	FROM community.inventory_units iu
+	JOIN community.inventory inv ON inv.id = iu.item_id AND inv.deleted_at IS NULL
+	LEFT JOIN community.storage_locations sl ON sl.id = iu.storage_location_id
+	LEFT JOIN community.profiles cp ON cp.id = iu.created_by
+	WHERE $1::UUID = ANY(iu.sharable_groups)
+	AND iu.item_id = $2` + additionalWhereClause + `
+	ORDER BY LOWER(iu.serial_number);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.InventoryUnit, 0)
+	for rows.Next() {
+		var unit model.InventoryUnit
+		if err := rows.Scan(
+			&unit.ID,
+			&unit.ItemID,
+			&unit.SerialNumber,
+			&unit.Status,
+			&unit.StorageLocationID,
+			&unit.Location,
+			&unit.Notes,
+			&unit.IsCheckedOut,
+			&unit.CreatedAt,
+			&unit.CreatedBy,
+			&unit.Creator,
+			&unit.UpdatedAt,
+			&unit.UpdatedBy,
+			pq.Array(&unit.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected units", err)
+			return nil, err
+		}
+		data = append(data, unit)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// normalizeInventoryUnit ...
+//
+// normalizeInventoryUnit trims the serial number and defaults the status of the selected unit to available
+func normalizeInventoryUnit(draftUnit *model.InventoryUnit) {
+	draftUnit.SerialNumber = strings.TrimSpace(draftUnit.SerialNumber)
+	draftUnit.Status = strings.ToLower(strings.TrimSpace(draftUnit.Status))
+	if len(draftUnit.Status) == 0 {
+		draftUnit.Status = InventoryUnitStatusAvailable
+	}
+}
+
+// validateInventoryUnit ...
+//
+// validateInventoryUnit validates the serial number, status, storage location and notes of the selected unit
+func validateInventoryUnit(draftUnit model.InventoryUnit) error {
+	if len(draftUnit.SerialNumber) == 0 || utf8.RuneCountInString(draftUnit.SerialNumber) > maxSerialNumberLength {
+		return errors.New(InvalidInventoryUnit)
+	}
+	if utf8.RuneCountInString(draftUnit.Notes) > maxInventoryUnitNotesLength {
+		return errors.New(InvalidInventoryUnit)
+	}
+	if !isValidInventoryUnitStatus(draftUnit.Status) {
+		return errors.New(InvalidInventoryUnit)
+	}
+	if len(draftUnit.StorageLocationID) > 0 {
+		if _, err := uuid.Parse(draftUnit.StorageLocationID); err != nil {
+			return errors.New(InvalidInventoryUnit)
+		}
+	}
+	return nil
+}
+
+// isValidInventoryUnitStatus ...
+//
+// returns true if the selected status is one of the supported unit statuses
+func isValidInventoryUnitStatus(status string) bool {
+	return slices.Contains(inventoryUnitStatuses, status)
+}
+
+// toDuplicateInventoryUnitError ...
+//
+// returns the duplicate unit error when the serial number is already used by another unit of the same asset
+func toDuplicateInventoryUnitError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationErrorCode {
+		return errors.New(DuplicateInventoryUnit)
+	}
+	return err
+}
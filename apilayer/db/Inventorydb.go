@@ -2,8 +2,12 @@ package db
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/earmuff-jam/fleetwise/config"
@@ -13,11 +17,15 @@ import (
 )
 
 const (
-	InvalidColumnName = "invalid column name"
+	InvalidColumnName     = "invalid column name"
+	DuplicateBarcodeOrSKU = "barcode or sku already exists"
+
+	// uniqueViolationErrorCode is the postgres error code for unique constraint violations
+	uniqueViolationErrorCode = "23505"
 )
 
 // RetrieveAllInventoriesForUser ...
-func RetrieveAllInventoriesForUser(user string, userID string, sinceDateTime string) ([]model.Inventory, error) {
+func RetrieveAllInventoriesForUser(user string, userID string, listParams model.InventoryListParams) (*model.InventoryList, error) {
 
 	db, err := SetupDB(user)
 	if err != nil {
@@ -31,52 +39,257 @@ func RetrieveAllInventoriesForUser(user string, userID string, sinceDateTime str
 		config.Log("unable to start transaction with selected db pool", err)
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	sortColumn, ok := inventorySortColumns[listParams.SortBy]
+	if !ok {
+		config.Log("unable to validate sort column", errors.New(InvalidColumnName))
+		return nil, errors.New(InvalidColumnName)
+	}
+
+	sortDirection := "DESC"
+	if strings.EqualFold(listParams.SortOrder, "asc") {
+		sortDirection = "ASC"
+	}
 
-	var additionalWhereClause string
 	var params []interface{}
 	params = append(params, userID)
 
-	if sinceDateTime != "" {
-		parsedTime, err := time.Parse(time.RFC3339, sinceDateTime)
+	additionalWhereClause, err := buildInventoryListWhereClause(listParams, &params)
+	if err != nil {
+		config.Log("unable to build filters for selected inventories", err)
+		return nil, err
+	}
+
+	var totalCount int
+	countSqlStr := "SELECT COUNT(*) FROM community.inventory inv WHERE inv.created_by = $1::UUID AND inv.deleted_at IS NULL" + additionalWhereClause + ";"
+	config.Log("SqlStr: %s", nil, countSqlStr)
+	err = tx.QueryRow(countSqlStr, params...).Scan(&totalCount)
+	if err != nil {
+		config.Log("unable to count inventories for selected user", err)
+		return nil, err
+	}
+
+	// keyset pagination is applied after the count so that the total count is not affected by the cursor
+	if len(listParams.Cursor) > 0 {
+		cursor, err := decodeInventoryListCursor(listParams.Cursor)
 		if err != nil {
-			config.Log("error parsing sinceDateTime", err)
+			config.Log("unable to decode selected cursor", err)
 			return nil, err
 		}
-		additionalWhereClause += " AND inv.updated_at >= $2"
-		params = append(params, parsedTime)
+		comparator := "<"
+		if sortDirection == "ASC" {
+			comparator = ">"
+		}
+		params = append(params, cursor.Value, cursor.ID)
+		additionalWhereClause += fmt.Sprintf(" AND (%s, inv.id) %s ($%d::%s, $%d::UUID)", sortColumn.expression, comparator, len(params)-1, sortColumn.castType, len(params))
 	}
 
-	data, err := retrieveAllInventoryDetailsForUser(tx, additionalWhereClause, params...)
+	orderBySqlStr := fmt.Sprintf("ORDER BY %s %s, inv.id %s", sortColumn.expression, sortDirection, sortDirection)
+	if listParams.Limit > 0 {
+		params = append(params, listParams.Limit)
+		orderBySqlStr += fmt.Sprintf(" LIMIT $%d", len(params))
+		if len(listParams.Cursor) == 0 && listParams.Offset > 0 {
+			params = append(params, listParams.Offset)
+			orderBySqlStr += fmt.Sprintf(" OFFSET $%d", len(params))
+		}
+	}
+
+	data, err := retrieveAllInventoryDetailsForUser(tx, additionalWhereClause, orderBySqlStr+";", params...)
 	if err != nil {
 		config.Log("unable to retrieve all inventory details for user", err)
 		return nil, err
 	}
 
+	err = applyPreferredUnits(tx, userID, data)
+	if err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		config.Log("unable to commit transaction", err)
 		return nil, err
 	}
 
+	resp := model.InventoryList{
+		Inventories: data,
+		TotalCount:  totalCount,
+	}
+
 	if len(data) == 0 {
 		config.Log("no assets found for selected user", nil)
-		return make([]model.Inventory, 0), nil
+		resp.Inventories = make([]model.Inventory, 0)
+		return &resp, nil
 	}
 
-	return data, nil
+	if listParams.Limit > 0 && len(data) == listParams.Limit {
+		lastInventory := data[len(data)-1]
+		resp.NextCursor, err = encodeInventoryListCursor(model.InventoryListCursor{
+			Value: sortColumn.valueOf(lastInventory),
+			ID:    lastInventory.ID,
+		})
+		if err != nil {
+			config.Log("unable to encode next cursor", err)
+			return nil, err
+		}
+	}
+
+	return &resp, nil
+}
+
+// inventorySortColumn ...
+//
+// inventorySortColumn is the sql expression used to sort the inventory list alongside the type used to cast
+// the cursor value and the func used to derieve the cursor value from the last row of the page.
+type inventorySortColumn struct {
+	expression string
+	castType   string
+	valueOf    func(model.Inventory) string
+}
+
+// inventorySortColumns ...
+//
+// inventorySortColumns is the whitelist of columns that the inventory list can be sorted against.
+// The empty key resembles the default sort order.
+var inventorySortColumns = map[string]inventorySortColumn{
+	"":           {"inv.updated_at", "TIMESTAMP WITH TIME ZONE", func(i model.Inventory) string { return i.UpdatedAt.Format(time.RFC3339Nano) }},
+	"updated_at": {"inv.updated_at", "TIMESTAMP WITH TIME ZONE", func(i model.Inventory) string { return i.UpdatedAt.Format(time.RFC3339Nano) }},
+	"created_at": {"inv.created_at", "TIMESTAMP WITH TIME ZONE", func(i model.Inventory) string { return i.CreatedAt.Format(time.RFC3339Nano) }},
+	"name":       {"inv.name", "TEXT", func(i model.Inventory) string { return i.Name }},
+	"price":      {"COALESCE(inv.price, 0)", "NUMERIC", func(i model.Inventory) string { return strconv.FormatFloat(i.Price, 'f', -1, 64) }},
+	"quantity":   {"COALESCE(inv.quantity, 0)", "INT", func(i model.Inventory) string { return strconv.Itoa(i.Quantity) }},
+	"status":     {"COALESCE(inv.status, '')", "TEXT", func(i model.Inventory) string { return i.Status }},
+	"location":   {"COALESCE(inv.location, '')", "TEXT", func(i model.Inventory) string { return i.Location }},
+	"sku":        {"COALESCE(inv.sku, '')", "TEXT", func(i model.Inventory) string { return i.SKU }},
+	"barcode":    {"COALESCE(inv.barcode, '')", "TEXT", func(i model.Inventory) string { return i.Barcode }},
+}
+
+// buildInventoryListWhereClause ...
+//
+// builds the additional where clause for the selected filters. params are appended in the same order
+// as the placeholders so that the caller can continue to add new params after the filters.
+func buildInventoryListWhereClause(listParams model.InventoryListParams, params *[]interface{}) (string, error) {
+	var whereClause string
+
+	if listParams.Since != "" {
+		parsedTime, err := time.Parse(time.RFC3339, listParams.Since)
+		if err != nil {
+			config.Log("error parsing sinceDateTime", err)
+			return "", err
+		}
+		*params = append(*params, parsedTime)
+		whereClause += fmt.Sprintf(" AND inv.updated_at >= $%d", len(*params))
+	}
+
+	if len(listParams.Statuses) > 0 {
+		*params = append(*params, pq.Array(listParams.Statuses))
+		whereClause += fmt.Sprintf(" AND inv.status = ANY($%d)", len(*params))
+	}
+
+	if len(listParams.StorageLocationIDs) > 0 {
+		*params = append(*params, pq.Array(listParams.StorageLocationIDs))
+		whereClause += fmt.Sprintf(" AND inv.storage_location_id = ANY($%d::UUID[])", len(*params))
+	}
+
+	if listParams.MinPrice != nil {
+		*params = append(*params, *listParams.MinPrice)
+		whereClause += fmt.Sprintf(" AND inv.price >= $%d", len(*params))
+	}
+
+	if listParams.MaxPrice != nil {
+		*params = append(*params, *listParams.MaxPrice)
+		whereClause += fmt.Sprintf(" AND inv.price <= $%d", len(*params))
+	}
+
+	if listParams.IsReturnable != nil {
+		*params = append(*params, *listParams.IsReturnable)
+		whereClause += fmt.Sprintf(" AND inv.is_returnable = $%d", len(*params))
+	}
+
+	if listParams.CategoryID != "" {
+		*params = append(*params, listParams.CategoryID)
+		whereClause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM community.category_item ci WHERE ci.item_id = inv.id AND ci.category_id = $%d::UUID)", len(*params))
+	}
+
+	if listParams.MaintenancePlanID != "" {
+		*params = append(*params, listParams.MaintenancePlanID)
+		whereClause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM community.maintenance_item mi WHERE mi.item_id = inv.id AND mi.maintenance_plan_id = $%d::UUID)", len(*params))
+	}
+
+	if len(listParams.TagIDs) > 0 {
+		tagWhereClause, err := buildTagWhereClause(TagEntityTypeInventory, "inv", listParams.TagIDs, params)
+		if err != nil {
+			config.Log("unable to build filter for selected tags", err)
+			return "", err
+		}
+		whereClause += tagWhereClause
+	}
+
+	for _, filter := range listParams.AttributeFilters {
+		attributeWhereClause, err := buildInventoryAttributeWhereClause(filter, params)
+		if err != nil {
+			config.Log("unable to build filter for selected attribute", err)
+			return "", err
+		}
+		whereClause += attributeWhereClause
+	}
+
+	return whereClause, nil
+}
+
+// encodeInventoryListCursor ...
+//
+// encodes the selected cursor into an opaque url safe string
+func encodeInventoryListCursor(cursor model.InventoryListCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeInventoryListCursor ...
+//
+// decodes the opaque cursor passed in by the client
+func decodeInventoryListCursor(draftCursor string) (*model.InventoryListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(draftCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor model.InventoryListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
 }
 
-func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.Inventory, error) {
+func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string, orderBySqlStr string, params ...interface{}) ([]model.Inventory, error) {
 	baseSqlStr := `SELECT
 		inv.id,
 		inv.name,
 		inv.description,
 		inv.price,
+		inv.currency,
 		inv.status,
 		inv.barcode,
 		inv.sku,
 		inv.color,
 		inv.quantity,
+		inv.reorder_point,
 		inv.bought_at,
+		inv.purchase_date,
+		inv.expiry_date,
+		inv.useful_life_years,
+		inv.salvage_value,
+		inv.depreciation_method,
+		inv.custom_attributes,
+		COALESCE(inv.parent_id::TEXT, ''),
 		inv.location,
 		inv.storage_location_id,
 		inv.is_returnable,
@@ -85,6 +298,12 @@ func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string
 		inv.min_weight,
 		inv.max_height,
 		inv.min_height,
+		inv.length,
+		inv.width,
+		inv.height,
+		inv.weight,
+		inv.dimension_unit,
+		inv.weight_unit,
 		inv.associated_image_url,
 		inv.created_by,
 		COALESCE(cp.username, cp.full_name, cp.email_address) AS creator_name,
@@ -98,8 +317,10 @@ func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string
 		LEFT JOIN community.profiles cp ON inv.created_by = cp.id
 		LEFT JOIN community.profiles up ON inv.updated_by = up.id`
 
-	whereSqlStr := "WHERE inv.created_by = $1::UUID"
-	orderBySqlStr := "ORDER BY inv.updated_at DESC;"
+	whereSqlStr := "WHERE inv.created_by = $1::UUID AND inv.deleted_at IS NULL"
+	if len(orderBySqlStr) == 0 {
+		orderBySqlStr = "ORDER BY inv.updated_at DESC;"
+	}
 
 	sqlStr := baseSqlStr + " " + whereSqlStr + additionalWhereClause + " " + orderBySqlStr
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -114,28 +335,46 @@ func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string
 	for rows.Next() {
 		var inventory model.Inventory
 
-		var returnLocation, associatedImageURL, color sql.NullString
-		var maxWeight, minWeight, maxHeight, minHeight sql.NullInt64
+		var returnLocation, associatedImageURL, color, depreciationMethod sql.NullString
+		var reorderPoint, usefulLifeYears sql.NullInt64
+		var measurements inventoryMeasurements
+		var purchaseDate sql.NullTime
+		var customAttributes []byte
 
 		if err := rows.Scan(
 			&inventory.ID,
 			&inventory.Name,
 			&inventory.Description,
 			&inventory.Price,
+			&inventory.Currency,
 			&inventory.Status,
 			&inventory.Barcode,
 			&inventory.SKU,
 			&color,
 			&inventory.Quantity,
+			&reorderPoint,
 			&inventory.BoughtAt,
+			&purchaseDate,
+			&inventory.ExpiryDate,
+			&usefulLifeYears,
+			&inventory.SalvageValue,
+			&depreciationMethod,
+			&customAttributes,
+			&inventory.ParentID,
 			&inventory.Location,
 			&inventory.StorageLocationID,
 			&inventory.IsReturnable,
 			&returnLocation,
-			&maxWeight,
-			&minWeight,
-			&maxHeight,
-			&minHeight,
+			&measurements.maxWeight,
+			&measurements.minWeight,
+			&measurements.maxHeight,
+			&measurements.minHeight,
+			&measurements.length,
+			&measurements.width,
+			&measurements.height,
+			&measurements.weight,
+			&measurements.dimensionUnit,
+			&measurements.weightUnit,
 			&associatedImageURL,
 			&inventory.CreatedBy,
 			&inventory.CreatorName,
@@ -153,21 +392,11 @@ func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string
 			inventory.ReturnLocation = returnLocation.String
 		}
 
-		if maxWeight.Valid {
-			inventory.MaxWeight = int(maxWeight.Int64)
+		if reorderPoint.Valid {
+			inventory.ReorderPoint = int(reorderPoint.Int64)
 		}
 
-		if minWeight.Valid {
-			inventory.MinWeight = int(minWeight.Int64)
-		}
-
-		if maxHeight.Valid {
-			inventory.MaxHeight = int(maxHeight.Int64)
-		}
-
-		if minHeight.Valid {
-			inventory.MinHeight = int(minHeight.Int64)
-		}
+		measurements.applyTo(&inventory)
 
 		if color.Valid {
 			inventory.Color = color.String
@@ -177,6 +406,9 @@ func retrieveAllInventoryDetailsForUser(tx *sql.Tx, additionalWhereClause string
 			inventory.AssociatedImageURL = associatedImageURL.String
 		}
 
+		applyDepreciation(&inventory, purchaseDate, usefulLifeYears, depreciationMethod)
+		inventory.CustomAttributes = parseCustomAttributes(customAttributes)
+
 		content, _, _, err := FetchImage(inventory.ID)
 		if err != nil {
 			if err.Error() == "NoSuchKey" {
@@ -216,6 +448,18 @@ func RetrieveSelectedInv(user string, userID string, invID string) (*model.Inven
 		return nil, err
 	}
 
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		return nil, err
+	}
+
+	err = convertInventoryUnits(data, unitSystem)
+	if err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		config.Log("unable to commit transaction", err)
 		return nil, err
@@ -247,6 +491,38 @@ func UpdateAsset(user string, userID string, draftUpdateAssetCols model.UpdateAs
 		return nil, errors.New(InvalidColumnName)
 	}
 
+	before, err := snapshotInventory(tx, userID, draftUpdateAssetCols.AssetID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if columnName == "quantity" {
+		var snapshot struct {
+			Quantity int `json:"quantity"`
+		}
+		if err := json.Unmarshal(before, &snapshot); err != nil {
+			config.Log("unable to parse snapshot of selected asset", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		quantity, err := strconv.Atoi(draftUpdateAssetCols.InputColumn)
+		if err != nil {
+			config.Log("unable to parse quantity of selected asset", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = validateSerializedInventoryQuantity(tx, draftUpdateAssetCols.AssetID, snapshot.Quantity, quantity)
+		if err != nil {
+			config.Log("unable to validate quantity of selected asset", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	sqlStr := fmt.Sprintf(`UPDATE 
 	community.inventory inv
 		SET %s = $1,
@@ -261,12 +537,21 @@ func UpdateAsset(user string, userID string, draftUpdateAssetCols model.UpdateAs
 	err = tx.QueryRow(sqlStr, draftUpdateAssetCols.InputColumn, userID, draftUpdateAssetCols.AssetID, time.Now()).Scan(&updatedInvID)
 	if err != nil {
 		config.Log("unable to update asset id", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordInventoryRevision(tx, userID, updatedInvID, before, InventoryRevisionActionUpdate)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
 		return nil, err
 	}
 
 	data, err := retrieveSelectedInv(tx, userID, updatedInvID)
 	if err != nil {
 		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -294,6 +579,13 @@ func UpdateAssetImage(user string, userID string, assetID string, assetImageURL
 		return false, err
 	}
 
+	before, err := snapshotInventory(tx, userID, assetID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return false, err
+	}
+
 	sqlStr := `UPDATE community.inventory inv
 		SET associated_image_url = $1,
 			updated_at = $4,
@@ -310,6 +602,13 @@ func UpdateAssetImage(user string, userID string, assetID string, assetImageURL
 		return false, err
 	}
 
+	err = recordInventoryRevision(tx, userID, updatedInvID, before, InventoryRevisionActionImage)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
+		return false, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		config.Log("unable to commit", err)
 		return false, err
@@ -317,13 +616,91 @@ func UpdateAssetImage(user string, userID string, assetID string, assetImageURL
 	return true, nil
 }
 
+// RetrieveInventoryByCode ...
+//
+// RetrieveInventoryByCode returns the asset that matches the scanned barcode or sku. Barcode and sku are unique
+// for each owner, so assets owned by the user are preferred over assets that are shared with the user.
+func RetrieveInventoryByCode(user string, userID string, barcode string, sku string) (*model.Inventory, error) {
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start trasanction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sqlStr := `SELECT inv.id
+	FROM community.inventory inv
+	WHERE $1::UUID = ANY(inv.sharable_groups)
+	AND inv.deleted_at IS NULL
+	AND (
+		($2 <> '' AND inv.barcode = $2)
+		OR ($3 <> '' AND inv.sku = $3)
+	)
+	ORDER BY (inv.created_by = $1::UUID) DESC, (inv.barcode = $2) DESC, inv.updated_at DESC
+	LIMIT 1;`
+
+	var selectedInvID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, userID, barcode, sku).Scan(&selectedInvID)
+	if err != nil {
+		config.Log("unable to find asset with selected barcode or sku", err)
+		return nil, err
+	}
+
+	data, err := retrieveSelectedInv(tx, userID, selectedInvID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		return nil, err
+	}
+
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		return nil, err
+	}
+
+	err = convertInventoryUnits(data, unitSystem)
+	if err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// toDuplicateBarcodeOrSKUError ...
+//
+// toDuplicateBarcodeOrSKUError replaces unique constraint violations with a readable error. Barcode and sku
+// are the only unique columns of an inventory for the selected owner.
+func toDuplicateBarcodeOrSKUError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationErrorCode {
+		return errors.New(DuplicateBarcodeOrSKU)
+	}
+	return err
+}
+
 // isValidColumnName ...
 //
 // isValidColumnName function is used to determine if the function is valid or not
 func isValidColumnName(columnName string) bool {
 	validColumns := map[string]bool{
-		"price":    true,
-		"quantity": true,
+		"price":         true,
+		"quantity":      true,
+		"reorder_point": true,
 	}
 	return validColumns[columnName]
 }
@@ -335,12 +712,21 @@ func retrieveSelectedInv(tx *sql.Tx, userID string, invID string) (*model.Invent
     inv.name,
     inv.description,
     inv.price,
+    inv.currency,
     inv.status,
     inv.barcode,
     inv.sku,
 	inv.color,
     inv.quantity,
+	inv.reorder_point,
 	inv.bought_at,
+	inv.purchase_date,
+	inv.expiry_date,
+	inv.useful_life_years,
+	inv.salvage_value,
+	inv.depreciation_method,
+	inv.custom_attributes,
+	COALESCE(inv.parent_id::TEXT, ''),
     inv.location,
     inv.storage_location_id,
 	inv.is_returnable,
@@ -351,6 +737,12 @@ func retrieveSelectedInv(tx *sql.Tx, userID string, invID string) (*model.Invent
 	inv.min_weight,
 	inv.max_height,
 	inv.min_height,
+	inv.length,
+	inv.width,
+	inv.height,
+	inv.weight,
+	inv.dimension_unit,
+	inv.weight_unit,
     inv.created_by,
     COALESCE(cp.username, cp.full_name, cp.email_address) AS creator_name,
     inv.created_at,
@@ -360,7 +752,7 @@ func retrieveSelectedInv(tx *sql.Tx, userID string, invID string) (*model.Invent
 FROM community.inventory inv
 LEFT JOIN community.profiles cp ON inv.created_by = cp.id
 LEFT JOIN community.profiles up ON inv.updated_by = up.id
-WHERE $1::UUID = ANY(inv.sharable_groups) AND inv.id = $2
+WHERE $1::UUID = ANY(inv.sharable_groups) AND inv.id = $2 AND inv.deleted_at IS NULL
 ORDER BY inv.updated_at DESC;
 	`
 
@@ -368,30 +760,48 @@ ORDER BY inv.updated_at DESC;
 	row := tx.QueryRow(sqlStr, userID, invID)
 
 	var inventory model.Inventory
-	var returnLocation, returnNotes, draftColor sql.NullString
-	var maxWeight, minWeight, maxHeight, minHeight sql.NullInt64
+	var returnLocation, returnNotes, draftColor, depreciationMethod sql.NullString
+	var reorderPoint, usefulLifeYears sql.NullInt64
+	var measurements inventoryMeasurements
+	var purchaseDate sql.NullTime
+	var customAttributes []byte
 
 	err := row.Scan(
 		&inventory.ID,
 		&inventory.Name,
 		&inventory.Description,
 		&inventory.Price,
+		&inventory.Currency,
 		&inventory.Status,
 		&inventory.Barcode,
 		&inventory.SKU,
 		&draftColor,
 		&inventory.Quantity,
+		&reorderPoint,
 		&inventory.BoughtAt,
+		&purchaseDate,
+		&inventory.ExpiryDate,
+		&usefulLifeYears,
+		&inventory.SalvageValue,
+		&depreciationMethod,
+		&customAttributes,
+		&inventory.ParentID,
 		&inventory.Location,
 		&inventory.StorageLocationID,
 		&inventory.IsReturnable,
 		&returnLocation,
 		&inventory.ReturnDateTime,
 		&returnNotes,
-		&maxWeight,
-		&minWeight,
-		&maxHeight,
-		&minHeight,
+		&measurements.maxWeight,
+		&measurements.minWeight,
+		&measurements.maxHeight,
+		&measurements.minHeight,
+		&measurements.length,
+		&measurements.width,
+		&measurements.height,
+		&measurements.weight,
+		&measurements.dimensionUnit,
+		&measurements.weightUnit,
 		&inventory.CreatedBy,
 		&inventory.CreatorName,
 		&inventory.CreatedAt,
@@ -415,23 +825,17 @@ ORDER BY inv.updated_at DESC;
 	if returnNotes.Valid {
 		inventory.ReturnNotes = returnNotes.String
 	}
-	if maxWeight.Valid {
-		inventory.MaxWeight = int(maxWeight.Int64)
-	}
-	if minWeight.Valid {
-		inventory.MinWeight = int(minWeight.Int64)
-	}
-	if maxHeight.Valid {
-		inventory.MaxHeight = int(maxHeight.Int64)
-	}
-	if minHeight.Valid {
-		inventory.MinHeight = int(minHeight.Int64)
+	if reorderPoint.Valid {
+		inventory.ReorderPoint = int(reorderPoint.Int64)
 	}
+	measurements.applyTo(&inventory)
 
 	if draftColor.Valid {
 		inventory.Color = draftColor.String
 	}
 
+	applyDepreciation(&inventory, purchaseDate, usefulLifeYears, depreciationMethod)
+	inventory.CustomAttributes = parseCustomAttributes(customAttributes)
 	return &inventory, nil
 }
 
@@ -451,8 +855,29 @@ func AddInventoryInBulk(user string, userID string, draftInventoryList model.Inv
 		return nil, err
 	}
 
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		tx.Rollback()
+		return nil, err
+	}
+
 	for _, v := range draftInventoryList.InventoryList {
 
+		if err := ValidateInventoryMeasurements(v); err != nil {
+			config.Log("unable to validate dimensions and weights", err)
+			tx.Rollback()
+			return nil, err
+		}
+		applyDefaultUnits(&v, unitSystem)
+
+		v.Currency, err = normalizeCurrency(v.Currency)
+		if err != nil {
+			config.Log("unable to validate currency", err)
+			tx.Rollback()
+			return nil, err
+		}
+
 		// storage location is unique key in the database.
 		// storage location can be shared across inventories and items that are stored in events.
 		parsedStorageLocationID, err := uuid.Parse(v.Location)
@@ -480,58 +905,11 @@ func AddInventoryInBulk(user string, userID string, draftInventoryList model.Inv
 			return nil, err
 		}
 
-		sqlStr := `INSERT INTO community.inventory (
-			name, 
-			description, 
-			price, 
-			status, 
-			barcode, 
-			sku,
-			color,
-			quantity, 
-			bought_at, 
-			location, 
-			storage_location_id,
-			min_height,
-			max_height,
-			min_weight,
-			max_weight,
-			created_by, 
-			created_at, 
-			updated_by, 
-			updated_at,
-			sharable_groups
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20);`
-
-		config.Log("SqlStr: %s", nil, sqlStr)
-		_, err = tx.Exec(
-			sqlStr,
-			v.Name,
-			v.Description,
-			v.Price,
-			v.Status,
-			v.Barcode,
-			v.SKU,
-			v.Color,
-			v.Quantity,
-			v.BoughtAt,
-			v.Location,
-			parsedStorageLocationID,
-			v.MinHeight,
-			v.MaxHeight,
-			v.MinWeight,
-			v.MaxWeight,
-			parsedCreatedByUUID,
-			time.Now(),
-			parsedCreatedByUUID,
-			time.Now(),
-			pq.Array(v.SharableGroups),
-		)
-
+		_, err = addInventoryInTx(tx, v, parsedStorageLocationID, parsedCreatedByUUID)
 		if err != nil {
 			config.Log("unable to add assets in bulk", err)
 			tx.Rollback()
-			return nil, err
+			return nil, toDuplicateBarcodeOrSKUError(err)
 		}
 
 	}
@@ -549,12 +927,19 @@ func AddInventoryInBulk(user string, userID string, draftInventoryList model.Inv
 
 	var params []interface{}
 	params = append(params, userID)
-	resp, err := retrieveAllInventoryDetailsForUser(tx, "", params...)
+	resp, err := retrieveAllInventoryDetailsForUser(tx, "", "", params...)
 	if err != nil {
 		config.Log("unable to retrieve all inventories for selected user", err)
 		return nil, err
 	}
 
+	for i := range resp {
+		if err := convertInventoryUnits(&resp[i], unitSystem); err != nil {
+			config.Log("unable to convert dimensions and weights", err)
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		config.Log("unable to process trasanction with selected db pool", err)
 		return nil, err
@@ -564,9 +949,97 @@ func AddInventoryInBulk(user string, userID string, draftInventoryList model.Inv
 	return draftInventoryList.InventoryList, nil
 }
 
+// addInventoryInTx ...
+//
+// adds the selected asset within the selected transaction and returns its id. The storage location id is either
+// the uuid of an existing storage location or nil. An empty currency defaults to the base currency of the creator.
+func addInventoryInTx(tx *sql.Tx, v model.Inventory, storageLocationID interface{}, createdBy uuid.UUID) (string, error) {
+	sqlStr := `INSERT INTO community.inventory (
+		name, 
+		description, 
+		price, 
+		status, 
+		barcode, 
+		sku,
+		color,
+		quantity, 
+		bought_at, 
+		location, 
+		storage_location_id,
+		min_height,
+		max_height,
+		min_weight,
+		max_weight,
+		length,
+		width,
+		height,
+		weight,
+		dimension_unit,
+		weight_unit,
+		created_by, 
+		created_at, 
+		updated_by, 
+		updated_at,
+		sharable_groups,
+		currency
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26,
+		COALESCE(NULLIF($27, ''), (SELECT p.base_currency FROM community.profiles p WHERE p.id = $22)))
+	RETURNING id;`
+
+	var inventoryID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(
+		sqlStr,
+		v.Name,
+		v.Description,
+		v.Price,
+		v.Status,
+		v.Barcode,
+		v.SKU,
+		v.Color,
+		v.Quantity,
+		v.BoughtAt,
+		v.Location,
+		storageLocationID,
+		v.MinHeight,
+		v.MaxHeight,
+		v.MinWeight,
+		v.MaxWeight,
+		v.Length,
+		v.Width,
+		v.Height,
+		v.Weight,
+		v.DimensionUnit,
+		v.WeightUnit,
+		createdBy,
+		time.Now(),
+		createdBy,
+		time.Now(),
+		pq.Array(v.SharableGroups),
+		v.Currency,
+	).Scan(&inventoryID)
+	if err != nil {
+		return "", err
+	}
+	return inventoryID, nil
+}
+
 // AddInventory ...
 func AddInventory(user string, userID string, draftInventory model.Inventory) (*model.Inventory, error) {
 
+	if err := ValidateInventoryMeasurements(draftInventory); err != nil {
+		config.Log("unable to validate dimensions and weights", err)
+		return nil, err
+	}
+
+	// the price is in the base currency of the user when the currency is not selected
+	currency, err := normalizeCurrency(draftInventory.Currency)
+	if err != nil {
+		config.Log("unable to validate currency", err)
+		return nil, err
+	}
+	draftInventory.Currency = currency
+
 	db, err := SetupDB(user)
 	if err != nil {
 		config.Log("unable to start the db", err)
@@ -622,6 +1095,28 @@ func AddInventory(user string, userID string, draftInventory model.Inventory) (*
 		draftInventory.UpdatedAt = currentTimestamp
 	}
 
+	err = validateInventoryCustomAttributes(tx, userID, "", draftInventory.CategoryIDs, draftInventory.CustomAttributes, nil)
+	if err != nil {
+		config.Log("unable to validate custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	draftCustomAttributes, err := formatCustomAttributes(draftInventory.CustomAttributes)
+	if err != nil {
+		config.Log("unable to format custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		tx.Rollback()
+		return nil, err
+	}
+	applyDefaultUnits(&draftInventory, unitSystem)
+
 	sqlStr = `INSERT INTO community.inventory (name,
 		description,
 		price,
@@ -644,8 +1139,23 @@ func AddInventory(user string, userID string, draftInventory model.Inventory) (*
 		created_at,
 		updated_by,
 		updated_at,
-		sharable_groups)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		sharable_groups,
+		reorder_point,
+		purchase_date,
+		useful_life_years,
+		salvage_value,
+		depreciation_method,
+		custom_attributes,
+		length,
+		width,
+		height,
+		weight,
+		dimension_unit,
+		weight_unit,
+		currency,
+		expiry_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, NULLIF($24, 0), $25, NULLIF($26, 0), $27, NULLIF($28, ''), $29, $30, $31, $32, $33, $34, $35,
+	COALESCE(NULLIF($36, ''), (SELECT p.base_currency FROM community.profiles p WHERE p.id = $19)), $37)
 RETURNING id;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -674,11 +1184,32 @@ RETURNING id;`
 		parsedCreatedByUUID,
 		draftInventory.UpdatedAt,
 		pq.Array([]uuid.UUID{parsedCreatedByUUID}),
+		draftInventory.ReorderPoint,
+		draftInventory.PurchaseDate,
+		draftInventory.UsefulLifeYears,
+		draftInventory.SalvageValue,
+		draftInventory.DepreciationMethod,
+		draftCustomAttributes,
+		draftInventory.Length,
+		draftInventory.Width,
+		draftInventory.Height,
+		draftInventory.Weight,
+		draftInventory.DimensionUnit,
+		draftInventory.WeightUnit,
+		draftInventory.Currency,
+		draftInventory.ExpiryDate,
 	).Scan(&draftInventory.ID)
 
 	if err != nil {
 		config.Log("unable to add selected inventory", err)
 		tx.Rollback()
+		return nil, toDuplicateBarcodeOrSKUError(err)
+	}
+
+	err = addInventoryToCategories(tx, userID, draftInventory.ID, draftInventory.CategoryIDs)
+	if err != nil {
+		config.Log("unable to add selected inventory to categories", err)
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -693,11 +1224,20 @@ RETURNING id;`
 		inv.name,
 		inv.description,
 		inv.price,
+		inv.currency,
 		inv.status,
 		inv.barcode,
 		inv.sku,
 		inv.quantity,
+		inv.reorder_point,
 		inv.bought_at,
+		inv.purchase_date,
+		inv.expiry_date,
+		inv.useful_life_years,
+		inv.salvage_value,
+		inv.depreciation_method,
+		inv.custom_attributes,
+		COALESCE(inv.parent_id::TEXT, ''),
 		inv.location,
 		inv.storage_location_id,
 		inv.is_returnable,
@@ -708,6 +1248,12 @@ RETURNING id;`
 		inv.min_weight,
 		inv.max_height,
 		inv.min_height,
+		inv.length,
+		inv.width,
+		inv.height,
+		inv.weight,
+		inv.dimension_unit,
+		inv.weight_unit,
 		inv.created_at,
 		inv.created_by,
 		coalesce (cp.full_name, cp.username, cp.email_address) as creator_name,
@@ -726,28 +1272,47 @@ RETURNING id;`
 	row := db.QueryRow(sqlStr, draftInventory.ID)
 
 	updatedInventory := model.Inventory{}
-	var returnNotes sql.NullString
+	var returnNotes, depreciationMethod sql.NullString
+	var reorderPoint, usefulLifeYears sql.NullInt64
+	var measurements inventoryMeasurements
+	var purchaseDate sql.NullTime
+	var customAttributes []byte
 
 	err = row.Scan(
 		&updatedInventory.ID,
 		&updatedInventory.Name,
 		&updatedInventory.Description,
 		&updatedInventory.Price,
+		&updatedInventory.Currency,
 		&updatedInventory.Status,
 		&updatedInventory.Barcode,
 		&updatedInventory.SKU,
 		&updatedInventory.Quantity,
+		&reorderPoint,
 		&updatedInventory.BoughtAt,
+		&purchaseDate,
+		&updatedInventory.ExpiryDate,
+		&usefulLifeYears,
+		&updatedInventory.SalvageValue,
+		&depreciationMethod,
+		&customAttributes,
+		&updatedInventory.ParentID,
 		&updatedInventory.Location,
 		&updatedInventory.StorageLocationID,
 		&updatedInventory.IsReturnable,
 		&updatedInventory.ReturnLocation,
 		&updatedInventory.ReturnDateTime,
 		&returnNotes,
-		&updatedInventory.MaxWeight,
-		&updatedInventory.MinWeight,
-		&updatedInventory.MaxHeight,
-		&updatedInventory.MinHeight,
+		&measurements.maxWeight,
+		&measurements.minWeight,
+		&measurements.maxHeight,
+		&measurements.minHeight,
+		&measurements.length,
+		&measurements.width,
+		&measurements.height,
+		&measurements.weight,
+		&measurements.dimensionUnit,
+		&measurements.weightUnit,
 		&updatedInventory.CreatedAt,
 		&updatedInventory.CreatedBy,
 		&updatedInventory.CreatorName,
@@ -764,11 +1329,36 @@ RETURNING id;`
 		updatedInventory.ReturnNotes = returnNotes.String
 	}
 
+	if reorderPoint.Valid {
+		updatedInventory.ReorderPoint = int(reorderPoint.Int64)
+	}
+
+	measurements.applyTo(&updatedInventory)
+	if err := convertInventoryUnits(&updatedInventory, unitSystem); err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		return nil, err
+	}
+
+	applyDepreciation(&updatedInventory, purchaseDate, usefulLifeYears, depreciationMethod)
+	updatedInventory.CustomAttributes = parseCustomAttributes(customAttributes)
 	return &updatedInventory, nil
 }
 
 // UpdateInventory ...
-func UpdateInventory(user string, userID string, draftInventory model.Inventory) (*model.Inventory, error) {
+func UpdateInventory(user string, userID string, draftInventory model.Inventory, ifMatch []string) (*model.Inventory, error) {
+
+	if err := ValidateInventoryMeasurements(draftInventory); err != nil {
+		config.Log("unable to validate dimensions and weights", err)
+		return nil, err
+	}
+
+	// the currency is retained when the client does not send it
+	currency, err := normalizeCurrency(draftInventory.Currency)
+	if err != nil {
+		config.Log("unable to validate currency", err)
+		return nil, err
+	}
+	draftInventory.Currency = currency
 
 	db, err := SetupDB(user)
 	if err != nil {
@@ -824,6 +1414,86 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		draftInventory.ReturnDateTime = nil
 	}
 
+	before, err := snapshotInventory(tx, userID, draftInventory.ID)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	var snapshot struct {
+		Quantity         int                    `json:"quantity"`
+		CustomAttributes map[string]interface{} `json:"custom_attributes"`
+	}
+	if err := json.Unmarshal(before, &snapshot); err != nil {
+		config.Log("unable to parse snapshot of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	current, err := retrieveSelectedInv(tx, userID, draftInventory.ID)
+	if err != nil {
+		config.Log("unable to retrieve asset details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !isInventoryETagMatch(ifMatch, current.UpdatedAt) {
+		config.Log("unable to update selected asset", errors.New(StaleInventory))
+		tx.Rollback()
+		return nil, errors.New(StaleInventory)
+	}
+
+	err = validateSerializedInventoryQuantity(tx, draftInventory.ID, snapshot.Quantity, draftInventory.Quantity)
+	if err != nil {
+		config.Log("unable to validate quantity of selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// depreciation details are retained when the client does not send them
+	retainDepreciation(&draftInventory, *current)
+	if err := ValidateDepreciation(draftInventory); err != nil {
+		config.Log("unable to validate depreciation details", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// custom attributes are retained when the client does not send them
+	if draftInventory.CustomAttributes == nil {
+		draftInventory.CustomAttributes = snapshot.CustomAttributes
+	}
+
+	err = validateInventoryCustomAttributes(tx, userID, draftInventory.ID, draftInventory.CategoryIDs, draftInventory.CustomAttributes, snapshot.CustomAttributes)
+	if err != nil {
+		config.Log("unable to validate custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	draftCustomAttributes, err := formatCustomAttributes(draftInventory.CustomAttributes)
+	if err != nil {
+		config.Log("unable to format custom attributes", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		config.Log("unable to retrieve unit system", err)
+		tx.Rollback()
+		return nil, err
+	}
+	applyDefaultUnits(&draftInventory, unitSystem)
+
+	// dimensions and weight are retained when the client does not send them
+	if err := retainMeasurements(&draftInventory, *current); err != nil {
+		config.Log("unable to retain dimensions and weights", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the reorder point, the currency and the expiry date are retained when the client does not send them
 	sqlStr = `UPDATE community.inventory inv
 	SET name = $2,
 		description = $3,
@@ -847,7 +1517,21 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		created_by = $21,
 		created_at = $22,
 		updated_by = $23,
-		updated_at = $24
+		updated_at = $24,
+		reorder_point = COALESCE(NULLIF($25, 0), inv.reorder_point),
+		purchase_date = $26,
+		useful_life_years = NULLIF($27, 0),
+		salvage_value = $28,
+		depreciation_method = NULLIF($29, ''),
+		custom_attributes = $30,
+		length = $31,
+		width = $32,
+		height = $33,
+		weight = $34,
+		dimension_unit = $35,
+		weight_unit = $36,
+		currency = COALESCE(NULLIF($37, ''), inv.currency),
+		expiry_date = COALESCE($38, inv.expiry_date)
 	WHERE inv.id = $1
 	RETURNING id;`
 
@@ -878,11 +1562,47 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		draftInventory.CreatedAt,
 		parsedCreatedByUUID,
 		time.Now(),
+		draftInventory.ReorderPoint,
+		draftInventory.PurchaseDate,
+		draftInventory.UsefulLifeYears,
+		draftInventory.SalvageValue,
+		draftInventory.DepreciationMethod,
+		draftCustomAttributes,
+		draftInventory.Length,
+		draftInventory.Width,
+		draftInventory.Height,
+		draftInventory.Weight,
+		draftInventory.DimensionUnit,
+		draftInventory.WeightUnit,
+		draftInventory.Currency,
+		draftInventory.ExpiryDate,
 	).Scan(&draftInventory.ID)
 
 	if err != nil {
 		config.Log("unable to update selected inventory", err)
 		tx.Rollback()
+		return nil, toDuplicateBarcodeOrSKUError(err)
+	}
+
+	// relocating the asset relocates every asset inside it
+	err = moveInventoryDescendants(tx, userID, draftInventory.ID)
+	if err != nil {
+		config.Log("unable to move descendants of selected inventory", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = addInventoryToCategories(tx, userID, draftInventory.ID, draftInventory.CategoryIDs)
+	if err != nil {
+		config.Log("unable to add selected inventory to categories", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordInventoryRevision(tx, userID, draftInventory.ID, before, InventoryRevisionActionUpdate)
+	if err != nil {
+		config.Log("unable to record revision for selected asset", err)
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -905,12 +1625,21 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		inv.name,
 		inv.description,
 		inv.price,
+		inv.currency,
 		inv.status,
 		inv.barcode,
 		inv.sku,
 		inv.color,
 		inv.quantity,
+		inv.reorder_point,
 		inv.bought_at,
+		inv.purchase_date,
+		inv.expiry_date,
+		inv.useful_life_years,
+		inv.salvage_value,
+		inv.depreciation_method,
+		inv.custom_attributes,
+		COALESCE(inv.parent_id::TEXT, ''),
 		inv.location,
 		inv.storage_location_id,
 		inv.is_returnable,
@@ -921,6 +1650,12 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		inv.min_weight,
 		inv.max_height,
 		inv.min_height,
+		inv.length,
+		inv.width,
+		inv.height,
+		inv.weight,
+		inv.dimension_unit,
+		inv.weight_unit,
 		inv.created_at,
 		inv.created_by,
 		coalesce (cp.full_name, cp.username, cp.email_address) as creator_name,
@@ -939,29 +1674,48 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 	row := tx.QueryRow(sqlGetUpdatedInventory, draftInventory.ID)
 
 	updatedInventory := model.Inventory{}
-	var returnNotes, draftColor sql.NullString
+	var returnNotes, draftColor, depreciationMethod sql.NullString
+	var reorderPoint, usefulLifeYears sql.NullInt64
+	var measurements inventoryMeasurements
+	var purchaseDate sql.NullTime
+	var customAttributes []byte
 
 	err = row.Scan(
 		&updatedInventory.ID,
 		&updatedInventory.Name,
 		&updatedInventory.Description,
 		&updatedInventory.Price,
+		&updatedInventory.Currency,
 		&updatedInventory.Status,
 		&updatedInventory.Barcode,
 		&updatedInventory.SKU,
 		&draftColor,
 		&updatedInventory.Quantity,
+		&reorderPoint,
 		&updatedInventory.BoughtAt,
+		&purchaseDate,
+		&updatedInventory.ExpiryDate,
+		&usefulLifeYears,
+		&updatedInventory.SalvageValue,
+		&depreciationMethod,
+		&customAttributes,
+		&updatedInventory.ParentID,
 		&updatedInventory.Location,
 		&updatedInventory.StorageLocationID,
 		&updatedInventory.IsReturnable,
 		&updatedInventory.ReturnLocation,
 		&updatedInventory.ReturnDateTime,
 		&returnNotes,
-		&updatedInventory.MaxWeight,
-		&updatedInventory.MinWeight,
-		&updatedInventory.MaxHeight,
-		&updatedInventory.MinHeight,
+		&measurements.maxWeight,
+		&measurements.minWeight,
+		&measurements.maxHeight,
+		&measurements.minHeight,
+		&measurements.length,
+		&measurements.width,
+		&measurements.height,
+		&measurements.weight,
+		&measurements.dimensionUnit,
+		&measurements.weightUnit,
 		&updatedInventory.CreatedAt,
 		&updatedInventory.CreatedBy,
 		&updatedInventory.CreatorName,
@@ -990,11 +1744,27 @@ func UpdateInventory(user string, userID string, draftInventory model.Inventory)
 		updatedInventory.ReturnNotes = returnNotes.String
 	}
 
+	if reorderPoint.Valid {
+		updatedInventory.ReorderPoint = int(reorderPoint.Int64)
+	}
+
+	measurements.applyTo(&updatedInventory)
+	if err := convertInventoryUnits(&updatedInventory, unitSystem); err != nil {
+		config.Log("unable to convert dimensions and weights", err)
+		return nil, err
+	}
+
+	applyDepreciation(&updatedInventory, purchaseDate, usefulLifeYears, depreciationMethod)
+	updatedInventory.CustomAttributes = parseCustomAttributes(customAttributes)
+
 	// Return the updated inventory object
 	return &updatedInventory, nil
 }
 
 // DeleteInventory ...
+//
+// DeleteInventory moves the selected inventories to the trash. Associations with categories and maintenance plans
+// are retained so that they can be restored along with the inventory.
 func DeleteInventory(user string, userID string, pruneInventoriesIDs []string) ([]string, error) {
 
 	db, err := SetupDB(user)
@@ -1003,7 +1773,7 @@ func DeleteInventory(user string, userID string, pruneInventoriesIDs []string) (
 	}
 	defer db.Close()
 
-	sqlStr := `DELETE FROM community.inventory WHERE id = ANY($1);`
+	sqlStr := `UPDATE community.inventory SET deleted_at = NOW() WHERE id = ANY($1) AND deleted_at IS NULL;`
 	config.Log("SqlStr: %s", nil, sqlStr)
 	_, err = db.Exec(sqlStr, pq.Array(pruneInventoriesIDs))
 	if err != nil {
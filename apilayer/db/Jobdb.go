@@ -0,0 +1,616 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+
+	InvalidJob         = "invalid job"
+	JobAlreadyFinished = "job is already finished"
+	JobIsNotFinished   = "job is queued or running"
+
+	// StaleJobError is the error of the jobs that ran out of attempts while the worker running them stopped responding
+	StaleJobError = "job stopped responding"
+
+	maxJobTypeLength            = 50
+	maxJobProgressMessageLength = 255
+)
+
+// jobStatuses are the statuses that the history of jobs can be filtered against
+var jobStatuses = []string{JobStatusQueued, JobStatusRunning, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled}
+
+// RetrieveJobs ...
+//
+// RetrieveJobs returns the history of jobs of the selected user with the most recent job first. The history can be
+// filtered by the status and type of the job. Limit is ignored when it is not positive.
+func RetrieveJobs(user string, userID string, status string, jobType string, limit int) ([]model.Job, error) {
+	if len(status) > 0 && !slices.Contains(jobStatuses, status) {
+		config.Log("unable to validate selected status", errors.New(InvalidJob))
+		return nil, fmt.Errorf("%s: unknown status %s", InvalidJob, status)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	data, err := retrieveJobs(tx, `$1::UUID = ANY(j.sharable_groups) AND ($2 = '' OR j.status = $2) AND ($3 = '' OR j.job_type = $3)`, limit, userID, status, jobType)
+	if err != nil {
+		config.Log("unable to retrieve jobs", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RetrieveJob ...
+//
+// RetrieveJob returns the selected job. Returns sql.ErrNoRows when the job is not found.
+func RetrieveJob(user string, userID string, jobID string) (*model.Job, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	data, err := retrieveJob(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddJob ...
+//
+// AddJob queues a new job of the selected type to be run as soon as a worker is available. The payload must be
+// valid json; the payload of each type is validated by the caller.
+func AddJob(user string, userID string, jobType string, payload json.RawMessage, maxAttempts int) (*model.Job, error) {
+	if len(jobType) == 0 || utf8.RuneCountInString(jobType) > maxJobTypeLength || maxAttempts <= 0 {
+		config.Log("unable to validate selected job", errors.New(InvalidJob))
+		return nil, errors.New(InvalidJob)
+	}
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	if !json.Valid(payload) {
+		config.Log("unable to validate selected payload", errors.New(InvalidJob))
+		return nil, fmt.Errorf("%s: payload must be valid json", InvalidJob)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.jobs (job_type, status, payload, max_attempts, run_at, created_at, created_by, updated_at, updated_by, sharable_groups)
+		VALUES ($2, $3, $4, $5, $6, $6, $1, $6, $1, ARRAY[$1::UUID])
+		RETURNING id;`
+
+	var jobID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, userID, jobType, JobStatusQueued, []byte(payload), maxAttempts, time.Now()).Scan(&jobID)
+	if err != nil {
+		config.Log("unable to add selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveJob(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// CancelJob ...
+//
+// CancelJob cancels the selected job. Queued jobs are cancelled right away. Running jobs are marked so that the
+// worker stops the job once it notices the request; the job stays running until then.
+func CancelJob(user string, userID string, jobID string) (*model.Job, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	status, err := retrieveJobStatusForUpdate(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if status != JobStatusQueued && status != JobStatusRunning {
+		config.Log("unable to cancel finished job", errors.New(JobAlreadyFinished))
+		tx.Rollback()
+		return nil, errors.New(JobAlreadyFinished)
+	}
+
+	sqlStr := `UPDATE community.jobs
+		SET cancel_requested = TRUE,
+			status = CASE WHEN status = $3 THEN $4 ELSE status END,
+			finished_at = CASE WHEN status = $3 THEN $5 ELSE finished_at END,
+			updated_at = $5,
+			updated_by = $1
+		WHERE $1::UUID = ANY(sharable_groups) AND id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, userID, jobID, JobStatusQueued, JobStatusCancelled, time.Now())
+	if err != nil {
+		config.Log("unable to cancel selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveJob(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RemoveJob ...
+//
+// RemoveJob removes the selected job from the history of the user. Only finished jobs can be removed. Returns the
+// removed job so that the file built by the job can be removed as well.
+func RemoveJob(user string, userID string, jobID string) (*model.Job, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	status, err := retrieveJobStatusForUpdate(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if status == JobStatusQueued || status == JobStatusRunning {
+		config.Log("unable to remove active job", errors.New(JobIsNotFinished))
+		tx.Rollback()
+		return nil, errors.New(JobIsNotFinished)
+	}
+
+	data, err := retrieveJob(tx, userID, jobID)
+	if err != nil {
+		config.Log("unable to retrieve selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `DELETE FROM community.jobs j WHERE $1::UUID = ANY(j.sharable_groups) AND j.id = $2;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, userID, jobID)
+	if err != nil {
+		config.Log("unable to remove selected job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// ClaimJob ...
+//
+// ClaimJob locks the next queued job that is due for the selected worker and marks it as running. Jobs that are
+// locked by another worker are skipped. Running jobs whose lock is older than the lock timeout are claimed again
+// since the worker running them stopped responding; the ones that ran out of attempts or were cancelled are
+// finished instead. Returns nil when there is no job to run.
+func ClaimJob(user string, workerID string, lockTimeout time.Duration) (*model.Job, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	staleSqlStr := `UPDATE community.jobs
+		SET status = CASE WHEN cancel_requested THEN $3 ELSE $4 END,
+			last_error = CASE WHEN cancel_requested THEN last_error ELSE $5 END,
+			locked_at = NULL,
+			locked_by = NULL,
+			finished_at = $1,
+			updated_at = $1
+		WHERE status = $6
+		AND locked_at < $1::TIMESTAMP WITH TIME ZONE - $2::DOUBLE PRECISION * INTERVAL '1 second'
+		AND (cancel_requested OR attempts >= max_attempts);`
+
+	config.Log("SqlStr: %s", nil, staleSqlStr)
+	_, err = tx.Exec(staleSqlStr, now, lockTimeout.Seconds(), JobStatusCancelled, JobStatusFailed, StaleJobError, JobStatusRunning)
+	if err != nil {
+		config.Log("unable to finish stale jobs", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.jobs j
+		SET status = $4,
+			attempts = j.attempts + 1,
+			locked_at = $1,
+			locked_by = $2,
+			started_at = COALESCE(j.started_at, $1),
+			updated_at = $1
+		WHERE j.id = (
+			SELECT q.id
+			FROM community.jobs q
+			WHERE (q.status = $5 AND q.run_at <= $1)
+			OR (q.status = $4 AND q.locked_at < $1::TIMESTAMP WITH TIME ZONE - $3::DOUBLE PRECISION * INTERVAL '1 second')
+			ORDER BY q.run_at, q.created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING j.id;`
+
+	var jobID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, now, workerID, lockTimeout.Seconds(), JobStatusRunning, JobStatusQueued).Scan(&jobID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		config.Log("unable to claim next job", err)
+		return nil, err
+	}
+
+	data, err := retrieveJobs(tx, `j.id = $1`, 0, jobID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve claimed job", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// HeartbeatJob ...
+//
+// HeartbeatJob renews the lock of the selected worker on the running job and returns true when the job is
+// cancelled. Returns sql.ErrNoRows when the worker no longer holds the lock, eg when the job was claimed again by
+// another worker.
+func HeartbeatJob(user string, workerID string, jobID string) (bool, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return false, err
+	}
+	defer db.Close()
+
+	sqlStr := `UPDATE community.jobs
+		SET locked_at = $3
+		WHERE id = $2 AND locked_by = $1 AND status = $4
+		RETURNING cancel_requested;`
+
+	var cancelRequested bool
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, workerID, jobID, time.Now(), JobStatusRunning).Scan(&cancelRequested)
+	if err != nil {
+		return false, err
+	}
+	return cancelRequested, nil
+}
+
+// UpdateJobProgress ...
+//
+// UpdateJobProgress saves the progress of the running job. Progress is the percent of the job that is completed
+// and is kept between 0 and 100.
+func UpdateJobProgress(user string, workerID string, jobID string, progress int, message string) error {
+	progress = max(0, min(100, progress))
+	if utf8.RuneCountInString(message) > maxJobProgressMessageLength {
+		message = string([]rune(message)[:maxJobProgressMessageLength])
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `UPDATE community.jobs
+		SET progress = $3, progress_message = NULLIF($4, ''), locked_at = $5, updated_at = $5
+		WHERE id = $2 AND locked_by = $1 AND status = $6
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, workerID, jobID, progress, message, time.Now(), JobStatusRunning).Scan(&jobID)
+	if err != nil {
+		config.Log("unable to update progress of selected job", err)
+		return err
+	}
+	return nil
+}
+
+// FinishJob ...
+//
+// FinishJob releases the lock of the selected worker and marks the job as succeeded, failed or cancelled. Result is
+// only saved for succeeded jobs.
+func FinishJob(user string, workerID string, jobID string, status string, result json.RawMessage, lastError string) error {
+	if status != JobStatusSucceeded && status != JobStatusFailed && status != JobStatusCancelled {
+		config.Log("unable to validate selected status", errors.New(InvalidJob))
+		return fmt.Errorf("%s: unknown status %s", InvalidJob, status)
+	}
+
+	var draftResult interface{}
+	if status == JobStatusSucceeded && len(result) > 0 {
+		draftResult = []byte(result)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `UPDATE community.jobs
+		SET status = $3,
+			result = $4,
+			progress = CASE WHEN $3 = $7 THEN 100 ELSE progress END,
+			last_error = COALESCE(NULLIF($5, ''), last_error),
+			locked_at = NULL,
+			locked_by = NULL,
+			finished_at = $6,
+			updated_at = $6
+		WHERE id = $2 AND locked_by = $1 AND status = $8
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, workerID, jobID, status, draftResult, lastError, time.Now(), JobStatusSucceeded, JobStatusRunning).Scan(&jobID)
+	if err != nil {
+		config.Log("unable to finish selected job", err)
+		return err
+	}
+	return nil
+}
+
+// RetryJob ...
+//
+// RetryJob releases the lock of the selected worker and queues the failed job again to be run at the selected time.
+// Jobs that are cancelled in the meantime are cancelled instead.
+func RetryJob(user string, workerID string, jobID string, runAt time.Time, lastError string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `UPDATE community.jobs
+		SET status = CASE WHEN cancel_requested THEN $5 ELSE $6 END,
+			finished_at = CASE WHEN cancel_requested THEN $7 ELSE finished_at END,
+			run_at = $3,
+			last_error = $4,
+			locked_at = NULL,
+			locked_by = NULL,
+			updated_at = $7
+		WHERE id = $2 AND locked_by = $1 AND status = $8
+		RETURNING id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, workerID, jobID, runAt, lastError, JobStatusCancelled, JobStatusQueued, time.Now(), JobStatusRunning).Scan(&jobID)
+	if err != nil {
+		config.Log("unable to retry selected job", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveJob ...
+//
+// returns the selected job visible to the user. Returns sql.ErrNoRows when the job is not found.
+func retrieveJob(tx *sql.Tx, userID string, jobID string) (*model.Job, error) {
+	data, err := retrieveJobs(tx, `$1::UUID = ANY(j.sharable_groups) AND j.id = $2`, 0, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &data[0], nil
+}
+
+// retrieveJobStatusForUpdate ...
+//
+// returns the status of the selected job and locks the job until the transaction ends
+func retrieveJobStatusForUpdate(tx *sql.Tx, userID string, jobID string) (string, error) {
+	sqlStr := `SELECT j.status FROM community.jobs j WHERE $1::UUID = ANY(j.sharable_groups) AND j.id = $2 FOR UPDATE;`
+
+	var status string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, userID, jobID).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// retrieveJobs ...
+//
+// returns the jobs that match the selected where clause with the most recent job first. Limit is ignored when it
+// is not positive.
+func retrieveJobs(tx *sql.Tx, whereClause string, limit int, params ...interface{}) ([]model.Job, error) {
+	sqlStr := `SELECT
+		j.id,
+		j.job_type,
+		j.status,
+		j.payload,
+		j.result,
+		j.progress,
+		COALESCE(j.progress_message, ''),
+		j.attempts,
+		j.max_attempts,
+		COALESCE(j.last_error, ''),
+		j.cancel_requested,
+		j.run_at,
+		j.started_at,
+		j.finished_at,
+		j.created_at,
+		COALESCE(j.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		j.updated_at,
+		COALESCE(j.updated_by::TEXT, ''),
+		j.sharable_groups
+	FROM community.jobs j
+	LEFT JOIN community.profiles cp ON cp.id = j.created_by
+	WHERE ` + whereClause + `
+	ORDER BY j.created_at DESC`
+	if limit > 0 {
+		sqlStr += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.Job, 0)
+	for rows.Next() {
+		var job model.Job
+		var payload, result []byte
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(
+			&job.ID,
+			&job.Type,
+			&job.Status,
+			&payload,
+			&result,
+			&job.Progress,
+			&job.ProgressMessage,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.CancelRequested,
+			&job.RunAt,
+			&startedAt,
+			&finishedAt,
+			&job.CreatedAt,
+			&job.CreatedBy,
+			&job.Creator,
+			&job.UpdatedAt,
+			&job.UpdatedBy,
+			pq.Array(&job.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected jobs", err)
+			return nil, err
+		}
+
+		job.Payload = payload
+		if len(result) > 0 {
+			job.Result = result
+		}
+		if startedAt.Valid {
+			job.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			job.FinishedAt = &finishedAt.Time
+		}
+		data = append(data, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
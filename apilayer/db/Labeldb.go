@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	LabelTypeInventory       = "inventory"
+	LabelTypeStorageLocation = "storage_location"
+)
+
+// RetrieveLabels ...
+//
+// RetrieveLabels returns the printable details of the selected assets followed by the selected storage locations.
+// Labels are returned in the same order as the selected ids. Assets and storage locations that are not shared with the user are skipped.
+func RetrieveLabels(user string, userID string, inventoryIDs []string, storageLocationIDs []string) ([]model.Label, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT inv.id, $3::TEXT AS type, inv.name, COALESCE(inv.barcode, ''), COALESCE(inv.sku, ''), array_position($2::UUID[], inv.id) AS position, 0 AS source
+		FROM community.inventory inv
+		WHERE inv.id = ANY($2::UUID[])
+		AND $1::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+	UNION ALL
+	SELECT sl.id, $5::TEXT AS type, sl.location, '', '', array_position($4::UUID[], sl.id) AS position, 1 AS source
+		FROM community.storage_locations sl
+		WHERE sl.id = ANY($4::UUID[])
+		AND $1::UUID = ANY(sl.sharable_groups)
+	ORDER BY source, position;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, pq.Array(inventoryIDs), LabelTypeInventory, pq.Array(storageLocationIDs), LabelTypeStorageLocation)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []model.Label
+	for rows.Next() {
+		var label model.Label
+		var position, source int
+
+		if err := rows.Scan(&label.ID, &label.Type, &label.Name, &label.Barcode, &label.SKU, &position, &source); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+		data = append(data, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,589 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	LoanStatusOpen    = "open"
+	LoanStatusOverdue = "overdue"
+	LoanStatusAll     = "all"
+
+	UnavailableAssetsForLoan = "unable to find selected assets or assets are already checked out"
+	InvalidLoanRequest       = "invalid asset or unit id in selected loan"
+)
+
+// RetrieveLoans ...
+func RetrieveLoans(user string, userID string, loanStatus string) ([]model.Loan, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var additionalWhereClause string
+	switch loanStatus {
+	case LoanStatusOpen:
+		additionalWhereClause = " AND EXISTS (SELECT 1 FROM community.loan_items li WHERE li.loan_id = l.id AND li.checked_in_at IS NULL)"
+	case LoanStatusOverdue:
+		additionalWhereClause = " AND l.due_date < NOW() AND EXISTS (SELECT 1 FROM community.loan_items li WHERE li.loan_id = l.id AND li.checked_in_at IS NULL)"
+	}
+
+	data, err := retrieveLoans(tx, additionalWhereClause, userID)
+	if err != nil {
+		config.Log("unable to retrieve loans for selected user", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// CheckOutAssets ...
+func CheckOutAssets(user string, userID string, draftLoan model.LoanRequest) (*model.Loan, error) {
+	// repeated ids are checked out once so that they are not counted as unavailable
+	var err error
+	draftLoan.AssetIDs, err = uniqueLoanItemIDs(draftLoan.AssetIDs)
+	if err != nil {
+		config.Log("unable to validate selected assets", err)
+		return nil, err
+	}
+	draftLoan.UnitIDs, err = uniqueLoanItemIDs(draftLoan.UnitIDs)
+	if err != nil {
+		config.Log("unable to validate selected units", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		config.Log("unable to parse user id", err)
+		return nil, err
+	}
+
+	borrowerID := sql.NullString{}
+	if len(draftLoan.BorrowerID) > 0 {
+		borrowerID = sql.NullString{String: draftLoan.BorrowerID, Valid: true}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	// the selected assets and units are locked so that concurrent loans cannot check out the same asset or unit.
+	// assets are locked before units and both in order of id, so that concurrent loans wait instead of deadlocking
+	sqlStr := `SELECT inv.id
+		FROM community.inventory inv
+		WHERE (inv.id = ANY($1::UUID[]) OR inv.id IN (SELECT iu.item_id FROM community.inventory_units iu WHERE iu.id = ANY($2::UUID[])))
+		AND $3::UUID = ANY(inv.sharable_groups)
+		ORDER BY inv.id
+		FOR UPDATE;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, pq.Array(draftLoan.AssetIDs), pq.Array(draftLoan.UnitIDs), parsedUserID)
+	if err != nil {
+		config.Log("unable to lock selected assets", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(draftLoan.UnitIDs) > 0 {
+		sqlStr = `SELECT iu.id
+			FROM community.inventory_units iu
+			WHERE iu.id = ANY($1::UUID[])
+			AND $2::UUID = ANY(iu.sharable_groups)
+			ORDER BY iu.id
+			FOR UPDATE;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		_, err = tx.Exec(sqlStr, pq.Array(draftLoan.UnitIDs), parsedUserID)
+		if err != nil {
+			config.Log("unable to lock selected units", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	sqlStr = `INSERT INTO community.loans (borrower_name, borrower_email, borrower_id, due_date, checked_out_at, notes, created_by, created_at, updated_by, updated_at, sharable_groups)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $5, $7, $5, $8)
+		RETURNING id;`
+
+	var loanID string
+	currentTime := time.Now()
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(
+		sqlStr,
+		draftLoan.BorrowerName,
+		draftLoan.BorrowerEmail,
+		borrowerID,
+		draftLoan.DueDate,
+		currentTime,
+		draftLoan.Notes,
+		parsedUserID,
+		pq.Array([]uuid.UUID{parsedUserID}),
+	).Scan(&loanID)
+	if err != nil {
+		config.Log("unable to add new loan", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// assets that are not shared with the user or are part of another open loan are skipped.
+	// an asset is part of an open loan when the asset itself or any of its units is checked out
+	var checkedOutRows int64
+	if len(draftLoan.AssetIDs) > 0 {
+		sqlStr = `INSERT INTO community.loan_items (loan_id, item_id, created_by, created_at, updated_by, updated_at, sharable_groups)
+			SELECT $1, inv.id, $3, $4, $3, $4, l.sharable_groups
+			FROM community.inventory inv
+			JOIN community.loans l ON l.id = $1
+			WHERE inv.id = ANY($2::UUID[])
+			AND $3::UUID = ANY(inv.sharable_groups)
+			AND inv.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM community.loan_items oli WHERE oli.item_id = inv.id AND oli.checked_in_at IS NULL
+			)
+			ON CONFLICT DO NOTHING;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		result, err := tx.Exec(sqlStr, loanID, pq.Array(draftLoan.AssetIDs), parsedUserID, currentTime)
+		if err != nil {
+			config.Log("unable to add assets to selected loan", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			config.Log("unable to retrieve checked out assets", err)
+			tx.Rollback()
+			return nil, err
+		}
+		checkedOutRows += rowsAffected
+	}
+
+	// only available units are checked out, and units cannot be checked out while the whole asset is on loan
+	if len(draftLoan.UnitIDs) > 0 {
+		sqlStr = `INSERT INTO community.loan_items (loan_id, item_id, unit_id, created_by, created_at, updated_by, updated_at, sharable_groups)
+			SELECT $1, iu.item_id, iu.id, $3, $4, $3, $4, l.sharable_groups
+			FROM community.inventory_units iu
+			JOIN community.inventory inv ON inv.id = iu.item_id
+			JOIN community.loans l ON l.id = $1
+			WHERE iu.id = ANY($2::UUID[])
+			AND $3::UUID = ANY(iu.sharable_groups)
+			AND inv.deleted_at IS NULL
+			AND iu.status = $5
+			AND NOT EXISTS (
+				SELECT 1 FROM community.loan_items oli WHERE oli.item_id = iu.item_id AND oli.unit_id IS NULL AND oli.checked_in_at IS NULL
+			)
+			ON CONFLICT DO NOTHING;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		result, err := tx.Exec(sqlStr, loanID, pq.Array(draftLoan.UnitIDs), parsedUserID, currentTime, InventoryUnitStatusAvailable)
+		if err != nil {
+			config.Log("unable to add units to selected loan", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			config.Log("unable to retrieve checked out units", err)
+			tx.Rollback()
+			return nil, err
+		}
+		checkedOutRows += rowsAffected
+
+		sqlStr = `UPDATE community.inventory_units iu
+			SET status = $2,
+				updated_by = $3,
+				updated_at = $4
+			FROM community.loan_items li
+			WHERE li.loan_id = $1
+			AND li.unit_id = iu.id;`
+
+		config.Log("SqlStr: %s", nil, sqlStr)
+		_, err = tx.Exec(sqlStr, loanID, InventoryUnitStatusInUse, parsedUserID, currentTime)
+		if err != nil {
+			config.Log("unable to update status of checked out units", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if int(checkedOutRows) != len(draftLoan.AssetIDs)+len(draftLoan.UnitIDs) {
+		config.Log("unable to check out selected assets", errors.New(UnavailableAssetsForLoan))
+		tx.Rollback()
+		return nil, errors.New(UnavailableAssetsForLoan)
+	}
+
+	data, err := retrieveLoans(tx, " AND l.id = $2", userID, loanID)
+	if err == nil && len(data) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		config.Log("unable to retrieve selected loan", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return &data[0], nil
+}
+
+// CheckInAssets ...
+func CheckInAssets(user string, userID string, loanID string, draftCheckIn model.LoanCheckInRequest) (*model.Loan, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	parsedLoanID, err := uuid.Parse(loanID)
+	if err != nil {
+		config.Log("unable to parse loan id", err)
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	var additionalWhereClause string
+	params := []interface{}{parsedLoanID, userID, time.Now(), draftCheckIn.ConditionNotes, InventoryUnitStatusInUse, InventoryUnitStatusAvailable}
+	if len(draftCheckIn.AssetIDs) > 0 || len(draftCheckIn.UnitIDs) > 0 {
+		additionalWhereClause = " AND (li.item_id = ANY($7::UUID[]) OR li.unit_id = ANY($8::UUID[]))"
+		params = append(params, pq.Array(draftCheckIn.AssetIDs), pq.Array(draftCheckIn.UnitIDs))
+	}
+
+	// checked in units are available again unless their status was changed while they were on loan
+	sqlStr := `WITH checked_in AS (
+			UPDATE community.loan_items li
+			SET checked_in_at = $3,
+				checked_in_by = $2,
+				condition_notes = $4,
+				updated_at = $3,
+				updated_by = $2
+			WHERE li.loan_id = $1
+			AND li.checked_in_at IS NULL
+			AND $2::UUID = ANY(li.sharable_groups)` + additionalWhereClause + `
+			RETURNING li.unit_id
+		),
+		available_units AS (
+			UPDATE community.inventory_units iu
+			SET status = $6,
+				updated_by = $2,
+				updated_at = $3
+			FROM checked_in ci
+			WHERE iu.id = ci.unit_id
+			AND iu.status = $5
+		)
+		SELECT COUNT(*) FROM checked_in;`
+
+	var rowsAffected int
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, params...).Scan(&rowsAffected)
+	if err != nil {
+		config.Log("unable to check in selected assets", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		config.Log("unable to find open assets for selected loan", sql.ErrNoRows)
+		tx.Rollback()
+		return nil, sql.ErrNoRows
+	}
+
+	data, err := retrieveLoans(tx, " AND l.id = $2", userID, parsedLoanID)
+	if err == nil && len(data) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		config.Log("unable to retrieve selected loan", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return &data[0], nil
+}
+
+// RetrieveAssetLoanHistory ...
+func RetrieveAssetLoanHistory(user string, userID string, assetID string) ([]model.LoanHistory, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT
+		l.id,
+		li.item_id,
+		COALESCE(li.unit_id::TEXT, ''),
+		COALESCE(iu.serial_number, ''),
+		l.borrower_name,
+		COALESCE(l.borrower_email, ''),
+		l.due_date,
+		l.checked_out_at,
+		li.checked_in_at,
+		COALESCE(cp.username, cp.full_name, cp.email_address, ''),
+		COALESCE(li.condition_notes, '')
+	FROM community.loan_items li
+	JOIN community.loans l ON l.id = li.loan_id
+	LEFT JOIN community.inventory_units iu ON iu.id = li.unit_id
+	LEFT JOIN community.profiles cp ON cp.id = li.checked_in_by
+	WHERE li.item_id = $2 AND $1::UUID = ANY(li.sharable_groups)
+	ORDER BY l.checked_out_at DESC;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, assetID)
+	if err != nil {
+		config.Log("unable to retrieve loan history for selected asset", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.LoanHistory, 0)
+	currentTime := time.Now()
+
+	for rows.Next() {
+		var loanHistory model.LoanHistory
+		var checkedInAt sql.NullTime
+
+		if err := rows.Scan(
+			&loanHistory.LoanID,
+			&loanHistory.ItemID,
+			&loanHistory.UnitID,
+			&loanHistory.SerialNumber,
+			&loanHistory.BorrowerName,
+			&loanHistory.BorrowerEmail,
+			&loanHistory.DueDate,
+			&loanHistory.CheckedOutAt,
+			&checkedInAt,
+			&loanHistory.CheckedInBy,
+			&loanHistory.ConditionNotes,
+		); err != nil {
+			config.Log("unable to scan loan history", err)
+			return nil, err
+		}
+
+		if checkedInAt.Valid {
+			loanHistory.CheckedInAt = &checkedInAt.Time
+		} else {
+			loanHistory.IsOverdue = loanHistory.DueDate.Before(currentTime)
+		}
+
+		data = append(data, loanHistory)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to process loan history", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// retrieveLoans ...
+//
+// retrieves the list of loans shared with the selected user alongside the assets of each loan.
+// userID is expected to be the first param
+func retrieveLoans(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.Loan, error) {
+	sqlStr := `SELECT
+		l.id,
+		l.borrower_name,
+		COALESCE(l.borrower_email, ''),
+		COALESCE(l.borrower_id::TEXT, ''),
+		l.due_date,
+		l.checked_out_at,
+		COALESCE(l.notes, ''),
+		l.created_at,
+		l.created_by,
+		COALESCE(cp.username, cp.full_name, cp.email_address) AS creator,
+		l.updated_at,
+		l.updated_by,
+		COALESCE(up.username, up.full_name, up.email_address) AS updator,
+		l.sharable_groups
+	FROM community.loans l
+	LEFT JOIN community.profiles cp ON cp.id = l.created_by
+	LEFT JOIN community.profiles up ON up.id = l.updated_by
+	WHERE $1::UUID = ANY(l.sharable_groups)` + additionalWhereClause + `
+	ORDER BY l.due_date ASC;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to retrieve loans", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.Loan, 0)
+	loanIndex := make(map[string]int)
+
+	for rows.Next() {
+		var loan model.Loan
+		var creator, updator sql.NullString
+
+		if err := rows.Scan(
+			&loan.ID,
+			&loan.BorrowerName,
+			&loan.BorrowerEmail,
+			&loan.BorrowerID,
+			&loan.DueDate,
+			&loan.CheckedOutAt,
+			&loan.Notes,
+			&loan.CreatedAt,
+			&loan.CreatedBy,
+			&creator,
+			&loan.UpdatedAt,
+			&loan.UpdatedBy,
+			&updator,
+			pq.Array(&loan.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected loan", err)
+			return nil, err
+		}
+
+		loan.Creator = creator.String
+		loan.Updator = updator.String
+		loan.Items = make([]model.LoanItem, 0)
+		loanIndex[loan.ID] = len(data)
+		data = append(data, loan)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to process selected loans", err)
+		return nil, err
+	}
+	rows.Close()
+
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	loanIDs := make([]string, 0, len(data))
+	for _, v := range data {
+		loanIDs = append(loanIDs, v.ID)
+	}
+
+	sqlStr = `SELECT
+		li.id,
+		li.loan_id,
+		li.item_id,
+		COALESCE(li.unit_id::TEXT, ''),
+		COALESCE(iu.serial_number, ''),
+		inv.name,
+		li.checked_in_at,
+		COALESCE(li.checked_in_by::TEXT, ''),
+		COALESCE(li.condition_notes, '')
+	FROM community.loan_items li
+	JOIN community.inventory inv ON inv.id = li.item_id
+	LEFT JOIN community.inventory_units iu ON iu.id = li.unit_id
+	WHERE li.loan_id = ANY($1::UUID[])
+	ORDER BY inv.name, LOWER(iu.serial_number);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	itemRows, err := tx.Query(sqlStr, pq.Array(loanIDs))
+	if err != nil {
+		config.Log("unable to retrieve loan items", err)
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	currentTime := time.Now()
+	for itemRows.Next() {
+		var loanItem model.LoanItem
+		var checkedInAt sql.NullTime
+
+		if err := itemRows.Scan(
+			&loanItem.ID,
+			&loanItem.LoanID,
+			&loanItem.ItemID,
+			&loanItem.UnitID,
+			&loanItem.SerialNumber,
+			&loanItem.Name,
+			&checkedInAt,
+			&loanItem.CheckedInBy,
+			&loanItem.ConditionNotes,
+		); err != nil {
+			config.Log("unable to scan selected loan item", err)
+			return nil, err
+		}
+
+		selectedLoan := &data[loanIndex[loanItem.LoanID]]
+		if checkedInAt.Valid {
+			loanItem.CheckedInAt = &checkedInAt.Time
+		} else if selectedLoan.DueDate.Before(currentTime) {
+			selectedLoan.IsOverdue = true
+		}
+		selectedLoan.Items = append(selectedLoan.Items, loanItem)
+	}
+
+	if err := itemRows.Err(); err != nil {
+		config.Log("unable to process selected loan items", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// uniqueLoanItemIDs ...
+//
+// uniqueLoanItemIDs validates the selected asset or unit ids and removes the duplicates
+func uniqueLoanItemIDs(ids []string) ([]string, error) {
+	selectedIDs := make(map[uuid.UUID]bool)
+	data := make([]string, 0, len(ids))
+	for _, v := range ids {
+		parsedID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, errors.New(InvalidLoanRequest)
+		}
+		if selectedIDs[parsedID] {
+			continue
+		}
+		selectedIDs[parsedID] = true
+		data = append(data, parsedID.String())
+	}
+	return data, nil
+}
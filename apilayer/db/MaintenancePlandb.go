@@ -12,7 +12,16 @@ import (
 )
 
 // RetrieveAllMaintenancePlans ...
-func RetrieveAllMaintenancePlans(user string, userID string, limit int) (*[]model.MaintenancePlan, error) {
+//
+// RetrieveAllMaintenancePlans returns the maintenance plans of the user. When tags are selected, only the plans that hold every selected tag are returned.
+func RetrieveAllMaintenancePlans(user string, userID string, limit int, tagIDs []string) (*[]model.MaintenancePlan, error) {
+	params := []interface{}{userID, limit}
+	tagWhereClause, err := buildTagWhereClause(TagEntityTypeMaintenancePlan, "mp", tagIDs, &params)
+	if err != nil {
+		config.Log("unable to build filter for selected tags", err)
+		return nil, err
+	}
+
 	db, err := SetupDB(user)
 	if err != nil {
 		config.Log("unable to setup db", err)
@@ -43,11 +52,12 @@ func RetrieveAllMaintenancePlans(user string, userID string, limit int) (*[]mode
 	LEFT JOIN community.profiles cp on cp.id = mp.created_by
 	LEFT JOIN community.profiles up on up.id = mp.updated_by
 	WHERE $1::UUID = ANY(mp.sharable_groups)
+	AND mp.deleted_at IS NULL` + tagWhereClause + `
 	ORDER BY mp.updated_at DESC
 	LIMIT $2;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
-	rows, err := db.Query(sqlStr, userID, limit)
+	rows, err := db.Query(sqlStr, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +135,7 @@ func RetrieveMaintenancePlan(user string, userID string, maintenanceID string) (
 	LEFT JOIN community.statuses ms on ms.id = mp.status
 	LEFT JOIN community.profiles cp on cp.id = mp.created_by
 	LEFT JOIN community.profiles up on up.id = mp.updated_by
-	WHERE $1::UUID = ANY(mp.sharable_groups) AND mp.id = $2;`
+	WHERE $1::UUID = ANY(mp.sharable_groups) AND mp.id = $2 AND mp.deleted_at IS NULL;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
 	row := db.QueryRow(sqlStr, userID, maintenanceID)
@@ -180,6 +190,8 @@ func RetrieveAllMaintenancePlanItems(user string, userID string, maintenancePlan
 		mi.id,
 		mi.maintenance_plan_id,
 		mi.item_id,
+		COALESCE(mi.unit_id::TEXT, ''),
+		COALESCE(iu.serial_number, ''),
 		i.name,
 		i.description,
 		i.price,
@@ -194,9 +206,10 @@ func RetrieveAllMaintenancePlanItems(user string, userID string, maintenancePlan
 		mi.sharable_groups
 	FROM community.maintenance_item mi
 	LEFT JOIN community.inventory i ON mi.item_id = i.id
+	LEFT JOIN community.inventory_units iu ON mi.unit_id = iu.id
 	LEFT JOIN community.profiles cp ON mi.created_by = cp.id
 	LEFT JOIN community.profiles up ON mi.updated_by = up.id
-	WHERE $1::UUID = ANY(mi.sharable_groups) AND mi.maintenance_plan_id = $2
+	WHERE $1::UUID = ANY(mi.sharable_groups) AND mi.maintenance_plan_id = $2 AND i.deleted_at IS NULL
 	ORDER BY mi.updated_at DESC FETCH FIRST $3 ROWS ONLY;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -212,7 +225,7 @@ func RetrieveAllMaintenancePlanItems(user string, userID string, maintenancePlan
 
 	for rows.Next() {
 		var ec model.MaintenanceItemResponse
-		if err := rows.Scan(&ec.ID, &ec.MaintenancePlanID, &ec.ItemID, &ec.Name, &ec.Description, &ec.Price, &ec.Quantity, &ec.Location, &ec.CreatedBy, &ec.Creator, &ec.CreatedAt, &ec.UpdatedBy, &ec.Updator, &ec.UpdatedAt, &sharableGroups); err != nil {
+		if err := rows.Scan(&ec.ID, &ec.MaintenancePlanID, &ec.ItemID, &ec.UnitID, &ec.SerialNumber, &ec.Name, &ec.Description, &ec.Price, &ec.Quantity, &ec.Location, &ec.CreatedBy, &ec.Creator, &ec.CreatedAt, &ec.UpdatedBy, &ec.Updator, &ec.UpdatedAt, &sharableGroups); err != nil {
 			config.Log("unable to retrieve maintenance items", err)
 			return nil, err
 		}
@@ -420,6 +433,8 @@ func UpdateMaintenancePlan(user string, draftMaintenancePlan *model.MaintenanceP
 }
 
 // RemoveMaintenancePlan ...
+//
+// RemoveMaintenancePlan moves the selected maintenance plan to the trash
 func RemoveMaintenancePlan(user string, planID string) error {
 	db, err := SetupDB(user)
 	if err != nil {
@@ -428,7 +443,7 @@ func RemoveMaintenancePlan(user string, planID string) error {
 	}
 	defer db.Close()
 
-	sqlStr := `DELETE FROM community.maintenance_plan WHERE id=$1;`
+	sqlStr := `UPDATE community.maintenance_plan SET deleted_at = NOW() WHERE id=$1 AND deleted_at IS NULL;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
 	_, err = db.Exec(sqlStr, planID)
@@ -477,6 +492,34 @@ func AddAssetToMaintenancePlan(user string, draftMaintenanceItemRequest *model.M
 		}
 	}
 
+	// units are added against their asset so that the plan lists the asset alongside the serial number of the unit
+	sqlStr = `INSERT INTO community.maintenance_item(maintenance_plan_id, item_id, unit_id, created_by, created_at, updated_by, updated_at, sharable_groups)
+		SELECT $1, iu.item_id, iu.id, $3, $4, $3, $4, $5
+		FROM community.inventory_units iu
+		WHERE iu.id = $2 AND $3::UUID = ANY(iu.sharable_groups);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	for _, unitID := range draftMaintenanceItemRequest.UnitIDs {
+		result, err := tx.Exec(
+			sqlStr,
+			draftMaintenanceItemRequest.ID,
+			unitID,
+			draftMaintenanceItemRequest.UserID,
+			currentTime,
+			pq.Array(draftMaintenanceItemRequest.Collaborators),
+		)
+		if err != nil {
+			tx.Rollback()
+			config.Log("Error executing query", err)
+			return nil, err
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+			tx.Rollback()
+			config.Log("unable to find selected unit", sql.ErrNoRows)
+			return nil, sql.ErrNoRows
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		config.Log("error committing transaction", err)
 		return nil, err
@@ -486,6 +529,8 @@ func AddAssetToMaintenancePlan(user string, draftMaintenanceItemRequest *model.M
 		mi.id,
 		mi.maintenance_plan_id,
 		mi.item_id,
+		COALESCE(mi.unit_id::TEXT, ''),
+		COALESCE(iu.serial_number, ''),
 		i.name,
 		i.description,
 		i.price,
@@ -500,9 +545,10 @@ func AddAssetToMaintenancePlan(user string, draftMaintenanceItemRequest *model.M
 		mi.sharable_groups
 	FROM community.maintenance_item mi
 	LEFT JOIN community.inventory i ON mi.item_id = i.id
+	LEFT JOIN community.inventory_units iu ON mi.unit_id = iu.id
 	LEFT JOIN community.profiles cp ON mi.created_by = cp.id
 	LEFT JOIN community.profiles up ON mi.updated_by = up.id
-	WHERE $1::UUID = ANY(mi.sharable_groups) AND mi.maintenance_plan_id = $2
+	WHERE $1::UUID = ANY(mi.sharable_groups) AND mi.maintenance_plan_id = $2 AND i.deleted_at IS NULL
 	ORDER BY mi.updated_at DESC;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -518,7 +564,7 @@ func AddAssetToMaintenancePlan(user string, draftMaintenanceItemRequest *model.M
 
 	for rows.Next() {
 		var ec model.MaintenanceItemResponse
-		if err := rows.Scan(&ec.ID, &ec.MaintenancePlanID, &ec.ItemID, &ec.Name, &ec.Description, &ec.Price, &ec.Quantity, &ec.Location, &ec.CreatedBy, &ec.Creator, &ec.CreatedAt, &ec.UpdatedBy, &ec.Updator, &ec.UpdatedAt, &sharableGroups); err != nil {
+		if err := rows.Scan(&ec.ID, &ec.MaintenancePlanID, &ec.ItemID, &ec.UnitID, &ec.SerialNumber, &ec.Name, &ec.Description, &ec.Price, &ec.Quantity, &ec.Location, &ec.CreatedBy, &ec.Creator, &ec.CreatedAt, &ec.UpdatedBy, &ec.Updator, &ec.UpdatedAt, &sharableGroups); err != nil {
 			config.Log("unable to retrieve maintenance items", err)
 			return nil, err
 		}
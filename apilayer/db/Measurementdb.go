@@ -0,0 +1,391 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+)
+
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+
+	InvalidMeasurement = "invalid measurement"
+	InvalidUnitSystem  = "invalid unit system"
+
+	MeasurementKindDimension = "dimension"
+	MeasurementKindWeight    = "weight"
+
+	// measurementPrecision is the number of decimal places kept when a measurement is converted into another unit
+	measurementPrecision = 3
+)
+
+// measurementRegex matches a number that is optionally followed by a unit, eg 12, 12kg, 3 ft or 4.5"
+var measurementRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?|\.\d+)\s*([a-zA-Z"'.]*)$`)
+
+// measurementUnits are the supported units of each kind of measurement along with the factor that converts the
+// unit into the smallest unit of the kind. Dimensions are converted into millimeters and weights into grams.
+var measurementUnits = map[string]map[string]float64{
+	MeasurementKindDimension: {
+		"mm": 1,
+		"cm": 10,
+		"m":  1000,
+		"in": 25.4,
+		"ft": 304.8,
+	},
+	MeasurementKindWeight: {
+		"g":  1,
+		"kg": 1000,
+		"oz": 28.349523125,
+		"lb": 453.59237,
+	},
+}
+
+// measurementUnitAliases are the spelled out forms of the supported units that are accepted in uploaded files
+var measurementUnitAliases = map[string]string{
+	"millimeter":  "mm",
+	"millimeters": "mm",
+	"centimeter":  "cm",
+	"centimeters": "cm",
+	"meter":       "m",
+	"meters":      "m",
+	"inch":        "in",
+	"inches":      "in",
+	`"`:           "in",
+	"foot":        "ft",
+	"feet":        "ft",
+	"'":           "ft",
+	"gram":        "g",
+	"grams":       "g",
+	"kilogram":    "kg",
+	"kilograms":   "kg",
+	"kgs":         "kg",
+	"ounce":       "oz",
+	"ounces":      "oz",
+	"lbs":         "lb",
+	"pound":       "lb",
+	"pounds":      "lb",
+}
+
+// preferredUnits are the units that dimensions and weights are converted into for each unit system
+var preferredUnits = map[string]map[string]string{
+	UnitSystemMetric: {
+		MeasurementKindDimension: "cm",
+		MeasurementKindWeight:    "kg",
+	},
+	UnitSystemImperial: {
+		MeasurementKindDimension: "in",
+		MeasurementKindWeight:    "lb",
+	},
+}
+
+// inventoryMeasurements ...
+//
+// inventoryMeasurements are the dimensions and weights of an asset as they are scanned from the db
+type inventoryMeasurements struct {
+	maxWeight     sql.NullFloat64
+	minWeight     sql.NullFloat64
+	maxHeight     sql.NullFloat64
+	minHeight     sql.NullFloat64
+	length        sql.NullFloat64
+	width         sql.NullFloat64
+	height        sql.NullFloat64
+	weight        sql.NullFloat64
+	dimensionUnit sql.NullString
+	weightUnit    sql.NullString
+}
+
+// applyTo ...
+//
+// copies the scanned dimensions and weights into the selected asset
+func (m inventoryMeasurements) applyTo(inventory *model.Inventory) {
+	inventory.MaxWeight = m.maxWeight.Float64
+	inventory.MinWeight = m.minWeight.Float64
+	inventory.MaxHeight = m.maxHeight.Float64
+	inventory.MinHeight = m.minHeight.Float64
+	inventory.Length = m.length.Float64
+	inventory.Width = m.width.Float64
+	inventory.Height = m.height.Float64
+	inventory.Weight = m.weight.Float64
+	inventory.DimensionUnit = m.dimensionUnit.String
+	inventory.WeightUnit = m.weightUnit.String
+}
+
+// ParseMeasurement ...
+//
+// ParseMeasurement parses a dimension or weight such as 12kg or 3 ft. The unit is empty when the value does not
+// have a unit suffix, otherwise the unit is one of the supported units of the selected kind.
+func ParseMeasurement(draftMeasurement string, kind string) (float64, string, error) {
+	draftMeasurement = strings.TrimSpace(draftMeasurement)
+	if len(draftMeasurement) == 0 {
+		return 0, "", nil
+	}
+
+	matches := measurementRegex.FindStringSubmatch(draftMeasurement)
+	if matches == nil {
+		return 0, "", errors.New(InvalidMeasurement)
+	}
+
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, "", errors.New(InvalidMeasurement)
+	}
+
+	unit, err := normalizeMeasurementUnit(matches[2], kind)
+	if err != nil {
+		return 0, "", err
+	}
+	return value, unit, nil
+}
+
+// ConvertMeasurement ...
+//
+// ConvertMeasurement converts the value from one unit into another unit of the same kind
+func ConvertMeasurement(value float64, fromUnit string, toUnit string, kind string) (float64, error) {
+	units := measurementUnits[kind]
+	fromFactor, isFromUnitValid := units[fromUnit]
+	toFactor, isToUnitValid := units[toUnit]
+	if !isFromUnitValid || !isToUnitValid {
+		return 0, errors.New(InvalidMeasurement)
+	}
+	if fromUnit == toUnit {
+		return value, nil
+	}
+
+	precision := math.Pow(10, measurementPrecision)
+	return math.Round(value*fromFactor/toFactor*precision) / precision, nil
+}
+
+// ValidateUnitSystem ...
+func ValidateUnitSystem(unitSystem string) error {
+	if _, ok := preferredUnits[unitSystem]; !ok {
+		return errors.New(InvalidUnitSystem)
+	}
+	return nil
+}
+
+// ValidateInventoryMeasurements ...
+//
+// ValidateInventoryMeasurements ensures that the dimensions and weights of the asset are not negative and that
+// the units are supported. Empty units are allowed and default to the unit system of the user.
+func ValidateInventoryMeasurements(draftInventory model.Inventory) error {
+	for _, v := range []float64{
+		draftInventory.MaxWeight,
+		draftInventory.MinWeight,
+		draftInventory.MaxHeight,
+		draftInventory.MinHeight,
+		draftInventory.Length,
+		draftInventory.Width,
+		draftInventory.Height,
+		draftInventory.Weight,
+	} {
+		if v < 0 {
+			return errors.New(InvalidMeasurement)
+		}
+	}
+
+	if _, ok := measurementUnits[MeasurementKindDimension][draftInventory.DimensionUnit]; len(draftInventory.DimensionUnit) > 0 && !ok {
+		return errors.New(InvalidMeasurement)
+	}
+	if _, ok := measurementUnits[MeasurementKindWeight][draftInventory.WeightUnit]; len(draftInventory.WeightUnit) > 0 && !ok {
+		return errors.New(InvalidMeasurement)
+	}
+	return nil
+}
+
+// ApplyRawInventoryMeasurements ...
+//
+// ApplyRawInventoryMeasurements parses the dimensions and weights of the uploaded row into the selected asset.
+// The first unit found in the row is used for every dimension or weight of the row and the remaining values are
+// converted into it. Values without a unit are assumed to be in that unit. If no value of the row has a unit,
+// the unit is left empty so that the unit system of the user is used.
+func ApplyRawInventoryMeasurements(rawInventory model.RawInventory, draftInventory *model.Inventory) error {
+	dimensionUnit, err := applyRawMeasurements(MeasurementKindDimension,
+		[]model.RawMeasurement{rawInventory.MaximumHeight, rawInventory.MinimumHeight, rawInventory.Length, rawInventory.Width, rawInventory.Height},
+		[]*float64{&draftInventory.MaxHeight, &draftInventory.MinHeight, &draftInventory.Length, &draftInventory.Width, &draftInventory.Height},
+	)
+	if err != nil {
+		return err
+	}
+
+	weightUnit, err := applyRawMeasurements(MeasurementKindWeight,
+		[]model.RawMeasurement{rawInventory.MaximumWeight, rawInventory.MinimumWeight, rawInventory.Weight},
+		[]*float64{&draftInventory.MaxWeight, &draftInventory.MinWeight, &draftInventory.Weight},
+	)
+	if err != nil {
+		return err
+	}
+
+	draftInventory.DimensionUnit = dimensionUnit
+	draftInventory.WeightUnit = weightUnit
+	return nil
+}
+
+// applyRawMeasurements ...
+//
+// parses each raw measurement of the selected kind into the destination at the same index and returns the unit
+// shared by all of them
+func applyRawMeasurements(kind string, rawMeasurements []model.RawMeasurement, destinations []*float64) (string, error) {
+	var sharedUnit string
+	units := make([]string, len(rawMeasurements))
+	for i, rawMeasurement := range rawMeasurements {
+		value, unit, err := ParseMeasurement(string(rawMeasurement), kind)
+		if err != nil {
+			return "", err
+		}
+		if len(sharedUnit) == 0 {
+			sharedUnit = unit
+		}
+		*destinations[i] = value
+		units[i] = unit
+	}
+
+	for i, unit := range units {
+		if len(unit) == 0 || unit == sharedUnit {
+			continue
+		}
+		convertedValue, err := ConvertMeasurement(*destinations[i], unit, sharedUnit, kind)
+		if err != nil {
+			return "", err
+		}
+		*destinations[i] = convertedValue
+	}
+	return sharedUnit, nil
+}
+
+// applyDefaultUnits ...
+//
+// fills in the units of the asset that are not selected with the units of the selected unit system
+func applyDefaultUnits(draftInventory *model.Inventory, unitSystem string) {
+	if len(draftInventory.DimensionUnit) == 0 {
+		draftInventory.DimensionUnit = preferredUnits[unitSystem][MeasurementKindDimension]
+	}
+	if len(draftInventory.WeightUnit) == 0 {
+		draftInventory.WeightUnit = preferredUnits[unitSystem][MeasurementKindWeight]
+	}
+}
+
+// convertInventoryUnits ...
+//
+// converts the dimensions and weights of the asset into the units of the selected unit system
+func convertInventoryUnits(inventory *model.Inventory, unitSystem string) error {
+	units, ok := preferredUnits[unitSystem]
+	if !ok {
+		return errors.New(InvalidUnitSystem)
+	}
+
+	if err := convertInventoryMeasurements(inventory, MeasurementKindDimension, units[MeasurementKindDimension]); err != nil {
+		return err
+	}
+	return convertInventoryMeasurements(inventory, MeasurementKindWeight, units[MeasurementKindWeight])
+}
+
+// convertInventoryMeasurements ...
+//
+// converts every dimension or every weight of the asset into the selected unit. Values of an asset without a unit
+// are assumed to be in the selected unit already.
+func convertInventoryMeasurements(inventory *model.Inventory, kind string, toUnit string) error {
+	unit := &inventory.DimensionUnit
+	measurements := []*float64{&inventory.MaxHeight, &inventory.MinHeight, &inventory.Length, &inventory.Width, &inventory.Height}
+	if kind == MeasurementKindWeight {
+		unit = &inventory.WeightUnit
+		measurements = []*float64{&inventory.MaxWeight, &inventory.MinWeight, &inventory.Weight}
+	}
+
+	if len(*unit) > 0 {
+		for _, v := range measurements {
+			convertedValue, err := ConvertMeasurement(*v, *unit, toUnit, kind)
+			if err != nil {
+				return err
+			}
+			*v = convertedValue
+		}
+	}
+	*unit = toUnit
+	return nil
+}
+
+// retainMeasurements ...
+//
+// keeps the stored dimensions and weight of the asset that are not sent by the client. The retained values are
+// converted into the units of the draft so that every dimension and weight is stored in the same unit.
+func retainMeasurements(draftInventory *model.Inventory, currentInventory model.Inventory) error {
+	if draftInventory.Length == 0 && draftInventory.Width == 0 && draftInventory.Height == 0 {
+		if err := convertInventoryMeasurements(&currentInventory, MeasurementKindDimension, draftInventory.DimensionUnit); err != nil {
+			return err
+		}
+		draftInventory.Length = currentInventory.Length
+		draftInventory.Width = currentInventory.Width
+		draftInventory.Height = currentInventory.Height
+	}
+	if draftInventory.Weight == 0 {
+		if err := convertInventoryMeasurements(&currentInventory, MeasurementKindWeight, draftInventory.WeightUnit); err != nil {
+			return err
+		}
+		draftInventory.Weight = currentInventory.Weight
+	}
+	return nil
+}
+
+// applyPreferredUnits ...
+//
+// converts the dimensions and weights of each asset into the unit system selected in the profile of the user
+func applyPreferredUnits(tx *sql.Tx, userID string, inventories []model.Inventory) error {
+	if len(inventories) == 0 {
+		return nil
+	}
+
+	unitSystem, err := retrieveUnitSystem(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range inventories {
+		if err := convertInventoryUnits(&inventories[i], unitSystem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retrieveUnitSystem ...
+//
+// returns the unit system selected in the profile of the user. Defaults to metric.
+func retrieveUnitSystem(tx *sql.Tx, userID string) (string, error) {
+	sqlStr := `SELECT p.unit_system FROM community.profiles p WHERE p.id = $1;`
+
+	var unitSystem string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err := tx.QueryRow(sqlStr, userID).Scan(&unitSystem)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UnitSystemMetric, nil
+		}
+		config.Log("unable to retrieve unit system of selected user", err)
+		return "", err
+	}
+	return unitSystem, nil
+}
+
+// normalizeMeasurementUnit ...
+//
+// returns the supported unit that matches the selected unit or alias
+func normalizeMeasurementUnit(draftUnit string, kind string) (string, error) {
+	unit := strings.TrimSuffix(strings.ToLower(draftUnit), ".")
+	if len(unit) == 0 {
+		return "", nil
+	}
+	if alias, ok := measurementUnitAliases[unit]; ok {
+		unit = alias
+	}
+	if _, ok := measurementUnits[kind][unit]; !ok {
+		return "", errors.New(InvalidMeasurement)
+	}
+	return unit, nil
+}
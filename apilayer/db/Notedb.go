@@ -12,7 +12,16 @@ import (
 )
 
 // RetrieveNotes ...
-func RetrieveNotes(user string, userID uuid.UUID) ([]model.Note, error) {
+//
+// RetrieveNotes returns the notes of the user. When tags are selected, only the notes that hold every selected tag are returned.
+func RetrieveNotes(user string, userID uuid.UUID, tagIDs []string) ([]model.Note, error) {
+	params := []interface{}{userID}
+	tagWhereClause, err := buildTagWhereClause(TagEntityTypeNote, "n", tagIDs, &params)
+	if err != nil {
+		config.Log("unable to build filter for selected tags", err)
+		return nil, err
+	}
+
 	db, err := SetupDB(user)
 	if err != nil {
 		config.Log("unable to setup db", err)
@@ -42,10 +51,11 @@ func RetrieveNotes(user string, userID uuid.UUID) ([]model.Note, error) {
 	LEFT JOIN community.profiles cp on cp.id = n.created_by
 	LEFT JOIN community.profiles up on up.id = n.updated_by
 	WHERE $1::UUID = ANY(n.sharable_groups)
+	AND n.deleted_at IS NULL` + tagWhereClause + `
 	ORDER BY n.updated_at DESC;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
-	rows, err := db.Query(sqlStr, userID)
+	rows, err := db.Query(sqlStr, params...)
 	if err != nil {
 		config.Log("unable to retrieve selected details", err)
 		return nil, err
@@ -292,6 +302,8 @@ func UpdateNote(user string, userID string, draftNote model.Note) (*model.Note,
 }
 
 // RemoveNote ...
+//
+// RemoveNote moves the selected note to the trash
 func RemoveNote(user string, draftNoteID string) error {
 	db, err := SetupDB(user)
 	if err != nil {
@@ -300,7 +312,7 @@ func RemoveNote(user string, draftNoteID string) error {
 	}
 	defer db.Close()
 
-	sqlStr := `DELETE FROM community.notes WHERE id=$1;`
+	sqlStr := `UPDATE community.notes SET deleted_at = NOW() WHERE id=$1 AND deleted_at IS NULL;`
 	config.Log("SqlStr: %s", nil, sqlStr)
 
 	_, err = db.Exec(sqlStr, draftNoteID)
@@ -32,6 +32,8 @@ func FetchAllUserProfiles(user string) ([]model.Profile, error) {
 			onlinestatus,
 			appearance,
 			grid_view,
+			unit_system,
+			base_currency,
 			role,
 			updated_at
         FROM community.profiles;
@@ -52,7 +54,7 @@ func FetchAllUserProfiles(user string) ([]model.Profile, error) {
 		var updated_at sql.NullTime
 		var userName, fullName, avatarUrl, emailAddress, phoneNumber, aboutMe, role sql.NullString
 
-		if err := rows.Scan(&draftProfile.ID, &userName, &fullName, &avatarUrl, &emailAddress, &phoneNumber, &aboutMe, &draftProfile.OnlineStatus, &draftProfile.Appearance, &draftProfile.GridView, &role, &updated_at); err != nil {
+		if err := rows.Scan(&draftProfile.ID, &userName, &fullName, &avatarUrl, &emailAddress, &phoneNumber, &aboutMe, &draftProfile.OnlineStatus, &draftProfile.Appearance, &draftProfile.GridView, &draftProfile.UnitSystem, &draftProfile.BaseCurrency, &role, &updated_at); err != nil {
 			config.Log("unable to scan selected details", err)
 			return nil, err
 		}
@@ -100,6 +102,8 @@ func FetchUserProfile(user string, userID string) (*model.Profile, error) {
 			onlinestatus,
 			appearance,
 			grid_view,
+			unit_system,
+			base_currency,
 			role,
 			updated_at
 		FROM community.profiles
@@ -126,7 +130,7 @@ func FetchUserProfile(user string, userID string) (*model.Profile, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		if err := rows.Scan(&profileID, &userName, &fullName, &avatarUrl, &emailAddress, &phoneNumber, &aboutMe, &draftProfile.OnlineStatus, &draftProfile.Appearance, &draftProfile.GridView, &role, &updated_at); err != nil {
+		if err := rows.Scan(&profileID, &userName, &fullName, &avatarUrl, &emailAddress, &phoneNumber, &aboutMe, &draftProfile.OnlineStatus, &draftProfile.Appearance, &draftProfile.GridView, &draftProfile.UnitSystem, &draftProfile.BaseCurrency, &role, &updated_at); err != nil {
 			config.Log("unable to scan selected details", err)
 			return nil, err
 		}
@@ -160,15 +164,15 @@ func FetchUserStats(user string, userID string) (model.ProfileStats, error) {
 	sqlStr := `SELECT 
 		(SELECT count(*) 
 				FROM community.category c 
-					WHERE c.created_by = $1
+					WHERE c.created_by = $1 AND c.deleted_at IS NULL
 			) AS total_categories,
     	(SELECT count(*) 
 				FROM community.maintenance_plan mp 
-					WHERE mp.created_by = $1
+					WHERE mp.created_by = $1 AND mp.deleted_at IS NULL
 			) AS total_maintenance_plans,
     	(SELECT count(*) 
 				FROM community.inventory i 
-					WHERE i.created_by = $1
+					WHERE i.created_by = $1 AND i.deleted_at IS NULL
 			) AS total_assets;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -198,8 +202,30 @@ func FetchNotifications(user string, userID string) ([]model.MaintenanceAlertNot
 	}
 	defer db.Close()
 
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction", err)
+		return nil, err
+	}
+
+	err = refreshExpiryAlerts(tx, userID)
+	if err != nil {
+		config.Log("unable to refresh expiry alerts", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit expiry alerts", err)
+		return nil, err
+	}
+
 	sqlStr := `SELECT 
-		maintenance_plan_id, 
+		maintenance_plan_id::TEXT, 
+		NULL AS item_id,
+		'' AS batch_id,
+		0 AS quantity,
+		0 AS reorder_point,
 		name,
 		"type", 
 		plan_due, 
@@ -209,7 +235,43 @@ func FetchNotifications(user string, userID string) ([]model.MaintenanceAlertNot
 		sharable_groups
 	FROM community.maintenance_alert ma
 	WHERE ma.is_read IS NOT TRUE
-	AND $1::UUID = ANY(ma.sharable_groups);`
+	AND $1::UUID = ANY(ma.sharable_groups)
+	AND NOT EXISTS (SELECT 1 FROM community.maintenance_plan mp WHERE mp.id = ma.maintenance_plan_id AND mp.deleted_at IS NOT NULL)
+	UNION ALL
+	SELECT
+		'' AS maintenance_plan_id,
+		lsa.item_id::TEXT,
+		'' AS batch_id,
+		lsa.quantity,
+		lsa.reorder_point,
+		lsa.name,
+		'low stock' AS "type",
+		lsa.updated_at AS plan_due,
+		lsa.is_read,
+		lsa.updated_at,
+		lsa.updated_by,
+		lsa.sharable_groups
+	FROM community.low_stock_alert lsa
+	WHERE lsa.is_read IS NOT TRUE
+	AND $1::UUID = ANY(lsa.sharable_groups)
+	AND NOT EXISTS (SELECT 1 FROM community.inventory inv WHERE inv.id = lsa.item_id AND inv.deleted_at IS NOT NULL)
+	UNION ALL
+	SELECT
+		'' AS maintenance_plan_id,
+		ea.item_id::TEXT,
+		COALESCE(ea.batch_id::TEXT, '') AS batch_id,
+		0 AS quantity,
+		0 AS reorder_point,
+		ea.name,
+		'` + ExpiryNotificationType + `' AS "type",
+		ea.expiry_date::TIMESTAMP WITH TIME ZONE AS plan_due,
+		ea.is_read,
+		ea.updated_at,
+		ea.updated_by,
+		ea.sharable_groups
+	FROM community.expiry_alert ea
+	WHERE ea.is_read IS NOT TRUE
+	AND $1::UUID = ANY(ea.sharable_groups);`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
 	rows, err := db.Query(sqlStr, userID)
@@ -223,10 +285,15 @@ func FetchNotifications(user string, userID string) ([]model.MaintenanceAlertNot
 
 	for rows.Next() {
 		var maintenanceAlertNotification model.MaintenanceAlertNotifications
+		var itemID sql.NullString
 		var sharableGroups pq.StringArray
 
 		err = rows.Scan(
 			&maintenanceAlertNotification.ID,
+			&itemID,
+			&maintenanceAlertNotification.BatchID,
+			&maintenanceAlertNotification.Quantity,
+			&maintenanceAlertNotification.ReorderPoint,
 			&maintenanceAlertNotification.Name,
 			&maintenanceAlertNotification.Type,
 			&maintenanceAlertNotification.PlanDue,
@@ -240,6 +307,10 @@ func FetchNotifications(user string, userID string) ([]model.MaintenanceAlertNot
 			return nil, err
 		}
 
+		if itemID.Valid {
+			maintenanceAlertNotification.ItemID = itemID.String
+		}
+
 		maintenanceAlertNotification.SharableGroups = sharableGroups
 		notifications = append(notifications, maintenanceAlertNotification)
 	}
@@ -276,13 +347,37 @@ func UpdateSelectedNotification(user string, userID string, draftSelectedMainten
 		maintenance_plan_id = $4
 	AND $3::UUID = ANY(sharable_groups);`
 
+	params := []interface{}{draftSelectedMaintenanceAlert.IsRead, time.Now(), userID, draftSelectedMaintenanceAlert.ID}
+
+	// low stock alerts are identified by the selected inventory
+	if len(draftSelectedMaintenanceAlert.ItemID) > 0 {
+		sqlStr = `UPDATE community.low_stock_alert 
+	SET 
+		is_read = $1, 
+		updated_at = $2, 
+		updated_by = $3 
+	WHERE 
+		item_id = $4
+	AND $3::UUID = ANY(sharable_groups);`
+		params[3] = draftSelectedMaintenanceAlert.ItemID
+	}
+
+	// expiry alerts are identified by the selected inventory and batch
+	if draftSelectedMaintenanceAlert.Type == ExpiryNotificationType {
+		sqlStr = `UPDATE community.expiry_alert 
+	SET 
+		is_read = $1, 
+		updated_at = $2, 
+		updated_by = $3 
+	WHERE 
+		item_id = $4
+	AND batch_id IS NOT DISTINCT FROM NULLIF($5, '')::UUID
+	AND $3::UUID = ANY(sharable_groups);`
+		params = append(params, draftSelectedMaintenanceAlert.BatchID)
+	}
+
 	config.Log("SqlStr: %s", nil, sqlStr)
-	_, err = tx.Exec(sqlStr,
-		draftSelectedMaintenanceAlert.IsRead,
-		time.Now(),
-		userID,
-		draftSelectedMaintenanceAlert.ID,
-	)
+	_, err = tx.Exec(sqlStr, params...)
 	if err != nil {
 		tx.Rollback()
 		config.Log("unable to scan selected values", err)
@@ -300,6 +395,21 @@ func UpdateSelectedNotification(user string, userID string, draftSelectedMainten
 
 // UpdateUserProfile ...
 func UpdateUserProfile(user string, userID string, draftProfile model.Profile) (*model.Profile, error) {
+	// the unit system is retained when the client does not send it
+	if len(draftProfile.UnitSystem) > 0 {
+		if err := ValidateUnitSystem(draftProfile.UnitSystem); err != nil {
+			config.Log("unable to validate unit system", err)
+			return nil, err
+		}
+	}
+
+	// the base currency is retained when the client does not send it
+	baseCurrency, err := normalizeCurrency(draftProfile.BaseCurrency)
+	if err != nil {
+		config.Log("unable to validate base currency", err)
+		return nil, err
+	}
+
 	db, err := SetupDB(user)
 	if err != nil {
 		config.Log("unable to setup db", err)
@@ -322,9 +432,11 @@ func UpdateUserProfile(user string, userID string, draftProfile model.Profile) (
 		onlinestatus=$7,
 		appearance=$8,
 		grid_view=$9,
-		updated_at=$10
+		updated_at=$10,
+		unit_system=COALESCE(NULLIF($11, ''), unit_system),
+		base_currency=COALESCE(NULLIF($12, ''), base_currency)
 		WHERE id=$1
-		RETURNING id, username, full_name, avatar_url, email_address, phone_number, about_me, onlinestatus, appearance, grid_view, updated_at;`
+		RETURNING id, username, full_name, avatar_url, email_address, phone_number, about_me, onlinestatus, appearance, grid_view, unit_system, base_currency, updated_at;`
 
 	var updatedProfile model.Profile
 	var avatarUrl sql.NullString // Assuming avatar_url is a string column, not bytea
@@ -341,6 +453,8 @@ func UpdateUserProfile(user string, userID string, draftProfile model.Profile) (
 		draftProfile.Appearance,
 		draftProfile.GridView,
 		time.Now(),
+		draftProfile.UnitSystem,
+		baseCurrency,
 	)
 
 	err = row.Scan(
@@ -354,6 +468,8 @@ func UpdateUserProfile(user string, userID string, draftProfile model.Profile) (
 		&updatedProfile.OnlineStatus,
 		&updatedProfile.Appearance,
 		&updatedProfile.GridView,
+		&updatedProfile.UnitSystem,
+		&updatedProfile.BaseCurrency,
 		&updatedProfile.UpdatedAt,
 	)
 
@@ -411,6 +527,7 @@ func getFavouriteItems(db *sql.DB, userID string, limit int) ([]model.FavouriteI
 		LEFT JOIN community.statuses s ON s.id = c.status 
 		LEFT JOIN community.maintenance_plan mp ON mp.id = fi.maintenance_plan_id
 		LEFT JOIN community.statuses ms ON ms.id = mp.status WHERE fi.created_by = $1 
+		AND c.deleted_at IS NULL AND mp.deleted_at IS NULL
 	FETCH FIRST $2 rows only;`
 
 	config.Log("SqlStr: %s", nil, sqlStr)
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/earmuff-jam/fleetwise/config"
@@ -11,6 +12,10 @@ import (
 )
 
 // RetrieveReports ...
+//
+// RetrieveReports returns the valuation of the assets in the base currency of the user. Each price is converted with
+// the exchange rate on its purchase date. Assets without a matching exchange rate are left out of the totals and
+// their currencies are listed as unconverted.
 func RetrieveReports(user string, userID uuid.UUID, sinceDateTime string, includeOverdueAssets string) ([]model.Report, error) {
 
 	db, err := SetupDB(user)
@@ -26,29 +31,39 @@ func RetrieveReports(user string, userID uuid.UUID, sinceDateTime string, includ
 		additionalWhereClause = "OR inv.return_datetime >= $2::TIMESTAMP WITH TIME ZONE"
 	}
 
-	draftSqlStr := `
+	filteredInventorySqlStr := `
 	WITH filtered_inventory AS (
 			SELECT 
 			inv.id,
-			inv.price
+			inv.price,
+			inv.currency,
+			%s AS exchange_rate,
+			inv.purchase_date,
+			inv.useful_life_years,
+			inv.salvage_value,
+			inv.depreciation_method
 			FROM community.inventory inv
 			WHERE 
 				(inv.updated_at >= $2::TIMESTAMP WITH TIME ZONE %s)
 				AND $1::UUID = ANY(inv.sharable_groups)
-		)
+				AND inv.deleted_at IS NULL
+		)`
+	filteredInventorySqlStr = fmt.Sprintf(filteredInventorySqlStr, exchangeRateSqlStr("inv", "$1"), additionalWhereClause)
+
+	draftSqlStr := filteredInventorySqlStr + `
 	SELECT 
-		(SELECT SUM(price) FROM filtered_inventory) AS total_cost,
-		(SELECT SUM(inv.price) 
+		(SELECT SUM(price * exchange_rate) FROM filtered_inventory) AS total_cost,
+		(SELECT SUM(inv.price * inv.exchange_rate) 
 			FROM filtered_inventory inv 
 			LEFT JOIN ( SELECT DISTINCT item_id FROM community.category_item ) ci ON ci.item_id = inv.id 
 			WHERE ci.item_id IS NOT NULL
-		) AS total_category_items_cost;`
+		) AS total_category_items_cost,
+		COALESCE((SELECT p.base_currency FROM community.profiles p WHERE p.id = $1::UUID), '') AS currency;`
 
-	parsedSqlStr := fmt.Sprintf(draftSqlStr, additionalWhereClause)
 	var reports []model.Report
 
-	config.Log("SqlStr: %s", nil, parsedSqlStr)
-	rows, err := db.Query(parsedSqlStr, userID, sinceDateTime)
+	config.Log("SqlStr: %s", nil, draftSqlStr)
+	rows, err := db.Query(draftSqlStr, userID, sinceDateTime)
 	if err != nil {
 		config.Log("unable to retrieve report details", err)
 		return nil, err
@@ -60,15 +75,21 @@ func RetrieveReports(user string, userID uuid.UUID, sinceDateTime string, includ
 		var totalValuationDraft sql.NullFloat64
 		var totalCategoryItemsCostDraft sql.NullFloat64
 
-		if err := rows.Scan(&totalValuationDraft, &totalCategoryItemsCostDraft); err != nil {
+		if err := rows.Scan(&totalValuationDraft, &totalCategoryItemsCostDraft, &draftReport.Currency); err != nil {
 			config.Log("unable to scan reports", err)
 			return nil, err
 		}
 		if totalValuationDraft.Valid {
-			draftReport.ItemValuation = totalValuationDraft.Float64
+			draftReport.ItemValuation = roundCurrency(totalValuationDraft.Float64)
 		}
 		if totalCategoryItemsCostDraft.Valid {
-			draftReport.TotalCategoryItemsCost = totalCategoryItemsCostDraft.Float64
+			draftReport.TotalCategoryItemsCost = roundCurrency(totalCategoryItemsCostDraft.Float64)
+		}
+
+		draftReport.TotalBookValue, draftReport.CategoryItemsBookValue, draftReport.UnconvertedCurrencies, err = retrieveReportBookValues(db, filteredInventorySqlStr, userID, sinceDateTime)
+		if err != nil {
+			config.Log("unable to retrieve book value of reports", err)
+			return nil, err
 		}
 
 		parsedTime, _ := time.Parse(time.RFC3339, sinceDateTime)
@@ -82,3 +103,78 @@ func RetrieveReports(user string, userID uuid.UUID, sinceDateTime string, includ
 	}
 	return reports, nil
 }
+
+// retrieveReportBookValues ...
+//
+// retrieveReportBookValues returns the current book value of the filtered inventories and of the filtered inventories
+// that belong to a category. Book value is derived from the depreciation schedule of each asset and is converted into
+// the base currency of the user. The currencies of the inventories that cannot be converted are returned in order.
+func retrieveReportBookValues(db *sql.DB, filteredInventorySqlStr string, userID uuid.UUID, sinceDateTime string) (float64, float64, []string, error) {
+	sqlStr := filteredInventorySqlStr + `
+	SELECT 
+		inv.id,
+		inv.price,
+		inv.currency,
+		inv.exchange_rate,
+		inv.purchase_date,
+		inv.useful_life_years,
+		inv.salvage_value,
+		inv.depreciation_method,
+		EXISTS (SELECT 1 FROM community.category_item ci WHERE ci.item_id = inv.id) AS is_category_item
+	FROM filtered_inventory inv;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, sinceDateTime)
+	if err != nil {
+		config.Log("unable to retrieve book value details", err)
+		return 0, 0, nil, err
+	}
+	defer rows.Close()
+
+	var totalBookValue, categoryItemsBookValue float64
+	unconvertedCurrencies := make([]string, 0)
+	for rows.Next() {
+		var inventory model.Inventory
+		var exchangeRate sql.NullFloat64
+		var isCategoryItem bool
+		var purchaseDate sql.NullTime
+		var usefulLifeYears sql.NullInt64
+		var depreciationMethod sql.NullString
+
+		if err := rows.Scan(
+			&inventory.ID,
+			&inventory.Price,
+			&inventory.Currency,
+			&exchangeRate,
+			&purchaseDate,
+			&usefulLifeYears,
+			&inventory.SalvageValue,
+			&depreciationMethod,
+			&isCategoryItem,
+		); err != nil {
+			config.Log("unable to scan book value details", err)
+			return 0, 0, nil, err
+		}
+
+		if !exchangeRate.Valid {
+			if !slices.Contains(unconvertedCurrencies, inventory.Currency) {
+				unconvertedCurrencies = append(unconvertedCurrencies, inventory.Currency)
+			}
+			continue
+		}
+
+		applyDepreciation(&inventory, purchaseDate, usefulLifeYears, depreciationMethod)
+		bookValue := inventory.BookValue * exchangeRate.Float64
+		totalBookValue += bookValue
+		if isCategoryItem {
+			categoryItemsBookValue += bookValue
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to process book value details", err)
+		return 0, 0, nil, err
+	}
+	slices.Sort(unconvertedCurrencies)
+	return roundCurrency(totalBookValue), roundCurrency(categoryItemsBookValue), unconvertedCurrencies, nil
+}
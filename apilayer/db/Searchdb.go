@@ -0,0 +1,107 @@
+package db
+
+import (
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+// SearchTypes ...
+//
+// list of entities that can be searched against
+var SearchTypes = []string{"inventory", "note", "category", "maintenance_plan"}
+
+// Search ...
+func Search(user string, userID string, searchText string, searchTypes []string, limit int) ([]model.SearchResult, error) {
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	if len(searchTypes) == 0 {
+		searchTypes = SearchTypes
+	}
+
+	sqlStr := `WITH search_query AS (
+		SELECT websearch_to_tsquery('english', $2) || websearch_to_tsquery('simple', $2) AS q
+	)
+	SELECT id, type, title, snippet, rank, updated_at FROM (
+		SELECT
+			inv.id,
+			'inventory' AS type,
+			inv.name AS title,
+			ts_headline('english', COALESCE(inv.description, ''), sq.q) AS snippet,
+			ts_rank(inv.search_vector, sq.q) AS rank,
+			inv.updated_at
+		FROM community.inventory inv, search_query sq
+		WHERE 'inventory' = ANY($3) AND inv.search_vector @@ sq.q AND inv.sharable_groups @> ARRAY[$1::UUID] AND inv.deleted_at IS NULL
+		UNION ALL
+		SELECT
+			n.id,
+			'note' AS type,
+			n.title,
+			ts_headline('english', COALESCE(n.description, ''), sq.q) AS snippet,
+			ts_rank(n.search_vector, sq.q) AS rank,
+			n.updated_at
+		FROM community.notes n, search_query sq
+		WHERE 'note' = ANY($3) AND n.search_vector @@ sq.q AND n.sharable_groups @> ARRAY[$1::UUID] AND n.deleted_at IS NULL
+		UNION ALL
+		SELECT
+			c.id,
+			'category' AS type,
+			c.name AS title,
+			ts_headline('english', COALESCE(c.description, ''), sq.q) AS snippet,
+			ts_rank(c.search_vector, sq.q) AS rank,
+			c.updated_at
+		FROM community.category c, search_query sq
+		WHERE 'category' = ANY($3) AND c.search_vector @@ sq.q AND c.sharable_groups @> ARRAY[$1::UUID] AND c.deleted_at IS NULL
+		UNION ALL
+		SELECT
+			mp.id,
+			'maintenance_plan' AS type,
+			mp.name AS title,
+			ts_headline('english', COALESCE(mp.description, ''), sq.q) AS snippet,
+			ts_rank(mp.search_vector, sq.q) AS rank,
+			mp.updated_at
+		FROM community.maintenance_plan mp, search_query sq
+		WHERE 'maintenance_plan' = ANY($3) AND mp.search_vector @@ sq.q AND mp.sharable_groups @> ARRAY[$1::UUID] AND mp.deleted_at IS NULL
+	) results
+	ORDER BY rank DESC, updated_at DESC
+	LIMIT $4;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, searchText, pq.Array(searchTypes), limit)
+	if err != nil {
+		config.Log("unable to query selected search text", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.SearchResult, 0)
+
+	for rows.Next() {
+		var searchResult model.SearchResult
+		if err := rows.Scan(
+			&searchResult.ID,
+			&searchResult.Type,
+			&searchResult.Title,
+			&searchResult.Snippet,
+			&searchResult.Rank,
+			&searchResult.UpdatedAt,
+		); err != nil {
+			config.Log("unable to scan search results", err)
+			return nil, err
+		}
+		data = append(data, searchResult)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to process search results", err)
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	StockMovementTypeConsume    = "consume"
+	StockMovementTypeRestock    = "restock"
+	StockMovementTypeAdjustment = "adjustment"
+	StockMovementTypeTransfer   = "transfer"
+
+	InvalidStockMovement = "invalid stock movement"
+	InsufficientStock    = "unable to consume more than the available quantity"
+)
+
+// RetrieveStockMovements ...
+func RetrieveStockMovements(user string, userID string, invID string) ([]model.StockMovement, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveStockMovements(tx, "", userID, invID)
+	if err != nil {
+		config.Log("unable to retrieve stock movements for selected asset", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// AddStockMovement ...
+//
+// AddStockMovement records a single movement in the ledger of the selected inventory. The quantity
+// of the inventory is derived from the ledger by the database trigger once the movement is added.
+// Serialized inventories derive their quantity from their units, so only transfers are recorded for them.
+func AddStockMovement(user string, userID string, invID string, draftStockMovement model.StockMovementRequest) (*model.StockMovement, error) {
+	quantityChange, err := deriveQuantityChange(draftStockMovement)
+	if err != nil {
+		config.Log("unable to derive quantity change", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		config.Log("unable to parse user id", err)
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	// lock the selected inventory so that concurrent movements are applied one after the other
+	sqlStr := `SELECT COALESCE(inv.quantity, 0), inv.storage_location_id, inv.sharable_groups
+		FROM community.inventory inv
+		WHERE inv.id = $1
+		AND $2::UUID = ANY(inv.sharable_groups)
+		AND inv.deleted_at IS NULL
+		FOR UPDATE;`
+
+	var currentQuantity int
+	var storageLocationID sql.NullString
+	var sharableGroups pq.StringArray
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, invID, parsedUserID).Scan(&currentQuantity, &storageLocationID, &sharableGroups)
+	if err != nil {
+		config.Log("unable to retrieve selected asset", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if quantityChange != 0 {
+		isSerialized, err := isSerializedInventory(tx, invID)
+		if err != nil {
+			config.Log("unable to retrieve units of selected asset", err)
+			tx.Rollback()
+			return nil, err
+		}
+		if isSerialized {
+			config.Log("unable to add stock movement", errors.New(SerializedInventoryQuantity))
+			tx.Rollback()
+			return nil, errors.New(SerializedInventoryQuantity)
+		}
+	}
+
+	if currentQuantity+quantityChange < 0 {
+		config.Log("unable to add stock movement", errors.New(InsufficientStock))
+		tx.Rollback()
+		return nil, errors.New(InsufficientStock)
+	}
+
+	fromStorageLocationID := sql.NullString{}
+	toStorageLocationID := sql.NullString{}
+	if draftStockMovement.MovementType == StockMovementTypeTransfer {
+		fromStorageLocationID = storageLocationID
+		toStorageLocationID = sql.NullString{String: draftStockMovement.StorageLocationID, Valid: true}
+	}
+
+	sqlStr = `INSERT INTO community.stock_movements (item_id, movement_type, quantity_change, reason, from_storage_location_id, to_storage_location_id, created_at, created_by, sharable_groups)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;`
+
+	var stockMovementID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(
+		sqlStr,
+		invID,
+		draftStockMovement.MovementType,
+		quantityChange,
+		draftStockMovement.Reason,
+		fromStorageLocationID,
+		toStorageLocationID,
+		time.Now(),
+		parsedUserID,
+		sharableGroups,
+	).Scan(&stockMovementID)
+	if err != nil {
+		config.Log("unable to add stock movement", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveStockMovements(tx, " AND sm.id = $3", userID, invID, stockMovementID)
+	if err == nil && len(data) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		config.Log("unable to retrieve selected stock movement", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+
+	return &data[0], nil
+}
+
+// deriveQuantityChange ...
+//
+// deriveQuantityChange returns the signed quantity change of the selected movement. Consume and restock
+// expect a positive quantity, adjustments can be signed and transfers do not change the quantity.
+func deriveQuantityChange(draftStockMovement model.StockMovementRequest) (int, error) {
+	switch draftStockMovement.MovementType {
+	case StockMovementTypeConsume:
+		if draftStockMovement.Quantity <= 0 {
+			return 0, errors.New(InvalidStockMovement)
+		}
+		return -draftStockMovement.Quantity, nil
+	case StockMovementTypeRestock:
+		if draftStockMovement.Quantity <= 0 {
+			return 0, errors.New(InvalidStockMovement)
+		}
+		return draftStockMovement.Quantity, nil
+	case StockMovementTypeAdjustment:
+		if draftStockMovement.Quantity == 0 {
+			return 0, errors.New(InvalidStockMovement)
+		}
+		return draftStockMovement.Quantity, nil
+	case StockMovementTypeTransfer:
+		if _, err := uuid.Parse(draftStockMovement.StorageLocationID); err != nil {
+			return 0, errors.New(InvalidStockMovement)
+		}
+		return 0, nil
+	}
+	return 0, errors.New(InvalidStockMovement)
+}
+
+// retrieveStockMovements ...
+//
+// retrieveStockMovements returns the ledger of the selected asset with the most recent movement first.
+// userID is always $1 and invID is always $2.
+func retrieveStockMovements(tx *sql.Tx, additionalWhereClause string, params ...interface{}) ([]model.StockMovement, error) {
+	sqlStr := `SELECT
+		sm.id,
+		sm.item_id,
+		sm.movement_type,
+		sm.quantity_change,
+		COALESCE(sm.reason, ''),
+		sm.from_storage_location_id,
+		sm.to_storage_location_id,
+		sm.created_at,
+		COALESCE(sm.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator,
+		sm.sharable_groups
+	FROM community.stock_movements sm
+	LEFT JOIN community.profiles cp ON cp.id = sm.created_by
+	WHERE $1::UUID = ANY(sm.sharable_groups)
+	AND sm.item_id = $2` + additionalWhereClause + `
+	ORDER BY sm.created_at DESC, sm.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []model.StockMovement
+	for rows.Next() {
+		var stockMovement model.StockMovement
+		var fromStorageLocationID, toStorageLocationID sql.NullString
+
+		if err := rows.Scan(
+			&stockMovement.ID,
+			&stockMovement.ItemID,
+			&stockMovement.MovementType,
+			&stockMovement.QuantityChange,
+			&stockMovement.Reason,
+			&fromStorageLocationID,
+			&toStorageLocationID,
+			&stockMovement.CreatedAt,
+			&stockMovement.CreatedBy,
+			&stockMovement.Creator,
+			pq.Array(&stockMovement.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+
+		if fromStorageLocationID.Valid {
+			stockMovement.FromStorageLocationID = fromStorageLocationID.String
+		}
+		if toStorageLocationID.Valid {
+			stockMovement.ToStorageLocationID = toStorageLocationID.String
+		}
+
+		data = append(data, stockMovement)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -142,3 +142,31 @@ func addNewStorageLocation(user string, draftLocation string, created_by string,
 
 	return nil
 }
+
+// retrieveOrAddStorageLocationInTx ...
+//
+// returns the id of the selected storage location within the selected transaction. Adds the storage location if
+// it does not already exist.
+func retrieveOrAddStorageLocationInTx(tx *sql.Tx, draftLocation string, userID string) (string, error) {
+	sqlStr := `INSERT INTO community.storage_locations(location, created_by, updated_by, created_at, updated_at, sharable_groups)
+		VALUES ($1, $2, $2, $3, $3, ARRAY[$2::UUID])
+		ON CONFLICT (location) DO NOTHING;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err := tx.Exec(sqlStr, draftLocation, userID, time.Now())
+	if err != nil {
+		config.Log("unable to add selected storage location", err)
+		return "", err
+	}
+
+	var locationID string
+	fetchSqlStr := `SELECT sl.id FROM community.storage_locations sl WHERE sl.location = $1;`
+
+	config.Log("SqlStr: %s", nil, fetchSqlStr)
+	err = tx.QueryRow(fetchSqlStr, draftLocation).Scan(&locationID)
+	if err != nil {
+		config.Log("unable to retrieve selected storage location", err)
+		return "", err
+	}
+	return locationID, nil
+}
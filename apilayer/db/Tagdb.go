@@ -0,0 +1,585 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	InvalidTag           = "invalid tag"
+	DuplicateTag         = "tag with the same name already exists"
+	InvalidTagEntityType = "invalid tag entity type"
+
+	TagEntityTypeInventory       = "inventory"
+	TagEntityTypeNote            = "note"
+	TagEntityTypeMaintenancePlan = "maintenance_plan"
+
+	// DefaultTagSuggestionLimit is the number of suggestions returned when the limit is not selected
+	DefaultTagSuggestionLimit = 10
+	// MaxTagSuggestionLimit is the maximum number of suggestions returned
+	MaxTagSuggestionLimit = 50
+
+	maxTagNameLength = 50
+)
+
+// tagColorRegex matches the hex color of the tag, eg #fff or #ffcc00
+var tagColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// tagEntity ...
+//
+// tagEntity is the table that can be tagged along with the column of tag_items that references it
+type tagEntity struct {
+	tableName  string
+	columnName string
+}
+
+// tagEntities are the tables that can be tagged keyed by the entity type
+var tagEntities = map[string]tagEntity{
+	TagEntityTypeInventory:       {tableName: "community.inventory", columnName: "item_id"},
+	TagEntityTypeNote:            {tableName: "community.notes", columnName: "note_id"},
+	TagEntityTypeMaintenancePlan: {tableName: "community.maintenance_plan", columnName: "maintenance_plan_id"},
+}
+
+// RetrieveTags ...
+func RetrieveTags(user string, userID string) ([]model.Tag, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data, err := retrieveTags(tx, "", "ORDER BY LOWER(t.name)", userID)
+	if err != nil {
+		config.Log("unable to retrieve tags", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RetrieveTagSuggestions ...
+//
+// RetrieveTagSuggestions returns the tags that contain the selected text. Tags that start with the selected text
+// are returned first, followed by the tags that are used the most.
+func RetrieveTagSuggestions(user string, userID string, searchText string, limit int) ([]model.Tag, error) {
+	if limit <= 0 {
+		limit = DefaultTagSuggestionLimit
+	}
+	if limit > MaxTagSuggestionLimit {
+		limit = MaxTagSuggestionLimit
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderBySqlStr := `ORDER BY
+		starts_with(LOWER(t.name), LOWER($2)) DESC,
+		(SELECT COUNT(*) FROM community.tag_items ti WHERE ti.tag_id = t.id) DESC,
+		LOWER(t.name)
+		LIMIT $3`
+
+	data, err := retrieveTags(tx, " AND strpos(LOWER(t.name), LOWER($2)) > 0", orderBySqlStr, userID, strings.TrimSpace(searchText), limit)
+	if err != nil {
+		config.Log("unable to retrieve tag suggestions", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// RetrieveTagCounts ...
+//
+// RetrieveTagCounts returns the number of assets, notes and maintenance plans that hold each tag. Only the rows
+// that are visible to the user and are not in the trash are counted.
+func RetrieveTagCounts(user string, userID string) ([]model.TagCount, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT
+			t.id,
+			t.name,
+			COALESCE(t.color, ''),
+			COUNT(inv.id) AS inventory_count,
+			COUNT(n.id) AS note_count,
+			COUNT(mp.id) AS maintenance_plan_count
+		FROM community.tags t
+		LEFT JOIN community.tag_items ti ON ti.tag_id = t.id
+		LEFT JOIN community.inventory inv ON inv.id = ti.item_id AND $1::UUID = ANY(inv.sharable_groups) AND inv.deleted_at IS NULL
+		LEFT JOIN community.notes n ON n.id = ti.note_id AND $1::UUID = ANY(n.sharable_groups) AND n.deleted_at IS NULL
+		LEFT JOIN community.maintenance_plan mp ON mp.id = ti.maintenance_plan_id AND $1::UUID = ANY(mp.sharable_groups) AND mp.deleted_at IS NULL
+		WHERE $1::UUID = ANY(t.sharable_groups)
+		GROUP BY t.id, t.name, t.color
+		ORDER BY COUNT(inv.id) + COUNT(n.id) + COUNT(mp.id) DESC, LOWER(t.name);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID)
+	if err != nil {
+		config.Log("unable to query selected tag counts", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.TagCount, 0)
+	for rows.Next() {
+		var tagCount model.TagCount
+		if err := rows.Scan(
+			&tagCount.ID,
+			&tagCount.Name,
+			&tagCount.Color,
+			&tagCount.InventoryCount,
+			&tagCount.NoteCount,
+			&tagCount.MaintenancePlanCount,
+		); err != nil {
+			config.Log("unable to scan selected tag counts", err)
+			return nil, err
+		}
+		tagCount.TotalCount = tagCount.InventoryCount + tagCount.NoteCount + tagCount.MaintenancePlanCount
+		data = append(data, tagCount)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// AddTag ...
+func AddTag(user string, userID string, draftTag model.Tag) (*model.Tag, error) {
+	if err := ValidateTag(draftTag); err != nil {
+		config.Log("unable to validate tag", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `INSERT INTO community.tags (name, color, created_at, created_by, updated_at, updated_by, sharable_groups)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $3, $4, $5)
+		RETURNING id;`
+
+	var tagID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, strings.TrimSpace(draftTag.Name), draftTag.Color, time.Now(), userID, pq.Array([]string{userID})).Scan(&tagID)
+	if err != nil {
+		config.Log("unable to add selected tag", err)
+		tx.Rollback()
+		return nil, toDuplicateTagError(err)
+	}
+
+	data, err := retrieveTags(tx, " AND t.id = $2", "", userID, tagID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected tag", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// UpdateTag ...
+func UpdateTag(user string, userID string, tagID string, draftTag model.Tag) (*model.Tag, error) {
+	if err := ValidateTag(draftTag); err != nil {
+		config.Log("unable to validate tag", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	sqlStr := `UPDATE community.tags t
+		SET name = $3,
+			color = NULLIF($4, ''),
+			updated_at = $5,
+			updated_by = $2
+		WHERE t.id = $1
+		AND $2::UUID = ANY(t.sharable_groups)
+		RETURNING t.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, tagID, userID, strings.TrimSpace(draftTag.Name), draftTag.Color, time.Now()).Scan(&tagID)
+	if err != nil {
+		config.Log("unable to update selected tag", err)
+		tx.Rollback()
+		return nil, toDuplicateTagError(err)
+	}
+
+	data, err := retrieveTags(tx, " AND t.id = $2", "", userID, tagID)
+	if err != nil || len(data) == 0 {
+		config.Log("unable to retrieve selected tag", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return &data[0], nil
+}
+
+// RemoveTag ...
+//
+// RemoveTag removes the selected tag from every asset, note and maintenance plan that holds it
+func RemoveTag(user string, userID string, tagID string) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `DELETE FROM community.tags t WHERE t.id = $1 AND $2::UUID = ANY(t.sharable_groups);`
+	config.Log("SqlStr: %s", nil, sqlStr)
+	result, err := db.Exec(sqlStr, tagID, userID)
+	if err != nil {
+		config.Log("unable to remove selected tag", err)
+		return err
+	}
+
+	removedRows, err := result.RowsAffected()
+	if err != nil {
+		config.Log("unable to retrieve removed tags", err)
+		return err
+	}
+	if removedRows == 0 {
+		config.Log("unable to find selected tag", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RetrieveEntityTags ...
+//
+// RetrieveEntityTags returns the tags of the selected asset, note or maintenance plan
+func RetrieveEntityTags(user string, userID string, entityType string, entityID string) ([]model.Tag, error) {
+	entity, ok := tagEntities[entityType]
+	if !ok {
+		config.Log("unable to retrieve tags of selected entity", errors.New(InvalidTagEntityType))
+		return nil, errors.New(InvalidTagEntityType)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTagEntity(tx, userID, entity, entityID, false); err != nil {
+		config.Log("unable to find selected entity", err)
+		return nil, err
+	}
+
+	data, err := retrieveEntityTags(tx, userID, entity, entityID)
+	if err != nil {
+		config.Log("unable to retrieve tags of selected entity", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// UpdateEntityTags ...
+//
+// UpdateEntityTags replaces the tags of the selected asset, note or maintenance plan with the selected tags.
+// Every selected tag must be visible to the user.
+func UpdateEntityTags(user string, userID string, entityType string, entityID string, tagIDs []string) ([]model.Tag, error) {
+	entity, ok := tagEntities[entityType]
+	if !ok {
+		config.Log("unable to update tags of selected entity", errors.New(InvalidTagEntityType))
+		return nil, errors.New(InvalidTagEntityType)
+	}
+
+	tagIDs, err := uniqueTagIDs(tagIDs)
+	if err != nil {
+		config.Log("unable to validate selected tags", err)
+		return nil, err
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		config.Log("unable to start transaction with selected db pool", err)
+		return nil, err
+	}
+
+	if err := lockTagEntity(tx, userID, entity, entityID, true); err != nil {
+		config.Log("unable to find selected entity", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr := `SELECT COUNT(*) FROM community.tags t WHERE t.id = ANY($1::UUID[]) AND $2::UUID = ANY(t.sharable_groups);`
+	var visibleTags int
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = tx.QueryRow(sqlStr, pq.Array(tagIDs), userID).Scan(&visibleTags)
+	if err != nil {
+		config.Log("unable to retrieve selected tags", err)
+		tx.Rollback()
+		return nil, err
+	}
+	if visibleTags != len(tagIDs) {
+		config.Log("unable to find every selected tag", errors.New(InvalidTag))
+		tx.Rollback()
+		return nil, errors.New(InvalidTag)
+	}
+
+	sqlStr = fmt.Sprintf(`DELETE FROM community.tag_items ti WHERE ti.%s = $1 AND NOT ti.tag_id = ANY($2::UUID[]);`, entity.columnName)
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, entityID, pq.Array(tagIDs))
+	if err != nil {
+		config.Log("unable to remove tags of selected entity", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	sqlStr = fmt.Sprintf(`INSERT INTO community.tag_items (tag_id, %s, created_at, created_by, sharable_groups)
+		SELECT t.id, e.id, $3, $4, e.sharable_groups
+		FROM community.tags t, %s e
+		WHERE t.id = ANY($2::UUID[])
+		AND e.id = $1
+		ON CONFLICT DO NOTHING;`, entity.columnName, entity.tableName)
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = tx.Exec(sqlStr, entityID, pq.Array(tagIDs), time.Now(), userID)
+	if err != nil {
+		config.Log("unable to add tags to selected entity", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	data, err := retrieveEntityTags(tx, userID, entity, entityID)
+	if err != nil {
+		config.Log("unable to retrieve tags of selected entity", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		config.Log("unable to commit transaction", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// ValidateTag ...
+func ValidateTag(draftTag model.Tag) error {
+	name := strings.TrimSpace(draftTag.Name)
+	if len(name) == 0 || utf8.RuneCountInString(name) > maxTagNameLength {
+		return errors.New(InvalidTag)
+	}
+	if len(draftTag.Color) > 0 && !tagColorRegex.MatchString(draftTag.Color) {
+		return errors.New(InvalidTag)
+	}
+	return nil
+}
+
+// buildTagWhereClause ...
+//
+// buildTagWhereClause builds the where clause that matches the rows holding every selected tag. alias is the alias
+// of the tagged table in the query and params are appended in the same order as the placeholders.
+func buildTagWhereClause(entityType string, alias string, tagIDs []string, params *[]interface{}) (string, error) {
+	entity, ok := tagEntities[entityType]
+	if !ok {
+		return "", errors.New(InvalidTagEntityType)
+	}
+
+	tagIDs, err := uniqueTagIDs(tagIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(tagIDs) == 0 {
+		return "", nil
+	}
+
+	*params = append(*params, pq.Array(tagIDs))
+	return fmt.Sprintf(" AND (SELECT COUNT(DISTINCT ti.tag_id) FROM community.tag_items ti WHERE ti.%s = %s.id AND ti.tag_id = ANY($%d::UUID[])) = %d",
+		entity.columnName, alias, len(*params), len(tagIDs)), nil
+}
+
+// lockTagEntity ...
+//
+// lockTagEntity ensures that the selected asset, note or maintenance plan is visible to the user and is not in the trash.
+// The row is locked when the tags of the row are about to change.
+func lockTagEntity(tx *sql.Tx, userID string, entity tagEntity, entityID string, isLocked bool) error {
+	sqlStr := fmt.Sprintf(`SELECT e.id FROM %s e WHERE e.id = $1 AND $2::UUID = ANY(e.sharable_groups) AND e.deleted_at IS NULL`, entity.tableName)
+	if isLocked {
+		sqlStr += " FOR UPDATE"
+	}
+	sqlStr += ";"
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	return tx.QueryRow(sqlStr, entityID, userID).Scan(&entityID)
+}
+
+// retrieveEntityTags ...
+//
+// retrieveEntityTags returns the tags of the selected row that are visible to the user
+func retrieveEntityTags(tx *sql.Tx, userID string, entity tagEntity, entityID string) ([]model.Tag, error) {
+	additionalWhereClause := fmt.Sprintf(" AND EXISTS (SELECT 1 FROM community.tag_items ti WHERE ti.tag_id = t.id AND ti.%s = $2)", entity.columnName)
+	return retrieveTags(tx, additionalWhereClause, "ORDER BY LOWER(t.name)", userID, entityID)
+}
+
+// retrieveTags ...
+//
+// retrieveTags returns the tags visible to the user that match the additional where clause. userID is always $1.
+func retrieveTags(tx *sql.Tx, additionalWhereClause string, orderBySqlStr string, params ...interface{}) ([]model.Tag, error) {
+	sqlStr := `SELECT
+			t.id,
+			t.name,
+			COALESCE(t.color, ''),
+			t.created_at,
+			COALESCE(t.created_by::TEXT, ''),
+			t.updated_at,
+			COALESCE(t.updated_by::TEXT, ''),
+			t.sharable_groups
+		FROM community.tags t
+		WHERE $1::UUID = ANY(t.sharable_groups)` + additionalWhereClause + `
+		` + orderBySqlStr + `;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := tx.Query(sqlStr, params...)
+	if err != nil {
+		config.Log("unable to query selected tags", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := make([]model.Tag, 0)
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(
+			&tag.ID,
+			&tag.Name,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.CreatedBy,
+			&tag.UpdatedAt,
+			&tag.UpdatedBy,
+			pq.Array(&tag.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected tags", err)
+			return nil, err
+		}
+		data = append(data, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// uniqueTagIDs ...
+//
+// uniqueTagIDs validates the selected tag ids and removes the duplicates
+func uniqueTagIDs(tagIDs []string) ([]string, error) {
+	selectedTagIDs := make(map[string]bool)
+	data := make([]string, 0, len(tagIDs))
+	for _, v := range tagIDs {
+		if _, err := uuid.Parse(v); err != nil {
+			return nil, errors.New(InvalidTag)
+		}
+		if selectedTagIDs[v] {
+			continue
+		}
+		selectedTagIDs[v] = true
+		data = append(data, v)
+	}
+	return data, nil
+}
+
+// toDuplicateTagError ...
+//
+// toDuplicateTagError replaces unique constraint violations with a readable error. Tag names are unique for each owner.
+func toDuplicateTagError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationErrorCode {
+		return errors.New(DuplicateTag)
+	}
+	return err
+}
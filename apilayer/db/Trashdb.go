@@ -0,0 +1,214 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/lib/pq"
+)
+
+const (
+	TrashItemTypeInventory       = "inventory"
+	TrashItemTypeNote            = "note"
+	TrashItemTypeCategory        = "category"
+	TrashItemTypeMaintenancePlan = "maintenance_plan"
+
+	InvalidTrashItemType = "invalid trash item type"
+)
+
+// trashItemTables ...
+//
+// list of tables that support soft deletion keyed by the type of the trash item
+var trashItemTables = map[string]string{
+	TrashItemTypeInventory:       "community.inventory",
+	TrashItemTypeNote:            "community.notes",
+	TrashItemTypeCategory:        "community.category",
+	TrashItemTypeMaintenancePlan: "community.maintenance_plan",
+}
+
+// RetrieveTrash ...
+//
+// RetrieveTrash returns every deleted inventory, note, category and maintenance plan that is shared with the user.
+// Most recently deleted items are returned first.
+func RetrieveTrash(user string, userID string) ([]model.TrashItem, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT t.id, t.type, t.title, t.description, t.deleted_at, COALESCE(t.created_by::TEXT, ''),
+		COALESCE(cp.username, cp.full_name, cp.email_address, '') AS creator, t.sharable_groups
+	FROM (
+		SELECT inv.id, $2::TEXT AS type, inv.name AS title, COALESCE(inv.description, '') AS description, inv.deleted_at, inv.created_by, inv.sharable_groups
+		FROM community.inventory inv
+		WHERE inv.deleted_at IS NOT NULL AND $1::UUID = ANY(inv.sharable_groups)
+		UNION ALL
+		SELECT n.id, $3::TEXT AS type, n.title, COALESCE(n.description, '') AS description, n.deleted_at, n.created_by, n.sharable_groups
+		FROM community.notes n
+		WHERE n.deleted_at IS NOT NULL AND $1::UUID = ANY(n.sharable_groups)
+		UNION ALL
+		SELECT c.id, $4::TEXT AS type, c.name AS title, COALESCE(c.description, '') AS description, c.deleted_at, c.created_by, c.sharable_groups
+		FROM community.category c
+		WHERE c.deleted_at IS NOT NULL AND $1::UUID = ANY(c.sharable_groups)
+		UNION ALL
+		SELECT mp.id, $5::TEXT AS type, mp.name AS title, COALESCE(mp.description, '') AS description, mp.deleted_at, mp.created_by, mp.sharable_groups
+		FROM community.maintenance_plan mp
+		WHERE mp.deleted_at IS NOT NULL AND $1::UUID = ANY(mp.sharable_groups)
+	) t
+	LEFT JOIN community.profiles cp ON cp.id = t.created_by
+	ORDER BY t.deleted_at DESC, t.id;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr, userID, TrashItemTypeInventory, TrashItemTypeNote, TrashItemTypeCategory, TrashItemTypeMaintenancePlan)
+	if err != nil {
+		config.Log("unable to query selected details", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []model.TrashItem{}
+	for rows.Next() {
+		var trashItem model.TrashItem
+		if err := rows.Scan(
+			&trashItem.ID,
+			&trashItem.Type,
+			&trashItem.Title,
+			&trashItem.Description,
+			&trashItem.DeletedAt,
+			&trashItem.CreatedBy,
+			&trashItem.Creator,
+			pq.Array(&trashItem.SharableGroups),
+		); err != nil {
+			config.Log("unable to scan selected details", err)
+			return nil, err
+		}
+		data = append(data, trashItem)
+	}
+
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// RestoreTrashItem ...
+//
+// RestoreTrashItem moves the selected item out of the trash. Associations with categories and maintenance plans
+// are retained while the item is in the trash and are restored along with it.
+func RestoreTrashItem(user string, userID string, itemType string, itemID string) (string, error) {
+	tableName, ok := trashItemTables[itemType]
+	if !ok {
+		config.Log("unable to restore selected item", errors.New(InvalidTrashItemType))
+		return "", errors.New(InvalidTrashItemType)
+	}
+
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return "", err
+	}
+	defer db.Close()
+
+	sqlStr := fmt.Sprintf(`UPDATE %s t
+		SET deleted_at = NULL
+		WHERE t.id = $1
+		AND $2::UUID = ANY(t.sharable_groups)
+		AND t.deleted_at IS NOT NULL
+		RETURNING t.id;`, tableName)
+
+	var restoredItemID string
+	config.Log("SqlStr: %s", nil, sqlStr)
+	err = db.QueryRow(sqlStr, itemID, userID).Scan(&restoredItemID)
+	if err != nil {
+		config.Log("unable to restore selected item", err)
+		return "", toDuplicateBarcodeOrSKUError(err)
+	}
+
+	return restoredItemID, nil
+}
+
+// PurgeDeletedItems ...
+//
+// PurgeDeletedItems permanently removes the rows that have been in the trash longer than the retention period along
+// with the images and attachments of the purged assets in the bucket
+func PurgeDeletedItems(user string, retentionDays int) error {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT community.purge_deleted_items($1);`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	_, err = db.Exec(sqlStr, retentionDays)
+	if err != nil {
+		config.Log("unable to purge deleted items", err)
+		return err
+	}
+
+	_, err = RemovePurgedBucketObjects(user)
+	return err
+}
+
+// RemovePurgedBucketObjects ...
+//
+// RemovePurgedBucketObjects removes the objects recorded by the purge from the bucket and returns the number of removed
+// objects. Objects that fail to be removed are kept so that the next purge retries them.
+func RemovePurgedBucketObjects(user string) (int, error) {
+	db, err := SetupDB(user)
+	if err != nil {
+		config.Log("unable to setup db", err)
+		return 0, err
+	}
+	defer db.Close()
+
+	sqlStr := `SELECT pbo.object_key FROM community.purged_bucket_objects pbo ORDER BY pbo.created_at;`
+
+	config.Log("SqlStr: %s", nil, sqlStr)
+	rows, err := db.Query(sqlStr)
+	if err != nil {
+		config.Log("unable to retrieve purged bucket objects", err)
+		return 0, err
+	}
+	defer rows.Close()
+
+	var objectKeys []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			config.Log("unable to scan purged bucket object", err)
+			return 0, err
+		}
+		objectKeys = append(objectKeys, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		config.Log("unable to validate selected rows", err)
+		return 0, err
+	}
+
+	removedObjects := 0
+	for _, objectKey := range objectKeys {
+		if err := bucket.RemoveDocumentFromBucket(objectKey); err != nil {
+			config.Log("unable to remove purged bucket object %s", err, objectKey)
+			continue
+		}
+
+		sqlStr = `DELETE FROM community.purged_bucket_objects WHERE object_key = $1;`
+		config.Log("SqlStr: %s", nil, sqlStr)
+		if _, err := db.Exec(sqlStr, objectKey); err != nil {
+			config.Log("unable to remove purged bucket object %s", err, objectKey)
+			return removedObjects, err
+		}
+		removedObjects++
+	}
+	return removedObjects, nil
+}
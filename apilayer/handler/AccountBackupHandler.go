@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxAccountBackupFileSizeInBytes is the max size of the uploaded account backup archive
+const maxAccountBackupFileSizeInBytes = 1 << 30
+
+// ExportAccountBackup ...
+// swagger:route GET /api/v1/profile/{id}/backup Profiles exportAccountBackup
+//
+// # Exports the entire account of the selected user as a zip archive. The archive holds the profile, assets, units,
+// attachments, storage locations, categories and their attributes, maintenance plans, the assets of each category and
+// maintenance plan, favourites, notes and tags as json alongside every document from the bucket. The manifest.json of
+// the archive holds the version of the archive.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: AccountBackup
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func ExportAccountBackup(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to export account backup with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		config.Log("Unable to export account backup with invalid id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	// the archive is written to a temporary file first so that a failed export is returned with the status code
+	archive, err := os.CreateTemp("", "account-backup-*.zip")
+	if err != nil {
+		config.Log("Unable to create temporary file", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	_, err = db.ExportAccountBackup(user, userID, archive)
+	if err != nil {
+		config.Log("Unable to export account backup", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = archive.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		config.Log("Unable to read account backup", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=fleetwise-backup-%s.zip", time.Now().Format("2006-01-02")))
+	rw.WriteHeader(http.StatusOK)
+	io.Copy(rw, archive)
+}
+
+// RestoreAccountBackup ...
+// swagger:route POST /api/v1/profile/{id}/backup/restore Profiles restoreAccountBackup
+//
+// # Restores the uploaded account backup archive into the account of the selected user. Every row is added with a
+// new id and the references between the rows are kept. Storage locations and tags with the same name are reused.
+// Nothing is restored if a single row cannot be restored. Returns the count of restored rows of each table.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: file
+//     in: formData
+//     description: The zip archive exported from an account. Max 1GB.
+//     required: true
+//     type: file
+//
+// Responses:
+// 200: AccountBackup
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func RestoreAccountBackup(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to restore account backup with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, maxAccountBackupFileSizeInBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		config.Log("Unable to retrieve file", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer file.Close()
+
+	resp, err := db.RestoreAccountBackup(user, userID, file, header.Size)
+	if err != nil {
+		config.Log("Unable to restore account backup", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExportAccountBackup(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/backup", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	ExportAccountBackup(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "application/zip", res.Header.Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	assert.Contains(t, files, "profile.json")
+	assert.Contains(t, files, "tables/inventory.json")
+	assert.Contains(t, files, "tables/category_item.json")
+	assert.Contains(t, files, "tables/stock_movements.json")
+	assert.Contains(t, files, "tables/inventory_revisions.json")
+	assert.Contains(t, files, "tables/loan_items.json")
+
+	rc, err := files["manifest.json"].Open()
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	defer rc.Close()
+
+	var manifest model.AccountBackup
+	err = json.NewDecoder(rc).Decode(&manifest)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.AccountBackupVersion, manifest.Version)
+	assert.Equal(t, prevUser.ID.String(), manifest.ProfileID)
+	for _, v := range manifest.Objects {
+		assert.Contains(t, files, "objects/"+v.Key)
+	}
+}
+
+func Test_ExportAccountBackup_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//backup", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	ExportAccountBackup(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_ExportAccountBackup_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/backup", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	ExportAccountBackup(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RestoreAccountBackup_MissingFile(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/backup/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	RestoreAccountBackup(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RestoreAccountBackup_InvalidArchive(t *testing.T) {
+	requestBody, contentType := buildAccountBackupRequestBody(t, []byte("not a zip archive"))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/backup/restore", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	RestoreAccountBackup(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RestoreAccountBackup_UnsupportedVersion(t *testing.T) {
+	var content bytes.Buffer
+	archive := zip.NewWriter(&content)
+	f, err := archive.Create("manifest.json")
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	json.NewEncoder(f).Encode(model.AccountBackup{Version: db.AccountBackupVersion + 1})
+	archive.Close()
+
+	requestBody, contentType := buildAccountBackupRequestBody(t, content.Bytes())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/backup/restore", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	RestoreAccountBackup(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Contains(t, string(data), db.UnsupportedAccountBackupVersion)
+}
+
+// builds the multipart form used to upload the selected account backup archive
+func buildAccountBackupRequestBody(t *testing.T, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "fleetwise-backup.zip")
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
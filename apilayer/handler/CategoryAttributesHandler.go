@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetCategoryAttributes ...
+// swagger:route GET /api/v1/category/{catID}/attributes Categories getCategoryAttributes
+//
+// # Retrieves the custom attributes defined by the selected category sorted by the sort order.
+//
+// Parameters:
+//   - +name: id
+//     in: query
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: catID
+//     in: path
+//     description: The id of the selected category
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []CategoryAttribute
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetCategoryAttributes(rw http.ResponseWriter, r *http.Request, user string) {
+
+	userID := r.URL.Query().Get("id")
+	categoryID := mux.Vars(r)["catID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve category attributes with empty user id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		config.Log("Unable to retrieve category attributes with invalid category id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveCategoryAttributes(user, userID, categoryID)
+	if err != nil {
+		config.Log("Unable to retrieve category attributes", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddCategoryAttribute ...
+// swagger:route POST /api/v1/category/{catID}/attributes Categories addCategoryAttribute
+//
+// # Adds a new custom attribute to the selected category. Supported types are text, number, date, enum and boolean.
+// Enum attributes require at least one option. Names must be lowercase snake case and unique within the category.
+//
+// Parameters:
+//   - +name: id
+//     in: query
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: catID
+//     in: path
+//     description: The id of the selected category
+//     required: true
+//     type: string
+//   - +name: CategoryAttribute
+//     in: body
+//     description: The attribute to add to the selected category
+//     type: CategoryAttribute
+//     required: true
+//
+// Responses:
+// 200: CategoryAttribute
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func AddCategoryAttribute(rw http.ResponseWriter, r *http.Request, user string) {
+
+	userID := r.URL.Query().Get("id")
+	categoryID := mux.Vars(r)["catID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add category attribute with empty user id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		config.Log("Unable to add category attribute with invalid category id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftAttribute model.CategoryAttribute
+	if err := json.NewDecoder(r.Body).Decode(&draftAttribute); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddCategoryAttribute(user, userID, categoryID, draftAttribute)
+	if err != nil {
+		config.Log("Unable to add category attribute", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateCategoryAttribute {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateCategoryAttribute ...
+// swagger:route PUT /api/v1/category/{catID}/attributes/{attributeID} Categories updateCategoryAttribute
+//
+// # Updates the label, type, options, sort order and required rule of the selected attribute. The name of the attribute
+// cannot be changed. Values already stored against the assets are not revalidated.
+//
+// Parameters:
+//   - +name: id
+//     in: query
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: catID
+//     in: path
+//     description: The id of the selected category
+//     required: true
+//     type: string
+//   - +name: attributeID
+//     in: path
+//     description: The id of the selected attribute
+//     required: true
+//     type: string
+//   - +name: CategoryAttribute
+//     in: body
+//     description: The updated attribute
+//     type: CategoryAttribute
+//     required: true
+//
+// Responses:
+// 200: CategoryAttribute
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func UpdateCategoryAttribute(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := r.URL.Query().Get("id")
+	categoryID := vars["catID"]
+	attributeID := vars["attributeID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update category attribute with empty user id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	for _, v := range []string{categoryID, attributeID} {
+		if _, err := uuid.Parse(v); err != nil {
+			config.Log("Unable to update category attribute with invalid id", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+	}
+
+	var draftAttribute model.CategoryAttribute
+	if err := json.NewDecoder(r.Body).Decode(&draftAttribute); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateCategoryAttribute(user, userID, categoryID, attributeID, draftAttribute)
+	if err != nil {
+		config.Log("Unable to update category attribute", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveCategoryAttribute ...
+// swagger:route DELETE /api/v1/category/{catID}/attributes/{attributeID} Categories removeCategoryAttribute
+//
+// # Removes the selected attribute from the category. Values already stored against the assets are retained.
+//
+// Parameters:
+//   - +name: id
+//     in: query
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: catID
+//     in: path
+//     description: The id of the selected category
+//     required: true
+//     type: string
+//   - +name: attributeID
+//     in: path
+//     description: The id of the selected attribute
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveCategoryAttribute(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := r.URL.Query().Get("id")
+	categoryID := vars["catID"]
+	attributeID := vars["attributeID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove category attribute with empty user id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	for _, v := range []string{categoryID, attributeID} {
+		if _, err := uuid.Parse(v); err != nil {
+			config.Log("Unable to remove category attribute with invalid id", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+	}
+
+	err := db.RemoveCategoryAttribute(user, userID, categoryID, attributeID)
+	if err != nil {
+		config.Log("Unable to remove category attribute", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(attributeID)
+}
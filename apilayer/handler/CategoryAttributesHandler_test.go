@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddCategoryAttribute_ValidateInventoryCustomAttributes(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedCategory, err := db.CreateCategory(config.CTO_USER, &model.Category{
+		Name:           "Vehicles",
+		Description:    "Cars and trucks in the household",
+		Color:          "#f7f7f7",
+		Status:         "general",
+		CreatedBy:      prevUser.ID.String(),
+		UpdatedBy:      prevUser.ID.String(),
+		SharableGroups: []string{prevUser.ID.String()},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	requestBody, err := json.Marshal(model.CategoryAttribute{
+		Name:       "mileage",
+		Label:      "Mileage",
+		FieldType:  db.CategoryAttributeTypeNumber,
+		IsRequired: true,
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/category/%s/attributes?id=%s", selectedCategory.ID, prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"catID": selectedCategory.ID})
+	w := httptest.NewRecorder()
+	AddCategoryAttribute(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedAttribute model.CategoryAttribute
+	err = json.Unmarshal(data, &selectedAttribute)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "mileage", selectedAttribute.Name)
+	assert.True(t, selectedAttribute.IsRequired)
+
+	draftInventory := model.Inventory{
+		Name:        "Pickup Truck",
+		Description: "Truck used for the weekend trips",
+		Price:       25000.00,
+		Status:      "HIDDEN",
+		Barcode:     "attribute#1231231231",
+		SKU:         "attribute#1231231231",
+		Quantity:    1,
+		Location:    "Driveway",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+		BoughtAt:    "Dealership",
+		CategoryIDs: []string{selectedCategory.ID},
+	}
+
+	// required attribute is missing
+	_, err = db.AddInventory(config.CTO_USER, prevUser.ID.String(), draftInventory)
+	assert.EqualError(t, err, db.InvalidCustomAttributes)
+
+	draftInventory.CustomAttributes = map[string]interface{}{"mileage": 12000}
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), draftInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, float64(12000), selectedInventory.CustomAttributes["mileage"])
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories?attr.mileage.min=10000", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetAllInventories(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var inventories []model.Inventory
+	err = json.Unmarshal(data, &inventories)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 1, len(inventories))
+	assert.Equal(t, selectedInventory.ID, inventories[0].ID)
+
+	// values of removed attributes are retained and do not prevent later updates of the asset
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/category/%s/attributes/%s?id=%s", selectedCategory.ID, selectedAttribute.ID, prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"catID": selectedCategory.ID, "attributeID": selectedAttribute.ID})
+	w = httptest.NewRecorder()
+	RemoveCategoryAttribute(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	selectedInventory.Description = "Truck used for the weekend trips and the move"
+	selectedInventory.CustomAttributes = nil
+	selectedInventory, err = db.UpdateInventory(config.CTO_USER, prevUser.ID.String(), *selectedInventory, nil)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, float64(12000), selectedInventory.CustomAttributes["mileage"])
+
+	selectedInventory, err = db.PatchInventory(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID, map[string]interface{}{"location": "Garage"}, nil)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, float64(12000), selectedInventory.CustomAttributes["mileage"])
+
+	// the retained value cannot be changed once the attribute is removed
+	selectedInventory.CustomAttributes = map[string]interface{}{"mileage": 15000}
+	_, err = db.UpdateInventory(config.CTO_USER, prevUser.ID.String(), *selectedInventory, nil)
+	assert.EqualError(t, err, db.InvalidCustomAttributes)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+	db.RemoveCategory(config.CTO_USER, selectedCategory.ID)
+}
+
+func Test_ValidateCustomAttributes_RetainedValues(t *testing.T) {
+	attributes := []model.CategoryAttribute{{Name: "mileage", FieldType: db.CategoryAttributeTypeNumber}}
+	storedAttributes := map[string]interface{}{"trim": "XLT"}
+
+	err := db.ValidateCustomAttributes(attributes, map[string]interface{}{"mileage": float64(12000), "trim": "XLT"}, storedAttributes)
+	assert.NoError(t, err)
+
+	err = db.ValidateCustomAttributes(attributes, map[string]interface{}{"trim": "Lariat"}, storedAttributes)
+	assert.EqualError(t, err, db.InvalidCustomAttributes)
+
+	err = db.ValidateCustomAttributes(attributes, map[string]interface{}{"color": "red"}, storedAttributes)
+	assert.EqualError(t, err, db.InvalidCustomAttributes)
+}
+
+func Test_GetCategoryAttributes_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/category/0802c692-b8e2-4824-a870-e52f4a0cccf8/attributes", nil)
+	req = mux.SetURLVars(req, map[string]string{"catID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetCategoryAttributes(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetCategoryAttributes_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/category/0802c692-b8e2-4824-a870-e52f4a0cccf8/attributes?id=0802c692-b8e2-4824-a870-e52f4a0cccf8", nil)
+	req = mux.SetURLVars(req, map[string]string{"catID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetCategoryAttributes(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddCategoryAttribute_InvalidFieldType(t *testing.T) {
+	requestBody, err := json.Marshal(model.CategoryAttribute{
+		Name:      "license_key",
+		FieldType: "password",
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/category/0802c692-b8e2-4824-a870-e52f4a0cccf8/attributes?id=0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"catID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddCategoryAttribute(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddCategoryAttribute_EnumWithoutOptions(t *testing.T) {
+	requestBody, err := json.Marshal(model.CategoryAttribute{
+		Name:      "fuel_type",
+		FieldType: db.CategoryAttributeTypeEnum,
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/category/0802c692-b8e2-4824-a870-e52f4a0cccf8/attributes?id=0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"catID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddCategoryAttribute(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetAllInventories_InvalidAttributeFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories?attr.mileage.min=many", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
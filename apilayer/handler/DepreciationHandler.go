@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryDepreciation ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/depreciation Depreciation getInventoryDepreciation
+//
+// # Retrieves the book value and the full depreciation schedule of the selected asset. Assets without a depreciation
+// method are not depreciated and their book value is the purchase cost.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: asOf
+//     in: query
+//     description: The date in YYYY-MM-DD format to compute the book value for. Defaults to the current time.
+//     required: false
+//     type: string
+//
+// Responses:
+// 200: Depreciation
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryDepreciation(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve depreciation with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to retrieve depreciation with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	asOf := time.Now()
+	if draftAsOf := r.URL.Query().Get("asOf"); len(draftAsOf) > 0 {
+		parsedAsOf, err := time.Parse(time.DateOnly, draftAsOf)
+		if err != nil {
+			config.Log("Unable to retrieve depreciation with invalid date", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		asOf = parsedAsOf
+	}
+
+	resp, err := db.RetrieveInventoryDepreciation(user, userID, invID, asOf)
+	if err != nil {
+		config.Log("Unable to retrieve depreciation", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetInventoryDepreciation(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	purchaseDate := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:               "Table Saw",
+		Description:        "Table saw used in the garage",
+		Price:              1000.00,
+		Status:             "HIDDEN",
+		Barcode:            "depreciation#1231231231",
+		SKU:                "depreciation#1231231231",
+		Quantity:           1,
+		Location:           "Garage",
+		CreatedAt:          time.Now(),
+		CreatedBy:          prevUser.ID.String(),
+		BoughtAt:           "Home Depot",
+		PurchaseDate:       &purchaseDate,
+		UsefulLifeYears:    5,
+		SalvageValue:       100.00,
+		DepreciationMethod: db.DepreciationMethodStraightLine,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/depreciation?asOf=2022-01-01", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	GetInventoryDepreciation(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var depreciation model.Depreciation
+	err = json.Unmarshal(data, &depreciation)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 640.00, depreciation.BookValue)
+	assert.Equal(t, 360.00, depreciation.AccumulatedDepreciation)
+	assert.Equal(t, 5, len(depreciation.Schedule))
+	assert.Equal(t, 180.00, depreciation.Schedule[0].Depreciation)
+	assert.Equal(t, 100.00, depreciation.Schedule[4].ClosingBookValue)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetInventoryDepreciation_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/depreciation", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryDepreciation(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryDepreciation_InvalidAsOf(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/depreciation?asOf=yesterday", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryDepreciation(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryDepreciation_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/depreciation", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryDepreciation(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddNewInventory_InvalidDepreciation(t *testing.T) {
+	draftInventory := model.Inventory{
+		Name:               "Table Saw",
+		Price:              1000.00,
+		SalvageValue:       100.00,
+		DepreciationMethod: db.DepreciationMethodDecliningBalance,
+	}
+
+	requestBody, err := json.Marshal(draftInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
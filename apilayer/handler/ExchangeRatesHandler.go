@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxExchangeRateFileSizeInBytes is the largest csv file of exchange rates that can be uploaded
+const maxExchangeRateFileSizeInBytes = 5 << 20
+
+// GetExchangeRates ...
+// swagger:route GET /api/v1/profile/{id}/exchange-rates ExchangeRates getExchangeRates
+//
+// # Retrieves the exchange rates that are visible to the selected user with the most recent rate first
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: from
+//     in: query
+//     description: The three letter code of the currency that is converted, eg EUR
+//     type: string
+//     required: false
+//   - +name: to
+//     in: query
+//     description: The three letter code of the currency that the rate converts into, eg USD
+//     type: string
+//     required: false
+//
+// Responses:
+// 200: []ExchangeRate
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetExchangeRates(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve exchange rates with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveExchangeRates(user, userID, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		config.Log("Unable to retrieve exchange rates", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddExchangeRates ...
+// swagger:route POST /api/v1/profile/{id}/exchange-rates ExchangeRates addExchangeRates
+//
+// # Adds the selected exchange rates for the selected user. Each rate converts one unit of the from currency
+// into the to currency starting on the effective date. A rate for the same currencies on the same effective
+// date replaces the existing rate.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: ExchangeRates
+//     in: body
+//     description: The list of exchange rates
+//     type: []ExchangeRate
+//     required: true
+//
+// Responses:
+// 200: []ExchangeRate
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func AddExchangeRates(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add exchange rates with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftExchangeRates []model.ExchangeRate
+	if err := json.NewDecoder(r.Body).Decode(&draftExchangeRates); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddExchangeRates(user, userID, draftExchangeRates)
+	if err != nil {
+		config.Log("Unable to add exchange rates", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UploadExchangeRates ...
+// swagger:route POST /api/v1/profile/{id}/exchange-rates/upload ExchangeRates uploadExchangeRates
+//
+// # Adds the exchange rates from the selected csv file. The first row is the header and must contain the
+// from_currency, to_currency, rate and effective_date columns. Either every row is added or none are.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: file
+//     in: formData
+//     description: The csv file of exchange rates
+//     required: true
+//     type: file
+//
+// Responses:
+// 200: []ExchangeRate
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func UploadExchangeRates(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to upload exchange rates with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, maxExchangeRateFileSizeInBytes)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		config.Log("Unable to retrieve file", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer file.Close()
+
+	draftExchangeRates, err := db.ParseExchangeRatesCSV(file)
+	if err != nil {
+		config.Log("Unable to parse exchange rates", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	resp, err := db.AddExchangeRates(user, userID, draftExchangeRates)
+	if err != nil {
+		config.Log("Unable to add exchange rates", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveExchangeRate ...
+// swagger:route DELETE /api/v1/profile/{id}/exchange-rates/{rateID} ExchangeRates removeExchangeRate
+//
+// # Removes the selected exchange rate
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: rateID
+//     in: path
+//     description: The id of the selected exchange rate
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveExchangeRate(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	rateID := vars["rateID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove exchange rate with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(rateID); err != nil {
+		config.Log("Unable to remove exchange rate with invalid rate id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveExchangeRate(user, userID, rateID)
+	if err != nil {
+		config.Log("Unable to remove exchange rate", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(rateID)
+}
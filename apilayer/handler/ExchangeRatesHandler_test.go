@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExchangeRates(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	purchaseDate := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:         "Espresso Machine",
+		Description:  "bought in europe",
+		Price:        200.00,
+		Currency:     "eur",
+		Status:       "HIDDEN",
+		Barcode:      "exchange-rates#1",
+		SKU:          "exchange-rates#1",
+		Quantity:     1,
+		Location:     "Kitchen",
+		PurchaseDate: &purchaseDate,
+		CreatedAt:    time.Now(),
+		CreatedBy:    prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "EUR", selectedInventory.Currency)
+
+	requestBody, contentType := buildExchangeRatesRequestBody(t, "from_currency,to_currency,rate,effective_date\nEUR,ZZZ,1.5,2024-01-01\nEUR,ZZZ,2.0,2024-06-01\n")
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/exchange-rates/upload", prevUser.ID.String()), requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	UploadExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var uploadedRates []model.ExchangeRate
+	err = json.Unmarshal(data, &uploadedRates)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 2, len(uploadedRates))
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/exchange-rates?from=eur&to=zzz", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetExchangeRates(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var exchangeRates []model.ExchangeRate
+	err = json.Unmarshal(data, &exchangeRates)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 2, len(exchangeRates))
+	// the most recent rate is returned first
+	assert.Equal(t, 2.0, exchangeRates[0].Rate)
+
+	for _, v := range uploadedRates {
+		req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/exchange-rates/%s", prevUser.ID.String(), v.ID), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "rateID": v.ID})
+		w = httptest.NewRecorder()
+		RemoveExchangeRate(w, req, config.CTO_USER)
+		res = w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, 200, res.StatusCode)
+	}
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
+
+func Test_GetExchangeRates_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//exchange-rates", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	GetExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddExchangeRates_InvalidCurrency(t *testing.T) {
+	requestBody, err := json.Marshal([]model.ExchangeRate{{FromCurrency: "EURO", ToCurrency: "USD", Rate: 1.1, EffectiveDate: time.Now()}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddExchangeRates_InvalidRate(t *testing.T) {
+	requestBody, err := json.Marshal([]model.ExchangeRate{{FromCurrency: "EUR", ToCurrency: "USD", Rate: 0, EffectiveDate: time.Now()}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_UploadExchangeRates_MissingColumn(t *testing.T) {
+	requestBody, contentType := buildExchangeRatesRequestBody(t, "from_currency,to_currency,rate\nEUR,USD,1.1\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates/upload", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	UploadExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_UploadExchangeRates_InvalidDate(t *testing.T) {
+	requestBody, contentType := buildExchangeRatesRequestBody(t, "from_currency,to_currency,rate,effective_date\nEUR,USD,1.1,31/01/2024\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates/upload", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	UploadExchangeRates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RemoveExchangeRate_InvalidRateID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "rateID": "1"})
+	w := httptest.NewRecorder()
+	RemoveExchangeRate(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetExchangeRates_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/exchange-rates", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetExchangeRates(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+// buildExchangeRatesRequestBody ...
+//
+// builds the multipart form used to upload the selected csv file of exchange rates
+func buildExchangeRatesRequestBody(t *testing.T, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "exchange_rates.csv")
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	part.Write([]byte(content))
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
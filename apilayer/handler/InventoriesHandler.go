@@ -1,22 +1,33 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/earmuff-jam/fleetwise/config"
 	"github.com/earmuff-jam/fleetwise/db"
 	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
-const defaultHiddenStatus = "HIDDEN"
+const (
+	defaultHiddenStatus = "HIDDEN"
+	defaultDraftStatus  = "DRAFT"
+)
 
 // GetAllInventories ...
 // swagger:route GET /api/v1/profile/{id}/inventories Assets getAllInventories
 //
-// # Retrieves the list of assets that belong to the selected user
+// # Retrieves the list of assets that belong to the selected user. Supports offset and cursor based pagination,
+// sorting against whitelisted columns and filtering. The total count of matching assets is returned in the
+// X-Total-Count header and the cursor for the next page is returned in the X-Next-Cursor header.
 //
 // // Parameters:
 //   - +name: id
@@ -24,12 +35,84 @@ const defaultHiddenStatus = "HIDDEN"
 //     description: The userID of the selected user
 //     required: true
 //     type: string
-//   - +name: until
+//   - +name: since
 //     in: query
-//     description: The timestamp with time zone until the data should be retrieved for. Returns all inventories if not passed in.
+//     description: The timestamp with time zone since the data should be retrieved for. Returns all inventories if not passed in.
 //     required: false
 //     type: string
 //     format: date-time
+//   - +name: limit
+//     in: query
+//     description: The max number of assets to return. Returns all assets if not passed in.
+//     required: false
+//     type: integer
+//     format: int32
+//   - +name: offset
+//     in: query
+//     description: The number of assets to skip. Ignored if cursor is passed in.
+//     required: false
+//     type: integer
+//     format: int32
+//   - +name: cursor
+//     in: query
+//     description: The opaque cursor returned in the X-Next-Cursor header of the previous page.
+//     required: false
+//     type: string
+//   - +name: sortBy
+//     in: query
+//     description: The column to sort against. One of name, price, quantity, status, location, sku, barcode, created_at, updated_at. Defaults to updated_at.
+//     required: false
+//     type: string
+//   - +name: sortOrder
+//     in: query
+//     description: The direction of the sort. One of asc, desc. Defaults to desc.
+//     required: false
+//     type: string
+//   - +name: status
+//     in: query
+//     description: The comma separated list of statuses to filter against.
+//     required: false
+//     type: string
+//   - +name: storageLocationID
+//     in: query
+//     description: The comma separated list of storage location ids to filter against.
+//     required: false
+//     type: string
+//   - +name: minPrice
+//     in: query
+//     description: The minimum price of the asset.
+//     required: false
+//     type: number
+//   - +name: maxPrice
+//     in: query
+//     description: The maximum price of the asset.
+//     required: false
+//     type: number
+//   - +name: isReturnable
+//     in: query
+//     description: The boolean flag to filter returnable assets.
+//     required: false
+//     type: boolean
+//   - +name: catID
+//     in: query
+//     description: The category id that the assets must belong to.
+//     required: false
+//     type: string
+//   - +name: mID
+//     in: query
+//     description: The maintenance plan id that the assets must belong to.
+//     required: false
+//     type: string
+//   - +name: attr.{name}
+//     in: query
+//     description: The value that the selected custom attribute must match. Use attr.{name}.min or attr.{name}.max with a number or a YYYY-MM-DD date to filter against a range.
+//     required: false
+//     type: string
+//   - +name: tag
+//     in: query
+//     description: The comma separated list of tag ids. The assets must hold every selected tag.
+//     required: false
+//     type: string
 //
 // Responses:
 // 200: []Inventory
@@ -40,7 +123,6 @@ func GetAllInventories(rw http.ResponseWriter, r *http.Request, user string) {
 
 	vars := mux.Vars(r)
 	userID := vars["id"]
-	sinceDateTime := r.URL.Query().Get("since")
 
 	if len(userID) <= 0 {
 		config.Log("Unable to retrieve assets with empty id", nil)
@@ -49,7 +131,15 @@ func GetAllInventories(rw http.ResponseWriter, r *http.Request, user string) {
 		return
 	}
 
-	resp, err := db.RetrieveAllInventoriesForUser(user, userID, sinceDateTime)
+	listParams, err := parseInventoryListParams(r)
+	if err != nil {
+		config.Log("Unable to parse query parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveAllInventoriesForUser(user, userID, *listParams)
 	if err != nil {
 		config.Log("Unable to retrieve all existing assets", err)
 		rw.WriteHeader(http.StatusBadRequest)
@@ -57,8 +147,144 @@ func GetAllInventories(rw http.ResponseWriter, r *http.Request, user string) {
 		return
 	}
 	rw.Header().Add("Content-Type", "application/json")
+	rw.Header().Add("X-Total-Count", strconv.Itoa(resp.TotalCount))
+	if len(resp.NextCursor) > 0 {
+		rw.Header().Add("X-Next-Cursor", resp.NextCursor)
+	}
 	rw.WriteHeader(http.StatusOK)
-	json.NewEncoder(rw).Encode(resp)
+	json.NewEncoder(rw).Encode(resp.Inventories)
+}
+
+// parseInventoryListParams ...
+//
+// parses the pagination, sort and filter query parameters of the inventory list
+func parseInventoryListParams(r *http.Request) (*model.InventoryListParams, error) {
+	return parseInventoryListQuery(r.URL.Query())
+}
+
+// parseInventoryListQuery ...
+//
+// parses the pagination, sort and filter values of the selected query of the inventory list
+func parseInventoryListQuery(query url.Values) (*model.InventoryListParams, error) {
+	listParams := model.InventoryListParams{
+		Since:             query.Get("since"),
+		Cursor:            query.Get("cursor"),
+		SortBy:            query.Get("sortBy"),
+		SortOrder:         query.Get("sortOrder"),
+		CategoryID:        query.Get("catID"),
+		MaintenancePlanID: query.Get("mID"),
+	}
+
+	if limit := query.Get("limit"); len(limit) > 0 {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 0 {
+			return nil, errors.New("invalid limit")
+		}
+		listParams.Limit = limitInt
+	}
+
+	if offset := query.Get("offset"); len(offset) > 0 {
+		offsetInt, err := strconv.Atoi(offset)
+		if err != nil || offsetInt < 0 {
+			return nil, errors.New("invalid offset")
+		}
+		listParams.Offset = offsetInt
+	}
+
+	if len(listParams.SortOrder) > 0 && !strings.EqualFold(listParams.SortOrder, "asc") && !strings.EqualFold(listParams.SortOrder, "desc") {
+		return nil, errors.New("invalid sort order")
+	}
+
+	listParams.Statuses = splitQueryValues(query["status"])
+	listParams.StorageLocationIDs = splitQueryValues(query["storageLocationID"])
+	for _, v := range listParams.StorageLocationIDs {
+		if _, err := uuid.Parse(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if minPrice := query.Get("minPrice"); len(minPrice) > 0 {
+		parsedMinPrice, err := strconv.ParseFloat(minPrice, 64)
+		if err != nil {
+			return nil, err
+		}
+		listParams.MinPrice = &parsedMinPrice
+	}
+
+	if maxPrice := query.Get("maxPrice"); len(maxPrice) > 0 {
+		parsedMaxPrice, err := strconv.ParseFloat(maxPrice, 64)
+		if err != nil {
+			return nil, err
+		}
+		listParams.MaxPrice = &parsedMaxPrice
+	}
+
+	if listParams.MinPrice != nil && listParams.MaxPrice != nil && *listParams.MinPrice > *listParams.MaxPrice {
+		return nil, errors.New("invalid price range")
+	}
+
+	if isReturnable := query.Get("isReturnable"); len(isReturnable) > 0 {
+		parsedIsReturnable, err := strconv.ParseBool(isReturnable)
+		if err != nil {
+			return nil, err
+		}
+		listParams.IsReturnable = &parsedIsReturnable
+	}
+
+	listParams.TagIDs = splitQueryValues(query["tag"])
+	for _, v := range listParams.TagIDs {
+		if _, err := uuid.Parse(v); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range []string{listParams.CategoryID, listParams.MaintenancePlanID} {
+		if len(v) > 0 {
+			if _, err := uuid.Parse(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// custom attributes are filtered as attr.<name>=value, attr.<name>.min=value or attr.<name>.max=value
+	for key, values := range query {
+		if !strings.HasPrefix(key, "attr.") {
+			continue
+		}
+		filter := model.InventoryAttributeFilter{
+			Name:     strings.TrimPrefix(key, "attr."),
+			Operator: db.AttributeFilterOperatorEqual,
+			Value:    values[0],
+		}
+		for _, operator := range []string{db.AttributeFilterOperatorMin, db.AttributeFilterOperatorMax} {
+			if strings.HasSuffix(filter.Name, "."+operator) {
+				filter.Name = strings.TrimSuffix(filter.Name, "."+operator)
+				filter.Operator = operator
+			}
+		}
+		if err := db.ValidateAttributeFilter(filter); err != nil {
+			return nil, err
+		}
+		listParams.AttributeFilters = append(listParams.AttributeFilters, filter)
+	}
+
+	return &listParams, nil
+}
+
+// splitQueryValues ...
+//
+// splits the query values that are either passed in as repeated keys or as comma separated values
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if len(item) > 0 {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
 }
 
 // GetInventoryByID ...
@@ -111,10 +337,83 @@ func GetInventoryByID(rw http.ResponseWriter, r *http.Request, user string) {
 	}
 
 	rw.Header().Add("Content-Type", "application/json")
+	rw.Header().Add("ETag", db.InventoryETag(resp.UpdatedAt))
 	rw.WriteHeader(http.StatusOK)
 	json.NewEncoder(rw).Encode(resp)
 }
 
+// LookupInventory ...
+// swagger:route GET /api/v1/profile/{id}/inventories/lookup Assets lookupInventory
+//
+// # Retrieves the asset that matches the scanned barcode or sku. Barcode and sku are unique for each owner.
+// If no asset matches the scan, a draft asset prefilled with the scanned values is returned so that the asset can be created from the scan.
+//
+// // Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: barcode
+//     in: query
+//     description: The scanned barcode of the asset. Either barcode or sku is required.
+//     required: false
+//     type: string
+//   - +name: sku
+//     in: query
+//     description: The scanned sku of the asset. Either barcode or sku is required.
+//     required: false
+//     type: string
+//
+// Responses:
+// 200: InventoryLookup
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func LookupInventory(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	barcode := strings.TrimSpace(r.URL.Query().Get("barcode"))
+	sku := strings.TrimSpace(r.URL.Query().Get("sku"))
+
+	if len(userID) <= 0 {
+		config.Log("Unable to lookup inventory with empty profile id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(barcode) <= 0 && len(sku) <= 0 {
+		config.Log("Unable to lookup inventory without barcode or sku", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryByCode(user, userID, barcode, sku)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		config.Log("Unable to lookup inventory with selected barcode or sku", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	lookup := model.InventoryLookup{Found: resp != nil, Inventory: resp}
+	if resp == nil {
+		lookup.Draft = &model.Inventory{
+			Barcode:  barcode,
+			SKU:      sku,
+			Quantity: 1,
+			Status:   defaultDraftStatus,
+		}
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(lookup)
+}
+
 // UpdateAssetColumn ...
 // swagger:route GET /api/v1/profile/{id}/inventories/{assetID} Assets updateAssetColumn
 //
@@ -184,6 +483,11 @@ func UpdateAssetColumn(rw http.ResponseWriter, r *http.Request, user string) {
 	resp, err := db.UpdateAsset(user, userID, updateAssetCol)
 	if err != nil {
 		config.Log("Unable to update asset", err)
+		if err.Error() == db.SerializedInventoryQuantity {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		rw.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(rw).Encode(err)
 		return
@@ -216,6 +520,7 @@ func UpdateAssetColumn(rw http.ResponseWriter, r *http.Request, user string) {
 // 200: []Inventory
 // 400: MessageResponse
 // 404: MessageResponse
+// 409: MessageResponse
 // 500: MessageResponse
 func AddInventoryInBulk(rw http.ResponseWriter, r *http.Request, user string) {
 	vars := mux.Vars(r)
@@ -248,10 +553,6 @@ func AddInventoryInBulk(rw http.ResponseWriter, r *http.Request, user string) {
 			SKU:            v.SKU,
 			Barcode:        v.Barcode,
 			BoughtAt:       v.PurchaseLocation,
-			MaxWeight:      v.MaximumWeight,
-			MinWeight:      v.MinimumWeight,
-			MaxHeight:      v.MaximumHeight,
-			MinHeight:      v.MinimumHeight,
 			Status:         defaultHiddenStatus,
 			CreatedAt:      time.Now(),
 			CreatedBy:      userID,
@@ -259,6 +560,14 @@ func AddInventoryInBulk(rw http.ResponseWriter, r *http.Request, user string) {
 			UpdatedBy:      userID,
 			SharableGroups: []string{userID},
 		}
+
+		// dimensions and weights can be uploaded with a unit suffix, eg 12kg or 3 ft
+		if err := db.ApplyRawInventoryMeasurements(v, &draftInventory); err != nil {
+			config.Log("unable to parse dimensions and weights", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		inventoryList = append(inventoryList, draftInventory)
 	}
 
@@ -272,6 +581,11 @@ func AddInventoryInBulk(rw http.ResponseWriter, r *http.Request, user string) {
 	resp, err := db.AddInventoryInBulk(user, userID, inventoryListRequest)
 	if err != nil {
 		config.Log("unable to add new item during bulk insert", err)
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -302,6 +616,7 @@ func AddInventoryInBulk(rw http.ResponseWriter, r *http.Request, user string) {
 // 200: Inventory
 // 400: MessageResponse
 // 404: MessageResponse
+// 409: MessageResponse
 // 500: MessageResponse
 func AddNewInventory(rw http.ResponseWriter, r *http.Request, user string) {
 	vars := mux.Vars(r)
@@ -321,9 +636,26 @@ func AddNewInventory(rw http.ResponseWriter, r *http.Request, user string) {
 		return
 	}
 
+	if err := db.ValidateDepreciation(inventory); err != nil {
+		config.Log("unable to validate depreciation details", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
 	resp, err := db.AddInventory(user, userID, inventory)
 	if err != nil {
 		config.Log("Unable to add new item", err)
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		if err.Error() == db.InvalidCustomAttributes {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -336,7 +668,8 @@ func AddNewInventory(rw http.ResponseWriter, r *http.Request, user string) {
 // UpdateSelectedInventory ...
 // swagger:route PUT /api/profile/{id}/inventories Assets updateSelectedInventory
 //
-// # Update selected inventory with details.
+// # Update selected inventory with details. The ETag returned when the inventory is retrieved can be sent as If-Match
+// to reject the update when the inventory has been modified by someone else in the meantime.
 //
 // Parameters:
 //   - +name: id
@@ -344,6 +677,11 @@ func AddNewInventory(rw http.ResponseWriter, r *http.Request, user string) {
 //     description: The id of the selected user
 //     type: string
 //     required: true
+//   - +name: If-Match
+//     in: header
+//     description: The ETag of the inventory the update is based on
+//     type: string
+//     required: false
 //   - +name: Inventory
 //     in: body
 //     description: The inventory object to add into the db
@@ -354,6 +692,8 @@ func AddNewInventory(rw http.ResponseWriter, r *http.Request, user string) {
 // 200: Inventory
 // 400: MessageResponse
 // 404: MessageResponse
+// 409: MessageResponse
+// 412: MessageResponse
 // 500: MessageResponse
 func UpdateSelectedInventory(rw http.ResponseWriter, r *http.Request, user string) {
 	vars := mux.Vars(r)
@@ -373,18 +713,157 @@ func UpdateSelectedInventory(rw http.ResponseWriter, r *http.Request, user strin
 		return
 	}
 
-	resp, err := db.UpdateInventory(user, userID, inventory)
+	if err := db.ValidateDepreciation(inventory); err != nil {
+		config.Log("unable to validate depreciation details", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	resp, err := db.UpdateInventory(user, userID, inventory, parseIfMatchHeader(r))
 	if err != nil {
 		config.Log("Unable to update selected inventory", err)
+		if err.Error() == db.StaleInventory {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		if err.Error() == db.InvalidCustomAttributes || err.Error() == db.SerializedInventoryQuantity || err.Error() == db.InvalidDepreciation {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	rw.Header().Add("Content-Type", "application/json")
+	rw.Header().Add("ETag", db.InventoryETag(resp.UpdatedAt))
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// PatchSelectedInventory ...
+// swagger:route PATCH /api/v1/profile/{id}/inventories/{invID} Assets patchSelectedInventory
+//
+// # Applies a json merge patch to the selected inventory. Only the fields in the patch are updated and null removes
+// the value of the field. The ETag returned when the inventory is retrieved can be sent as If-Match to reject the
+// patch when the inventory has been modified by someone else in the meantime.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected inventory
+//     type: string
+//     required: true
+//   - +name: If-Match
+//     in: header
+//     description: The ETag of the inventory the patch is based on
+//     type: string
+//     required: false
+//   - +name: Inventory
+//     in: body
+//     description: The json merge patch of the selected inventory
+//     type: Inventory
+//     required: true
+//
+// Responses:
+// 200: Inventory
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 412: MessageResponse
+// 415: MessageResponse
+// 500: MessageResponse
+func PatchSelectedInventory(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to patch selected inventory without id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to patch selected inventory with invalid inventory id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	if len(contentType) > 0 && contentType != "application/merge-patch+json" && contentType != "application/json" {
+		config.Log("Unable to patch selected inventory with content type %s", nil, contentType)
+		rw.WriteHeader(http.StatusUnsupportedMediaType)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.PatchInventory(user, userID, invID, patch, parseIfMatchHeader(r))
+	if err != nil {
+		config.Log("Unable to patch selected inventory", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.StaleInventory {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.Header().Add("ETag", db.InventoryETag(resp.UpdatedAt))
 	rw.WriteHeader(http.StatusOK)
 	json.NewEncoder(rw).Encode(resp)
 }
 
+// parseIfMatchHeader ...
+//
+// returns the entity tags of the If-Match header. Empty list is returned when the header is not present.
+func parseIfMatchHeader(r *http.Request) []string {
+	var ifMatch []string
+	for _, v := range r.Header.Values("If-Match") {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); len(tag) > 0 {
+				ifMatch = append(ifMatch, tag)
+			}
+		}
+	}
+	return ifMatch
+}
+
 // RemoveSelectedInventory ...
 // swagger:route POST /api/profile/{id}/inventories Assets removeSelectedInventory
 //
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -105,6 +106,146 @@ func Test_GetAllInventories_InvalidDBUser(t *testing.T) {
 	assert.Equal(t, "400 Bad Request", res.Status)
 }
 
+func Test_GetAllInventories_Paginated(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories?limit=1&sortBy=name&sortOrder=asc", prevUser.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var firstPage []model.Inventory
+	err = json.Unmarshal(data, &firstPage)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 1, len(firstPage))
+	assert.NotEmpty(t, res.Header.Get("X-Total-Count"))
+
+	nextCursor := res.Header.Get("X-Next-Cursor")
+	assert.NotEmpty(t, nextCursor)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories?limit=1&sortBy=name&sortOrder=asc&cursor=%s", prevUser.ID, nextCursor), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetAllInventories(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var secondPage []model.Inventory
+	err = json.Unmarshal(data, &secondPage)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.LessOrEqual(t, len(secondPage), 1)
+	if len(secondPage) == 1 {
+		assert.NotEqual(t, firstPage[0].ID, secondPage[0].ID)
+	}
+}
+
+func Test_GetAllInventories_Filtered(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories?status=HIDDEN,DRAFT&minPrice=0&maxPrice=100&isReturnable=false&offset=0&limit=10", prevUser.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var inventories []model.Inventory
+	err = json.Unmarshal(data, &inventories)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	for _, v := range inventories {
+		assert.Contains(t, []string{"HIDDEN", "DRAFT"}, v.Status)
+		assert.LessOrEqual(t, v.Price, 100.00)
+		assert.False(t, v.IsReturnable)
+	}
+}
+
+func Test_GetAllInventories_InvalidSortColumn(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories?sortBy=sharable_groups", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetAllInventories_InvalidPriceRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories?minPrice=20&maxPrice=10", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetAllInventories_InvalidLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories?limit=-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetAllInventories(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
 func Test_GetInventoryByID(t *testing.T) {
 
 	// profile are automatically derieved from the auth table. due to this, we attempt to create a new user
@@ -201,6 +342,158 @@ func Test_GetInventoryByID_InvalidDBUser(t *testing.T) {
 	assert.Equal(t, "400 Bad Request", res.Status)
 }
 
+func Test_LookupInventory(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/lookup?barcode=%s", prevUser.ID.String(), url.QueryEscape("barcode#1123928")), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	LookupInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var lookup model.InventoryLookup
+	err = json.Unmarshal(data, &lookup)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.True(t, lookup.Found)
+	assert.Nil(t, lookup.Draft)
+	assert.Equal(t, "barcode#1123928", lookup.Inventory.Barcode)
+}
+
+func Test_LookupInventory_CreateFromScan(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/lookup?sku=%s", prevUser.ID.String(), "unknown-sku-0001"), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	LookupInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var lookup model.InventoryLookup
+	err = json.Unmarshal(data, &lookup)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.False(t, lookup.Found)
+	assert.Nil(t, lookup.Inventory)
+	assert.Equal(t, "unknown-sku-0001", lookup.Draft.SKU)
+	assert.Equal(t, 1, lookup.Draft.Quantity)
+}
+
+func Test_LookupInventory_NoBarcodeOrSKU(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/lookup", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	LookupInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_LookupInventory_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/lookup?barcode=1231231231", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	LookupInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_LookupInventory_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/lookup?barcode=1231231231", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	LookupInventory(w, req, config.CEO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddNewInventory_DuplicateBarcode(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	draftInventory := model.Inventory{
+		Name:      "Duplicate kitty litter",
+		Status:    "HIDDEN",
+		Barcode:   "barcode#1123928",
+		Quantity:  1,
+		Location:  "Broom Closet",
+		CreatedAt: time.Now(),
+		CreatedBy: prevUser.ID.String(),
+	}
+
+	requestBody, err := json.Marshal(draftInventory)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 409, res.StatusCode)
+	assert.Equal(t, "409 Conflict", res.Status)
+}
+
 func Test_AddInventoryInBulk(t *testing.T) {
 
 	// profile are automatically derieved from the auth table. due to this, we attempt to create a new user
@@ -282,7 +575,7 @@ func Test_AddInventoryInBulk_NoUserID(t *testing.T) {
 	assert.Equal(t, "400 Bad Request", res.Status)
 }
 
-func Test_AddNewInventory(t *testing.T) {
+func Test_AddInventoryInBulk_UnitSuffix(t *testing.T) {
 
 	draftUserCredentials := model.UserCredentials{
 		Email:             "admin@gmail.com",
@@ -296,36 +589,474 @@ func Test_AddNewInventory(t *testing.T) {
 		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	draftInventory := model.Inventory{
-		Name:           "Alexandro Kitteyy Litter",
-		Description:    "Kitty litter for a pro name game",
-		Price:          23.99,
-		Status:         "HIDDEN",
-		Barcode:        "1231231231",
-		SKU:            "1231231231",
-		Quantity:       12,
-		IsReturnable:   true,
-		ReturnLocation: "Target",
-		MaxWeight:      12,
-		MinWeight:      120,
-		MaxHeight:      24,
-		MinHeight:      12,
-		Location:       "Broom Closet",
-		CreatedAt:      time.Now(),
-		CreatedBy:      prevUser.ID.String(),
-		BoughtAt:       "Walmart",
+	inventoryMap := make(map[string]model.RawInventory)
+	inventoryMap["0"] = model.RawInventory{
+		Name:            "Camping Cooler",
+		Description:     "Hard sided cooler",
+		Price:           89.99,
+		Barcode:         "unitsuffix#1",
+		SKU:             "unitsuffix#1",
+		Quantity:        1,
+		StorageLocation: "Garage",
+		MaximumWeight:   "12kg",
+		MinimumWeight:   "500 g",
+		Length:          "3 ft",
+		Height:          "12",
+	}
+
+	requestBody, err := json.Marshal(inventoryMap)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/bulk", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	AddInventoryInBulk(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedInventories []model.Inventory
+	err = json.Unmarshal(data, &selectedInventories)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var removeInventory []string
+	for _, v := range selectedInventories {
+		if v.Barcode != "unitsuffix#1" {
+			continue
+		}
+		removeInventory = append(removeInventory, v.ID)
+
+		// values are returned in the metric unit system of the test user
+		assert.Equal(t, "kg", v.WeightUnit)
+		assert.Equal(t, 12.0, v.MaxWeight)
+		assert.Equal(t, 0.5, v.MinWeight)
+		assert.Equal(t, "cm", v.DimensionUnit)
+		assert.Equal(t, 91.44, v.Length)
+		assert.Equal(t, 365.76, v.Height)
+	}
+	assert.Equal(t, 1, len(removeInventory))
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_AddInventoryInBulk_InvalidMeasurement(t *testing.T) {
+	inventoryMap := make(map[string]model.RawInventory)
+	inventoryMap["0"] = model.RawInventory{
+		Name:          "Camping Cooler",
+		MaximumWeight: "12 parsecs",
+	}
+
+	requestBody, err := json.Marshal(inventoryMap)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/bulk", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryInBulk(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddNewInventory(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	draftInventory := model.Inventory{
+		Name:           "Alexandro Kitteyy Litter",
+		Description:    "Kitty litter for a pro name game",
+		Price:          23.99,
+		Status:         "HIDDEN",
+		Barcode:        "1231231231",
+		SKU:            "1231231231",
+		Quantity:       12,
+		IsReturnable:   true,
+		ReturnLocation: "Target",
+		MaxWeight:      12,
+		MinWeight:      120,
+		MaxHeight:      24,
+		MinHeight:      12,
+		Location:       "Broom Closet",
+		CreatedAt:      time.Now(),
+		CreatedBy:      prevUser.ID.String(),
+		BoughtAt:       "Walmart",
+	}
+
+	// Marshal the draftEvent into JSON bytes
+	requestBody, err := json.Marshal(draftInventory)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	w := httptest.NewRecorder()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedInventory model.Inventory
+	err = json.Unmarshal(data, &selectedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventory.Name)
+	assert.Equal(t, "Broom Closet", selectedInventory.Location)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, selectedInventory.ID, removeInventory)
+}
+
+func Test_AddNewInventory_WrongUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddNewInventory_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddNewInventory_IncorrectUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "request"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddNewInventory_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddNewInventory(w, req, config.CEO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddNewInventory_Return_Notes(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	draftInventoryWithReturnNotes := model.Inventory{
+		Name:           "Alexandro Kitteyy Litter",
+		Description:    "Kitty litter for a pro name game",
+		Price:          23.99,
+		Status:         "HIDDEN",
+		Barcode:        "1231231231",
+		SKU:            "1231231231",
+		Quantity:       12,
+		IsReturnable:   true,
+		ReturnLocation: "Target",
+		ReturnNotes:    "print out the return label",
+		MaxWeight:      12,
+		MinWeight:      120,
+		MaxHeight:      24,
+		MinHeight:      12,
+		Location:       "Broom Closet",
+		CreatedAt:      time.Now(),
+		CreatedBy:      prevUser.ID.String(),
+		BoughtAt:       "Walmart",
+	}
+
+	// Marshal the draftEvent into JSON bytes
+	requestBody, err := json.Marshal(draftInventoryWithReturnNotes)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	w := httptest.NewRecorder()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedInventoryWithReturnNotes model.Inventory
+	err = json.Unmarshal(data, &selectedInventoryWithReturnNotes)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventoryWithReturnNotes.Name)
+	assert.Equal(t, "Broom Closet", selectedInventoryWithReturnNotes.Location)
+
+	// cleanup
+	removeInventory := []string{selectedInventoryWithReturnNotes.ID}
+	db.DeleteInventory(config.CTO_USER, selectedInventoryWithReturnNotes.ID, removeInventory)
+}
+
+func Test_UpdateSelectedInventory(t *testing.T) {
+	// profile are automatically derieved from the auth table. due to this, we attempt to create a new user
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	draftInventory := model.Inventory{
+		Name:        "Alexandro Kitteyy Litter",
+		Description: "Kitty litter for a pro name game",
+		Price:       23.99,
+		Status:      "HIDDEN",
+		Barcode:     "1231231231",
+		SKU:         "1231231231",
+		Quantity:    12,
+		Location:    "Broom Closet",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+		BoughtAt:    "Walmart",
+	}
+
+	// Marshal the draftEvent into JSON bytes
+	requestBody, err := json.Marshal(draftInventory)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	w := httptest.NewRecorder()
+	AddNewInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedInventory model.Inventory
+	err = json.Unmarshal(data, &selectedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventory.Name)
+	assert.Equal(t, "Broom Closet", selectedInventory.Location)
+
+	// test to perform update but with consistent storage location name
+	// TODO: write another test that checks two different inserts into storage locations as
+	// this only tests updating the found name.
+
+	selectedInventory.Name = "Alexandro Kitty Litter"
+
+	// Marshal the draftEvent into JSON bytes
+	requestBody, err = json.Marshal(selectedInventory)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	UpdateSelectedInventory(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var updatedInventory model.Inventory
+	err = json.Unmarshal(data, &updatedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "Alexandro Kitty Litter", updatedInventory.Name)
+	assert.Equal(t, "Broom Closet", updatedInventory.Location)
+
+	// cleanup fn
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, selectedInventory.ID, removeInventory)
+}
+
+func Test_UpdateSelectedInventory_RetainsReorderPoint(t *testing.T) {
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:         "Printer Paper",
+		Description:  "A4 80gsm",
+		Price:        5.99,
+		Status:       "HIDDEN",
+		Barcode:      "reorder#1",
+		SKU:          "reorder#1",
+		Quantity:     10,
+		ReorderPoint: 4,
+		Location:     "Office",
+		CreatedAt:    time.Now(),
+		CreatedBy:    prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	// the edit form does not send the reorder point
+	selectedInventory.Name = "Printer Paper A4"
+	selectedInventory.ReorderPoint = 0
+
+	requestBody, err := json.Marshal(selectedInventory)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	assert.NotContains(t, string(requestBody), "reorder_point")
+
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	UpdateSelectedInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var updatedInventory model.Inventory
+	err = json.Unmarshal(data, &updatedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "Printer Paper A4", updatedInventory.Name)
+	assert.Equal(t, 4, updatedInventory.ReorderPoint)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
+
+func Test_UpdateSelectedInventory_RetainsDepreciation(t *testing.T) {
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	purchaseDate := time.Now().AddDate(-1, 0, 0).Truncate(24 * time.Hour)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:               "Laptop",
+		Description:        "14 inch work laptop",
+		Price:              1200.00,
+		Status:             "HIDDEN",
+		Barcode:            "depreciation#1",
+		SKU:                "depreciation#1",
+		Quantity:           1,
+		Location:           "Office",
+		PurchaseDate:       &purchaseDate,
+		UsefulLifeYears:    4,
+		SalvageValue:       200,
+		DepreciationMethod: db.DepreciationMethodStraightLine,
+		CreatedAt:          time.Now(),
+		CreatedBy:          prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	// Marshal the draftEvent into JSON bytes
-	requestBody, err := json.Marshal(draftInventory)
+	// the edit form does not send the depreciation details
+	selectedInventory.Name = "Work Laptop"
+	selectedInventory.PurchaseDate = nil
+	selectedInventory.UsefulLifeYears = 0
+	selectedInventory.SalvageValue = 0
+	selectedInventory.DepreciationMethod = ""
+
+	requestBody, err := json.Marshal(selectedInventory)
 	if err != nil {
 		t.Errorf("failed to marshal JSON: %v", err)
 	}
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
-	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
 	w := httptest.NewRecorder()
-	AddNewInventory(w, req, config.CTO_USER)
+	UpdateSelectedInventory(w, req, config.CTO_USER)
 	res := w.Result()
 	defer res.Body.Close()
 	data, err := io.ReadAll(res.Body)
@@ -334,70 +1065,23 @@ func Test_AddNewInventory(t *testing.T) {
 	}
 	assert.Equal(t, 200, res.StatusCode)
 
-	var selectedInventory model.Inventory
-	err = json.Unmarshal(data, &selectedInventory)
+	var updatedInventory model.Inventory
+	err = json.Unmarshal(data, &updatedInventory)
 	if err != nil {
 		t.Errorf("expected error to be nil got %v", err)
 	}
-
-	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventory.Name)
-	assert.Equal(t, "Broom Closet", selectedInventory.Location)
+	assert.Equal(t, "Work Laptop", updatedInventory.Name)
+	assert.NotNil(t, updatedInventory.PurchaseDate)
+	assert.Equal(t, 4, updatedInventory.UsefulLifeYears)
+	assert.Equal(t, 200.0, updatedInventory.SalvageValue)
+	assert.Equal(t, db.DepreciationMethodStraightLine, updatedInventory.DepreciationMethod)
+	assert.Less(t, updatedInventory.BookValue, updatedInventory.Price)
 
 	// cleanup
-	removeInventory := []string{selectedInventory.ID}
-	db.DeleteInventory(config.CTO_USER, selectedInventory.ID, removeInventory)
-}
-
-func Test_AddNewInventory_WrongUserID(t *testing.T) {
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
-	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
-	w := httptest.NewRecorder()
-	config.PreloadAllTestVariables()
-	AddNewInventory(w, req, config.CTO_USER)
-	res := w.Result()
-
-	assert.Equal(t, 400, res.StatusCode)
-	assert.Equal(t, "400 Bad Request", res.Status)
-}
-
-func Test_AddNewInventory_NoUserID(t *testing.T) {
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
-	req = mux.SetURLVars(req, map[string]string{"id": ""})
-	w := httptest.NewRecorder()
-	config.PreloadAllTestVariables()
-	AddNewInventory(w, req, config.CTO_USER)
-	res := w.Result()
-
-	assert.Equal(t, 400, res.StatusCode)
-	assert.Equal(t, "400 Bad Request", res.Status)
-}
-
-func Test_AddNewInventory_IncorrectUserID(t *testing.T) {
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
-	req = mux.SetURLVars(req, map[string]string{"id": "request"})
-	w := httptest.NewRecorder()
-	config.PreloadAllTestVariables()
-	AddNewInventory(w, req, config.CTO_USER)
-	res := w.Result()
-
-	assert.Equal(t, 400, res.StatusCode)
-	assert.Equal(t, "400 Bad Request", res.Status)
-}
-
-func Test_AddNewInventory_InvalidDBUser(t *testing.T) {
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories", nil)
-	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
-	w := httptest.NewRecorder()
-	config.PreloadAllTestVariables()
-	AddNewInventory(w, req, config.CEO_USER)
-	res := w.Result()
-
-	assert.Equal(t, 400, res.StatusCode)
-	assert.Equal(t, "400 Bad Request", res.Status)
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
 }
 
-func Test_AddNewInventory_Return_Notes(t *testing.T) {
-
+func Test_UpdateSelectedInventory_RetainsMeasurements(t *testing.T) {
 	draftUserCredentials := model.UserCredentials{
 		Email:             "admin@gmail.com",
 		Role:              "TESTER",
@@ -410,37 +1094,47 @@ func Test_AddNewInventory_Return_Notes(t *testing.T) {
 		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	draftInventoryWithReturnNotes := model.Inventory{
-		Name:           "Alexandro Kitteyy Litter",
-		Description:    "Kitty litter for a pro name game",
-		Price:          23.99,
-		Status:         "HIDDEN",
-		Barcode:        "1231231231",
-		SKU:            "1231231231",
-		Quantity:       12,
-		IsReturnable:   true,
-		ReturnLocation: "Target",
-		ReturnNotes:    "print out the return label",
-		MaxWeight:      12,
-		MinWeight:      120,
-		MaxHeight:      24,
-		MinHeight:      12,
-		Location:       "Broom Closet",
-		CreatedAt:      time.Now(),
-		CreatedBy:      prevUser.ID.String(),
-		BoughtAt:       "Walmart",
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:          "Storage Crate",
+		Description:   "Stackable plastic crate",
+		Price:         15.00,
+		Status:        "HIDDEN",
+		Barcode:       "measurement#1",
+		SKU:           "measurement#1",
+		Quantity:      1,
+		Location:      "Garage",
+		Length:        60,
+		Width:         40,
+		Height:        30,
+		Weight:        2,
+		DimensionUnit: "cm",
+		WeightUnit:    "kg",
+		CreatedAt:     time.Now(),
+		CreatedBy:     prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
 	}
+	storedInventory := *selectedInventory
 
-	// Marshal the draftEvent into JSON bytes
-	requestBody, err := json.Marshal(draftInventoryWithReturnNotes)
+	// the edit form does not send the dimensions, the weight or their units
+	selectedInventory.Name = "Stackable Storage Crate"
+	selectedInventory.Length = 0
+	selectedInventory.Width = 0
+	selectedInventory.Height = 0
+	selectedInventory.Weight = 0
+	selectedInventory.DimensionUnit = ""
+	selectedInventory.WeightUnit = ""
+
+	requestBody, err := json.Marshal(selectedInventory)
 	if err != nil {
 		t.Errorf("failed to marshal JSON: %v", err)
 	}
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
-	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
 	w := httptest.NewRecorder()
-	AddNewInventory(w, req, config.CTO_USER)
+	UpdateSelectedInventory(w, req, config.CTO_USER)
 	res := w.Result()
 	defer res.Body.Close()
 	data, err := io.ReadAll(res.Body)
@@ -449,22 +1143,24 @@ func Test_AddNewInventory_Return_Notes(t *testing.T) {
 	}
 	assert.Equal(t, 200, res.StatusCode)
 
-	var selectedInventoryWithReturnNotes model.Inventory
-	err = json.Unmarshal(data, &selectedInventoryWithReturnNotes)
+	var updatedInventory model.Inventory
+	err = json.Unmarshal(data, &updatedInventory)
 	if err != nil {
 		t.Errorf("expected error to be nil got %v", err)
 	}
-
-	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventoryWithReturnNotes.Name)
-	assert.Equal(t, "Broom Closet", selectedInventoryWithReturnNotes.Location)
+	assert.Equal(t, "Stackable Storage Crate", updatedInventory.Name)
+	assert.Equal(t, storedInventory.DimensionUnit, updatedInventory.DimensionUnit)
+	assert.Equal(t, storedInventory.WeightUnit, updatedInventory.WeightUnit)
+	assert.InDelta(t, storedInventory.Length, updatedInventory.Length, 0.01)
+	assert.InDelta(t, storedInventory.Width, updatedInventory.Width, 0.01)
+	assert.InDelta(t, storedInventory.Height, updatedInventory.Height, 0.01)
+	assert.InDelta(t, storedInventory.Weight, updatedInventory.Weight, 0.01)
 
 	// cleanup
-	removeInventory := []string{selectedInventoryWithReturnNotes.ID}
-	db.DeleteInventory(config.CTO_USER, selectedInventoryWithReturnNotes.ID, removeInventory)
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
 }
 
-func Test_UpdateSelectedInventory(t *testing.T) {
-	// profile are automatically derieved from the auth table. due to this, we attempt to create a new user
+func Test_UpdateSelectedInventory_RetainsExpiryDate(t *testing.T) {
 	draftUserCredentials := model.UserCredentials{
 		Email:             "admin@gmail.com",
 		Role:              "TESTER",
@@ -477,30 +1173,37 @@ func Test_UpdateSelectedInventory(t *testing.T) {
 		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	draftInventory := model.Inventory{
-		Name:        "Alexandro Kitteyy Litter",
-		Description: "Kitty litter for a pro name game",
-		Price:       23.99,
+	expiryDate := time.Now().AddDate(0, 0, 10)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Fire Extinguisher",
+		Description: "extinguisher in the kitchen",
+		Price:       45.00,
 		Status:      "HIDDEN",
-		Barcode:     "1231231231",
-		SKU:         "1231231231",
-		Quantity:    12,
-		Location:    "Broom Closet",
+		Barcode:     "expiry#1",
+		SKU:         "expiry#1",
+		Quantity:    1,
+		Location:    "Kitchen",
+		ExpiryDate:  &expiryDate,
 		CreatedAt:   time.Now(),
 		CreatedBy:   prevUser.ID.String(),
-		BoughtAt:    "Walmart",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
 	}
 
-	// Marshal the draftEvent into JSON bytes
-	requestBody, err := json.Marshal(draftInventory)
+	// the edit form does not send the expiry date
+	selectedInventory.Name = "Kitchen Fire Extinguisher"
+	selectedInventory.ExpiryDate = nil
+
+	requestBody, err := json.Marshal(selectedInventory)
 	if err != nil {
 		t.Errorf("failed to marshal JSON: %v", err)
 	}
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories", draftUserCredentials.ID.String()), bytes.NewBuffer(requestBody))
-	req = mux.SetURLVars(req, map[string]string{"id": draftUserCredentials.ID.String()})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
 	w := httptest.NewRecorder()
-	AddNewInventory(w, req, config.CTO_USER)
+	UpdateSelectedInventory(w, req, config.CTO_USER)
 	res := w.Result()
 	defer res.Body.Close()
 	data, err := io.ReadAll(res.Body)
@@ -509,51 +1212,93 @@ func Test_UpdateSelectedInventory(t *testing.T) {
 	}
 	assert.Equal(t, 200, res.StatusCode)
 
-	var selectedInventory model.Inventory
-	err = json.Unmarshal(data, &selectedInventory)
+	var updatedInventory model.Inventory
+	err = json.Unmarshal(data, &updatedInventory)
 	if err != nil {
 		t.Errorf("expected error to be nil got %v", err)
 	}
+	assert.Equal(t, "Kitchen Fire Extinguisher", updatedInventory.Name)
+	if assert.NotNil(t, updatedInventory.ExpiryDate) {
+		assert.Equal(t, expiryDate.Format(time.DateOnly), updatedInventory.ExpiryDate.Format(time.DateOnly))
+	}
 
-	assert.Equal(t, "Alexandro Kitteyy Litter", selectedInventory.Name)
-	assert.Equal(t, "Broom Closet", selectedInventory.Location)
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
 
-	// test to perform update but with consistent storage location name
-	// TODO: write another test that checks two different inserts into storage locations as
-	// this only tests updating the found name.
+func Test_UpdateSelectedInventory_IfMatch(t *testing.T) {
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
 
-	selectedInventory.Name = "Alexandro Kitty Litter"
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
 
-	// Marshal the draftEvent into JSON bytes
-	requestBody, err = json.Marshal(selectedInventory)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Cordless Drill",
+		Description: "18V drill with two batteries",
+		Price:       89.00,
+		Status:      "HIDDEN",
+		Barcode:     "ifmatch#1",
+		SKU:         "ifmatch#1",
+		Quantity:    1,
+		Location:    "Garage",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	GetInventoryByID(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	selectedInventory.Name = "Cordless Drill 18V"
+	requestBody, err := json.Marshal(selectedInventory)
 	if err != nil {
 		t.Errorf("failed to marshal JSON: %v", err)
 	}
 
 	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
 	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	req.Header.Set("If-Match", etag)
 	w = httptest.NewRecorder()
 	UpdateSelectedInventory(w, req, config.CTO_USER)
 	res = w.Result()
 	defer res.Body.Close()
-	data, err = io.ReadAll(res.Body)
-	if err != nil {
-		t.Errorf("expected error to be nil got %v", err)
-	}
 	assert.Equal(t, 200, res.StatusCode)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
 
-	var updatedInventory model.Inventory
-	err = json.Unmarshal(data, &updatedInventory)
+	// the update is rejected as it is based on the state before the first update
+	selectedInventory.Name = "Cordless Drill 12V"
+	requestBody, err = json.Marshal(selectedInventory)
 	if err != nil {
-		t.Errorf("expected error to be nil got %v", err)
+		t.Errorf("failed to marshal JSON: %v", err)
 	}
 
-	assert.Equal(t, "Alexandro Kitty Litter", updatedInventory.Name)
-	assert.Equal(t, "Broom Closet", updatedInventory.Location)
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	UpdateSelectedInventory(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 412, res.StatusCode)
 
-	// cleanup fn
-	removeInventory := []string{selectedInventory.ID}
-	db.DeleteInventory(config.CTO_USER, selectedInventory.ID, removeInventory)
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
 }
 
 func Test_UpdateSelectedInventory_WrongUserID(t *testing.T) {
@@ -918,6 +1663,128 @@ func Test_UpdateAssetColumn_NoUserID(t *testing.T) {
 	assert.Equal(t, "400 Bad Request", res.Status)
 }
 
+func Test_PatchSelectedInventory(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Circular Saw",
+		Description: "7-1/4 inch blade",
+		Price:       120.00,
+		Status:      "HIDDEN",
+		Barcode:     "patch#1",
+		SKU:         "patch#1",
+		Quantity:    1,
+		Location:    "Garage",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	GetInventoryByID(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/profile/%s/inventories/%s", prevUser.ID.String(), selectedInventory.ID), bytes.NewBufferString(`{"price": 99.5, "quantity": 3, "description": null}`))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+
+	var patchedInventory model.Inventory
+	err = json.Unmarshal(data, &patchedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "Circular Saw", patchedInventory.Name)
+	assert.Equal(t, 99.5, patchedInventory.Price)
+	assert.Equal(t, 3, patchedInventory.Quantity)
+	assert.Equal(t, "", patchedInventory.Description)
+
+	// the patch is rejected as it is based on the state before the first patch
+	req = httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/profile/%s/inventories/%s", prevUser.ID.String(), selectedInventory.ID), bytes.NewBufferString(`{"price": 10}`))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 412, res.StatusCode)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
+
+func Test_PatchSelectedInventory_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBufferString(`{"price": 10}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_PatchSelectedInventory_ReadOnlyField(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBufferString(`{"created_by": "0802c692-b8e2-4824-a870-e52f4a0cccf8"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_PatchSelectedInventory_UnsupportedContentType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBufferString(`price=10`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 415, res.StatusCode)
+}
+
+func Test_PatchSelectedInventory_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBufferString(`{"price": 10}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	PatchSelectedInventory(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
 func Test_RemoveSelectedInventory(t *testing.T) {
 	// profile are automatically derieved from the auth table. due to this, we attempt to create a new user
 	draftUserCredentials := model.UserCredentials{
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxAttachmentSizeInBytes is the largest document that can be attached to an asset
+const maxAttachmentSizeInBytes = 25 << 20
+
+// GetInventoryAttachments ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/attachments InventoryAttachments getInventoryAttachments
+//
+// # Retrieves the metadata of all documents attached to the selected asset. Most recent attachments are returned first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []InventoryAttachment
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryAttachments(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve attachments with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to retrieve attachments with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryAttachments(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve attachments", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddInventoryAttachment ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/attachments InventoryAttachments addInventoryAttachment
+//
+// # Attaches a document such as a receipt, manual, warranty card or invoice to the selected asset. The document is
+// uploaded as multipart form data under attachment and the type of the document is passed in as type.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: type
+//     in: formData
+//     description: The type of the document. One of receipt, manual, warranty, invoice or other
+//     required: true
+//     type: string
+//   - +name: attachment
+//     in: formData
+//     description: The document to attach to the selected asset
+//     required: true
+//     type: file
+//
+// Responses:
+// 200: InventoryAttachment
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func AddInventoryAttachment(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add attachment with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to add attachment with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, maxAttachmentSizeInBytes)
+	file, header, err := r.FormFile("attachment")
+	if err != nil {
+		config.Log("Unable to retrieve file", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer file.Close()
+
+	resp, err := db.AddInventoryAttachment(user, userID, invID, r.FormValue("type"), file, header)
+	if err != nil {
+		config.Log("Unable to add attachment", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// DownloadInventoryAttachment ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/attachments/{attachmentID} InventoryAttachments downloadInventoryAttachment
+//
+// # Downloads the selected document attached to the selected asset with the original filename and content type.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: attachmentID
+//     in: path
+//     description: The id of the selected attachment
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func DownloadInventoryAttachment(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	attachmentID := vars["attachmentID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to download attachment with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to download attachment with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(attachmentID); err != nil {
+		config.Log("Unable to download attachment with invalid attachment id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	attachment, content, err := db.RetrieveInventoryAttachmentContent(user, userID, invID, attachmentID)
+	if err != nil {
+		config.Log("Unable to download attachment", err)
+		if errors.Is(err, sql.ErrNoRows) || err.Error() == "NoSuchKey" {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", attachment.ContentType)
+	rw.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	rw.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(content)
+}
+
+// RemoveInventoryAttachment ...
+// swagger:route DELETE /api/v1/profile/{id}/inventories/{invID}/attachments/{attachmentID} InventoryAttachments removeInventoryAttachment
+//
+// # Removes the selected document attached to the selected asset along with the document in the bucket.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: attachmentID
+//     in: path
+//     description: The id of the selected attachment
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveInventoryAttachment(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	attachmentID := vars["attachmentID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove attachment with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to remove attachment with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(attachmentID); err != nil {
+		config.Log("Unable to remove attachment with invalid attachment id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveInventoryAttachment(user, userID, invID, attachmentID)
+	if err != nil {
+		config.Log("Unable to remove attachment", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(attachmentID)
+}
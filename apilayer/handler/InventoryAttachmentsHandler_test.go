@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddInventoryAttachment_GetInventoryAttachments(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Air Fryer",
+		Description: "Air fryer used in the kitchen",
+		Price:       89.99,
+		Status:      "HIDDEN",
+		Barcode:     "attachment#1231231231",
+		SKU:         "attachment#1231231231",
+		Quantity:    1,
+		Location:    "Kitchen",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+		BoughtAt:    "Costco",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	content := []byte("receipt of the air fryer")
+	body, contentType := buildAttachmentRequestBody(t, db.InventoryAttachmentTypeReceipt, "receipt.txt", content)
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/attachments", prevUser.ID.String(), selectedInventory.ID), body)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	AddInventoryAttachment(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var attachment model.InventoryAttachment
+	err = json.Unmarshal(data, &attachment)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "receipt.txt", attachment.FileName)
+	assert.Equal(t, db.InventoryAttachmentTypeReceipt, attachment.AttachmentType)
+	assert.Equal(t, int64(len(content)), attachment.SizeInBytes)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/attachments", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	GetInventoryAttachments(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var attachments []model.InventoryAttachment
+	err = json.Unmarshal(data, &attachments)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 1, len(attachments))
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/attachments/%s", prevUser.ID.String(), selectedInventory.ID, attachment.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "attachmentID": attachment.ID})
+	w = httptest.NewRecorder()
+	DownloadInventoryAttachment(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, content, data)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/attachments/%s", prevUser.ID.String(), selectedInventory.ID, attachment.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "attachmentID": attachment.ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryAttachment(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetInventoryAttachments_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/attachments", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryAttachments(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryAttachments_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/attachments", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryAttachments(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryAttachment_InvalidAttachmentType(t *testing.T) {
+	body, contentType := buildAttachmentRequestBody(t, "photo", "photo.txt", []byte("photo"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/attachments", body)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryAttachment(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_DownloadInventoryAttachment_InvalidAttachmentID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/attachments/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "attachmentID": "1"})
+	w := httptest.NewRecorder()
+	DownloadInventoryAttachment(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+// buildAttachmentRequestBody ...
+//
+// builds the multipart form used to upload the selected attachment
+func buildAttachmentRequestBody(t *testing.T, attachmentType string, fileName string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("type", attachmentType); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	part, err := writer.CreateFormFile("attachment", fileName)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+)
+
+// BulkEditInventories ...
+// swagger:route POST /api/v1/profile/{id}/inventories/bulk/edit Assets bulkEditInventories
+//
+// # Applies the selected operations to many inventories at once. Inventories are selected either by ids or by the
+// same filter as the inventory list. Each operation either sets the field, increments the field by a percentage or
+// clears the field. Changes are applied only if every inventory is updated, otherwise nothing is changed and the
+// result of each inventory explains what failed.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: InventoryBulkEditRequest
+//     in: body
+//     description: The selected inventories and the operations to apply to them
+//     type: InventoryBulkEditRequest
+//     required: true
+//
+// Responses:
+// 200: InventoryBulkEditResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: InventoryBulkEditResponse
+// 500: MessageResponse
+func BulkEditInventories(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to bulk edit inventories with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftBulkEdit model.InventoryBulkEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftBulkEdit); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.BulkEditInventory(user, userID, draftBulkEdit)
+	if err != nil {
+		config.Log("Unable to bulk edit inventories", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	if !resp.IsApplied {
+		rw.WriteHeader(http.StatusConflict)
+		json.NewEncoder(rw).Encode(resp)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
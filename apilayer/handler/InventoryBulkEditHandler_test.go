@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BulkEditInventories(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var selectedIDs []string
+	for i := 0; i < 3; i++ {
+		selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+			Name:        fmt.Sprintf("Extension Cord %d", i),
+			Description: "25 ft outdoor cord",
+			Price:       20.00,
+			Status:      "HIDDEN",
+			Barcode:     fmt.Sprintf("bulkedit#%d", i),
+			SKU:         fmt.Sprintf("bulkedit#%d", i),
+			Quantity:    2,
+			Location:    "Garage",
+			CreatedAt:   time.Now(),
+			CreatedBy:   prevUser.ID.String(),
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		selectedIDs = append(selectedIDs, selectedInventory.ID)
+	}
+
+	draftBulkEdit := model.InventoryBulkEditRequest{
+		IDs: selectedIDs,
+		Operations: []model.InventoryBulkEditOperation{
+			{Field: "status", Operation: db.InventoryBulkEditOperationSet, Value: "DRAFT"},
+			{Field: "price", Operation: db.InventoryBulkEditOperationIncrementPercent, Value: 10},
+			{Field: "description", Operation: db.InventoryBulkEditOperationClear},
+		},
+	}
+	requestBody, err := json.Marshal(draftBulkEdit)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/bulk/edit", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var resp model.InventoryBulkEditResponse
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.True(t, resp.IsApplied)
+	assert.Equal(t, 3, resp.UpdatedCount)
+
+	updatedInventory, err := db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedIDs[0])
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "DRAFT", updatedInventory.Status)
+	assert.Equal(t, 22.00, updatedInventory.Price)
+	assert.Equal(t, "", updatedInventory.Description)
+
+	// an unknown asset rolls back the changes of every other asset
+	draftBulkEdit.IDs = append(selectedIDs, "0802c692-b8e2-4824-a870-e52f4a0cccf8")
+	draftBulkEdit.Operations = []model.InventoryBulkEditOperation{
+		{Field: "quantity", Operation: db.InventoryBulkEditOperationSet, Value: 7},
+	}
+	requestBody, err = json.Marshal(draftBulkEdit)
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/bulk/edit", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	updatedInventory, err = db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedIDs[0])
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 2, updatedInventory.Quantity)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), selectedIDs)
+}
+
+func Test_BulkEditInventories_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile//inventories/bulk/edit", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_BulkEditInventories_InvalidOperation(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryBulkEditRequest{
+		IDs: []string{"0802c692-b8e2-4824-a870-e52f4a0cccf8"},
+		Operations: []model.InventoryBulkEditOperation{
+			{Field: "status", Operation: db.InventoryBulkEditOperationIncrementPercent, Value: 10},
+		},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/bulk/edit", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_BulkEditInventories_IDsAndFilter(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryBulkEditRequest{
+		IDs:    []string{"0802c692-b8e2-4824-a870-e52f4a0cccf8"},
+		Filter: &model.InventoryListParams{Statuses: []string{"HIDDEN"}},
+		Operations: []model.InventoryBulkEditOperation{
+			{Field: "status", Operation: db.InventoryBulkEditOperationSet, Value: "DRAFT"},
+		},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/bulk/edit", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_BulkEditInventories_InvalidDBUser(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryBulkEditRequest{
+		IDs: []string{"0802c692-b8e2-4824-a870-e52f4a0cccf8"},
+		Operations: []model.InventoryBulkEditOperation{
+			{Field: "status", Operation: db.InventoryBulkEditOperationSet, Value: "DRAFT"},
+		},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/bulk/edit", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	BulkEditInventories(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryDuplicates ...
+// swagger:route GET /api/v1/profile/{id}/inventories/duplicates InventoryDuplicates getInventoryDuplicates
+//
+// # Retrieves pairs of assets that are likely to be duplicates. Pairs are scored from 0 to 1 by matching barcode,
+// matching sku and the similarity of the names, with the highest score first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: minScore
+//     in: query
+//     description: The minimum score of the returned pairs, between 0 and 1. Defaults to 0.6
+//     required: false
+//     type: number
+//   - +name: limit
+//     in: query
+//     description: The maximum number of returned pairs. Defaults to 50
+//     required: false
+//     type: integer
+//
+// Responses:
+// 200: []InventoryDuplicateCandidate
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryDuplicates(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve duplicate assets with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var minScore float64
+	if draftMinScore := r.URL.Query().Get("minScore"); len(draftMinScore) > 0 {
+		parsedMinScore, err := strconv.ParseFloat(draftMinScore, 64)
+		if err != nil || parsedMinScore < 0 || parsedMinScore > 1 {
+			config.Log("Unable to retrieve duplicate assets with invalid minimum score", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		minScore = parsedMinScore
+	}
+
+	var limit int
+	if draftLimit := r.URL.Query().Get("limit"); len(draftLimit) > 0 {
+		parsedLimit, err := strconv.Atoi(draftLimit)
+		if err != nil || parsedLimit < 0 {
+			config.Log("Unable to retrieve duplicate assets with invalid limit", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	resp, err := db.RetrieveInventoryDuplicates(user, userID, minScore, limit)
+	if err != nil {
+		config.Log("Unable to retrieve duplicate assets", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// MergeInventory ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/merge InventoryDuplicates mergeInventory
+//
+// # Merges the selected duplicate into the selected asset. The quantity of the duplicate is added to the asset and
+// categories, maintenance plans, attachments, loans, units, child assets and the image of the duplicate are moved to the asset
+// in a single transaction. The duplicate is removed once it is merged.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the surviving asset
+//     required: true
+//     type: string
+//   - +name: InventoryMergeRequest
+//     in: body
+//     description: The duplicate that is merged into the surviving asset
+//     type: InventoryMergeRequest
+//     required: true
+//
+// Responses:
+// 200: Inventory
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func MergeInventory(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to merge assets with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to merge assets with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftMerge model.InventoryMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftMerge); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(draftMerge.DuplicateID); err != nil {
+		config.Log("Unable to merge assets with invalid duplicate id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.MergeInventory(user, userID, invID, draftMerge.DuplicateID)
+	if err != nil {
+		config.Log("Unable to merge assets", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.InventoryParentCycle || err.Error() == db.InventoryMergeOnLoan || err.Error() == db.DuplicateBarcodeOrSKU || err.Error() == db.DuplicateInventoryUnit {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
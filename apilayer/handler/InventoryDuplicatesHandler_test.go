@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetInventoryDuplicates_MergeInventory(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var selectedInventories []*model.Inventory
+	for i, v := range []struct {
+		name     string
+		quantity int
+	}{
+		{"Cordless Drill 18V", 2},
+		{"Cordless Drill 18V", 3},
+	} {
+		selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+			Name:      v.name,
+			Price:     80.00,
+			Status:    "HIDDEN",
+			Barcode:   fmt.Sprintf("duplicate#%d", i),
+			Quantity:  v.quantity,
+			Location:  "Garage",
+			CreatedAt: time.Now(),
+			CreatedBy: prevUser.ID.String(),
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		selectedInventories = append(selectedInventories, selectedInventory)
+	}
+	survivor, duplicate := selectedInventories[0], selectedInventories[1]
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/duplicates?minScore=0.9", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	GetInventoryDuplicates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var candidates []model.InventoryDuplicateCandidate
+	err = json.Unmarshal(data, &candidates)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	isFound := false
+	for _, v := range candidates {
+		if v.Inventory.ID == survivor.ID && v.Duplicate.ID == duplicate.ID {
+			isFound = true
+			assert.Equal(t, 1.0, v.Score)
+			assert.Contains(t, v.Reasons, db.InventoryDuplicateReasonName)
+		}
+	}
+	assert.True(t, isFound)
+
+	requestBody, err := json.Marshal(model.InventoryMergeRequest{DuplicateID: duplicate.ID})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/merge", prevUser.ID.String(), survivor.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": survivor.ID})
+	w = httptest.NewRecorder()
+	MergeInventory(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var merged model.Inventory
+	err = json.Unmarshal(data, &merged)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, survivor.ID, merged.ID)
+	assert.Equal(t, 5, merged.Quantity)
+
+	// the duplicate is removed once it is merged
+	_, err = db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), duplicate.ID)
+	assert.NotNil(t, err)
+
+	// cleanup
+	removeInventory := []string{survivor.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetInventoryDuplicates_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/duplicates", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	GetInventoryDuplicates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryDuplicates_InvalidMinScore(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/duplicates?minScore=1.5", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryDuplicates(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryDuplicates_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/duplicates", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryDuplicates(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_MergeInventory_InvalidDuplicateID(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryMergeRequest{DuplicateID: "invalid"})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/merge", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	MergeInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_MergeInventory_SelfMerge(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryMergeRequest{DuplicateID: "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/merge", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	MergeInventory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_MergeInventory_InvalidDBUser(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryMergeRequest{DuplicateID: "1802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/merge", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	MergeInventory(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetExpiringInventories ...
+// swagger:route GET /api/v1/profile/{id}/inventories/expiring Inventories getExpiringInventories
+//
+// # Retrieves the inventories and batches that expire within the selected number of days with the earliest
+// expiry date first. Inventories and batches that have already expired are included.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: days
+//     in: query
+//     description: The number of days from today. Defaults to 30, max 3650.
+//     type: integer
+//     required: false
+//
+// Responses:
+// 200: []ExpiringInventory
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetExpiringInventories(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve expiring inventories with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var days int
+	if draftDays := r.URL.Query().Get("days"); len(draftDays) > 0 {
+		parsedDays, err := strconv.Atoi(draftDays)
+		if err != nil || parsedDays <= 0 {
+			config.Log("Unable to retrieve expiring inventories with invalid days", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		days = parsedDays
+	}
+
+	resp, err := db.RetrieveExpiringInventories(user, userID, days)
+	if err != nil {
+		config.Log("Unable to retrieve expiring inventories", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetInventoryBatches ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/batches Inventories getInventoryBatches
+//
+// # Retrieves the batches of the selected asset with the earliest expiry date first
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []InventoryBatch
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryBatches(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve batches with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to retrieve batches with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryBatches(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve batches", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddInventoryBatch ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/batches Inventories addInventoryBatch
+//
+// # Adds a new batch to the selected asset. Lot numbers are unique for each asset regardless of case.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: InventoryBatch
+//     in: body
+//     description: The lot number, quantity and expiry date of the batch
+//     type: InventoryBatch
+//     required: true
+//
+// Responses:
+// 200: InventoryBatch
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func AddInventoryBatch(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add batch with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to add batch with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftBatch model.InventoryBatch
+	if err := json.NewDecoder(r.Body).Decode(&draftBatch); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddInventoryBatch(user, userID, invID, draftBatch)
+	if err != nil {
+		config.Log("Unable to add batch", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateInventoryBatch {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateInventoryBatch ...
+// swagger:route PUT /api/v1/profile/{id}/inventories/{invID}/batches/{batchID} Inventories updateInventoryBatch
+//
+// # Updates the lot number, quantity, expiry date and notes of the selected batch
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: batchID
+//     in: path
+//     description: The id of the selected batch
+//     type: string
+//     required: true
+//   - +name: InventoryBatch
+//     in: body
+//     description: The lot number, quantity and expiry date of the batch
+//     type: InventoryBatch
+//     required: true
+//
+// Responses:
+// 200: InventoryBatch
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateInventoryBatch(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	batchID := vars["batchID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update batch with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to update batch with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(batchID); err != nil {
+		config.Log("Unable to update batch with invalid batch id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftBatch model.InventoryBatch
+	if err := json.NewDecoder(r.Body).Decode(&draftBatch); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateInventoryBatch(user, userID, invID, batchID, draftBatch)
+	if err != nil {
+		config.Log("Unable to update batch", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateInventoryBatch {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveInventoryBatch ...
+// swagger:route DELETE /api/v1/profile/{id}/inventories/{invID}/batches/{batchID} Inventories removeInventoryBatch
+//
+// # Removes the selected batch from the selected asset
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: batchID
+//     in: path
+//     description: The id of the selected batch
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveInventoryBatch(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	batchID := vars["batchID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove batch with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to remove batch with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(batchID); err != nil {
+		config.Log("Unable to remove batch with invalid batch id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveInventoryBatch(user, userID, invID, batchID)
+	if err != nil {
+		config.Log("Unable to remove batch", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(batchID)
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InventoryExpiry(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	expiryDate := time.Now().AddDate(0, 0, 10)
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "First Aid Kit",
+		Description: "kit in the garage",
+		Price:       25.00,
+		Status:      "HIDDEN",
+		Barcode:     "inventory-expiry#1",
+		SKU:         "inventory-expiry#1",
+		Quantity:    4,
+		Location:    "Garage",
+		ExpiryDate:  &expiryDate,
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	requestBody, err := json.Marshal(model.InventoryBatch{
+		LotNumber:  "LOT-A",
+		Quantity:   2,
+		ExpiryDate: time.Now().AddDate(0, 0, 5),
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/batches", prevUser.ID.String(), selectedInventory.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	AddInventoryBatch(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedBatch model.InventoryBatch
+	err = json.Unmarshal(data, &selectedBatch)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "LOT-A", selectedBatch.LotNumber)
+
+	// lot numbers are unique for each asset regardless of case
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/batches", prevUser.ID.String(), selectedInventory.ID), bytes.NewBuffer(bytes.Replace(requestBody, []byte("LOT-A"), []byte("lot-a"), 1)))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	AddInventoryBatch(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/expiring?days=30", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetExpiringInventories(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var expiringInventories []model.ExpiringInventory
+	err = json.Unmarshal(data, &expiringInventories)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var foundInventory, foundBatch bool
+	for _, v := range expiringInventories {
+		if v.ItemID != selectedInventory.ID {
+			continue
+		}
+		if v.BatchID == selectedBatch.ID {
+			foundBatch = true
+		} else if len(v.BatchID) == 0 {
+			foundInventory = true
+		}
+	}
+	assert.True(t, foundInventory)
+	assert.True(t, foundBatch)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/notifications", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetNotifications(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var notifications []model.MaintenanceAlertNotifications
+	err = json.Unmarshal(data, &notifications)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var expiryNotifications int
+	for _, v := range notifications {
+		if v.Type == db.ExpiryNotificationType && v.ItemID == selectedInventory.ID {
+			expiryNotifications++
+		}
+	}
+	assert.Equal(t, 2, expiryNotifications)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/batches/%s", prevUser.ID.String(), selectedInventory.ID, selectedBatch.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "batchID": selectedBatch.ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryBatch(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
+
+func Test_GetExpiringInventories_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/expiring", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	GetExpiringInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetExpiringInventories_InvalidDays(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/expiring?days=soon", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetExpiringInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetExpiringInventories_DaysOutOfRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/expiring?days=5000", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetExpiringInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryBatch_EmptyLotNumber(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryBatch{Quantity: 1, ExpiryDate: time.Now()})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/batches", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryBatch(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryBatch_MissingExpiryDate(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryBatch{LotNumber: "LOT-A", Quantity: 1})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/batches", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryBatch(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_UpdateInventoryBatch_InvalidBatchID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/batches/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "batchID": "1"})
+	w := httptest.NewRecorder()
+	UpdateInventoryBatch(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryBatches_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/batches", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryBatches(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/utils"
+	"github.com/gorilla/mux"
+)
+
+const (
+	inventoryExportFormatNDJSON = "ndjson"
+
+	// inventoryExportListSeparator joins the categories and maintenance plans of an asset into a single cell
+	inventoryExportListSeparator = "; "
+)
+
+// inventoryExportContentTypes are the supported formats of the export along with the content type of each format
+var inventoryExportContentTypes = map[string]string{
+	utils.SpreadsheetFileTypeCSV:  "text/csv; charset=utf-8",
+	utils.SpreadsheetFileTypeXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	inventoryExportFormatNDJSON:   "application/x-ndjson",
+}
+
+// inventoryExportColumns are the headers of the exported csv and xlsx files. The headers match the fields of
+// RawInventory so that the exported file can be uploaded again without a column mapping.
+var inventoryExportColumns = []interface{}{
+	"name", "description", "price", "currency", "quantity", "Storage Location", "color", "sku", "barcode",
+	"Purchase Location", "Maximum Weight", "Minimum Weight", "Maximum Height", "Minimum Height", "Length", "Width",
+	"Height", "Weight", "Categories", "Maintenance Plans",
+}
+
+// ExportInventories ...
+// swagger:route GET /api/v1/profile/{id}/inventories/export Assets exportInventories
+//
+// # Streams every asset of the selected user as a csv, xlsx or ndjson file. The columns match the bulk upload so that
+// the file can be imported again; the categories and maintenance plans of each asset are exported alongside. Supports
+// the same sort and filter query parameters as the list of assets, limit, offset and cursor are ignored.
+//
+// // Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: format
+//     in: query
+//     description: The format of the exported file. One of csv, xlsx, ndjson. Defaults to csv.
+//     required: false
+//     type: string
+//   - +name: sortBy
+//     in: query
+//     description: The column to sort against. One of name, price, quantity, status, location, sku, barcode, created_at, updated_at. Defaults to updated_at.
+//     required: false
+//     type: string
+//   - +name: sortOrder
+//     in: query
+//     description: The direction of the sort. One of asc, desc. Defaults to desc.
+//     required: false
+//     type: string
+//   - +name: status
+//     in: query
+//     description: The comma separated list of statuses to filter against.
+//     required: false
+//     type: string
+//   - +name: storageLocationID
+//     in: query
+//     description: The comma separated list of storage location ids to filter against.
+//     required: false
+//     type: string
+//   - +name: catID
+//     in: query
+//     description: The category id that the assets must belong to.
+//     required: false
+//     type: string
+//   - +name: mID
+//     in: query
+//     description: The maintenance plan id that the assets must belong to.
+//     required: false
+//     type: string
+//   - +name: tag
+//     in: query
+//     description: The comma separated list of tag ids. The assets must hold every selected tag.
+//     required: false
+//     type: string
+//
+// Responses:
+// 200: []InventoryExport
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func ExportInventories(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to export assets with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if len(format) == 0 {
+		format = utils.SpreadsheetFileTypeCSV
+	}
+	contentType, ok := inventoryExportContentTypes[format]
+	if !ok {
+		config.Log("Unable to export assets with unsupported format", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	listParams, err := parseInventoryListParams(r)
+	if err != nil {
+		config.Log("Unable to parse query parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	listParams.Limit, listParams.Offset, listParams.Cursor = 0, 0, ""
+
+	// the response is only started once the first asset is retrieved so that errors from the db can still be
+	// returned with the status code
+	isStarted, err := writeInventoryExport(user, userID, *listParams, format, rw, func() {
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", inventoryExportFileName(format)))
+		rw.WriteHeader(http.StatusOK)
+	}, nil)
+	if err != nil {
+		if !isStarted {
+			config.Log("Unable to export assets", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		// the status code is already sent, the file is left incomplete
+		config.Log("Unable to complete export of assets", err)
+	}
+}
+
+// writeInventoryExport ...
+//
+// writes the assets of the selected user to w in the selected format. start is called once before anything is
+// written to w and exported is called with the number of written assets and the total count of assets after each
+// asset; both are optional.
+// Returns true when anything is written to w so that the caller knows if errors can still be reported.
+func writeInventoryExport(user string, userID string, listParams model.InventoryListParams, format string, w io.Writer, start func(), exported func(count int, totalCount int) error) (bool, error) {
+	var encoder *json.Encoder
+	var spreadsheetWriter utils.SpreadsheetWriter
+	isStarted := false
+	startExport := func() error {
+		isStarted = true
+		if start != nil {
+			start()
+		}
+
+		if format == inventoryExportFormatNDJSON {
+			encoder = json.NewEncoder(w)
+			return nil
+		}
+		var err error
+		spreadsheetWriter, err = utils.NewSpreadsheetWriter(format, w)
+		if err != nil {
+			return err
+		}
+		return spreadsheetWriter.WriteRow(inventoryExportColumns)
+	}
+
+	count := 0
+	err := db.ExportInventories(user, userID, listParams, func(draftInventory model.InventoryExport, totalCount int) error {
+		if !isStarted {
+			if err := startExport(); err != nil {
+				return err
+			}
+		}
+		var err error
+		if encoder != nil {
+			err = encoder.Encode(draftInventory)
+		} else {
+			err = spreadsheetWriter.WriteRow(inventoryExportValues(draftInventory))
+		}
+		if err != nil {
+			return err
+		}
+		count++
+		if exported != nil {
+			return exported(count, totalCount)
+		}
+		return nil
+	})
+	if err != nil {
+		return isStarted, err
+	}
+
+	if !isStarted {
+		if err := startExport(); err != nil {
+			return isStarted, err
+		}
+	}
+	if spreadsheetWriter != nil {
+		return isStarted, spreadsheetWriter.Close()
+	}
+	return isStarted, nil
+}
+
+// inventoryExportFileName ...
+//
+// returns the name of the exported file of the selected format
+func inventoryExportFileName(format string) string {
+	return fmt.Sprintf("inventories-%s.%s", time.Now().Format("2006-01-02"), format)
+}
+
+// inventoryExportValues ...
+//
+// returns the cells of the selected asset in the same order as inventoryExportColumns
+func inventoryExportValues(draftInventory model.InventoryExport) []interface{} {
+	return []interface{}{
+		draftInventory.Name,
+		draftInventory.Description,
+		draftInventory.Price,
+		draftInventory.Currency,
+		draftInventory.Quantity,
+		draftInventory.StorageLocation,
+		draftInventory.Color,
+		draftInventory.SKU,
+		draftInventory.Barcode,
+		draftInventory.PurchaseLocation,
+		string(draftInventory.MaximumWeight),
+		string(draftInventory.MinimumWeight),
+		string(draftInventory.MaximumHeight),
+		string(draftInventory.MinimumHeight),
+		string(draftInventory.Length),
+		string(draftInventory.Width),
+		string(draftInventory.Height),
+		string(draftInventory.Weight),
+		strings.Join(draftInventory.Categories, inventoryExportListSeparator),
+		strings.Join(draftInventory.MaintenancePlans, inventoryExportListSeparator),
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/utils"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExportInventories(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	for _, format := range []string{utils.SpreadsheetFileTypeCSV, utils.SpreadsheetFileTypeXLSX} {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/export?format=%s&sortBy=name&sortOrder=asc", prevUser.ID.String(), format), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+		w := httptest.NewRecorder()
+		ExportInventories(w, req, config.CTO_USER)
+		res := w.Result()
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Equal(t, inventoryExportContentTypes[format], res.Header.Get("Content-Type"))
+
+		// the exported file is read the same way as an uploaded file
+		rows, err := utils.ReadSpreadsheet(format, data, 0)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		assert.Equal(t, len(inventoryExportColumns), len(rows[0]))
+		assert.Equal(t, "Storage Location", rows[0][5])
+		assert.Equal(t, "Maintenance Plans", rows[0][19])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/export?format=ndjson", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	ExportInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var draftInventory model.InventoryExport
+		err := json.Unmarshal(scanner.Bytes(), &draftInventory)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		assert.NotEmpty(t, draftInventory.Name)
+		assert.NotNil(t, draftInventory.Categories)
+	}
+}
+
+func Test_ExportInventories_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/export", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	ExportInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_ExportInventories_UnsupportedFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/export?format=pdf", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	ExportInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_ExportInventories_InvalidSortColumn(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/export?sortBy=password", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	ExportInventories(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Content-Disposition"))
+}
+
+func Test_ExportInventories_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/export", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	ExportInventories(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
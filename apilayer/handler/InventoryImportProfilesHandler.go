@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryImportMappers ...
+// swagger:route GET /api/v1/profile/{id}/inventories/imports/mappers Inventories getInventoryImportMappers
+//
+// # Retrieves the supported sources of the uploaded file alongside the fields that each source detects from the header
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []InventoryImportMapper
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryImportMappers(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve import mappers with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp := db.RetrieveInventoryImportMappers()
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetInventoryImportProfiles ...
+// swagger:route GET /api/v1/profile/{id}/inventories/imports/profiles Inventories getInventoryImportProfiles
+//
+// # Retrieves the saved column mappings of the selected user sorted by name
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []InventoryImportProfile
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryImportProfiles(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve import profiles with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryImportProfiles(user, userID)
+	if err != nil {
+		config.Log("Unable to retrieve import profiles", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddInventoryImportProfile ...
+// swagger:route POST /api/v1/profile/{id}/inventories/imports/profiles Inventories addInventoryImportProfile
+//
+// # Saves a column mapping to reuse when files with the same layout are uploaded. Names are unique for each user.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: InventoryImportProfile
+//     in: body
+//     description: The name, source and column mapping of the profile
+//     type: InventoryImportProfile
+//     required: true
+//
+// Responses:
+// 200: InventoryImportProfile
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func AddInventoryImportProfile(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add import profile with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftProfile model.InventoryImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&draftProfile); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddInventoryImportProfile(user, userID, draftProfile)
+	if err != nil {
+		config.Log("Unable to add import profile", err)
+		if err.Error() == db.DuplicateInventoryImportProfile {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateInventoryImportProfile ...
+// swagger:route PUT /api/v1/profile/{id}/inventories/imports/profiles/{profileID} Inventories updateInventoryImportProfile
+//
+// # Updates the name, source and column mapping of the selected profile. Imports that used the profile are not changed.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: profileID
+//     in: path
+//     description: The id of the selected profile
+//     type: string
+//     required: true
+//   - +name: InventoryImportProfile
+//     in: body
+//     description: The name, source and column mapping of the profile
+//     type: InventoryImportProfile
+//     required: true
+//
+// Responses:
+// 200: InventoryImportProfile
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateInventoryImportProfile(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	profileID := vars["profileID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update import profile with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(profileID); err != nil {
+		config.Log("Unable to update import profile with invalid profile id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftProfile model.InventoryImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&draftProfile); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateInventoryImportProfile(user, userID, profileID, draftProfile)
+	if err != nil {
+		config.Log("Unable to update import profile", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateInventoryImportProfile {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveInventoryImportProfile ...
+// swagger:route DELETE /api/v1/profile/{id}/inventories/imports/profiles/{profileID} Inventories removeInventoryImportProfile
+//
+// # Removes the selected profile. Imports that used the profile are not changed.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: profileID
+//     in: path
+//     description: The id of the selected profile
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveInventoryImportProfile(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	profileID := vars["profileID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove import profile with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(profileID); err != nil {
+		config.Log("Unable to remove import profile with invalid profile id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveInventoryImportProfile(user, userID, profileID)
+	if err != nil {
+		config.Log("Unable to remove import profile", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(profileID)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InventoryImportProfiles(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	requestBody, err := json.Marshal(model.InventoryImportProfile{
+		Name:   "Inventory Import Profile Homebox",
+		Source: db.InventoryImportSourceHomebox,
+		Mapping: map[string]string{
+			"name":       "HB.name",
+			"quantity":   "HB.quantity",
+			"location":   "HB.location",
+			"sku":        "HB.asset_id",
+			"categories": "HB.labels",
+			"color":      "HB.field.Colour",
+		},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/profiles", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedProfile model.InventoryImportProfile
+	err = json.Unmarshal(data, &selectedProfile)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.InventoryImportSourceHomebox, selectedProfile.Source)
+	assert.Equal(t, "HB.labels", selectedProfile.Mapping["categories"])
+
+	// profile names are unique regardless of case
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/profiles", prevUser.ID.String()), bytes.NewBuffer(bytes.ToLower(requestBody)))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	// columns of the profile that are missing from the file are left out
+	content := "HB.import_ref,HB.location,HB.labels,HB.quantity,HB.name,HB.asset_id\n" +
+		",Garage,Inventory Import Camping; inventory import camping;Inventory Import Outdoors,2,Inventory Import Tent,inventory-import-profiles#1\n"
+
+	requestBody2, contentType := buildInventoryImportRequestBodyWithFields(t, "homebox.csv", content, map[string]string{"profileID": selectedProfile.ID})
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports", prevUser.ID.String()), requestBody2)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedImport model.InventoryImport
+	err = json.Unmarshal(data, &selectedImport)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.InventoryImportSourceHomebox, selectedImport.Source)
+	assert.Equal(t, "HB.labels", selectedImport.Mapping["categories"])
+	assert.Equal(t, "", selectedImport.Mapping["color"])
+	assert.Equal(t, 1, selectedImport.ValidRows)
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/%s/commit", prevUser.ID.String(), selectedImport.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "importID": selectedImport.ID})
+	w = httptest.NewRecorder()
+	CommitInventoryImport(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	importedInventory, err := db.RetrieveInventoryByCode(config.CTO_USER, prevUser.ID.String(), "", "inventory-import-profiles#1")
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "Inventory Import Tent", importedInventory.Name)
+	assert.Equal(t, 2, importedInventory.Quantity)
+
+	// labels are added as categories once regardless of case
+	categories, err := db.RetrieveAllCategories(config.CTO_USER, prevUser.ID.String(), 1000)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	importedCategoryIDs := make([]string, 0)
+	for _, v := range categories {
+		if v.Name == "Inventory Import Camping" || v.Name == "Inventory Import Outdoors" {
+			importedCategoryIDs = append(importedCategoryIDs, v.ID)
+			items, err := db.RetrieveAllCategoryItems(config.CTO_USER, prevUser.ID.String(), v.ID, 1000)
+			if err != nil {
+				t.Errorf("expected error to be nil got %v", err)
+			}
+			assert.Equal(t, 1, len(items))
+			assert.Equal(t, importedInventory.ID, items[0].ItemID)
+		}
+	}
+	assert.Equal(t, 2, len(importedCategoryIDs))
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/profiles", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetInventoryImportProfiles(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/profiles/%s", prevUser.ID.String(), selectedProfile.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "profileID": selectedProfile.ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryImportProfile(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	// cleanup
+	db.RemoveInventoryImport(config.CTO_USER, prevUser.ID.String(), selectedImport.ID)
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{importedInventory.ID})
+	for _, v := range importedCategoryIDs {
+		db.RemoveCategory(config.CTO_USER, v)
+	}
+}
+
+func Test_GetInventoryImportMappers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/mappers", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryImportMappers(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var mappers []model.InventoryImportMapper
+	err = json.Unmarshal(data, &mappers)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	names := make([]string, 0, len(mappers))
+	for _, v := range mappers {
+		names = append(names, v.Name)
+		assert.Contains(t, v.Fields, "name")
+		assert.Contains(t, v.Fields, "categories")
+	}
+	assert.Equal(t, []string{
+		db.InventoryImportSourceFleetwise,
+		db.InventoryImportSourceSnipeIT,
+		db.InventoryImportSourceHomebox,
+		db.InventoryImportSourceGoogleSheets,
+	}, names)
+}
+
+func Test_AddInventoryImportProfile_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile//inventories/imports/profiles", bytes.NewBufferString(`{"name":"Snipe-IT","source":"snipeit","mapping":{"name":"Asset Name"}}`))
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImportProfile_UnknownSource(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles", bytes.NewBufferString(`{"name":"Spreadsheet","source":"spreadsheet","mapping":{"name":"Asset Name"}}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImportProfile_UnknownField(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles", bytes.NewBufferString(`{"name":"Snipe-IT","source":"snipeit","mapping":{"model":"Model No."}}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImportProfile_EmptyMapping(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles", bytes.NewBufferString(`{"name":"Snipe-IT","source":"snipeit","mapping":{"name":" "}}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_UpdateInventoryImportProfile_InvalidProfileID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles/1", bytes.NewBufferString(`{"name":"Snipe-IT","source":"snipeit","mapping":{"name":"Asset Name"}}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "profileID": "1"})
+	w := httptest.NewRecorder()
+	UpdateInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RemoveInventoryImportProfile_InvalidProfileID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "profileID": "1"})
+	w := httptest.NewRecorder()
+	RemoveInventoryImportProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryImportProfiles_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/profiles", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryImportProfiles(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
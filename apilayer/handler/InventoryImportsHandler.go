@@ -0,0 +1,432 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxInventoryImportFileSizeInBytes is the largest csv or xlsx file of assets that can be uploaded
+const maxInventoryImportFileSizeInBytes = 10 << 20
+
+// GetInventoryImports ...
+// swagger:route GET /api/v1/profile/{id}/inventories/imports Inventories getInventoryImports
+//
+// # Retrieves the imports of the selected user with the most recent import first
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []InventoryImport
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryImports(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve imports with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryImports(user, userID)
+	if err != nil {
+		config.Log("Unable to retrieve imports", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetInventoryImport ...
+// swagger:route GET /api/v1/profile/{id}/inventories/imports/{importID} Inventories getInventoryImport
+//
+// # Retrieves the selected import alongside the errors and warnings of each row
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: importID
+//     in: path
+//     description: The id of the selected import
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: InventoryImport
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryImport(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	importID := vars["importID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve import with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(importID); err != nil {
+		config.Log("Unable to retrieve import with invalid import id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryImport(user, userID, importID)
+	if err != nil {
+		config.Log("Unable to retrieve import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddInventoryImport ...
+// swagger:route POST /api/v1/profile/{id}/inventories/imports Inventories addInventoryImport
+//
+// # Uploads a csv or xlsx file of assets as a dry run. The rows are validated without adding any asset and the
+// response lists the errors and warnings of each row. The first row that is not empty is the header and the first
+// worksheet of an xlsx file is used. The import is added as pending until it is committed. Max 5000 rows. Files
+// exported from Snipe-IT, Homebox and Google Sheets are mapped by selecting the source, and a saved column mapping is
+// reused by selecting the profile.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: file
+//     in: formData
+//     description: The csv or xlsx file of assets. Max 10MB.
+//     required: true
+//     type: file
+//   - +name: mapping
+//     in: formData
+//     description: The header of the column to use for each field, eg { "name": "Item Name" }. Derived from the header when empty.
+//     required: false
+//     type: string
+//   - +name: source
+//     in: formData
+//     description: The name of the mapper of the file, eg fleetwise, snipeit, homebox or google_sheets. Defaults to fleetwise.
+//     required: false
+//     type: string
+//   - +name: profileID
+//     in: formData
+//     description: The id of the saved profile to use for the source and column mapping
+//     required: false
+//     type: string
+//
+// Responses:
+// 200: InventoryImport
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func AddInventoryImport(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add import with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, maxInventoryImportFileSizeInBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		config.Log("Unable to retrieve file", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer file.Close()
+
+	var draftMapping map[string]string
+	if mapping := r.FormValue("mapping"); len(mapping) > 0 {
+		if err := json.Unmarshal([]byte(mapping), &draftMapping); err != nil {
+			config.Log("Unable to decode column mapping", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(db.InvalidInventoryImportMapping)
+			return
+		}
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		config.Log("Unable to read file", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	fileName := filepath.Base(header.Filename)
+	fileType, err := utils.DetectSpreadsheetFileType(fileName, content)
+	if err != nil {
+		config.Log("Unable to detect file type", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	rows, err := utils.ReadSpreadsheet(fileType, content, db.MaxInventoryImportRows+1)
+	if err != nil {
+		config.Log("Unable to read spreadsheet", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	resp, err := db.AddInventoryImport(user, userID, fileName, fileType, r.FormValue("source"), r.FormValue("profileID"), rows, draftMapping)
+	if err != nil {
+		config.Log("Unable to add import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateInventoryImportMapping ...
+// swagger:route PUT /api/v1/profile/{id}/inventories/imports/{importID} Inventories updateInventoryImportMapping
+//
+// # Updates the column mapping of the selected pending import and validates its rows again. An empty mapping is derived from the header.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: importID
+//     in: path
+//     description: The id of the selected import
+//     type: string
+//     required: true
+//   - +name: InventoryImportMapping
+//     in: body
+//     description: The header of the column to use for each field
+//     type: InventoryImportMapping
+//     required: true
+//
+// Responses:
+// 200: InventoryImport
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateInventoryImportMapping(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	importID := vars["importID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update import with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(importID); err != nil {
+		config.Log("Unable to update import with invalid import id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftMapping model.InventoryImportMapping
+	if err := json.NewDecoder(r.Body).Decode(&draftMapping); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateInventoryImportMapping(user, userID, importID, draftMapping.Mapping)
+	if err != nil {
+		config.Log("Unable to update import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.InventoryImportAlreadyCommitted {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// CommitInventoryImport ...
+// swagger:route POST /api/v1/profile/{id}/inventories/imports/{importID}/commit Inventories commitInventoryImport
+//
+// # Adds an asset for each row of the selected pending import without errors. The rows are validated again before
+// they are added and unknown storage locations are added. Rows with errors are skipped and listed in the response.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: importID
+//     in: path
+//     description: The id of the selected import
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: InventoryImport
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func CommitInventoryImport(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	importID := vars["importID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to commit import with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(importID); err != nil {
+		config.Log("Unable to commit import with invalid import id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.CommitInventoryImport(user, userID, importID)
+	if err != nil {
+		config.Log("Unable to commit import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.InventoryImportAlreadyCommitted {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveInventoryImport ...
+// swagger:route DELETE /api/v1/profile/{id}/inventories/imports/{importID} Inventories removeInventoryImport
+//
+// # Removes the selected import. Assets added by the import are not removed.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: importID
+//     in: path
+//     description: The id of the selected import
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveInventoryImport(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	importID := vars["importID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove import with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(importID); err != nil {
+		config.Log("Unable to remove import with invalid import id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveInventoryImport(user, userID, importID)
+	if err != nil {
+		config.Log("Unable to remove import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(importID)
+}
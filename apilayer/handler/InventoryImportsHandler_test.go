@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InventoryImports(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	content := "Item Name,Notes,Price,Qty,Storage Location,SKU,Weight\n" +
+		"Inventory Import Lantern,battery powered,\"$1,200.50\",2,Garage,inventory-imports#1,2kg\n" +
+		"Inventory Import Stove,,abc,1,Garage,inventory-imports#2,\n" +
+		"Inventory Import Chair,,10,1,Garage,inventory-imports#1,\n"
+
+	requestBody, contentType := buildInventoryImportRequestBody(t, "assets.csv", content, "")
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports", prevUser.ID.String()), requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedImport model.InventoryImport
+	err = json.Unmarshal(data, &selectedImport)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.InventoryImportStatusPending, selectedImport.Status)
+	assert.Equal(t, "Item Name", selectedImport.Mapping["name"])
+	assert.Equal(t, []string{"Notes"}, selectedImport.UnmappedHeaders)
+	assert.Equal(t, 3, selectedImport.TotalRows)
+	assert.Equal(t, 1, selectedImport.ValidRows)
+
+	// rows are reported with their row number in the spreadsheet
+	assert.Equal(t, 2, len(selectedImport.Issues))
+	assert.Equal(t, 3, selectedImport.Issues[0].Row)
+	assert.Equal(t, "price", selectedImport.Issues[0].Errors[0].Field)
+	assert.Equal(t, 4, selectedImport.Issues[1].Row)
+	assert.Equal(t, "sku", selectedImport.Issues[1].Errors[0].Field)
+
+	// unmapped columns can be mapped before the import is committed
+	requestBody2, err := json.Marshal(model.InventoryImportMapping{Mapping: map[string]string{
+		"name":        "Item Name",
+		"description": "Notes",
+		"price":       "Price",
+		"quantity":    "Qty",
+		"location":    "Storage Location",
+		"sku":         "SKU",
+		"weight":      "Weight",
+	}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/%s", prevUser.ID.String(), selectedImport.ID), bytes.NewBuffer(requestBody2))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "importID": selectedImport.ID})
+	w = httptest.NewRecorder()
+	UpdateInventoryImportMapping(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/%s/commit", prevUser.ID.String(), selectedImport.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "importID": selectedImport.ID})
+	w = httptest.NewRecorder()
+	CommitInventoryImport(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var committedImport model.InventoryImport
+	err = json.Unmarshal(data, &committedImport)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.InventoryImportStatusCommitted, committedImport.Status)
+	assert.Equal(t, 1, committedImport.ImportedRows)
+
+	importedInventory, err := db.RetrieveInventoryByCode(config.CTO_USER, prevUser.ID.String(), "", "inventory-imports#1")
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "Inventory Import Lantern", importedInventory.Name)
+	assert.Equal(t, "battery powered", importedInventory.Description)
+	assert.Equal(t, 1200.50, importedInventory.Price)
+	assert.Equal(t, 2, importedInventory.Quantity)
+
+	// imports can only be committed once
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/%s/commit", prevUser.ID.String(), selectedImport.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "importID": selectedImport.ID})
+	w = httptest.NewRecorder()
+	CommitInventoryImport(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/imports/%s", prevUser.ID.String(), selectedImport.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "importID": selectedImport.ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryImport(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{importedInventory.ID})
+}
+
+func Test_AddInventoryImport_NoUserID(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBody(t, "assets.csv", "Name\nCamping Lantern\n", "")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile//inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImport_MissingFile(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", bytes.NewBufferString("Name\nCamping Lantern\n"))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImport_UnsupportedFile(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBody(t, "assets.xls", "Name\nCamping Lantern\n", "")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImport_MissingHeader(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBody(t, "assets.csv", "\n\n", "")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImport_InvalidMapping(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBody(t, "assets.csv", "Name\nCamping Lantern\n", `{"name":"Missing Column"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_CommitInventoryImport_InvalidImportID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports/1/commit", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "importID": "1"})
+	w := httptest.NewRecorder()
+	CommitInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryImports_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryImports(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryImport_UnknownSource(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBodyWithFields(t, "assets.csv", "Name\nCamping Lantern\n", map[string]string{"source": "spreadsheet"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Contains(t, string(data), db.UnknownInventoryImportSource)
+}
+
+func Test_AddInventoryImport_InvalidProfileID(t *testing.T) {
+	requestBody, contentType := buildInventoryImportRequestBodyWithFields(t, "assets.csv", "Name\nCamping Lantern\n", map[string]string{"profileID": "1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/imports", requestBody)
+	req.Header.Set("Content-Type", contentType)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryImport(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Contains(t, string(data), db.InvalidInventoryImportProfile)
+}
+
+// builds the multipart form used to upload the selected file of assets with an optional column mapping
+func buildInventoryImportRequestBody(t *testing.T, fileName string, content string, mapping string) (*bytes.Buffer, string) {
+	fields := make(map[string]string)
+	if len(mapping) > 0 {
+		fields["mapping"] = mapping
+	}
+	return buildInventoryImportRequestBodyWithFields(t, fileName, content, fields)
+}
+
+// builds the multipart form used to upload the selected file of assets alongside the selected form fields
+func buildInventoryImportRequestBodyWithFields(t *testing.T, fileName string, content string, fields map[string]string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	part.Write([]byte(content))
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
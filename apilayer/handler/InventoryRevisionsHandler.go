@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryHistory ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/history InventoryRevisions getInventoryHistory
+//
+// # Retrieves the field level history of the selected asset. Each revision contains the before and after value
+// of every column that was changed. Most recent revisions are returned first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []InventoryRevision
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryHistory(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve asset history with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(invID) <= 0 {
+		config.Log("Unable to retrieve asset history with empty asset id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryRevisions(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve asset history", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RevertInventoryRevision ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/history/{revisionID}/revert InventoryRevisions revertInventoryRevision
+//
+// # Restores the selected asset to the state right after the selected revision. Every change made after the selected
+// revision is undone in a single transaction and the revert is recorded as a new revision.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: revisionID
+//     in: path
+//     description: The id of the selected revision
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: Inventory
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func RevertInventoryRevision(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	revisionID := vars["revisionID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to revert asset with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to revert asset with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(revisionID); err != nil {
+		config.Log("Unable to revert asset with invalid revision id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RevertInventoryRevision(user, userID, invID, revisionID)
+	if err != nil {
+		config.Log("Unable to revert asset", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
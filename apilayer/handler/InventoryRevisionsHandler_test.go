@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetInventoryHistory_RevertInventoryRevision(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Camping Lantern",
+		Description: "Lantern used for the weekend trips",
+		Price:       12.99,
+		Status:      "HIDDEN",
+		Barcode:     "history#1231231231",
+		SKU:         "history#1231231231",
+		Quantity:    4,
+		Location:    "Broom Closet",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+		BoughtAt:    "Walmart",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	for _, draftUpdateInv := range []model.UpdateAssetColumn{
+		{AssetID: selectedInventory.ID, ColumnName: "name", InputColumn: "Camping Lantern v2"},
+		{AssetID: selectedInventory.ID, ColumnName: "quantity", InputColumn: "1"},
+	} {
+		_, err := db.UpdateAsset(config.CTO_USER, prevUser.ID.String(), draftUpdateInv)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/history", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	GetInventoryHistory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var revisions []model.InventoryRevision
+	err = json.Unmarshal(data, &revisions)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, "update", revisions[0].Action)
+	assert.JSONEq(t, "4", string(revisions[0].Changes["quantity"].Before))
+	assert.JSONEq(t, "1", string(revisions[0].Changes["quantity"].After))
+	assert.JSONEq(t, `"Camping Lantern"`, string(revisions[1].Changes["name"].Before))
+
+	// reverting to the first revision keeps the new name but restores the quantity
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/history/%s/revert", prevUser.ID.String(), selectedInventory.ID, revisions[1].ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "revisionID": revisions[1].ID})
+	w = httptest.NewRecorder()
+	RevertInventoryRevision(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var revertedInventory model.Inventory
+	err = json.Unmarshal(data, &revertedInventory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "Camping Lantern v2", revertedInventory.Name)
+	assert.Equal(t, 4, revertedInventory.Quantity)
+
+	revisions, err = db.RetrieveInventoryRevisions(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, "revert", revisions[0].Action)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetInventoryHistory_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryHistory(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryHistory_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryHistory(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RevertInventoryRevision_InvalidRevisionID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/history/1/revert", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "revisionID": "1"})
+	w := httptest.NewRecorder()
+	RevertInventoryRevision(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
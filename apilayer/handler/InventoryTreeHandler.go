@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryTree ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/tree InventoryTree getInventoryTree
+//
+// # Retrieves the selected asset along with every asset inside it. Each node contains the rolled up value and quantity
+// of the asset and all of its descendants.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: InventoryTreeNode
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryTree(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve asset tree with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to retrieve asset tree with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryTree(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve asset tree", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateInventoryParent ...
+// swagger:route PUT /api/v1/profile/{id}/inventories/{invID}/parent InventoryTree updateInventoryParent
+//
+// # Places the selected asset inside the selected parent. The asset and every asset inside it are moved to the storage
+// location of the parent. Empty parent id detaches the asset from its parent. Parents that would create a cycle are rejected.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: InventoryParentRequest
+//     in: body
+//     description: The parent of the selected asset
+//     type: InventoryParentRequest
+//     required: true
+//
+// Responses:
+// 200: Inventory
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateInventoryParent(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update asset parent with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to update asset parent with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftParent model.InventoryParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftParent); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(draftParent.ParentID) > 0 {
+		if _, err := uuid.Parse(draftParent.ParentID); err != nil {
+			config.Log("Unable to update asset parent with invalid parent id", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+	}
+
+	resp, err := db.UpdateInventoryParent(user, userID, invID, draftParent.ParentID)
+	if err != nil {
+		config.Log("Unable to update asset parent", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.InventoryParentCycle {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
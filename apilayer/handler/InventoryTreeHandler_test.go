@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UpdateInventoryParent_GetInventoryTree(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var selectedInventories []*model.Inventory
+	for i, v := range []struct {
+		name     string
+		price    float64
+		location string
+	}{
+		{"Work Van", 30000.00, "Driveway"},
+		{"Toolbox", 120.00, "Garage"},
+		{"Cordless Drill", 80.00, "Basement"},
+	} {
+		selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+			Name:      v.name,
+			Price:     v.price,
+			Status:    "HIDDEN",
+			Barcode:   fmt.Sprintf("tree#%d", i),
+			SKU:       fmt.Sprintf("tree#%d", i),
+			Quantity:  1,
+			Location:  v.location,
+			CreatedAt: time.Now(),
+			CreatedBy: prevUser.ID.String(),
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		selectedInventories = append(selectedInventories, selectedInventory)
+	}
+	van, toolbox, drill := selectedInventories[0], selectedInventories[1], selectedInventories[2]
+
+	for _, v := range []struct{ child, parent string }{{toolbox.ID, van.ID}, {drill.ID, toolbox.ID}} {
+		requestBody, err := json.Marshal(model.InventoryParentRequest{ParentID: v.parent})
+		if err != nil {
+			t.Errorf("failed to marshal JSON: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/parent", prevUser.ID.String(), v.child), bytes.NewBuffer(requestBody))
+		req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": v.child})
+		w := httptest.NewRecorder()
+		UpdateInventoryParent(w, req, config.CTO_USER)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, 200, res.StatusCode)
+	}
+
+	// placing the van inside the drill creates a cycle
+	requestBody, err := json.Marshal(model.InventoryParentRequest{ParentID: drill.ID})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/parent", prevUser.ID.String(), van.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": van.ID})
+	w := httptest.NewRecorder()
+	UpdateInventoryParent(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/tree", prevUser.ID.String(), van.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": van.ID})
+	w = httptest.NewRecorder()
+	GetInventoryTree(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var tree model.InventoryTreeNode
+	err = json.Unmarshal(data, &tree)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 30200.00, tree.RolledUpValue)
+	assert.Equal(t, 3, tree.RolledUpQuantity)
+	assert.Equal(t, 1, len(tree.Children))
+	assert.Equal(t, 1, len(tree.Children[0].Children))
+	assert.Equal(t, van.StorageLocationID, tree.Children[0].Children[0].StorageLocationID)
+
+	// cleanup
+	removeInventory := []string{van.ID, toolbox.ID, drill.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetInventoryTree_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/tree", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryTree(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryTree_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/tree", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryTree(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_UpdateInventoryParent_SelfParent(t *testing.T) {
+	requestBody, err := json.Marshal(model.InventoryParentRequest{ParentID: "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/parent", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	UpdateInventoryParent(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetInventoryUnits ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/units Inventories getInventoryUnits
+//
+// # Retrieves the serialized units of the selected asset ordered by serial number
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: status
+//     in: query
+//     description: One of available, in_use, in_repair, broken, lost or retired
+//     type: string
+//     required: false
+//
+// Responses:
+// 200: []InventoryUnit
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetInventoryUnits(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve units with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to retrieve units with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveInventoryUnits(user, userID, invID, r.URL.Query().Get("status"))
+	if err != nil {
+		config.Log("Unable to retrieve units", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddInventoryUnits ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/units Inventories addInventoryUnits
+//
+// # Adds one or more serialized units to the selected asset. Serial numbers are unique for each asset regardless of case.
+// The quantity of the asset is derived from its units that are not lost or retired.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: InventoryUnits
+//     in: body
+//     description: The list of units with their serial number, status and optional storage location. Max 500 units.
+//     type: []InventoryUnit
+//     required: true
+//
+// Responses:
+// 200: []InventoryUnit
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func AddInventoryUnits(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add units with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to add units with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftUnits []model.InventoryUnit
+	if err := json.NewDecoder(r.Body).Decode(&draftUnits); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddInventoryUnits(user, userID, invID, draftUnits)
+	if err != nil {
+		config.Log("Unable to add units", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateInventoryUnit {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateInventoryUnit ...
+// swagger:route PUT /api/v1/profile/{id}/inventories/{invID}/units/{unitID} Inventories updateInventoryUnit
+//
+// # Updates the serial number, status, storage location and notes of the selected unit
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: unitID
+//     in: path
+//     description: The id of the selected unit
+//     type: string
+//     required: true
+//   - +name: InventoryUnit
+//     in: body
+//     description: The serial number, status and optional storage location of the unit
+//     type: InventoryUnit
+//     required: true
+//
+// Responses:
+// 200: InventoryUnit
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateInventoryUnit(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	unitID := vars["unitID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update unit with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to update unit with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(unitID); err != nil {
+		config.Log("Unable to update unit with invalid unit id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftUnit model.InventoryUnit
+	if err := json.NewDecoder(r.Body).Decode(&draftUnit); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateInventoryUnit(user, userID, invID, unitID, draftUnit)
+	if err != nil {
+		config.Log("Unable to update unit", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateInventoryUnit {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveInventoryUnit ...
+// swagger:route DELETE /api/v1/profile/{id}/inventories/{invID}/units/{unitID} Inventories removeInventoryUnit
+//
+// # Removes the selected unit alongside its loan and maintenance history. Units that are checked out cannot be removed.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     type: string
+//     required: true
+//   - +name: unitID
+//     in: path
+//     description: The id of the selected unit
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func RemoveInventoryUnit(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+	unitID := vars["unitID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove unit with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(invID); err != nil {
+		config.Log("Unable to remove unit with invalid asset id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(unitID); err != nil {
+		config.Log("Unable to remove unit with invalid unit id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveInventoryUnit(user, userID, invID, unitID)
+	if err != nil {
+		config.Log("Unable to remove unit", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.InventoryUnitOnLoan {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(unitID)
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InventoryUnits(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Walkie Talkie",
+		Description: "radios for the trail",
+		Price:       40.00,
+		Status:      "HIDDEN",
+		Barcode:     "inventory-units#1",
+		SKU:         "inventory-units#1",
+		Quantity:    5,
+		Location:    "Garage",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	requestBody, err := json.Marshal([]model.InventoryUnit{
+		{SerialNumber: "WT-001"},
+		{SerialNumber: "WT-002", Status: "broken"},
+		{SerialNumber: "WT-003", Status: "lost"},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units", prevUser.ID.String(), selectedInventory.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w := httptest.NewRecorder()
+	AddInventoryUnits(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedUnits []model.InventoryUnit
+	err = json.Unmarshal(data, &selectedUnits)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 3, len(selectedUnits))
+	assert.Equal(t, "WT-001", selectedUnits[0].SerialNumber)
+	assert.Equal(t, db.InventoryUnitStatusAvailable, selectedUnits[0].Status)
+
+	// quantity is derived from the units that are not lost or retired
+	updatedInventory, err := db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 2, updatedInventory.Quantity)
+
+	// serial numbers are unique for each asset regardless of case
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units", prevUser.ID.String(), selectedInventory.ID), bytes.NewBufferString(`[{"serial_number":"wt-001"}]`))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	AddInventoryUnits(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	// stock movements cannot change the quantity of a serialized asset
+	requestBody, err = json.Marshal(model.StockMovementRequest{MovementType: db.StockMovementTypeRestock, Quantity: 1})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/stock", prevUser.ID.String(), selectedInventory.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	AddStockMovement(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+
+	// neither can bulk edits or updates of the asset
+	bulkEditResp, err := db.BulkEditInventory(config.CTO_USER, prevUser.ID.String(), model.InventoryBulkEditRequest{
+		IDs:        []string{selectedInventory.ID},
+		Operations: []model.InventoryBulkEditOperation{{Field: "quantity", Operation: db.InventoryBulkEditOperationSet, Value: float64(5)}},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.False(t, bulkEditResp.IsApplied)
+	assert.Equal(t, db.SerializedInventoryQuantity, bulkEditResp.Results[0].Error)
+
+	updatedInventory.Quantity = 5
+	updatedInventory.Location = updatedInventory.StorageLocationID
+	_, err = db.UpdateInventory(config.CTO_USER, prevUser.ID.String(), *updatedInventory, nil)
+	assert.EqualError(t, err, db.SerializedInventoryQuantity)
+
+	_, err = db.UpdateAsset(config.CTO_USER, prevUser.ID.String(), model.UpdateAssetColumn{
+		AssetID:     selectedInventory.ID,
+		ColumnName:  "quantity",
+		InputColumn: "5",
+	})
+	assert.EqualError(t, err, db.SerializedInventoryQuantity)
+
+	// only available units can be checked out
+	requestBody, err = json.Marshal(model.LoanRequest{
+		BorrowerName: "John Doe",
+		DueDate:      time.Now().AddDate(0, 0, 7),
+		UnitIDs:      []string{selectedUnits[1].ID},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	requestBody, err = json.Marshal(model.LoanRequest{
+		BorrowerName: "John Doe",
+		DueDate:      time.Now().AddDate(0, 0, 7),
+		UnitIDs:      []string{selectedUnits[0].ID},
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedLoan model.Loan
+	err = json.Unmarshal(data, &selectedLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 1, len(selectedLoan.Items))
+	assert.Equal(t, selectedUnits[0].ID, selectedLoan.Items[0].UnitID)
+	assert.Equal(t, "WT-001", selectedLoan.Items[0].SerialNumber)
+
+	// checked out units are in use until they are checked in
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units?status=in_use", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	GetInventoryUnits(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var inUseUnits []model.InventoryUnit
+	err = json.Unmarshal(data, &inUseUnits)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 1, len(inUseUnits))
+	assert.Equal(t, selectedUnits[0].ID, inUseUnits[0].ID)
+
+	// units that are checked out cannot be removed
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units/%s", prevUser.ID.String(), selectedInventory.ID, selectedUnits[0].ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "unitID": selectedUnits[0].ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryUnit(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	requestBody, err = json.Marshal(model.LoanCheckInRequest{UnitIDs: []string{selectedUnits[0].ID}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans/%s/checkin", prevUser.ID.String(), selectedLoan.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "loanID": selectedLoan.ID})
+	w = httptest.NewRecorder()
+	CheckInAssets(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	requestBody, err = json.Marshal(model.InventoryUnit{SerialNumber: "WT-002", Status: "available"})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units/%s", prevUser.ID.String(), selectedInventory.ID, selectedUnits[1].ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "unitID": selectedUnits[1].ID})
+	w = httptest.NewRecorder()
+	UpdateInventoryUnit(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units?status=available", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	GetInventoryUnits(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var availableUnits []model.InventoryUnit
+	err = json.Unmarshal(data, &availableUnits)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 2, len(availableUnits))
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/units/%s", prevUser.ID.String(), selectedInventory.ID, selectedUnits[0].ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedInventory.ID, "unitID": selectedUnits[0].ID})
+	w = httptest.NewRecorder()
+	RemoveInventoryUnit(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	updatedInventory, err = db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 1, updatedInventory.Quantity)
+
+	// cleanup
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+}
+
+func Test_GetInventoryUnits_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryUnits(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryUnits_InvalidStatus(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units?status=missing", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryUnits(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryUnits_EmptySerialNumber(t *testing.T) {
+	requestBody, err := json.Marshal([]model.InventoryUnit{{SerialNumber: "  "}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryUnits(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddInventoryUnits_InvalidStatus(t *testing.T) {
+	requestBody, err := json.Marshal([]model.InventoryUnit{{SerialNumber: "WT-001", Status: "misplaced"}})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddInventoryUnits(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RemoveInventoryUnit_InvalidUnitID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "unitID": "1"})
+	w := httptest.NewRecorder()
+	RemoveInventoryUnit(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetInventoryUnits_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/units", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetInventoryUnits(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
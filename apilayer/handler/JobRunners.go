@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/service"
+	"github.com/earmuff-jam/fleetwise/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	JobTypeInventoryImportCommit = "inventory_import_commit"
+	JobTypeInventoryExport       = "inventory_export"
+	JobTypeLabelSheet            = "label_sheet"
+	JobTypeAccountBackup         = "account_backup"
+)
+
+// RegisterJobRunners ...
+//
+// RegisterJobRunners adds the long running operations of the api as the types of jobs that can be queued
+func RegisterJobRunners() {
+	service.RegisterJob(JobTypeInventoryImportCommit, service.JobDefinition{
+		Validate: validateInventoryImportJob,
+		Run:      runInventoryImportJob,
+	})
+	service.RegisterJob(JobTypeInventoryExport, service.JobDefinition{
+		Validate: validateInventoryExportJob,
+		Run:      runInventoryExportJob,
+	})
+	service.RegisterJob(JobTypeLabelSheet, service.JobDefinition{
+		Validate: validateLabelSheetJob,
+		Run:      runLabelSheetJob,
+	})
+	service.RegisterJob(JobTypeAccountBackup, service.JobDefinition{
+		Run: runAccountBackupJob,
+	})
+}
+
+// validateInventoryImportJob ...
+//
+// ensures that the payload refers to a valid import id
+func validateInventoryImportJob(userID string, payload json.RawMessage) error {
+	var draftPayload model.InventoryImportJobPayload
+	if err := decodeJobPayload(payload, &draftPayload); err != nil {
+		return err
+	}
+	_, err := uuid.Parse(draftPayload.ImportID)
+	return err
+}
+
+// runInventoryImportJob ...
+//
+// commits the selected pending import and returns the committed import
+func runInventoryImportJob(ctx context.Context, user string, job model.Job, progress service.JobProgressFunc) (interface{}, error) {
+	var draftPayload model.InventoryImportJobPayload
+	if err := decodeJobPayload(job.Payload, &draftPayload); err != nil {
+		return nil, service.PermanentJobError(err)
+	}
+
+	resp, err := db.CommitInventoryImportWithProgress(user, job.CreatedBy, draftPayload.ImportID, func(processedRows int, totalRows int) error {
+		return progress(percentOf(processedRows, totalRows), fmt.Sprintf("added %d of %d rows", processedRows, totalRows))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || err.Error() == db.InventoryImportAlreadyCommitted {
+			return nil, service.PermanentJobError(err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// validateInventoryExportJob ...
+//
+// ensures that the payload has a supported format and valid sort and filter query parameters
+func validateInventoryExportJob(userID string, payload json.RawMessage) error {
+	_, _, err := parseInventoryExportJob(payload)
+	return err
+}
+
+// runInventoryExportJob ...
+//
+// exports the assets of the user in the selected format and stores the file in the bucket
+func runInventoryExportJob(ctx context.Context, user string, job model.Job, progress service.JobProgressFunc) (interface{}, error) {
+	draftPayload, listParams, err := parseInventoryExportJob(job.Payload)
+	if err != nil {
+		return nil, service.PermanentJobError(err)
+	}
+
+	file, err := os.CreateTemp("", "inventory-export-*."+draftPayload.Format)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	// the export is reported up to 90 percent, the rest is left for the upload of the file
+	_, err = writeInventoryExport(user, job.CreatedBy, *listParams, draftPayload.Format, file, nil, func(count int, totalCount int) error {
+		return progress(count*90/totalCount, fmt.Sprintf("exported %d of %d assets", count, totalCount))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadJobFile(job, file, inventoryExportFileName(draftPayload.Format), inventoryExportContentTypes[draftPayload.Format], progress)
+}
+
+// validateLabelSheetJob ...
+//
+// ensures that the payload has at least one asset or storage location and a supported layout and symbology
+func validateLabelSheetJob(userID string, payload json.RawMessage) error {
+	var draftLabelSheet model.LabelSheetRequest
+	if err := decodeJobPayload(payload, &draftLabelSheet); err != nil {
+		return err
+	}
+	return validateLabelSheetRequest(&draftLabelSheet)
+}
+
+// runLabelSheetJob ...
+//
+// prints the label sheet of the selected assets and storage locations and stores the pdf in the bucket
+func runLabelSheetJob(ctx context.Context, user string, job model.Job, progress service.JobProgressFunc) (interface{}, error) {
+	var draftLabelSheet model.LabelSheetRequest
+	if err := decodeJobPayload(job.Payload, &draftLabelSheet); err != nil {
+		return nil, service.PermanentJobError(err)
+	}
+	if err := validateLabelSheetRequest(&draftLabelSheet); err != nil {
+		return nil, service.PermanentJobError(err)
+	}
+
+	labels, err := db.RetrieveLabels(user, job.CreatedBy, draftLabelSheet.InventoryIDs, draftLabelSheet.StorageLocationIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := progress(30, fmt.Sprintf("printing %d labels", len(labels))); err != nil {
+		return nil, err
+	}
+
+	content, err := service.GenerateLabelSheet(labels, draftLabelSheet.Symbology, draftLabelSheet.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadJobFile(job, bytes.NewReader(content), fmt.Sprintf("labels-%s.pdf", draftLabelSheet.Layout), "application/pdf", progress)
+}
+
+// runAccountBackupJob ...
+//
+// exports the account backup archive of the user and stores the archive in the bucket
+func runAccountBackupJob(ctx context.Context, user string, job model.Job, progress service.JobProgressFunc) (interface{}, error) {
+	archive, err := os.CreateTemp("", "account-backup-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := progress(10, "exporting account"); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExportAccountBackup(user, job.CreatedBy, archive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.PermanentJobError(err)
+		}
+		return nil, err
+	}
+
+	return uploadJobFile(job, archive, fmt.Sprintf("fleetwise-backup-%s.zip", job.CreatedAt.Format("2006-01-02")), "application/zip", progress)
+}
+
+// uploadJobFile ...
+//
+// stores the content of the selected job in the bucket under the id of the job. content is read from the start.
+func uploadJobFile(job model.Job, content io.ReadSeeker, fileName string, contentType string, progress service.JobProgressFunc) (*model.JobFile, error) {
+	if err := progress(90, "uploading file"); err != nil {
+		return nil, err
+	}
+
+	size, err := content.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objectKey := fmt.Sprintf("jobs/%s/%s", job.ID, fileName)
+	if err := bucket.UploadStreamInBucket(objectKey, content, size, contentType); err != nil {
+		return nil, err
+	}
+
+	return &model.JobFile{
+		FileName:    fileName,
+		ContentType: contentType,
+		ObjectKey:   objectKey,
+		SizeInBytes: size,
+	}, nil
+}
+
+// parseInventoryExportJob ...
+//
+// returns the payload of the export along with the sort and filter of the assets. Format defaults to csv.
+func parseInventoryExportJob(payload json.RawMessage) (*model.InventoryExportJobPayload, *model.InventoryListParams, error) {
+	var draftPayload model.InventoryExportJobPayload
+	if err := decodeJobPayload(payload, &draftPayload); err != nil {
+		return nil, nil, err
+	}
+
+	draftPayload.Format = strings.ToLower(draftPayload.Format)
+	if len(draftPayload.Format) == 0 {
+		draftPayload.Format = utils.SpreadsheetFileTypeCSV
+	}
+	if _, ok := inventoryExportContentTypes[draftPayload.Format]; !ok {
+		return nil, nil, fmt.Errorf("unsupported export format: %s", draftPayload.Format)
+	}
+
+	query, err := url.ParseQuery(draftPayload.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	listParams, err := parseInventoryListQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	listParams.Limit, listParams.Offset, listParams.Cursor = 0, 0, ""
+	return &draftPayload, listParams, nil
+}
+
+// decodeJobPayload ...
+//
+// decodes the payload of the job into v. An empty payload leaves v unchanged.
+func decodeJobPayload(payload json.RawMessage, v interface{}) error {
+	if len(payload) == 0 || string(payload) == "null" {
+		return nil
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// percentOf ...
+//
+// returns count as the percent of total
+func percentOf(count int, total int) int {
+	if total <= 0 {
+		return 0
+	}
+	return count * 100 / total
+}
@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/bucket"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultJobHistoryLimit = 50
+	maxJobHistoryLimit     = 200
+)
+
+// GetJobs ...
+// swagger:route GET /api/v1/profile/{id}/jobs Jobs getJobs
+//
+// # Retrieves the history of jobs of the selected user with the most recent job first
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: status
+//     in: query
+//     description: The status to filter against. One of queued, running, succeeded, failed, cancelled.
+//     type: string
+//     required: false
+//   - +name: type
+//     in: query
+//     description: The type of job to filter against
+//     type: string
+//     required: false
+//   - +name: limit
+//     in: query
+//     description: The max number of jobs to return. Defaults to 50, at most 200.
+//     type: integer
+//     required: false
+//
+// Responses:
+// 200: []Job
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetJobs(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve jobs with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	limit := defaultJobHistoryLimit
+	if limitStr := r.URL.Query().Get("limit"); len(limitStr) > 0 {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			config.Log("Unable to retrieve jobs with invalid limit", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		limit = min(parsedLimit, maxJobHistoryLimit)
+	}
+
+	resp, err := db.RetrieveJobs(user, userID, r.URL.Query().Get("status"), r.URL.Query().Get("type"), limit)
+	if err != nil {
+		config.Log("Unable to retrieve jobs", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddJob ...
+// swagger:route POST /api/v1/profile/{id}/jobs Jobs addJob
+//
+// # Queues a long running operation to be run in the background. The job is polled with the progress route.
+// Supported types are inventory_import_commit, inventory_export, label_sheet and account_backup.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: JobRequest
+//     in: body
+//     description: The type of the job and its payload
+//     type: JobRequest
+//     required: true
+//
+// Responses:
+// 200: Job
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func AddJob(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add job with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftJob model.JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftJob); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := service.EnqueueJob(user, userID, draftJob)
+	if err != nil {
+		config.Log("Unable to add job", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetJob ...
+// swagger:route GET /api/v1/profile/{id}/jobs/{jobID} Jobs getJob
+//
+// # Retrieves the selected job alongside its payload and result
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: jobID
+//     in: path
+//     description: The id of the selected job
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: Job
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetJob(rw http.ResponseWriter, r *http.Request, user string) {
+	userID, jobID, ok := parseJobVars(rw, r)
+	if !ok {
+		return
+	}
+
+	resp, err := db.RetrieveJob(user, userID, jobID)
+	if err != nil {
+		config.Log("Unable to retrieve selected job", err)
+		writeJobError(rw, err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetJobProgress ...
+// swagger:route GET /api/v1/profile/{id}/jobs/{jobID}/progress Jobs getJobProgress
+//
+// # Retrieves the status and progress of the selected job without its payload and result. Used to poll the job.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: jobID
+//     in: path
+//     description: The id of the selected job
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: JobProgress
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetJobProgress(rw http.ResponseWriter, r *http.Request, user string) {
+	userID, jobID, ok := parseJobVars(rw, r)
+	if !ok {
+		return
+	}
+
+	job, err := db.RetrieveJob(user, userID, jobID)
+	if err != nil {
+		config.Log("Unable to retrieve progress of selected job", err)
+		writeJobError(rw, err)
+		return
+	}
+
+	resp := model.JobProgress{
+		ID:              job.ID,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		ProgressMessage: job.ProgressMessage,
+		Attempts:        job.Attempts,
+		CancelRequested: job.CancelRequested,
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// CancelJob ...
+// swagger:route POST /api/v1/profile/{id}/jobs/{jobID}/cancel Jobs cancelJob
+//
+// # Cancels the selected job. Queued jobs are cancelled right away, running jobs are stopped once the worker notices
+// the request. Finished jobs cannot be cancelled.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: jobID
+//     in: path
+//     description: The id of the selected job
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: Job
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func CancelJob(rw http.ResponseWriter, r *http.Request, user string) {
+	userID, jobID, ok := parseJobVars(rw, r)
+	if !ok {
+		return
+	}
+
+	resp, err := db.CancelJob(user, userID, jobID)
+	if err != nil {
+		config.Log("Unable to cancel selected job", err)
+		writeJobError(rw, err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// DownloadJobFile ...
+// swagger:route GET /api/v1/profile/{id}/jobs/{jobID}/download Jobs downloadJobFile
+//
+// # Downloads the file built by the selected job, eg the exported assets or the printed label sheet. Only succeeded
+// jobs have a file.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: jobID
+//     in: path
+//     description: The id of the selected job
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func DownloadJobFile(rw http.ResponseWriter, r *http.Request, user string) {
+	userID, jobID, ok := parseJobVars(rw, r)
+	if !ok {
+		return
+	}
+
+	job, err := db.RetrieveJob(user, userID, jobID)
+	if err != nil {
+		config.Log("Unable to retrieve selected job", err)
+		writeJobError(rw, err)
+		return
+	}
+
+	if job.Status != db.JobStatusSucceeded {
+		config.Log("Unable to download file of unfinished job", nil)
+		rw.WriteHeader(http.StatusConflict)
+		json.NewEncoder(rw).Encode(fmt.Sprintf("job is %s", job.Status))
+		return
+	}
+
+	jobFile, ok := retrieveJobFile(*job)
+	if !ok {
+		config.Log("Unable to find file of selected job", nil)
+		rw.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	// the file is streamed from the bucket as exports and label sheets can be large
+	object, size, _, err := bucket.RetrieveDocumentStreamFromBucket(jobFile.ObjectKey)
+	if err != nil {
+		config.Log("Unable to retrieve file of selected job", err)
+		if err.Error() == "NoSuchKey" {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	defer object.Close()
+
+	rw.Header().Set("Content-Type", jobFile.ContentType)
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", jobFile.FileName))
+	rw.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(rw, object); err != nil {
+		config.Log("Unable to stream file of selected job", err)
+	}
+}
+
+// RemoveJob ...
+// swagger:route DELETE /api/v1/profile/{id}/jobs/{jobID} Jobs removeJob
+//
+// # Removes the selected job from the history of the user along with the file built by the job. Queued and running
+// jobs must be cancelled first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: jobID
+//     in: path
+//     description: The id of the selected job
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func RemoveJob(rw http.ResponseWriter, r *http.Request, user string) {
+	userID, jobID, ok := parseJobVars(rw, r)
+	if !ok {
+		return
+	}
+
+	job, err := db.RemoveJob(user, userID, jobID)
+	if err != nil {
+		config.Log("Unable to remove selected job", err)
+		writeJobError(rw, err)
+		return
+	}
+
+	if jobFile, ok := retrieveJobFile(*job); ok {
+		if err := bucket.RemoveDocumentFromBucket(jobFile.ObjectKey); err != nil {
+			// the job is already removed, the file is left behind in the bucket
+			config.Log("Unable to remove file of selected job", err)
+		}
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(jobID)
+}
+
+// parseJobVars ...
+//
+// returns the user id and job id of the request. Writes the bad request response when either is invalid.
+func parseJobVars(rw http.ResponseWriter, r *http.Request) (string, string, bool) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	jobID := vars["jobID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve job with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return "", "", false
+	}
+
+	if _, err := uuid.Parse(jobID); err != nil {
+		config.Log("Unable to retrieve job with invalid job id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return "", "", false
+	}
+	return userID, jobID, true
+}
+
+// writeJobError ...
+//
+// writes the response of the selected error. Missing jobs are not found and jobs in the wrong status are a conflict.
+func writeJobError(rw http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		rw.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+	if err.Error() == db.JobAlreadyFinished || err.Error() == db.JobIsNotFinished {
+		rw.WriteHeader(http.StatusConflict)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(rw).Encode(err.Error())
+}
+
+// retrieveJobFile ...
+//
+// returns the file built by the selected job. Jobs without a file, eg the commit of an import, return false.
+func retrieveJobFile(job model.Job) (model.JobFile, bool) {
+	var jobFile model.JobFile
+	if len(job.Result) == 0 || json.Unmarshal(job.Result, &jobFile) != nil || len(jobFile.ObjectKey) == 0 {
+		return jobFile, false
+	}
+	return jobFile, true
+}
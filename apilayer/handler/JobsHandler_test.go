@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Jobs(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	RegisterJobRunners()
+
+	// the workers are not started in tests so the job stays queued until it is cancelled
+	requestBody := `{"type":"inventory_export","payload":{"format":"ndjson","query":"sortBy=name"}}`
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/jobs", prevUser.ID.String()), bytes.NewBufferString(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	AddJob(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedJob model.Job
+	err = json.Unmarshal(data, &selectedJob)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, JobTypeInventoryExport, selectedJob.Type)
+	assert.Equal(t, db.JobStatusQueued, selectedJob.Status)
+	assert.Equal(t, 0, selectedJob.Attempts)
+
+	jobVars := map[string]string{"id": prevUser.ID.String(), "jobID": selectedJob.ID}
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/jobs/%s/progress", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	GetJobProgress(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedProgress model.JobProgress
+	err = json.Unmarshal(data, &selectedProgress)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.JobStatusQueued, selectedProgress.Status)
+	assert.Equal(t, 0, selectedProgress.Progress)
+
+	// active jobs cannot be removed
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/jobs/%s", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	RemoveJob(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	// queued jobs are cancelled right away
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/jobs/%s/cancel", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	CancelJob(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	err = json.Unmarshal(data, &selectedJob)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, db.JobStatusCancelled, selectedJob.Status)
+	assert.NotNil(t, selectedJob.FinishedAt)
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/jobs/%s/cancel", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	CancelJob(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	// only succeeded jobs have a file
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/jobs/%s/download", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	DownloadJobFile(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 409, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/jobs?status=cancelled&type=inventory_export", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	GetJobs(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedJobs []model.Job
+	err = json.Unmarshal(data, &selectedJobs)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.GreaterOrEqual(t, len(selectedJobs), 1)
+	assert.Equal(t, selectedJob.ID, selectedJobs[0].ID)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/profile/%s/jobs/%s", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	RemoveJob(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/jobs/%s", prevUser.ID.String(), selectedJob.ID), nil)
+	req = mux.SetURLVars(req, jobVars)
+	w = httptest.NewRecorder()
+	GetJob(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 404, res.StatusCode)
+}
+
+func Test_AddJob_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile//jobs", bytes.NewBufferString(`{"type":"account_backup"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	AddJob(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddJob_UnknownType(t *testing.T) {
+	RegisterJobRunners()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs", bytes.NewBufferString(`{"type":"image_resize"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	AddJob(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_AddJob_InvalidPayload(t *testing.T) {
+	RegisterJobRunners()
+	payloads := []string{
+		`{"type":"inventory_import_commit","payload":{"import_id":"1"}}`,
+		`{"type":"inventory_export","payload":{"format":"pdf"}}`,
+		`{"type":"inventory_export","payload":{"query":"sortBy=model"}}`,
+		`{"type":"label_sheet","payload":{"layout":"avery5160"}}`,
+	}
+	for _, v := range payloads {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs", bytes.NewBufferString(v))
+		req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+		w := httptest.NewRecorder()
+		AddJob(w, req, config.CTO_USER)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, 400, res.StatusCode, v)
+	}
+}
+
+func Test_GetJobProgress_InvalidJobID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs/1/progress", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "jobID": "1"})
+	w := httptest.NewRecorder()
+	GetJobProgress(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_CancelJob_InvalidJobID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs/1/cancel", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "jobID": "1"})
+	w := httptest.NewRecorder()
+	CancelJob(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetJobs_InvalidLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs?limit=-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetJobs(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetJobs_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/jobs", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetJobs(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
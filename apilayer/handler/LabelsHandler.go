@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/earmuff-jam/fleetwise/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetLabelImage ...
+// swagger:route GET /api/v1/profile/{id}/labels/{labelID} Labels getLabelImage
+//
+// # Renders the label of the selected asset or storage location as a PNG. QR labels encode a deep link that resolves back
+// to the selected asset or storage location. Code128 labels encode the barcode, sku or id of the asset in that order.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: labelID
+//     in: path
+//     description: The id of the selected asset or storage location
+//     required: true
+//     type: string
+//   - +name: type
+//     in: query
+//     description: The type of the label. One of inventory, storage_location. Defaults to inventory.
+//     required: false
+//     type: string
+//   - +name: symbology
+//     in: query
+//     description: The symbology of the label. One of qr, code128. Defaults to qr.
+//     required: false
+//     type: string
+//   - +name: size
+//     in: query
+//     description: The size of the label in pixels. Defaults to 256.
+//     required: false
+//     type: integer
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetLabelImage(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	labelID := vars["labelID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve label with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(labelID); err != nil {
+		config.Log("Unable to retrieve label with invalid label id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	labelType := r.URL.Query().Get("type")
+	if len(labelType) <= 0 {
+		labelType = db.LabelTypeInventory
+	}
+
+	symbology := r.URL.Query().Get("symbology")
+	if len(symbology) <= 0 {
+		symbology = service.LABEL_SYMBOLOGY_QR
+	}
+
+	size := service.DEFAULT_LABEL_IMAGE_SIZE
+	if draftSize := r.URL.Query().Get("size"); len(draftSize) > 0 {
+		parsedSize, err := strconv.Atoi(draftSize)
+		if err != nil || parsedSize <= 0 {
+			config.Log("Unable to retrieve label with invalid size", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		size = parsedSize
+	}
+
+	if !isValidLabelSymbology(symbology) {
+		config.Log("Unable to retrieve label with invalid symbology", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var inventoryIDs, storageLocationIDs []string
+	switch labelType {
+	case db.LabelTypeInventory:
+		inventoryIDs = []string{labelID}
+	case db.LabelTypeStorageLocation:
+		storageLocationIDs = []string{labelID}
+	default:
+		config.Log("Unable to retrieve label with invalid type", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	labels, err := db.RetrieveLabels(user, userID, inventoryIDs, storageLocationIDs)
+	if err != nil {
+		config.Log("Unable to retrieve label details", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	if len(labels) == 0 {
+		config.Log("Unable to find selected label", nil)
+		rw.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	content, err := service.GenerateLabelImage(labels[0], symbology, size)
+	if err != nil {
+		config.Log("Unable to generate label image", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "image/png")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s-%s.png", labelID, symbology))
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(content)
+}
+
+// GetLabelSheet ...
+// swagger:route POST /api/v1/profile/{id}/labels/sheet Labels getLabelSheet
+//
+// # Renders the labels of the selected assets and storage locations as a PDF sheet in the selected avery layout.
+// Supported layouts are avery5160, avery5163, avery5167 and avery22805. Defaults to avery5160 and qr symbology.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: LabelSheetRequest
+//     in: body
+//     description: The selected assets, storage locations, symbology and layout
+//     required: true
+//     type: LabelSheetRequest
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetLabelSheet(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve label sheet with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftLabelSheet model.LabelSheetRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftLabelSheet); err != nil {
+		config.Log("unable to decode selected data", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := validateLabelSheetRequest(&draftLabelSheet); err != nil {
+		config.Log("Unable to validate label sheet", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	labels, err := db.RetrieveLabels(user, userID, draftLabelSheet.InventoryIDs, draftLabelSheet.StorageLocationIDs)
+	if err != nil {
+		config.Log("Unable to retrieve label details", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	content, err := service.GenerateLabelSheet(labels, draftLabelSheet.Symbology, draftLabelSheet.Layout)
+	if err != nil {
+		config.Log("Unable to generate label sheet", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/pdf")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=labels-%s.pdf", draftLabelSheet.Layout))
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(content)
+}
+
+// isValidLabelSymbology ...
+//
+// isValidLabelSymbology function is used to determine if the selected symbology is supported
+func isValidLabelSymbology(symbology string) bool {
+	return symbology == service.LABEL_SYMBOLOGY_QR || symbology == service.LABEL_SYMBOLOGY_CODE128
+}
+
+// validateLabelSheetRequest ...
+//
+// validateLabelSheetRequest function is used to ensure that the selected label sheet has at least one asset or
+// storage location with valid ids. Empty symbology and layout are set to the defaults.
+func validateLabelSheetRequest(draftLabelSheet *model.LabelSheetRequest) error {
+	if len(draftLabelSheet.InventoryIDs) == 0 && len(draftLabelSheet.StorageLocationIDs) == 0 {
+		return errors.New("missing selected assets or storage locations")
+	}
+
+	if len(draftLabelSheet.Symbology) <= 0 {
+		draftLabelSheet.Symbology = service.LABEL_SYMBOLOGY_QR
+	}
+
+	if len(draftLabelSheet.Layout) <= 0 {
+		draftLabelSheet.Layout = service.DEFAULT_AVERY_LAYOUT
+	}
+
+	if _, ok := service.AVERY_LAYOUTS[draftLabelSheet.Layout]; !ok {
+		return errors.New(service.INVALID_LABEL_LAYOUT)
+	}
+	if !isValidLabelSymbology(draftLabelSheet.Symbology) {
+		return errors.New(service.INVALID_LABEL_SYMBOLOGY)
+	}
+
+	for _, v := range append(append([]string{}, draftLabelSheet.InventoryIDs...), draftLabelSheet.StorageLocationIDs...) {
+		if _, err := uuid.Parse(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetLoans ...
+// swagger:route GET /api/v1/profile/{id}/loans Loans getLoans
+//
+// # Retrieves the list of loans shared with the selected user alongside the assets in each loan.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: status
+//     in: query
+//     description: The status of the loans to return. One of open, overdue, all. Defaults to open.
+//     required: false
+//     type: string
+//
+// Responses:
+// 200: []Loan
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetLoans(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	loanStatus := strings.ToLower(r.URL.Query().Get("status"))
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve loans with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(loanStatus) == 0 {
+		loanStatus = db.LoanStatusOpen
+	}
+
+	if loanStatus != db.LoanStatusOpen && loanStatus != db.LoanStatusOverdue && loanStatus != db.LoanStatusAll {
+		config.Log("Unable to retrieve loans with invalid status", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveLoans(user, userID, loanStatus)
+	if err != nil {
+		config.Log("Unable to retrieve loans", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// CheckOutAssets ...
+// swagger:route POST /api/v1/profile/{id}/loans Loans checkOutAssets
+//
+// # Checks out one or more assets to a borrower with a due date. Assets that are already checked out cannot be checked out again.
+// Single units of serialized assets can be checked out with their unit ids, as long as the unit is available.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: LoanRequest
+//     in: body
+//     description: The borrower details and the list of assets to check out
+//     required: true
+//     type: LoanRequest
+//
+// Responses:
+// 200: Loan
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func CheckOutAssets(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to check out assets with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftLoan model.LoanRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftLoan); err != nil {
+		config.Log("unable to decode selected data", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if len(strings.TrimSpace(draftLoan.BorrowerName)) == 0 || len(draftLoan.AssetIDs)+len(draftLoan.UnitIDs) == 0 || draftLoan.DueDate.IsZero() {
+		config.Log("Unable to check out assets without borrower, assets or due date", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.CheckOutAssets(user, userID, draftLoan)
+	if err != nil {
+		config.Log("Unable to check out selected assets", err)
+		if err.Error() == db.UnavailableAssetsForLoan {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// CheckInAssets ...
+// swagger:route POST /api/v1/profile/{id}/loans/{loanID}/checkin Loans checkInAssets
+//
+// # Checks in the assets of the selected loan with condition notes. All open assets are checked in if no assets or units are passed in.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: loanID
+//     in: path
+//     description: The id of the selected loan
+//     required: true
+//     type: string
+//   - +name: LoanCheckInRequest
+//     in: body
+//     description: The list of assets to check in and the condition notes
+//     required: true
+//     type: LoanCheckInRequest
+//
+// Responses:
+// 200: Loan
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func CheckInAssets(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	loanID := vars["loanID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to check in assets with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(loanID); err != nil {
+		config.Log("Unable to check in assets with invalid loan id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftCheckIn model.LoanCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftCheckIn); err != nil {
+		config.Log("unable to decode selected data", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, v := range append(append([]string{}, draftCheckIn.AssetIDs...), draftCheckIn.UnitIDs...) {
+		if _, err := uuid.Parse(v); err != nil {
+			config.Log("Unable to check in assets with invalid asset or unit id", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+	}
+
+	resp, err := db.CheckInAssets(user, userID, loanID, draftCheckIn)
+	if err != nil {
+		config.Log("Unable to check in selected assets", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetAssetLoanHistory ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/loans Loans getAssetLoanHistory
+//
+// # Retrieves the loan history of the selected asset. Most recent loans are returned first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []LoanHistory
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetAssetLoanHistory(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve loan history with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(invID) <= 0 {
+		config.Log("Unable to retrieve loan history with empty asset id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveAssetLoanHistory(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve loan history", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
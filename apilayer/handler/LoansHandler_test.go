@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckOutAndCheckInAssets(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	inventories, err := db.RetrieveAllInventoriesForUser(config.CTO_USER, prevUser.ID.String(), model.InventoryListParams{})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.GreaterOrEqual(t, len(inventories.Inventories), 1)
+	selectedAssetID := inventories.Inventories[0].ID
+
+	draftLoan := model.LoanRequest{
+		BorrowerName:  "John Doe",
+		BorrowerEmail: "john@gmail.com",
+		DueDate:       time.Now().AddDate(0, 0, 7),
+		Notes:         "Borrowed for the weekend",
+		// repeated ids are checked out once
+		AssetIDs: []string{selectedAssetID, selectedAssetID},
+	}
+
+	requestBody, err := json.Marshal(draftLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var selectedLoan model.Loan
+	err = json.Unmarshal(data, &selectedLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, "John Doe", selectedLoan.BorrowerName)
+	assert.Equal(t, 1, len(selectedLoan.Items))
+	assert.Equal(t, selectedAssetID, selectedLoan.Items[0].ItemID)
+
+	// checking out the same asset again should fail while the loan is open
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans", prevUser.ID.String()), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w = httptest.NewRecorder()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res = w.Result()
+
+	assert.Equal(t, 409, res.StatusCode)
+
+	draftCheckIn := model.LoanCheckInRequest{
+		AssetIDs:       []string{selectedAssetID},
+		ConditionNotes: "Returned with minor scratches",
+	}
+
+	requestBody, err = json.Marshal(draftCheckIn)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans/%s/checkin", prevUser.ID.String(), selectedLoan.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "loanID": selectedLoan.ID})
+	w = httptest.NewRecorder()
+	CheckInAssets(w, req, config.CTO_USER)
+	res = w.Result()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var updatedLoan model.Loan
+	err = json.Unmarshal(data, &updatedLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 1, len(updatedLoan.Items))
+	assert.NotNil(t, updatedLoan.Items[0].CheckedInAt)
+	assert.Equal(t, "Returned with minor scratches", updatedLoan.Items[0].ConditionNotes)
+
+	// the loan has no open assets left once every asset is checked in
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans/%s/checkin", prevUser.ID.String(), selectedLoan.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "loanID": selectedLoan.ID})
+	w = httptest.NewRecorder()
+	CheckInAssets(w, req, config.CTO_USER)
+	res = w.Result()
+
+	assert.Equal(t, 404, res.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/loans", prevUser.ID.String(), selectedAssetID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedAssetID})
+	w = httptest.NewRecorder()
+	GetAssetLoanHistory(w, req, config.CTO_USER)
+	res = w.Result()
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var loanHistory []model.LoanHistory
+	err = json.Unmarshal(data, &loanHistory)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.GreaterOrEqual(t, len(loanHistory), 1)
+	assert.Equal(t, selectedLoan.ID, loanHistory[0].LoanID)
+}
+
+func Test_GetLoans(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/loans?status=all", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	GetLoans(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "200 OK", res.Status)
+}
+
+func Test_GetLoans_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetLoans(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetLoans_InvalidStatus(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans?status=lost", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetLoans(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetLoans_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetLoans(w, req, config.CEO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_CheckOutAssets_MissingDueDate(t *testing.T) {
+
+	draftLoan := model.LoanRequest{
+		BorrowerName: "John Doe",
+		AssetIDs:     []string{"0802c692-b8e2-4824-a870-e52f4a0cccf8"},
+	}
+
+	requestBody, err := json.Marshal(draftLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_CheckOutAssets_InvalidAssetID(t *testing.T) {
+
+	draftLoan := model.LoanRequest{
+		BorrowerName: "John Doe",
+		DueDate:      time.Now().AddDate(0, 0, 7),
+		AssetIDs:     []string{"1"},
+	}
+
+	requestBody, err := json.Marshal(draftLoan)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	CheckOutAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_CheckInAssets_NoLoanID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans//checkin", bytes.NewBuffer([]byte("{}")))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "loanID": ""})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	CheckInAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_CheckInAssets_InvalidLoanID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans/1/checkin", bytes.NewBuffer([]byte("{}")))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "loanID": "1"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	CheckInAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_CheckInAssets_InvalidAssetID(t *testing.T) {
+	requestBody, err := json.Marshal(model.LoanCheckInRequest{
+		AssetIDs: []string{"1"},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/loans/0802c692-b8e2-4824-a870-e52f4a0cccf8/checkin", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "loanID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	CheckInAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_CheckInAssets_UnknownLoan(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	unknownLoanID := uuid.New().String()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/loans/%s/checkin", prevUser.ID.String(), unknownLoanID), bytes.NewBuffer([]byte("{}")))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "loanID": unknownLoanID})
+	w := httptest.NewRecorder()
+	CheckInAssets(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 404, res.StatusCode)
+}
@@ -31,6 +31,11 @@ import (
 //     required: true
 //     type: integer
 //     format: int32
+//   - +name: tag
+//     in: query
+//     description: The comma separated list of tag ids. The plans must hold every selected tag.
+//     required: false
+//     type: string
 //
 // Responses:
 //
@@ -53,7 +58,8 @@ func GetAllMaintenancePlans(rw http.ResponseWriter, r *http.Request, user string
 	if err != nil {
 		limitInt = 10
 	}
-	resp, err := db.RetrieveAllMaintenancePlans(user, userID, limitInt)
+	tagIDs := splitQueryValues(r.URL.Query()["tag"])
+	resp, err := db.RetrieveAllMaintenancePlans(user, userID, limitInt, tagIDs)
 	if err != nil {
 		config.Log("Unable to retrieve maintenance plans", err)
 		rw.WriteHeader(http.StatusBadRequest)
@@ -187,7 +193,7 @@ func GetAllMaintenancePlanItems(rw http.ResponseWriter, r *http.Request, user st
 // AddItemsInMaintenancePlan ...
 // swagger:route POST /api/v1/category/items MaintenancePlans addItemsInMaintenancePlan
 //
-// # Add selected items in a specific maintenance plan
+// # Add selected items in a specific maintenance plan. Single units of serialized assets can be added with their unit ids.
 //
 // Parameters:
 //   - +name: MaintenanceItemRequest
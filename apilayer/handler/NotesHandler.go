@@ -22,6 +22,11 @@ import (
 //     description: The userID of the selected user
 //     type: string
 //     required: true
+//   - +name: tag
+//     in: query
+//     description: The comma separated list of tag ids. The notes must hold every selected tag.
+//     type: string
+//     required: false
 //
 // Responses:
 // 200: []Note
@@ -46,7 +51,8 @@ func GetNotes(rw http.ResponseWriter, r *http.Request, user string) {
 		return
 	}
 
-	resp, err := db.RetrieveNotes(user, parsedUUID)
+	tagIDs := splitQueryValues(r.URL.Query()["tag"])
+	resp, err := db.RetrieveNotes(user, parsedUUID, tagIDs)
 	if err != nil {
 		config.Log("Unable to retrieve notes", err)
 		rw.WriteHeader(http.StatusBadRequest)
@@ -125,6 +125,8 @@ func GetProfileStats(rw http.ResponseWriter, r *http.Request, user string) {
 //
 // # Retrieves the notifications of all the maintenance plans that are in alert status.
 // Alert status is defined as having due_date within 7 days from the current timestamp.
+// Inventories that are below their reorder point are returned as low stock notifications.
+// Inventories and batches that expire within 30 days are returned as expiry notifications.
 //
 // Parameters:
 //   - +name: id
@@ -166,7 +168,9 @@ func GetNotifications(rw http.ResponseWriter, r *http.Request, user string) {
 // UpdateSelectedMaintenanceNotification ...
 // swagger:route GET /api/v1/profile/{id}/notifications Profiles updateSelectedMaintenanceNotification
 //
-// # Updates a selected maintenance notification between read and unread state
+// # Updates a selected maintenance notification between read and unread state.
+// Low stock notifications are updated if the item_id is passed in.
+// Expiry notifications are updated if the type is expiry along with the item_id and the batch_id.
 //
 // Parameters:
 //   - +name: id
@@ -467,6 +471,11 @@ func UpdateProfile(rw http.ResponseWriter, r *http.Request, user string) {
 	resp, err := db.UpdateUserProfile(user, userID, updatedProfile)
 	if err != nil {
 		config.Log("Unable to update profile details", err)
+		if err.Error() == db.InvalidUnitSystem || err.Error() == db.InvalidCurrency {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -268,6 +268,27 @@ func Test_UpdateProfileApi(t *testing.T) {
 	assert.Equal(t, "400 Bad Request", res.Status)
 }
 
+func Test_UpdateProfileApi_InvalidUnitSystem(t *testing.T) {
+	requestBody, err := json.Marshal(model.Profile{
+		Username:     "john",
+		FullName:     "John Doe",
+		EmailAddress: "admin@gmail.com",
+		PhoneNumber:  "1234567890",
+		UnitSystem:   "nautical",
+	})
+	if err != nil {
+		t.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	UpdateProfile(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
 func Test_GetFavouriteItems(t *testing.T) {
 	config.PreloadAllTestVariables()
 
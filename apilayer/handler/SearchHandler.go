@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+)
+
+const defaultSearchLimit = 25
+const maxSearchLimit = 100
+
+// Search ...
+// swagger:route GET /api/v1/search Search search
+//
+// # Retrieves the ranked list of assets, notes, categories and maintenance plans that match the search text.
+// Only the items that are shared with the selected user are returned.
+//
+// Parameters:
+//   - +name: id
+//     in: query
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: q
+//     in: query
+//     description: The search text. Supports quoted phrases, OR and - to exclude words.
+//     required: true
+//     type: string
+//   - +name: types
+//     in: query
+//     description: The comma separated list of types to search against. One of inventory, note, category, maintenance_plan. Searches all types if not passed in.
+//     required: false
+//     type: string
+//   - +name: limit
+//     in: query
+//     description: The max number of results. Defaults to 25, max 100.
+//     required: false
+//     type: integer
+//     format: int32
+//
+// Responses:
+// 200: []SearchResult
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func Search(rw http.ResponseWriter, r *http.Request, user string) {
+
+	userID := r.URL.Query().Get("id")
+	searchText := strings.TrimSpace(r.URL.Query().Get("q"))
+	limit := r.URL.Query().Get("limit")
+
+	if _, err := uuid.Parse(userID); err != nil {
+		config.Log("Unable to search with invalid user id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(searchText) <= 0 {
+		config.Log("Unable to search with empty search text", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	searchTypes := splitQueryValues(r.URL.Query()["types"])
+	for _, v := range searchTypes {
+		isValidType := false
+		for _, searchType := range db.SearchTypes {
+			if v == searchType {
+				isValidType = true
+			}
+		}
+		if !isValidType {
+			config.Log("Unable to search with invalid type %s", nil, v)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil || limitInt <= 0 {
+		limitInt = defaultSearchLimit
+	}
+	if limitInt > maxSearchLimit {
+		limitInt = maxSearchLimit
+	}
+
+	resp, err := db.Search(user, userID, searchText, searchTypes, limitInt)
+	if err != nil {
+		config.Log("Unable to retrieve search results", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
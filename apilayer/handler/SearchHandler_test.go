@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Search(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/search?id=%s&q=%s", prevUser.ID, "litter"), nil)
+	w := httptest.NewRecorder()
+	Search(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var searchResults []model.SearchResult
+	err = json.Unmarshal(data, &searchResults)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.GreaterOrEqual(t, len(searchResults), 1)
+	assert.Equal(t, "inventory", searchResults[0].Type)
+}
+
+func Test_Search_SelectedTypes(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/search?id=%s&q=%s&types=note,category", prevUser.ID, "litter"), nil)
+	w := httptest.NewRecorder()
+	Search(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var searchResults []model.SearchResult
+	err = json.Unmarshal(data, &searchResults)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	for _, v := range searchResults {
+		assert.NotEqual(t, "inventory", v.Type)
+		assert.NotEqual(t, "maintenance_plan", v.Type)
+	}
+}
+
+func Test_Search_EmptySearchText(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?id=0802c692-b8e2-4824-a870-e52f4a0cccf8&q=", nil)
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	Search(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_Search_InvalidType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?id=0802c692-b8e2-4824-a870-e52f4a0cccf8&q=litter&types=profiles", nil)
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	Search(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_Search_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=litter", nil)
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	Search(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_Search_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?id=0802c692-b8e2-4824-a870-e52f4a0cccf8&q=litter", nil)
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	Search(w, req, config.CEO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
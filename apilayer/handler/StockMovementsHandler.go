@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+)
+
+// GetStockMovements ...
+// swagger:route GET /api/v1/profile/{id}/inventories/{invID}/stock StockMovements getStockMovements
+//
+// # Retrieves the quantity ledger of the selected asset. Most recent movements are returned first.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []StockMovement
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetStockMovements(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve stock movements with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(invID) <= 0 {
+		config.Log("Unable to retrieve stock movements with empty asset id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveStockMovements(user, userID, invID)
+	if err != nil {
+		config.Log("Unable to retrieve stock movements", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddStockMovement ...
+// swagger:route POST /api/v1/profile/{id}/inventories/{invID}/stock StockMovements addStockMovement
+//
+// # Records a consume, restock, adjustment or transfer of the selected asset. The quantity of the asset is derived from the ledger.
+// Assets that drop below their reorder point raise a low stock notification. Serialized assets only accept transfers
+// since their quantity is derived from their units.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: invID
+//     in: path
+//     description: The id of the selected asset
+//     required: true
+//     type: string
+//   - +name: StockMovementRequest
+//     in: body
+//     description: The stock movement to record
+//     required: true
+//     type: StockMovementRequest
+//
+// Responses:
+// 200: StockMovement
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func AddStockMovement(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	invID := vars["invID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add stock movement with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if len(invID) <= 0 {
+		config.Log("Unable to add stock movement with empty asset id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftStockMovement model.StockMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftStockMovement); err != nil {
+		config.Log("unable to decode selected data", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch draftStockMovement.MovementType {
+	case db.StockMovementTypeConsume, db.StockMovementTypeRestock, db.StockMovementTypeAdjustment, db.StockMovementTypeTransfer:
+	default:
+		config.Log("Unable to add stock movement with invalid movement type", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddStockMovement(user, userID, invID, draftStockMovement)
+	if err != nil {
+		config.Log("Unable to add stock movement", err)
+		if err.Error() == db.InvalidStockMovement || err.Error() == db.InsufficientStock || err.Error() == db.SerializedInventoryQuantity {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
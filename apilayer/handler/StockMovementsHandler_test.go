@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddStockMovement(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	inventories, err := db.RetrieveAllInventoriesForUser(config.CTO_USER, prevUser.ID.String(), model.InventoryListParams{})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.GreaterOrEqual(t, len(inventories.Inventories), 1)
+	selectedAsset := inventories.Inventories[0]
+
+	// restock and consume the same amount so that the selected asset is left untouched
+	for _, draftStockMovement := range []model.StockMovementRequest{
+		{MovementType: "restock", Quantity: 2, Reason: "bought new batch"},
+		{MovementType: "consume", Quantity: 2, Reason: "used for weekend trip"},
+	} {
+		requestBody, err := json.Marshal(draftStockMovement)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/stock", prevUser.ID.String(), selectedAsset.ID), bytes.NewBuffer(requestBody))
+		req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedAsset.ID})
+		w := httptest.NewRecorder()
+		AddStockMovement(w, req, config.CTO_USER)
+		res := w.Result()
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+		res.Body.Close()
+
+		assert.Equal(t, 200, res.StatusCode)
+
+		var stockMovement model.StockMovement
+		err = json.Unmarshal(data, &stockMovement)
+		if err != nil {
+			t.Errorf("expected error to be nil got %v", err)
+		}
+
+		assert.Equal(t, draftStockMovement.MovementType, stockMovement.MovementType)
+		assert.Equal(t, draftStockMovement.Reason, stockMovement.Reason)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/stock", prevUser.ID.String(), selectedAsset.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedAsset.ID})
+	w := httptest.NewRecorder()
+	GetStockMovements(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.Equal(t, 200, res.StatusCode)
+
+	var stockMovements []model.StockMovement
+	err = json.Unmarshal(data, &stockMovements)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	assert.GreaterOrEqual(t, len(stockMovements), 2)
+	assert.Equal(t, -2, stockMovements[0].QuantityChange)
+
+	updatedAsset, err := db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedAsset.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, selectedAsset.Quantity, updatedAsset.Quantity)
+}
+
+func Test_AddStockMovement_InsufficientStock(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	inventories, err := db.RetrieveAllInventoriesForUser(config.CTO_USER, prevUser.ID.String(), model.InventoryListParams{})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.GreaterOrEqual(t, len(inventories.Inventories), 1)
+	selectedAsset := inventories.Inventories[0]
+
+	requestBody, err := json.Marshal(model.StockMovementRequest{MovementType: "consume", Quantity: selectedAsset.Quantity + 1})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/inventories/%s/stock", prevUser.ID.String(), selectedAsset.ID), bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "invID": selectedAsset.ID})
+	w := httptest.NewRecorder()
+	AddStockMovement(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddStockMovement_InvalidMovementType(t *testing.T) {
+
+	requestBody, err := json.Marshal(model.StockMovementRequest{MovementType: "stolen", Quantity: 1})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/stock", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddStockMovement(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_AddStockMovement_InvalidQuantity(t *testing.T) {
+
+	requestBody, err := json.Marshal(model.StockMovementRequest{MovementType: "consume", Quantity: 0})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/stock", bytes.NewBuffer(requestBody))
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	AddStockMovement(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetStockMovements_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/stock", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetStockMovements(w, req, config.CTO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
+
+func Test_GetStockMovements_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/stock", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "invID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	config.PreloadAllTestVariables()
+	GetStockMovements(w, req, config.CEO_USER)
+	res := w.Result()
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Equal(t, "400 Bad Request", res.Status)
+}
@@ -0,0 +1,495 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetTags ...
+// swagger:route GET /api/v1/profile/{id}/tags Tags getTags
+//
+// # Retrieves the list of tags that are visible to the selected user
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetTags(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve tags with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveTags(user, userID)
+	if err != nil {
+		config.Log("Unable to retrieve tags", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetTagSuggestions ...
+// swagger:route GET /api/v1/profile/{id}/tags/autocomplete Tags getTagSuggestions
+//
+// # Retrieves the tags that contain the selected text. Tags that start with the selected text are returned
+// first, followed by the tags that are used the most.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: q
+//     in: query
+//     description: The text that the tag name must contain
+//     type: string
+//     required: false
+//   - +name: limit
+//     in: query
+//     description: The maximum number of suggestions. Defaults to 10, max 50.
+//     type: integer
+//     required: false
+//
+// Responses:
+// 200: []Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetTagSuggestions(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve tag suggestions with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var limit int
+	if draftLimit := r.URL.Query().Get("limit"); len(draftLimit) > 0 {
+		parsedLimit, err := strconv.Atoi(draftLimit)
+		if err != nil || parsedLimit < 0 {
+			config.Log("Unable to retrieve tag suggestions with invalid limit", err)
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	resp, err := db.RetrieveTagSuggestions(user, userID, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		config.Log("Unable to retrieve tag suggestions", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// GetTagCounts ...
+// swagger:route GET /api/v1/profile/{id}/tags/counts Tags getTagCounts
+//
+// # Retrieves the number of assets, notes and maintenance plans that hold each tag of the selected user
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []TagCount
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetTagCounts(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve tag counts with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveTagCounts(user, userID)
+	if err != nil {
+		config.Log("Unable to retrieve tag counts", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// AddTag ...
+// swagger:route POST /api/v1/profile/{id}/tags Tags addTag
+//
+// # Adds a new tag for the selected user. Tag names are unique for each user regardless of case and the
+// color is an optional hex color, eg #ffcc00
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: Tag
+//     in: body
+//     description: The name and the color of the tag
+//     type: Tag
+//     required: true
+//
+// Responses:
+// 200: Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func AddTag(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to add tag with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftTag model.Tag
+	if err := json.NewDecoder(r.Body).Decode(&draftTag); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.AddTag(user, userID, draftTag)
+	if err != nil {
+		config.Log("Unable to add tag", err)
+		if err.Error() == db.DuplicateTag {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateTag ...
+// swagger:route PUT /api/v1/profile/{id}/tags/{tagID} Tags updateTag
+//
+// # Updates the name and the color of the selected tag
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: tagID
+//     in: path
+//     description: The id of the selected tag
+//     type: string
+//     required: true
+//   - +name: Tag
+//     in: body
+//     description: The name and the color of the tag
+//     type: Tag
+//     required: true
+//
+// Responses:
+// 200: Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func UpdateTag(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	tagID := vars["tagID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update tag with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(tagID); err != nil {
+		config.Log("Unable to update tag with invalid tag id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftTag model.Tag
+	if err := json.NewDecoder(r.Body).Decode(&draftTag); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateTag(user, userID, tagID, draftTag)
+	if err != nil {
+		config.Log("Unable to update tag", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateTag {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RemoveTag ...
+// swagger:route DELETE /api/v1/profile/{id}/tags/{tagID} Tags removeTag
+//
+// # Removes the selected tag from every asset, note and maintenance plan and then removes the tag
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: tagID
+//     in: path
+//     description: The id of the selected tag
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func RemoveTag(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	tagID := vars["tagID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to remove tag with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(tagID); err != nil {
+		config.Log("Unable to remove tag with invalid tag id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	err := db.RemoveTag(user, userID, tagID)
+	if err != nil {
+		config.Log("Unable to remove tag", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(tagID)
+}
+
+// GetEntityTags ...
+// swagger:route GET /api/v1/profile/{id}/tags/{entityType}/{entityID} Tags getEntityTags
+//
+// # Retrieves the tags of the selected asset, note or maintenance plan
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: entityType
+//     in: path
+//     description: The type of the selected row. One of inventory, note, maintenance_plan.
+//     type: string
+//     required: true
+//   - +name: entityID
+//     in: path
+//     description: The id of the selected asset, note or maintenance plan
+//     type: string
+//     required: true
+//
+// Responses:
+// 200: []Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetEntityTags(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	entityType := vars["entityType"]
+	entityID := vars["entityID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve tags with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(entityID); err != nil {
+		config.Log("Unable to retrieve tags with invalid entity id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveEntityTags(user, userID, entityType, entityID)
+	if err != nil {
+		config.Log("Unable to retrieve tags of selected entity", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// UpdateEntityTags ...
+// swagger:route PUT /api/v1/profile/{id}/tags/{entityType}/{entityID} Tags updateEntityTags
+//
+// # Replaces the tags of the selected asset, note or maintenance plan with the selected tags. An empty list
+// removes every tag.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The id of the selected user
+//     type: string
+//     required: true
+//   - +name: entityType
+//     in: path
+//     description: The type of the selected row. One of inventory, note, maintenance_plan.
+//     type: string
+//     required: true
+//   - +name: entityID
+//     in: path
+//     description: The id of the selected asset, note or maintenance plan
+//     type: string
+//     required: true
+//   - +name: TagAssignmentRequest
+//     in: body
+//     description: The ids of the tags
+//     type: TagAssignmentRequest
+//     required: true
+//
+// Responses:
+// 200: []Tag
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func UpdateEntityTags(rw http.ResponseWriter, r *http.Request, user string) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	entityType := vars["entityType"]
+	entityID := vars["entityID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to update tags with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(entityID); err != nil {
+		config.Log("Unable to update tags with invalid entity id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	var draftTagAssignment model.TagAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&draftTagAssignment); err != nil {
+		config.Log("Unable to decode request parameters", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.UpdateEntityTags(user, userID, entityType, entityID, draftTagAssignment.TagIDs)
+	if err != nil {
+		config.Log("Unable to update tags of selected entity", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err.Error())
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetTrash ...
+// swagger:route GET /api/v1/profile/{id}/trash Trash getTrash
+//
+// # Retrieves the deleted assets, notes, categories and maintenance plans that are shared with the selected user.
+// Deleted items are permanently removed once they have been in the trash longer than the retention period.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: []TrashItem
+// 400: MessageResponse
+// 404: MessageResponse
+// 500: MessageResponse
+func GetTrash(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to retrieve trash with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RetrieveTrash(user, userID)
+	if err != nil {
+		config.Log("Unable to retrieve trash", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
+
+// RestoreTrashItem ...
+// swagger:route POST /api/v1/profile/{id}/trash/{type}/{itemID}/restore Trash restoreTrashItem
+//
+// # Restores the selected item from the trash. Associations with categories and maintenance plans are restored as well.
+// Assets whose barcode or sku has been reused while in the trash cannot be restored.
+//
+// Parameters:
+//   - +name: id
+//     in: path
+//     description: The userID of the selected user
+//     required: true
+//     type: string
+//   - +name: type
+//     in: path
+//     description: The type of the selected item. One of inventory, note, category, maintenance_plan.
+//     required: true
+//     type: string
+//   - +name: itemID
+//     in: path
+//     description: The id of the selected item
+//     required: true
+//     type: string
+//
+// Responses:
+// 200: MessageResponse
+// 400: MessageResponse
+// 404: MessageResponse
+// 409: MessageResponse
+// 500: MessageResponse
+func RestoreTrashItem(rw http.ResponseWriter, r *http.Request, user string) {
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	itemType := vars["type"]
+	itemID := vars["itemID"]
+
+	if len(userID) <= 0 {
+		config.Log("Unable to restore item with empty id", nil)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	if _, err := uuid.Parse(itemID); err != nil {
+		config.Log("Unable to restore item with invalid item id", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(nil)
+		return
+	}
+
+	resp, err := db.RestoreTrashItem(user, userID, itemType, itemID)
+	if err != nil {
+		config.Log("Unable to restore selected item", err)
+		if err.Error() == db.InvalidTrashItemType {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(nil)
+			return
+		}
+		if err.Error() == db.DuplicateBarcodeOrSKU {
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(err.Error())
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(err)
+		return
+	}
+
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetTrash_RestoreTrashItem(t *testing.T) {
+
+	draftUserCredentials := model.UserCredentials{
+		Email:             "admin@gmail.com",
+		Role:              "TESTER",
+		EncryptedPassword: "1231231",
+	}
+
+	config.PreloadAllTestVariables()
+	prevUser, err := db.RetrieveUser(config.CTO_USER, &draftUserCredentials)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	selectedInventory, err := db.AddInventory(config.CTO_USER, prevUser.ID.String(), model.Inventory{
+		Name:        "Folding Camping Chair",
+		Description: "Chair used for the weekend trips",
+		Price:       32.99,
+		Status:      "HIDDEN",
+		Barcode:     "trash#1231231231",
+		SKU:         "trash#1231231231",
+		Quantity:    2,
+		Location:    "Broom Closet",
+		CreatedAt:   time.Now(),
+		CreatedBy:   prevUser.ID.String(),
+		BoughtAt:    "Walmart",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	_, err = db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), []string{selectedInventory.ID})
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	_, err = db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID)
+	assert.Error(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/profile/%s/trash", prevUser.ID.String()), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String()})
+	w := httptest.NewRecorder()
+	GetTrash(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, 200, res.StatusCode)
+
+	var trashItems []model.TrashItem
+	err = json.Unmarshal(data, &trashItems)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+
+	var selectedTrashItem model.TrashItem
+	for _, v := range trashItems {
+		if v.ID == selectedInventory.ID {
+			selectedTrashItem = v
+		}
+	}
+	assert.Equal(t, "inventory", selectedTrashItem.Type)
+	assert.Equal(t, "Folding Camping Chair", selectedTrashItem.Title)
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/profile/%s/trash/inventory/%s/restore", prevUser.ID.String(), selectedInventory.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": prevUser.ID.String(), "type": "inventory", "itemID": selectedInventory.ID})
+	w = httptest.NewRecorder()
+	RestoreTrashItem(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+
+	restoredInventory, err := db.RetrieveSelectedInv(config.CTO_USER, prevUser.ID.String(), selectedInventory.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	assert.Equal(t, "Folding Camping Chair", restoredInventory.Name)
+
+	// restoring an item that is not in the trash
+	w = httptest.NewRecorder()
+	RestoreTrashItem(w, req, config.CTO_USER)
+	res = w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 404, res.StatusCode)
+
+	// cleanup
+	removeInventory := []string{selectedInventory.ID}
+	db.DeleteInventory(config.CTO_USER, prevUser.ID.String(), removeInventory)
+}
+
+func Test_GetTrash_NoUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile//trash", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": ""})
+	w := httptest.NewRecorder()
+	GetTrash(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_GetTrash_InvalidDBUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/trash", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	GetTrash(w, req, config.CEO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RestoreTrashItem_InvalidType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/trash/storage_location/0802c692-b8e2-4824-a870-e52f4a0cccf8/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "type": "storage_location", "itemID": "0802c692-b8e2-4824-a870-e52f4a0cccf8"})
+	w := httptest.NewRecorder()
+	RestoreTrashItem(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func Test_RestoreTrashItem_InvalidItemID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/profile/0802c692-b8e2-4824-a870-e52f4a0cccf8/trash/inventory/1/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "0802c692-b8e2-4824-a870-e52f4a0cccf8", "type": "inventory", "itemID": "1"})
+	w := httptest.NewRecorder()
+	RestoreTrashItem(w, req, config.CTO_USER)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// AccountBackup ...
+// swagger:model AccountBackup
+//
+// AccountBackup is the manifest of an account backup archive. Tables is the count of rows of each table in the
+// archive and Objects are the documents from the bucket that are stored alongside. Version is the format of the
+// archive; archives of a newer version cannot be restored.
+type AccountBackup struct {
+	Version   int                   `json:"version"`
+	CreatedAt time.Time             `json:"created_at"`
+	ProfileID string                `json:"profile_id"`
+	Tables    map[string]int        `json:"tables"`
+	Objects   []AccountBackupObject `json:"objects"`
+}
+
+// AccountBackupObject ...
+// swagger:model AccountBackupObject
+//
+// AccountBackupObject is a single document from the bucket. Key is the name of the document in the bucket of the
+// account that was backed up; the document is stored under objects/{key} in the archive.
+type AccountBackupObject struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
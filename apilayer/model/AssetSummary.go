@@ -12,6 +12,7 @@ type AssetSummary struct {
 	Type           string    `json:"type"`
 	ReturnTime     time.Time `json:"returntime"`
 	Price          float64   `json:"price"`
+	Currency       string    `json:"currency"`
 	Items          []string  `json:"items"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// CategoryAttribute ...
+// swagger:model CategoryAttribute
+//
+// CategoryAttribute is a typed custom field defined by a category. Assets in the category store the value
+// of the attribute in custom_attributes keyed by the name of the attribute. Options are only used by enum attributes.
+type CategoryAttribute struct {
+	ID             string    `json:"id"`
+	CategoryID     string    `json:"category_id"`
+	Name           string    `json:"name"`
+	Label          string    `json:"label"`
+	FieldType      string    `json:"field_type"`
+	IsRequired     bool      `json:"is_required"`
+	Options        []string  `json:"options"`
+	SortOrder      int       `json:"sort_order"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      string    `json:"created_by"`
+	Creator        string    `json:"creator"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      string    `json:"updated_by"`
+	Updator        string    `json:"updator"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
+
+// InventoryAttributeFilter ...
+// swagger:model InventoryAttributeFilter
+//
+// InventoryAttributeFilter is used to filter the list of inventories against the value of a custom attribute.
+// Operator is one of eq, min or max. min and max compare numbers numerically and dates chronologically.
+type InventoryAttributeFilter struct {
+	Name     string `json:"name"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
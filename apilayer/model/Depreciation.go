@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Depreciation ...
+// swagger:model Depreciation
+//
+// Depreciation is the current book value and the full depreciation schedule of the selected asset.
+// Assets that are not depreciated retain their purchase cost as book value and have an empty schedule.
+type Depreciation struct {
+	ItemID                  string               `json:"item_id"`
+	Method                  string               `json:"method"`
+	PurchaseCost            float64              `json:"purchase_cost"`
+	SalvageValue            float64              `json:"salvage_value"`
+	UsefulLifeYears         int                  `json:"useful_life_years"`
+	PurchaseDate            *time.Time           `json:"purchase_date,omitempty"`
+	AsOf                    time.Time            `json:"as_of"`
+	BookValue               float64              `json:"book_value"`
+	AccumulatedDepreciation float64              `json:"accumulated_depreciation"`
+	Schedule                []DepreciationPeriod `json:"schedule"`
+}
+
+// DepreciationPeriod ...
+// swagger:model DepreciationPeriod
+//
+// DepreciationPeriod is a single year in the depreciation schedule of the selected asset
+type DepreciationPeriod struct {
+	Year                    int       `json:"year"`
+	StartDate               time.Time `json:"start_date"`
+	EndDate                 time.Time `json:"end_date"`
+	OpeningBookValue        float64   `json:"opening_book_value"`
+	Depreciation            float64   `json:"depreciation"`
+	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
+	ClosingBookValue        float64   `json:"closing_book_value"`
+}
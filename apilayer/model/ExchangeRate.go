@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ExchangeRate ...
+// swagger:model ExchangeRate
+//
+// ExchangeRate converts one unit of the from currency into the to currency starting on the effective date
+type ExchangeRate struct {
+	ID             string    `json:"id"`
+	FromCurrency   string    `json:"from_currency"`
+	ToCurrency     string    `json:"to_currency"`
+	Rate           float64   `json:"rate"`
+	EffectiveDate  time.Time `json:"effective_date"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      string    `json:"created_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      string    `json:"updated_by"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
+	"strconv"
 	"time"
 )
 
@@ -9,35 +12,52 @@ import (
 //
 // Inventory is the selected row for each inventory
 type Inventory struct {
-	ID                 string     `json:"id"`
-	Name               string     `json:"name"`
-	Description        string     `json:"description"`
-	Price              float64    `json:"price"`
-	Status             string     `json:"status"`
-	Barcode            string     `json:"barcode"`
-	SKU                string     `json:"sku"`
-	Color              string     `json:"color,omitempty"`
-	Quantity           int        `json:"quantity"`
-	Location           string     `json:"location"`
-	StorageLocationID  string     `json:"storage_location_id"`
-	IsReturnable       bool       `json:"is_returnable"`
-	ReturnLocation     string     `json:"return_location"`
-	ReturnDateTime     *time.Time `json:"return_datetime,omitempty"`
-	ReturnNotes        string     `json:"return_notes,omitempty"`
-	MaxWeight          int        `json:"max_weight,omitempty"`
-	MinWeight          int        `json:"min_weight,omitempty"`
-	MaxHeight          int        `json:"max_height,omitempty"`
-	MinHeight          int        `json:"min_height,omitempty"`
-	AssociatedImageURL string     `json:"associated_image_url"`
-	Image              []byte     `json:"image,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
-	CreatedBy          string     `json:"created_by"`
-	CreatorName        string     `json:"creator_name"`
-	UpdatedAt          time.Time  `json:"updated_at"`
-	UpdatedBy          string     `json:"updated_by"`
-	UpdaterName        string     `json:"updator"`
-	BoughtAt           string     `json:"bought_at"`
-	SharableGroups     []string   `json:"sharable_groups"`
+	ID                 string                 `json:"id"`
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description"`
+	Price              float64                `json:"price"`
+	Currency           string                 `json:"currency"`
+	Status             string                 `json:"status"`
+	Barcode            string                 `json:"barcode"`
+	SKU                string                 `json:"sku"`
+	Color              string                 `json:"color,omitempty"`
+	Quantity           int                    `json:"quantity"`
+	ReorderPoint       int                    `json:"reorder_point,omitempty"`
+	Location           string                 `json:"location"`
+	StorageLocationID  string                 `json:"storage_location_id"`
+	IsReturnable       bool                   `json:"is_returnable"`
+	ReturnLocation     string                 `json:"return_location"`
+	ReturnDateTime     *time.Time             `json:"return_datetime,omitempty"`
+	ReturnNotes        string                 `json:"return_notes,omitempty"`
+	MaxWeight          float64                `json:"max_weight,omitempty"`
+	MinWeight          float64                `json:"min_weight,omitempty"`
+	MaxHeight          float64                `json:"max_height,omitempty"`
+	MinHeight          float64                `json:"min_height,omitempty"`
+	Length             float64                `json:"length,omitempty"`
+	Width              float64                `json:"width,omitempty"`
+	Height             float64                `json:"height,omitempty"`
+	Weight             float64                `json:"weight,omitempty"`
+	DimensionUnit      string                 `json:"dimension_unit,omitempty"`
+	WeightUnit         string                 `json:"weight_unit,omitempty"`
+	AssociatedImageURL string                 `json:"associated_image_url"`
+	Image              []byte                 `json:"image,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	CreatedBy          string                 `json:"created_by"`
+	CreatorName        string                 `json:"creator_name"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	UpdatedBy          string                 `json:"updated_by"`
+	UpdaterName        string                 `json:"updator"`
+	BoughtAt           string                 `json:"bought_at"`
+	PurchaseDate       *time.Time             `json:"purchase_date,omitempty"`
+	ExpiryDate         *time.Time             `json:"expiry_date,omitempty"`
+	UsefulLifeYears    int                    `json:"useful_life_years,omitempty"`
+	SalvageValue       float64                `json:"salvage_value,omitempty"`
+	DepreciationMethod string                 `json:"depreciation_method,omitempty"`
+	BookValue          float64                `json:"book_value"`
+	CustomAttributes   map[string]interface{} `json:"custom_attributes"`
+	CategoryIDs        []string               `json:"category_ids,omitempty"`
+	ParentID           string                 `json:"parent_id,omitempty"`
+	SharableGroups     []string               `json:"sharable_groups"`
 }
 
 // RawInventory ...
@@ -45,19 +65,63 @@ type Inventory struct {
 //
 // RawInventory is used to derieve the single row from bulk uploaded excel file
 type RawInventory struct {
-	Name             string  `json:"name"`
-	Description      string  `json:"description"`
-	Price            float64 `json:"price"`
-	Quantity         int64   `json:"quantity"`
-	StorageLocation  string  `json:"Storage Location"`
-	Color            string  `json:"color"`
-	SKU              string  `json:"sku"`
-	Barcode          string  `json:"barcode"`
-	PurchaseLocation string  `json:"Purchase Location"`
-	MaximumWeight    int     `json:"Maximum Weight"`
-	MinimumWeight    int     `json:"Minimum Weight"`
-	MaximumHeight    int     `json:"Maximum Height"`
-	MinimumHeight    int     `json:"Minimum Height"`
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	Price            float64        `json:"price"`
+	Currency         string         `json:"currency"`
+	Quantity         int64          `json:"quantity"`
+	StorageLocation  string         `json:"Storage Location"`
+	Color            string         `json:"color"`
+	SKU              string         `json:"sku"`
+	Barcode          string         `json:"barcode"`
+	PurchaseLocation string         `json:"Purchase Location"`
+	MaximumWeight    RawMeasurement `json:"Maximum Weight"`
+	MinimumWeight    RawMeasurement `json:"Minimum Weight"`
+	MaximumHeight    RawMeasurement `json:"Maximum Height"`
+	MinimumHeight    RawMeasurement `json:"Minimum Height"`
+	Length           RawMeasurement `json:"Length"`
+	Width            RawMeasurement `json:"Width"`
+	Height           RawMeasurement `json:"Height"`
+	Weight           RawMeasurement `json:"Weight"`
+}
+
+// RawMeasurement ...
+//
+// RawMeasurement is a dimension or weight from the bulk uploaded excel file. The cell is either a bare number
+// or a number with a unit suffix, eg 12kg or 3 ft.
+type RawMeasurement string
+
+// UnmarshalJSON ...
+//
+// accepts both numbers and strings so that cells with and without a unit suffix can be uploaded
+func (m *RawMeasurement) UnmarshalJSON(data []byte) error {
+	var draftValue interface{}
+	if err := json.Unmarshal(data, &draftValue); err != nil {
+		return err
+	}
+
+	switch v := draftValue.(type) {
+	case nil:
+		*m = ""
+	case string:
+		*m = RawMeasurement(v)
+	case float64:
+		*m = RawMeasurement(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return errors.New("invalid measurement")
+	}
+	return nil
+}
+
+// InventoryLookup ...
+// swagger:model InventoryLookup
+//
+// InventoryLookup is the result of a scanned barcode or sku. If no asset matches the scan, a draft
+// asset is returned that can be used to create a new asset from the scan.
+type InventoryLookup struct {
+	Found     bool       `json:"found"`
+	Inventory *Inventory `json:"inventory,omitempty"`
+	Draft     *Inventory `json:"draft,omitempty"`
 }
 
 // InventoryListRequest ...
@@ -104,3 +168,45 @@ type StorageLocation struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 	SharableGroups []string  `json:"sharable_groups"`
 }
+
+// InventoryListParams ...
+// swagger:model InventoryListParams
+//
+// InventoryListParams is used to paginate, sort and filter the list of inventories for the selected user.
+// Cursor takes precedence over Offset when both are passed in.
+type InventoryListParams struct {
+	Since              string                     `json:"since,omitempty"`
+	Limit              int                        `json:"limit,omitempty"`
+	Offset             int                        `json:"offset,omitempty"`
+	Cursor             string                     `json:"cursor,omitempty"`
+	SortBy             string                     `json:"sort_by,omitempty"`
+	SortOrder          string                     `json:"sort_order,omitempty"`
+	Statuses           []string                   `json:"status,omitempty"`
+	StorageLocationIDs []string                   `json:"storage_location_id,omitempty"`
+	MinPrice           *float64                   `json:"min_price,omitempty"`
+	MaxPrice           *float64                   `json:"max_price,omitempty"`
+	IsReturnable       *bool                      `json:"is_returnable,omitempty"`
+	CategoryID         string                     `json:"category_id,omitempty"`
+	MaintenancePlanID  string                     `json:"maintenance_plan_id,omitempty"`
+	AttributeFilters   []InventoryAttributeFilter `json:"attribute_filters,omitempty"`
+	TagIDs             []string                   `json:"tag_ids,omitempty"`
+}
+
+// InventoryListCursor ...
+//
+// InventoryListCursor is the decoded form of the opaque cursor used for keyset pagination.
+// Value is the last seen value of the sorted column and ID is the last seen inventory id.
+type InventoryListCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// InventoryList ...
+//
+// InventoryList is the paginated result of the inventory list. TotalCount is the count of all rows that
+// match the selected filters regardless of the limit, NextCursor is empty when there are no more rows.
+type InventoryList struct {
+	Inventories []Inventory `json:"inventories"`
+	TotalCount  int         `json:"total_count"`
+	NextCursor  string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// InventoryAttachment ...
+// swagger:model InventoryAttachment
+//
+// InventoryAttachment is the metadata of a single document attached to an inventory. The document itself is stored in the bucket
+type InventoryAttachment struct {
+	ID             string    `json:"id"`
+	ItemID         string    `json:"item_id"`
+	AttachmentType string    `json:"attachment_type"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	SizeInBytes    int64     `json:"size_in_bytes"`
+	ObjectKey      string    `json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      string    `json:"created_by"`
+	Creator        string    `json:"creator"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
@@ -0,0 +1,44 @@
+package model
+
+// InventoryBulkEditOperation ...
+// swagger:model InventoryBulkEditOperation
+//
+// InventoryBulkEditOperation is a single change applied to every selected asset. Operation is one of set,
+// increment_percent or clear. Value is required for set and increment_percent.
+type InventoryBulkEditOperation struct {
+	Field     string      `json:"field"`
+	Operation string      `json:"op"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// InventoryBulkEditRequest ...
+// swagger:model InventoryBulkEditRequest
+//
+// InventoryBulkEditRequest selects the assets either by ids or by filter and the operations to apply to them
+type InventoryBulkEditRequest struct {
+	IDs        []string                     `json:"ids,omitempty"`
+	Filter     *InventoryListParams         `json:"filter,omitempty"`
+	Operations []InventoryBulkEditOperation `json:"operations"`
+}
+
+// InventoryBulkEditResult ...
+// swagger:model InventoryBulkEditResult
+//
+// InventoryBulkEditResult is the outcome of the bulk edit for a single asset. Status is one of updated, failed
+// or rolled_back. Rolled back assets were valid but were not updated because another asset failed.
+type InventoryBulkEditResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// InventoryBulkEditResponse ...
+// swagger:model InventoryBulkEditResponse
+//
+// InventoryBulkEditResponse is the outcome of the bulk edit. Changes are applied only if every asset is updated.
+type InventoryBulkEditResponse struct {
+	IsApplied    bool                      `json:"is_applied"`
+	UpdatedCount int                       `json:"updated_count"`
+	FailedCount  int                       `json:"failed_count"`
+	Results      []InventoryBulkEditResult `json:"results"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// InventoryDuplicateItem ...
+// swagger:model InventoryDuplicateItem
+//
+// InventoryDuplicateItem is the summary of an asset that is part of a duplicate candidate
+type InventoryDuplicateItem struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Barcode   string    `json:"barcode"`
+	SKU       string    `json:"sku"`
+	Quantity  int       `json:"quantity"`
+	Location  string    `json:"location"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InventoryDuplicateCandidate ...
+// swagger:model InventoryDuplicateCandidate
+//
+// InventoryDuplicateCandidate is a pair of assets that are likely to be the same asset. The asset that was created
+// first is suggested as the surviving asset. Score ranges from 0 to 1 and reasons list the signals that matched.
+type InventoryDuplicateCandidate struct {
+	Inventory InventoryDuplicateItem `json:"inventory"`
+	Duplicate InventoryDuplicateItem `json:"duplicate"`
+	Score     float64                `json:"score"`
+	Reasons   []string               `json:"reasons"`
+}
+
+// InventoryMergeRequest ...
+// swagger:model InventoryMergeRequest
+//
+// InventoryMergeRequest is used to merge the selected duplicate into the surviving asset
+type InventoryMergeRequest struct {
+	DuplicateID string `json:"duplicate_id"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// InventoryBatch ...
+// swagger:model InventoryBatch
+//
+// InventoryBatch is a part of an inventory that shares the same lot number and expiry date
+type InventoryBatch struct {
+	ID             string    `json:"id"`
+	ItemID         string    `json:"item_id"`
+	LotNumber      string    `json:"lot_number"`
+	Quantity       int       `json:"quantity"`
+	ExpiryDate     time.Time `json:"expiry_date"`
+	Notes          string    `json:"notes,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      string    `json:"created_by"`
+	Creator        string    `json:"creator"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      string    `json:"updated_by"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
+
+// ExpiringInventory ...
+// swagger:model ExpiringInventory
+//
+// ExpiringInventory is an inventory or a batch of an inventory that expires within the selected number of days.
+// BatchID and LotNumber are empty when the expiry date is set on the inventory itself.
+type ExpiringInventory struct {
+	ItemID          string    `json:"item_id"`
+	BatchID         string    `json:"batch_id,omitempty"`
+	Name            string    `json:"name"`
+	LotNumber       string    `json:"lot_number,omitempty"`
+	Quantity        int       `json:"quantity"`
+	Location        string    `json:"location"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	DaysUntilExpiry int       `json:"days_until_expiry"`
+	IsExpired       bool      `json:"is_expired"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// InventoryExport ...
+// swagger:model InventoryExport
+//
+// InventoryExport is a single asset of the exported inventory. The fields of RawInventory are kept so that the
+// exported file can be uploaded again, Categories and MaintenancePlans are the names of the categories and the
+// maintenance plans that the asset belongs to.
+type InventoryExport struct {
+	RawInventory
+	Categories       []string `json:"Categories"`
+	MaintenancePlans []string `json:"Maintenance Plans"`
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// InventoryImport ...
+// swagger:model InventoryImport
+//
+// InventoryImport is a csv or xlsx file uploaded to add assets in bulk. Source is the tool that the file is exported
+// from, eg snipeit or homebox. Mapping is the header of the column used for each field of the asset. Issues are the rows with errors or warnings; rows with errors are not imported when the
+// import is committed.
+type InventoryImport struct {
+	ID              string               `json:"id"`
+	FileName        string               `json:"file_name"`
+	FileType        string               `json:"file_type"`
+	Source          string               `json:"source"`
+	Status          string               `json:"status"`
+	Headers         []string             `json:"headers"`
+	Mapping         map[string]string    `json:"mapping"`
+	UnmappedHeaders []string             `json:"unmapped_headers"`
+	TotalRows       int                  `json:"total_rows"`
+	ValidRows       int                  `json:"valid_rows"`
+	ImportedRows    int                  `json:"imported_rows"`
+	Issues          []InventoryImportRow `json:"issues"`
+	CommittedAt     *time.Time           `json:"committed_at,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	CreatedBy       string               `json:"created_by"`
+	Creator         string               `json:"creator"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	UpdatedBy       string               `json:"updated_by"`
+	SharableGroups  []string             `json:"sharable_groups"`
+}
+
+// InventoryImportRow ...
+// swagger:model InventoryImportRow
+//
+// InventoryImportRow is the list of errors and warnings found in a single row of the uploaded file. Row is the row
+// number as displayed in the spreadsheet.
+type InventoryImportRow struct {
+	Row      int                    `json:"row"`
+	Errors   []InventoryImportIssue `json:"errors,omitempty"`
+	Warnings []InventoryImportIssue `json:"warnings,omitempty"`
+}
+
+// InventoryImportIssue ...
+// swagger:model InventoryImportIssue
+//
+// InventoryImportIssue is a single error or warning of a cell in the uploaded file. Field is empty when the issue
+// is not caused by a single cell, eg when the asset could not be added.
+type InventoryImportIssue struct {
+	Field   string `json:"field,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// InventoryImportMapping ...
+// swagger:model InventoryImportMapping
+//
+// InventoryImportMapping is the header of the column to use for each field of the asset, eg { "name": "Item Name" }.
+// Fields that are not selected are left empty.
+type InventoryImportMapping struct {
+	Mapping map[string]string `json:"mapping"`
+}
+
+// InventoryImportMapper ...
+// swagger:model InventoryImportMapper
+//
+// InventoryImportMapper is a supported layout of the uploaded file. Name is the source selected when the file is
+// uploaded and Fields are the fields of the asset that the mapper detects from the header.
+type InventoryImportMapper struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Fields      []string `json:"fields"`
+}
+
+// InventoryImportProfile ...
+// swagger:model InventoryImportProfile
+//
+// InventoryImportProfile is a column mapping saved by the user to reuse when files with the same layout are
+// uploaded. Mapping is the header of the column to use for each field, eg { "categories": "Category" }.
+type InventoryImportProfile struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Source         string            `json:"source"`
+	Mapping        map[string]string `json:"mapping"`
+	CreatedAt      time.Time         `json:"created_at"`
+	CreatedBy      string            `json:"created_by"`
+	Creator        string            `json:"creator"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	UpdatedBy      string            `json:"updated_by"`
+	SharableGroups []string          `json:"sharable_groups"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// InventoryRevision ...
+// swagger:model InventoryRevision
+//
+// InventoryRevision is a single change made to an inventory. Changes are keyed by the name of the changed column
+type InventoryRevision struct {
+	ID             string                          `json:"id"`
+	RevisionNumber int64                           `json:"revision_number"`
+	ItemID         string                          `json:"item_id"`
+	Action         string                          `json:"action"`
+	Changes        map[string]InventoryFieldChange `json:"changes"`
+	CreatedAt      time.Time                       `json:"created_at"`
+	CreatedBy      string                          `json:"created_by"`
+	Creator        string                          `json:"creator"`
+	SharableGroups []string                        `json:"sharable_groups"`
+}
+
+// InventoryFieldChange ...
+// swagger:model InventoryFieldChange
+//
+// InventoryFieldChange is the value of a single column before and after the change
+type InventoryFieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
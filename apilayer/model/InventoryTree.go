@@ -0,0 +1,28 @@
+package model
+
+// InventoryTreeNode ...
+// swagger:model InventoryTreeNode
+//
+// InventoryTreeNode is a single asset in the composition tree. Rolled up value and quantity include the asset
+// itself along with every descendant of the asset.
+type InventoryTreeNode struct {
+	ID                string              `json:"id"`
+	ParentID          string              `json:"parent_id"`
+	Name              string              `json:"name"`
+	Status            string              `json:"status"`
+	Price             float64             `json:"price"`
+	Quantity          int                 `json:"quantity"`
+	Location          string              `json:"location"`
+	StorageLocationID string              `json:"storage_location_id"`
+	RolledUpValue     float64             `json:"rolled_up_value"`
+	RolledUpQuantity  int                 `json:"rolled_up_quantity"`
+	Children          []InventoryTreeNode `json:"children"`
+}
+
+// InventoryParentRequest ...
+// swagger:model InventoryParentRequest
+//
+// InventoryParentRequest is used to place an asset inside another asset. Empty parent id detaches the asset from its parent.
+type InventoryParentRequest struct {
+	ParentID string `json:"parent_id"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// InventoryUnit ...
+// swagger:model InventoryUnit
+//
+// InventoryUnit is a single serialized unit of an inventory. The quantity of the inventory is derived from
+// its units that are not lost or retired. IsCheckedOut is true when the unit is part of an open loan.
+type InventoryUnit struct {
+	ID                string    `json:"id"`
+	ItemID            string    `json:"item_id"`
+	SerialNumber      string    `json:"serial_number"`
+	Status            string    `json:"status"`
+	StorageLocationID string    `json:"storage_location_id,omitempty"`
+	Location          string    `json:"location,omitempty"`
+	Notes             string    `json:"notes,omitempty"`
+	IsCheckedOut      bool      `json:"is_checked_out"`
+	CreatedAt         time.Time `json:"created_at"`
+	CreatedBy         string    `json:"created_by"`
+	Creator           string    `json:"creator"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	UpdatedBy         string    `json:"updated_by"`
+	SharableGroups    []string  `json:"sharable_groups"`
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job ...
+// swagger:model Job
+//
+// Job is a long running operation that is queued by the user and run by the workers of the api. Payload is the
+// input of the job and depends on the type of the job. Result is only set once the job has succeeded. Progress is the
+// percent of the job that is completed.
+type Job struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Payload         json.RawMessage `json:"payload"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Progress        int             `json:"progress"`
+	ProgressMessage string          `json:"progress_message"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	LastError       string          `json:"last_error"`
+	CancelRequested bool            `json:"cancel_requested"`
+	RunAt           time.Time       `json:"run_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       string          `json:"created_by"`
+	Creator         string          `json:"creator"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	UpdatedBy       string          `json:"updated_by"`
+	SharableGroups  []string        `json:"sharable_groups"`
+}
+
+// JobRequest ...
+// swagger:model JobRequest
+//
+// JobRequest is used to queue a new job. Payload depends on the type of the job, eg { "import_id": "..." } for the
+// inventory_import_commit job.
+type JobRequest struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// JobProgress ...
+// swagger:model JobProgress
+//
+// JobProgress is the current state of the selected job without its payload and result. Used to poll the job.
+type JobProgress struct {
+	ID              string `json:"id"`
+	Status          string `json:"status"`
+	Progress        int    `json:"progress"`
+	ProgressMessage string `json:"progress_message"`
+	Attempts        int    `json:"attempts"`
+	CancelRequested bool   `json:"cancel_requested"`
+}
+
+// JobFile ...
+// swagger:model JobFile
+//
+// JobFile is the result of the jobs that build a file, eg exports and label sheets. The file is stored in the bucket
+// under the object key and is downloaded from the job.
+type JobFile struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	ObjectKey   string `json:"object_key"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+// InventoryImportJobPayload ...
+// swagger:model InventoryImportJobPayload
+//
+// InventoryImportJobPayload is the payload of the job that commits the selected pending import
+type InventoryImportJobPayload struct {
+	ImportID string `json:"import_id"`
+}
+
+// InventoryExportJobPayload ...
+// swagger:model InventoryExportJobPayload
+//
+// InventoryExportJobPayload is the payload of the job that exports the assets of the user. Query holds the same sort
+// and filter query parameters as the export of the assets, eg status=HIDDEN&sortBy=name.
+type InventoryExportJobPayload struct {
+	Format string `json:"format"`
+	Query  string `json:"query"`
+}
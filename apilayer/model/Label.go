@@ -0,0 +1,26 @@
+package model
+
+// Label ...
+// swagger:model Label
+//
+// Label is the printable details of a single asset or storage location
+type Label struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Barcode  string `json:"barcode"`
+	SKU      string `json:"sku"`
+	DeepLink string `json:"deep_link"`
+}
+
+// LabelSheetRequest ...
+// swagger:model LabelSheetRequest
+//
+// LabelSheetRequest is used to print a sheet of labels for the selected assets and storage locations.
+// Symbology is either code128 or qr. Layout is one of the supported avery layouts.
+type LabelSheetRequest struct {
+	InventoryIDs       []string `json:"inventoryIDs"`
+	StorageLocationIDs []string `json:"storageLocationIDs"`
+	Symbology          string   `json:"symbology"`
+	Layout             string   `json:"layout"`
+}
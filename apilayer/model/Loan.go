@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+// Loan ...
+// swagger:model Loan
+//
+// Loan is the list of assets that are checked out to a single borrower
+type Loan struct {
+	ID             string     `json:"id"`
+	BorrowerName   string     `json:"borrower_name"`
+	BorrowerEmail  string     `json:"borrower_email"`
+	BorrowerID     string     `json:"borrower_id,omitempty"`
+	DueDate        time.Time  `json:"due_date"`
+	CheckedOutAt   time.Time  `json:"checked_out_at"`
+	Notes          string     `json:"notes"`
+	IsOverdue      bool       `json:"is_overdue"`
+	Items          []LoanItem `json:"items"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CreatedBy      string     `json:"created_by"`
+	Creator        string     `json:"creator"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	UpdatedBy      string     `json:"updated_by"`
+	Updator        string     `json:"updator"`
+	SharableGroups []string   `json:"sharable_groups"`
+}
+
+// LoanItem ...
+// swagger:model LoanItem
+//
+// LoanItem is a single asset within a loan. CheckedInAt is empty until the asset is returned.
+// UnitID and SerialNumber are set when a single unit of a serialized asset is checked out
+type LoanItem struct {
+	ID             string     `json:"id"`
+	LoanID         string     `json:"loan_id"`
+	ItemID         string     `json:"item_id"`
+	UnitID         string     `json:"unit_id,omitempty"`
+	SerialNumber   string     `json:"serial_number,omitempty"`
+	Name           string     `json:"name"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInBy    string     `json:"checked_in_by,omitempty"`
+	ConditionNotes string     `json:"condition_notes,omitempty"`
+}
+
+// LoanRequest ...
+// swagger:model LoanRequest
+//
+// LoanRequest is used to check out one or more assets or units of serialized assets to a borrower
+type LoanRequest struct {
+	BorrowerName  string    `json:"borrower_name"`
+	BorrowerEmail string    `json:"borrower_email"`
+	BorrowerID    string    `json:"borrower_id"`
+	DueDate       time.Time `json:"due_date"`
+	Notes         string    `json:"notes"`
+	AssetIDs      []string  `json:"assetIDs"`
+	UnitIDs       []string  `json:"unitIDs"`
+}
+
+// LoanCheckInRequest ...
+// swagger:model LoanCheckInRequest
+//
+// LoanCheckInRequest is used to check in assets of a loan. All open assets are checked in if AssetIDs and UnitIDs
+// are empty. Every open unit of the selected AssetIDs is checked in as well
+type LoanCheckInRequest struct {
+	AssetIDs       []string `json:"assetIDs"`
+	UnitIDs        []string `json:"unitIDs"`
+	ConditionNotes string   `json:"condition_notes"`
+}
+
+// LoanHistory ...
+// swagger:model LoanHistory
+//
+// LoanHistory is a single check out and check in record for the selected asset
+type LoanHistory struct {
+	LoanID         string     `json:"loan_id"`
+	ItemID         string     `json:"item_id"`
+	UnitID         string     `json:"unit_id,omitempty"`
+	SerialNumber   string     `json:"serial_number,omitempty"`
+	BorrowerName   string     `json:"borrower_name"`
+	BorrowerEmail  string     `json:"borrower_email"`
+	DueDate        time.Time  `json:"due_date"`
+	CheckedOutAt   time.Time  `json:"checked_out_at"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInBy    string     `json:"checked_in_by,omitempty"`
+	ConditionNotes string     `json:"condition_notes,omitempty"`
+	IsOverdue      bool       `json:"is_overdue"`
+}
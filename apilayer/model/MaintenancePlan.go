@@ -30,11 +30,13 @@ type MaintenancePlan struct {
 // MaintenanceItemRequest ...
 // swagger:model MaintenanceItemRequest
 //
-// MaintenanceItemRequest instance to resemble list of assets that can be added to a maintenance plan item
+// MaintenanceItemRequest instance to resemble list of assets that can be added to a maintenance plan item.
+// UnitIDs are the single units of serialized assets that are added to the maintenance plan
 type MaintenanceItemRequest struct {
 	ID            string   `json:"id"`
 	UserID        string   `json:"userID"`
 	AssetIDs      []string `json:"assetIDs"`
+	UnitIDs       []string `json:"unitIDs"`
 	Collaborators []string `json:"collaborators"`
 }
 
@@ -46,6 +48,8 @@ type MaintenanceItemResponse struct {
 	ID                string    `json:"id"`
 	MaintenancePlanID string    `json:"plan_id"`
 	ItemID            string    `json:"item_id"`
+	UnitID            string    `json:"unit_id,omitempty"`
+	SerialNumber      string    `json:"serial_number,omitempty"`
 	Name              string    `json:"name"`
 	Description       string    `json:"description"`
 	Price             string    `json:"price"`
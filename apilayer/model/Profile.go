@@ -22,6 +22,8 @@ type Profile struct {
 	OnlineStatus bool      `json:"online_status"`
 	Appearance   bool      `json:"appearance"`
 	GridView     bool      `json:"grid_view"`
+	UnitSystem   string    `json:"unit_system"`
+	BaseCurrency string    `json:"base_currency"`
 	CreatedAt    time.Time `json:"created_at,omitempty"`
 	CreatedBy    string    `json:"created_by,omitempty"`
 	Creator      string    `json:"creator,omitempty"`
@@ -53,9 +55,15 @@ type ProfileStats struct {
 // MaintenanceAlertNotifications ...
 // swagger:model MaintenanceAlertNotifications
 //
-// MaintenanceAlertNotifications object that returns the notifications alert for the maintenance plans that are within 7 days of being due
+// MaintenanceAlertNotifications object that returns the notifications alert for the maintenance plans that are within 7 days of being due.
+// Low stock alerts share the same object and are identified by the item_id of the inventory that dropped below its reorder point.
+// Expiry alerts are identified by the item_id and the batch_id of the inventory that is about to expire. The plan_due is the expiry date.
 type MaintenanceAlertNotifications struct {
 	ID             string    `json:"maintenance_plan_id"`
+	ItemID         string    `json:"item_id,omitempty"`
+	BatchID        string    `json:"batch_id,omitempty"`
+	Quantity       int       `json:"quantity,omitempty"`
+	ReorderPoint   int       `json:"reorder_point,omitempty"`
 	Name           string    `json:"name"`
 	Type           string    `json:"type"`
 	PlanDue        time.Time `json:"plan_due"`
@@ -72,6 +80,9 @@ type MaintenanceAlertNotifications struct {
 // MaintenanceAlertNotificationRequest object that is used to update the db based on user selection.
 type MaintenanceAlertNotificationRequest struct {
 	ID        string    `json:"maintenance_plan_id"`
+	ItemID    string    `json:"item_id,omitempty"`
+	BatchID   string    `json:"batch_id,omitempty"`
+	Type      string    `json:"type,omitempty"`
 	IsRead    bool      `json:"is_read"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 	UpdatedBy string    `json:"updated_by,omitempty"`
@@ -11,6 +11,10 @@ type Report struct {
 	SelectedTimeRange      time.Time `json:"selected_time_range"`
 	ItemValuation          float64   `json:"total_valuation"`
 	TotalCategoryItemsCost float64   `json:"cost_category_items"`
+	TotalBookValue         float64   `json:"total_book_value"`
+	CategoryItemsBookValue float64   `json:"book_value_category_items"`
+	Currency               string    `json:"currency"`
+	UnconvertedCurrencies  []string  `json:"unconverted_currencies,omitempty"`
 	CreatedAt              time.Time `json:"created_at"`
 	CreatedBy              string    `json:"created_by"`
 	CreatorName            string    `json:"creator_name"`
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SearchResult ...
+// swagger:model SearchResult
+//
+// SearchResult is a single ranked hit from the global search. Type is one of inventory, note, category or maintenance_plan
+type SearchResult struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// StockMovement ...
+// swagger:model StockMovement
+//
+// StockMovement is a single row in the quantity ledger of an inventory. The quantity of an inventory is the sum of all its stock movements
+type StockMovement struct {
+	ID                    string    `json:"id"`
+	ItemID                string    `json:"item_id"`
+	MovementType          string    `json:"movement_type"`
+	QuantityChange        int       `json:"quantity_change"`
+	Reason                string    `json:"reason"`
+	FromStorageLocationID string    `json:"from_storage_location_id,omitempty"`
+	ToStorageLocationID   string    `json:"to_storage_location_id,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	CreatedBy             string    `json:"created_by"`
+	Creator               string    `json:"creator"`
+	SharableGroups        []string  `json:"sharable_groups"`
+}
+
+// StockMovementRequest ...
+// swagger:model StockMovementRequest
+//
+// StockMovementRequest is used to record a consume, restock, adjustment or transfer of an inventory.
+// Quantity is always positive for consume and restock, and can be negative for an adjustment.
+// StorageLocationID is the destination of a transfer.
+type StockMovementRequest struct {
+	MovementType      string `json:"movement_type"`
+	Quantity          int    `json:"quantity"`
+	Reason            string `json:"reason"`
+	StorageLocationID string `json:"storage_location_id"`
+}
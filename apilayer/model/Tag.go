@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Tag ...
+// swagger:model Tag
+//
+// Tag is a lightweight colored label that can be attached to assets, notes and maintenance plans
+type Tag struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Color          string    `json:"color"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      string    `json:"created_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      string    `json:"updated_by"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
+
+// TagCount ...
+// swagger:model TagCount
+//
+// TagCount is the number of assets, notes and maintenance plans visible to the user that hold the tag
+type TagCount struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	Color                string `json:"color"`
+	InventoryCount       int    `json:"inventory_count"`
+	NoteCount            int    `json:"note_count"`
+	MaintenancePlanCount int    `json:"maintenance_plan_count"`
+	TotalCount           int    `json:"total_count"`
+}
+
+// TagAssignmentRequest ...
+// swagger:model TagAssignmentRequest
+//
+// TagAssignmentRequest replaces the tags of the selected asset, note or maintenance plan
+type TagAssignmentRequest struct {
+	TagIDs []string `json:"tag_ids"`
+}
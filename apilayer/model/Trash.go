@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TrashItem ...
+// swagger:model TrashItem
+//
+// TrashItem is a single deleted row in the trash. Type is one of inventory, note, category or maintenance_plan
+type TrashItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	DeletedAt      time.Time `json:"deleted_at"`
+	CreatedBy      string    `json:"created_by,omitempty"`
+	Creator        string    `json:"creator,omitempty"`
+	SharableGroups []string  `json:"sharable_groups"`
+}
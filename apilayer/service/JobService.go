@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+)
+
+const (
+	UNKNOWN_JOB_TYPE = "unknown job type"
+
+	DEFAULT_JOB_WORKER_COUNT = 2
+	DEFAULT_JOB_MAX_ATTEMPTS = 3
+
+	// JOB_POLL_INTERVAL is the time that an idle worker waits before it looks for a queued job again
+	JOB_POLL_INTERVAL = 2 * time.Second
+	// JOB_HEARTBEAT_INTERVAL is the time between each renewal of the lock on the running job
+	JOB_HEARTBEAT_INTERVAL = 10 * time.Second
+	// JOB_LOCK_TIMEOUT is the time after which a running job without a heartbeat is claimed by another worker
+	JOB_LOCK_TIMEOUT = 2 * time.Minute
+	// JOB_PROGRESS_INTERVAL is the min time between each saved progress of the running job
+	JOB_PROGRESS_INTERVAL = time.Second
+
+	// JOB_RETRY_BASE_DELAY is the delay before the first retry of a failed job. Each retry doubles the delay.
+	JOB_RETRY_BASE_DELAY = 30 * time.Second
+	JOB_RETRY_MAX_DELAY  = 30 * time.Minute
+)
+
+// JobProgressFunc ...
+//
+// JobProgressFunc saves the progress of the running job as the percent that is completed alongside a short message.
+// Returns an error once the job is cancelled so that the runner can stop.
+type JobProgressFunc func(progress int, message string) error
+
+// JobRunner ...
+//
+// JobRunner runs the selected job as the selected db user and returns the result of the job. ctx is cancelled when
+// the job is cancelled by the user or when the worker loses the lock on the job.
+type JobRunner func(ctx context.Context, user string, job model.Job, progress JobProgressFunc) (interface{}, error)
+
+// JobDefinition ...
+//
+// JobDefinition is a type of job that can be queued. Validate checks the payload when the job is queued and Run
+// runs the job. MaxAttempts defaults to DEFAULT_JOB_MAX_ATTEMPTS.
+type JobDefinition struct {
+	Validate    func(userID string, payload json.RawMessage) error
+	Run         JobRunner
+	MaxAttempts int
+}
+
+// permanentJobError is an error that is not retried
+type permanentJobError struct {
+	err error
+}
+
+func (e permanentJobError) Error() string { return e.err.Error() }
+func (e permanentJobError) Unwrap() error { return e.err }
+
+var (
+	jobDefinitionsMu sync.RWMutex
+	jobDefinitions   = make(map[string]JobDefinition)
+)
+
+// PermanentJobError ...
+//
+// PermanentJobError marks the selected error so that the job fails right away instead of being retried, eg when the
+// payload refers to a row that no longer exists.
+func PermanentJobError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentJobError{err: err}
+}
+
+// RegisterJob ...
+//
+// RegisterJob adds the selected type of job that can be queued and run by the workers. Registering the same type
+// again replaces the previous definition.
+func RegisterJob(jobType string, definition JobDefinition) {
+	if definition.MaxAttempts <= 0 {
+		definition.MaxAttempts = DEFAULT_JOB_MAX_ATTEMPTS
+	}
+	jobDefinitionsMu.Lock()
+	defer jobDefinitionsMu.Unlock()
+	jobDefinitions[jobType] = definition
+}
+
+// EnqueueJob ...
+//
+// EnqueueJob validates the payload of the selected job and queues the job for the selected user
+func EnqueueJob(user string, userID string, draftJob model.JobRequest) (*model.Job, error) {
+	definition, ok := retrieveJobDefinition(draftJob.Type)
+	if !ok {
+		config.Log("unable to find selected job type", nil)
+		return nil, fmt.Errorf("%s: %s", UNKNOWN_JOB_TYPE, draftJob.Type)
+	}
+
+	if definition.Validate != nil {
+		if err := definition.Validate(userID, draftJob.Payload); err != nil {
+			config.Log("unable to validate job payload", err)
+			return nil, fmt.Errorf("%s: %s", db.InvalidJob, err.Error())
+		}
+	}
+
+	return db.AddJob(user, userID, draftJob.Type, draftJob.Payload, definition.MaxAttempts)
+}
+
+// StartJobWorkers ...
+//
+// StartJobWorkers starts the pool of workers that run the queued jobs as the selected db user until ctx is done. The
+// size of the pool is read from JOB_WORKER_COUNT and defaults to DEFAULT_JOB_WORKER_COUNT; zero disables the workers.
+func StartJobWorkers(ctx context.Context, user string) {
+	workerCount := DEFAULT_JOB_WORKER_COUNT
+	if count := os.Getenv("JOB_WORKER_COUNT"); len(count) > 0 {
+		parsedCount, err := strconv.Atoi(count)
+		if err != nil || parsedCount < 0 {
+			config.Log("unable to parse job worker count. Using default - %d", err, DEFAULT_JOB_WORKER_COUNT)
+		} else {
+			workerCount = parsedCount
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	for i := 0; i < workerCount; i++ {
+		workerID := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), i)
+		go runJobWorker(ctx, user, workerID)
+	}
+	config.Log("started %d job workers", nil, workerCount)
+}
+
+// JobRetryDelay ...
+//
+// JobRetryDelay returns the delay before the selected attempt of a failed job is retried. The delay doubles with
+// each attempt up to JOB_RETRY_MAX_DELAY.
+func JobRetryDelay(attempt int) time.Duration {
+	delay := JOB_RETRY_BASE_DELAY
+	for i := 1; i < attempt && delay < JOB_RETRY_MAX_DELAY; i++ {
+		delay *= 2
+	}
+	return min(delay, JOB_RETRY_MAX_DELAY)
+}
+
+// retrieveJobDefinition ...
+//
+// returns the definition of the selected type of job
+func retrieveJobDefinition(jobType string) (JobDefinition, bool) {
+	jobDefinitionsMu.RLock()
+	defer jobDefinitionsMu.RUnlock()
+	definition, ok := jobDefinitions[jobType]
+	return definition, ok
+}
+
+// runJobWorker ...
+//
+// claims and runs the queued jobs one at a time until ctx is done
+func runJobWorker(ctx context.Context, user string, workerID string) {
+	for ctx.Err() == nil {
+		job, err := db.ClaimJob(user, workerID, JOB_LOCK_TIMEOUT)
+		if err != nil {
+			config.Log("unable to claim job", err)
+		}
+		if err == nil && job != nil {
+			runJob(ctx, user, workerID, *job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(JOB_POLL_INTERVAL):
+		}
+	}
+}
+
+// runJob ...
+//
+// runs the selected job while the lock on the job is renewed in the background. The job is finished as succeeded,
+// cancelled or failed, or queued again with a delay when attempts are left.
+func runJob(ctx context.Context, user string, workerID string, job model.Job) {
+	definition, ok := retrieveJobDefinition(job.Type)
+	if !ok {
+		config.Log("unable to find selected job type", nil)
+		if err := db.FinishJob(user, workerID, job.ID, db.JobStatusFailed, nil, UNKNOWN_JOB_TYPE); err != nil {
+			config.Log("unable to finish selected job", err)
+		}
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var isCancelRequested, isLockLost atomic.Bool
+	heartbeatDone := make(chan struct{})
+	var heartbeat sync.WaitGroup
+	heartbeat.Add(1)
+	go func() {
+		defer heartbeat.Done()
+		ticker := time.NewTicker(JOB_HEARTBEAT_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				cancelRequested, err := db.HeartbeatJob(user, workerID, job.ID)
+				if errors.Is(err, sql.ErrNoRows) {
+					isLockLost.Store(true)
+					cancel()
+					return
+				}
+				if err != nil {
+					config.Log("unable to renew lock on selected job", err)
+					continue
+				}
+				if cancelRequested {
+					isCancelRequested.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	var lastProgressAt time.Time
+	progress := func(percent int, message string) error {
+		if err := jobCtx.Err(); err != nil {
+			return err
+		}
+		if time.Since(lastProgressAt) < JOB_PROGRESS_INTERVAL {
+			return nil
+		}
+		lastProgressAt = time.Now()
+		err := db.UpdateJobProgress(user, workerID, job.ID, percent, message)
+		if errors.Is(err, sql.ErrNoRows) {
+			isLockLost.Store(true)
+			cancel()
+			return err
+		}
+		return nil
+	}
+
+	result, err := runJobSafely(jobCtx, definition.Run, user, job, progress)
+	close(heartbeatDone)
+	heartbeat.Wait()
+
+	switch {
+	case isLockLost.Load():
+		config.Log("lost lock on selected job", err)
+		return
+	case err == nil:
+		finishJob(user, workerID, job.ID, result)
+	case isCancelRequested.Load():
+		if err := db.FinishJob(user, workerID, job.ID, db.JobStatusCancelled, nil, ""); err != nil {
+			config.Log("unable to cancel selected job", err)
+		}
+	case ctx.Err() != nil:
+		// the workers are stopped, the job is queued again right away so that the next worker picks it up
+		if err := db.RetryJob(user, workerID, job.ID, time.Now(), err.Error()); err != nil {
+			config.Log("unable to queue selected job again", err)
+		}
+	case errors.As(err, &permanentJobError{}) || job.Attempts >= job.MaxAttempts:
+		config.Log("unable to run selected job", err)
+		if err := db.FinishJob(user, workerID, job.ID, db.JobStatusFailed, nil, err.Error()); err != nil {
+			config.Log("unable to finish selected job", err)
+		}
+	default:
+		config.Log("unable to run selected job. Retrying", err)
+		if err := db.RetryJob(user, workerID, job.ID, time.Now().Add(JobRetryDelay(job.Attempts)), err.Error()); err != nil {
+			config.Log("unable to queue selected job again", err)
+		}
+	}
+}
+
+// runJobSafely ...
+//
+// runs the selected job and returns a panic of the runner as an error so that a single job cannot stop the worker
+func runJobSafely(ctx context.Context, run JobRunner, user string, job model.Job, progress JobProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx, user, job, progress)
+}
+
+// finishJob ...
+//
+// saves the result of the succeeded job
+func finishJob(user string, workerID string, jobID string, result interface{}) {
+	var draftResult json.RawMessage
+	if result != nil {
+		content, err := json.Marshal(result)
+		if err != nil {
+			config.Log("unable to marshal job result", err)
+			if err := db.FinishJob(user, workerID, jobID, db.JobStatusFailed, nil, err.Error()); err != nil {
+				config.Log("unable to finish selected job", err)
+			}
+			return
+		}
+		draftResult = content
+	}
+	if err := db.FinishJob(user, workerID, jobID, db.JobStatusSucceeded, draftResult, ""); err != nil {
+		config.Log("unable to finish selected job", err)
+	}
+}
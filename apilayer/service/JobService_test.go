@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JobRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, JobRetryDelay(1))
+	assert.Equal(t, time.Minute, JobRetryDelay(2))
+	assert.Equal(t, 2*time.Minute, JobRetryDelay(3))
+	assert.Equal(t, JOB_RETRY_MAX_DELAY, JobRetryDelay(10))
+	assert.Equal(t, JOB_RETRY_MAX_DELAY, JobRetryDelay(100))
+}
+
+func Test_PermanentJobError(t *testing.T) {
+	err := errors.New("inventory import is already committed")
+
+	permanentErr := PermanentJobError(err)
+	assert.True(t, errors.As(permanentErr, &permanentJobError{}))
+	assert.True(t, errors.Is(permanentErr, err))
+	assert.Equal(t, err.Error(), permanentErr.Error())
+
+	assert.False(t, errors.As(err, &permanentJobError{}))
+	assert.Nil(t, PermanentJobError(nil))
+}
+
+func Test_EnqueueJob_UnknownType(t *testing.T) {
+	_, err := EnqueueJob("", "0802c692-b8e2-4824-a870-e52f4a0cccf8", model.JobRequest{Type: "unregistered_job"})
+	assert.ErrorContains(t, err, UNKNOWN_JOB_TYPE)
+}
+
+func Test_EnqueueJob_InvalidPayload(t *testing.T) {
+	RegisterJob("test_job", JobDefinition{
+		Validate: func(userID string, payload json.RawMessage) error { return errors.New("missing name") },
+	})
+	_, err := EnqueueJob("", "0802c692-b8e2-4824-a870-e52f4a0cccf8", model.JobRequest{Type: "test_job"})
+	assert.ErrorContains(t, err, "missing name")
+}
+
+func Test_RunJobSafely(t *testing.T) {
+	_, err := runJobSafely(context.Background(), func(_ context.Context, _ string, _ model.Job, _ JobProgressFunc) (interface{}, error) {
+		panic("unexpected")
+	}, "", model.Job{}, nil)
+	assert.ErrorContains(t, err, "unexpected")
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	LABEL_SYMBOLOGY_CODE128 = "code128"
+	LABEL_SYMBOLOGY_QR      = "qr"
+
+	DEFAULT_LABEL_IMAGE_SIZE = 256
+	MAX_LABEL_IMAGE_SIZE     = 2048
+
+	DEFAULT_AVERY_LAYOUT = "avery5160"
+
+	INVALID_LABEL_SYMBOLOGY = "invalid label symbology"
+	INVALID_LABEL_LAYOUT    = "invalid label layout"
+)
+
+// averyLayout ...
+//
+// averyLayout is the position of each label on a US letter sheet. All measurements are in inches.
+type averyLayout struct {
+	Columns         int
+	Rows            int
+	LabelWidth      float64
+	LabelHeight     float64
+	LeftMargin      float64
+	TopMargin       float64
+	HorizontalPitch float64
+	VerticalPitch   float64
+}
+
+// AVERY_LAYOUTS are the supported label sheets
+var AVERY_LAYOUTS = map[string]averyLayout{
+	"avery5160":  {Columns: 3, Rows: 10, LabelWidth: 2.625, LabelHeight: 1, LeftMargin: 0.1875, TopMargin: 0.5, HorizontalPitch: 2.75, VerticalPitch: 1},
+	"avery5163":  {Columns: 2, Rows: 5, LabelWidth: 4, LabelHeight: 2, LeftMargin: 0.15625, TopMargin: 0.5, HorizontalPitch: 4.1875, VerticalPitch: 2},
+	"avery5167":  {Columns: 4, Rows: 20, LabelWidth: 1.75, LabelHeight: 0.5, LeftMargin: 0.28125, TopMargin: 0.5, HorizontalPitch: 2.0625, VerticalPitch: 0.5},
+	"avery22805": {Columns: 4, Rows: 6, LabelWidth: 1.5, LabelHeight: 1.5, LeftMargin: 0.6875, TopMargin: 0.625, HorizontalPitch: 1.875, VerticalPitch: 1.65},
+}
+
+// BuildLabelDeepLink ...
+//
+// BuildLabelDeepLink returns the link to the web application that resolves back to the selected asset or storage location.
+// Storage locations resolve to the list of assets filtered by the selected location. The web application is read from
+// WEB_APPLICATION_URL since REACT_APP_LOCALHOST_URL points at the api.
+func BuildLabelDeepLink(label model.Label) string {
+	webApplicationEndpoint := strings.TrimRight(os.Getenv("WEB_APPLICATION_URL"), "/")
+	if len(webApplicationEndpoint) <= 0 {
+		config.Log("unable to determine the web application endpoint. defaulting to relative links", nil)
+	}
+
+	if label.Type == db.LabelTypeStorageLocation {
+		return fmt.Sprintf("%s/inventories/list?storageLocationID=%s", webApplicationEndpoint, url.QueryEscape(label.ID))
+	}
+	return fmt.Sprintf("%s/inventories/%s/update", webApplicationEndpoint, url.PathEscape(label.ID))
+}
+
+// GenerateLabelImage ...
+//
+// GenerateLabelImage renders the selected label as a PNG. QR labels encode the deep link of the label
+// while Code128 labels encode the barcode, sku or id of the label in that order.
+func GenerateLabelImage(label model.Label, symbology string, size int) ([]byte, error) {
+	if size <= 0 {
+		size = DEFAULT_LABEL_IMAGE_SIZE
+	}
+	if size > MAX_LABEL_IMAGE_SIZE {
+		size = MAX_LABEL_IMAGE_SIZE
+	}
+
+	img, err := encodeLabel(label, symbology, size)
+	if err != nil {
+		config.Log("unable to encode selected label", err)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		config.Log("unable to encode label image", err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateLabelSheet ...
+//
+// GenerateLabelSheet renders the selected labels as a PDF in the selected avery layout. Labels that do not fit
+// on a single sheet continue on the next page.
+func GenerateLabelSheet(labels []model.Label, symbology string, layoutName string) ([]byte, error) {
+	if len(layoutName) <= 0 {
+		layoutName = DEFAULT_AVERY_LAYOUT
+	}
+
+	layout, ok := AVERY_LAYOUTS[layoutName]
+	if !ok {
+		config.Log("unable to find selected layout", errors.New(INVALID_LABEL_LAYOUT))
+		return nil, errors.New(INVALID_LABEL_LAYOUT)
+	}
+
+	pdf := gofpdf.New("P", "in", "Letter", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 8)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	labelsPerPage := layout.Columns * layout.Rows
+	for i, label := range labels {
+		if i%labelsPerPage == 0 {
+			pdf.AddPage()
+		}
+
+		position := i % labelsPerPage
+		x := layout.LeftMargin + float64(position%layout.Columns)*layout.HorizontalPitch
+		y := layout.TopMargin + float64(position/layout.Columns)*layout.VerticalPitch
+
+		img, err := encodeLabel(label, symbology, DEFAULT_LABEL_IMAGE_SIZE)
+		if err != nil {
+			config.Log("unable to encode selected label", err)
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			config.Log("unable to encode label image", err)
+			return nil, err
+		}
+
+		imageName := fmt.Sprintf("label-%d", i)
+		pdf.RegisterImageOptionsReader(imageName, gofpdf.ImageOptions{ImageType: "PNG"}, &buf)
+		drawLabel(pdf, translate, label, symbology, imageName, x, y, layout.LabelWidth, layout.LabelHeight)
+	}
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		config.Log("unable to generate label sheet", err)
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// encodeLabel ...
+//
+// encodeLabel returns the scaled barcode of the selected label. Code128 falls back to the id if the barcode or sku
+// cannot be encoded.
+func encodeLabel(label model.Label, symbology string, size int) (image.Image, error) {
+	switch symbology {
+	case LABEL_SYMBOLOGY_QR:
+		code, err := qr.Encode(BuildLabelDeepLink(label), qr.M, qr.Auto)
+		if err != nil {
+			return nil, err
+		}
+		scaledCode, err := barcode.Scale(code, size, size)
+		if err != nil {
+			return nil, err
+		}
+		return toGrayImage(scaledCode), nil
+	case LABEL_SYMBOLOGY_CODE128:
+		code, err := code128.Encode(labelCode(label))
+		if err != nil {
+			code, err = code128.Encode(label.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		width := size
+		if code.Bounds().Dx() > width {
+			width = code.Bounds().Dx()
+		}
+		scaledCode, err := barcode.Scale(code, width, size/2)
+		if err != nil {
+			return nil, err
+		}
+		return toGrayImage(scaledCode), nil
+	}
+	return nil, errors.New(INVALID_LABEL_SYMBOLOGY)
+}
+
+// toGrayImage ...
+//
+// toGrayImage converts the selected barcode into an 8 bit grayscale image. Barcodes are rendered
+// with 16 bit depth which cannot be embedded in the label sheet.
+func toGrayImage(img image.Image) *image.Gray {
+	grayImage := image.NewGray(img.Bounds())
+	draw.Draw(grayImage, grayImage.Bounds(), img, img.Bounds().Min, draw.Src)
+	return grayImage
+}
+
+// labelCode ...
+//
+// labelCode returns the human readable code of the selected label
+func labelCode(label model.Label) string {
+	if len(label.Barcode) > 0 {
+		return label.Barcode
+	}
+	if len(label.SKU) > 0 {
+		return label.SKU
+	}
+	return label.ID
+}
+
+// drawLabel ...
+//
+// drawLabel places the barcode and the name of the selected label inside the label boundary. QR codes are
+// placed on the left with the text on the right unless the label is too narrow, in which case the text is placed below.
+func drawLabel(pdf *gofpdf.Fpdf, translate func(string) string, label model.Label, symbology string, imageName string, x, y, w, h float64) {
+	padding := 0.06
+	lineHeight := 0.14
+	imageOptions := gofpdf.ImageOptions{ImageType: "PNG"}
+
+	if symbology == LABEL_SYMBOLOGY_QR {
+		qrSize := h - 2*padding
+		if qrSize > w-2*padding {
+			qrSize = w - 2*padding
+		}
+
+		textX := x + 2*padding + qrSize
+		textWidth := x + w - padding - textX
+		if textWidth >= 0.5 {
+			pdf.ImageOptions(imageName, x+padding, y+padding, qrSize, qrSize, false, imageOptions, 0, "")
+			pdf.SetXY(textX, y+padding)
+			pdf.CellFormat(textWidth, lineHeight, fitText(pdf, translate(label.Name), textWidth), "", 2, "L", false, 0, "")
+			pdf.CellFormat(textWidth, lineHeight, fitText(pdf, translate(labelCode(label)), textWidth), "", 2, "L", false, 0, "")
+			return
+		}
+
+		qrSize -= lineHeight
+		pdf.ImageOptions(imageName, x+(w-qrSize)/2, y+padding, qrSize, qrSize, false, imageOptions, 0, "")
+		pdf.SetXY(x+padding, y+padding+qrSize)
+		pdf.CellFormat(w-2*padding, lineHeight, fitText(pdf, translate(label.Name), w-2*padding), "", 0, "C", false, 0, "")
+		return
+	}
+
+	barcodeHeight := h - 2*padding - 2*lineHeight
+	pdf.SetXY(x+padding, y+padding)
+	pdf.CellFormat(w-2*padding, lineHeight, fitText(pdf, translate(label.Name), w-2*padding), "", 0, "C", false, 0, "")
+	pdf.ImageOptions(imageName, x+padding, y+padding+lineHeight, w-2*padding, barcodeHeight, false, imageOptions, 0, "")
+	pdf.SetXY(x+padding, y+padding+lineHeight+barcodeHeight)
+	pdf.CellFormat(w-2*padding, lineHeight, fitText(pdf, translate(labelCode(label)), w-2*padding), "", 0, "C", false, 0, "")
+}
+
+// fitText ...
+//
+// fitText truncates the selected text so that it fits within the selected width
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
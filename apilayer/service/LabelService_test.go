@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/earmuff-jam/fleetwise/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BuildLabelDeepLink(t *testing.T) {
+
+	os.Setenv("REACT_APP_LOCALHOST_URL", "http://localhost:8087")
+	os.Setenv("WEB_APPLICATION_URL", "http://localhost:3000/")
+
+	inventoryLink := BuildLabelDeepLink(model.Label{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8", Type: "inventory"})
+	assert.Equal(t, "http://localhost:3000/inventories/0802c692-b8e2-4824-a870-e52f4a0cccf8/update", inventoryLink)
+
+	storageLocationLink := BuildLabelDeepLink(model.Label{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8", Type: "storage_location"})
+	assert.Equal(t, "http://localhost:3000/inventories/list?storageLocationID=0802c692-b8e2-4824-a870-e52f4a0cccf8", storageLocationLink)
+}
+
+func Test_GenerateLabelImage(t *testing.T) {
+
+	label := model.Label{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8", Type: "inventory", Name: "Tent", Barcode: "barcode#1123928"}
+
+	content, err := GenerateLabelImage(label, LABEL_SYMBOLOGY_QR, 128)
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, 128, img.Bounds().Dx())
+	assert.Equal(t, 128, img.Bounds().Dy())
+
+	content, err = GenerateLabelImage(label, LABEL_SYMBOLOGY_CODE128, 0)
+	assert.NoError(t, err)
+
+	img, err = png.Decode(bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, DEFAULT_LABEL_IMAGE_SIZE/2, img.Bounds().Dy())
+}
+
+func Test_GenerateLabelImage_InvalidSymbology(t *testing.T) {
+
+	_, err := GenerateLabelImage(model.Label{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8"}, "ean13", 128)
+	assert.EqualError(t, err, INVALID_LABEL_SYMBOLOGY)
+}
+
+func Test_GenerateLabelSheet(t *testing.T) {
+
+	var labels []model.Label
+	for i := 0; i < 35; i++ {
+		labels = append(labels, model.Label{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8", Type: "inventory", Name: "A very long asset name that does not fit in the label", SKU: "sku#123456734"})
+	}
+
+	for layoutName := range AVERY_LAYOUTS {
+		for _, symbology := range []string{LABEL_SYMBOLOGY_QR, LABEL_SYMBOLOGY_CODE128} {
+			content, err := GenerateLabelSheet(labels, symbology, layoutName)
+			assert.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(content, []byte("%PDF")))
+		}
+	}
+}
+
+func Test_GenerateLabelSheet_InvalidLayout(t *testing.T) {
+
+	_, err := GenerateLabelSheet([]model.Label{{ID: "0802c692-b8e2-4824-a870-e52f4a0cccf8"}}, LABEL_SYMBOLOGY_QR, "avery0000")
+	assert.EqualError(t, err, INVALID_LABEL_LAYOUT)
+}
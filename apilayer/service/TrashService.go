@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/earmuff-jam/fleetwise/config"
+	"github.com/earmuff-jam/fleetwise/db"
+)
+
+const (
+	DEFAULT_TRASH_RETENTION_DAYS = 30
+
+	// TRASH_PURGE_INTERVAL is the time between each purge of the trash
+	TRASH_PURGE_INTERVAL = 24 * time.Hour
+)
+
+// StartTrashPurge ...
+//
+// StartTrashPurge permanently removes the items that have been in the trash longer than TRASH_RETENTION_DAYS once
+// the api starts and then once every TRASH_PURGE_INTERVAL until ctx is done. The images and attachments of the purged
+// assets are removed from the bucket alongside.
+func StartTrashPurge(ctx context.Context, user string) {
+	retentionDays := DEFAULT_TRASH_RETENTION_DAYS
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); len(days) > 0 {
+		parsedDays, err := strconv.Atoi(days)
+		if err != nil || parsedDays <= 0 {
+			config.Log("unable to parse trash retention days. Using default - %d", err, DEFAULT_TRASH_RETENTION_DAYS)
+		} else {
+			retentionDays = parsedDays
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(TRASH_PURGE_INTERVAL)
+		defer ticker.Stop()
+		for {
+			if err := db.PurgeDeletedItems(user, retentionDays); err != nil {
+				config.Log("unable to purge trash", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,497 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	SpreadsheetFileTypeCSV  = "csv"
+	SpreadsheetFileTypeXLSX = "xlsx"
+
+	UnsupportedSpreadsheet = "unsupported spreadsheet. only csv and xlsx files are supported"
+	InvalidSpreadsheet     = "unable to read selected spreadsheet"
+	TooManySpreadsheetRows = "too many rows in the selected file"
+
+	// maxSpreadsheetRows is the number of rows of a worksheet in excel
+	maxSpreadsheetRows = 1048576
+
+	// maxSpreadsheetPartSize is the largest uncompressed part of an xlsx file that is read into memory
+	maxSpreadsheetPartSize = 64 << 20
+)
+
+// DetectSpreadsheetFileType ...
+//
+// DetectSpreadsheetFileType returns the file type of the selected spreadsheet from the file name. Files without
+// a known extension are sniffed, since xlsx files are zip archives and start with the zip signature.
+func DetectSpreadsheetFileType(fileName string, content []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		return SpreadsheetFileTypeCSV, nil
+	case ".xlsx":
+		return SpreadsheetFileTypeXLSX, nil
+	case ".xls", ".ods", ".numbers":
+		return "", errors.New(UnsupportedSpreadsheet)
+	}
+	if bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		return SpreadsheetFileTypeXLSX, nil
+	}
+	return SpreadsheetFileTypeCSV, nil
+}
+
+// ReadSpreadsheet ...
+//
+// ReadSpreadsheet returns every row of the selected csv file or of the first worksheet of the selected xlsx file.
+// The index of each row matches its row number in the spreadsheet, so empty rows are returned as empty slices.
+// Files with more than maxRows rows are rejected; maxRows defaults to the number of rows of a worksheet in excel.
+func ReadSpreadsheet(fileType string, content []byte, maxRows int) ([][]string, error) {
+	if maxRows <= 0 || maxRows > maxSpreadsheetRows {
+		maxRows = maxSpreadsheetRows
+	}
+	switch fileType {
+	case SpreadsheetFileTypeCSV:
+		return readCSV(content, maxRows)
+	case SpreadsheetFileTypeXLSX:
+		return readXLSX(content, maxRows)
+	}
+	return nil, errors.New(UnsupportedSpreadsheet)
+}
+
+// readCSV ...
+//
+// reads the selected csv file. The byte order mark added by excel is removed and files that use semicolons as the
+// separator, which excel does for locales with a decimal comma, are detected from the header.
+func readCSV(content []byte, maxRows int) ([][]string, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	header, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", InvalidSpreadsheet, err)
+		}
+		if len(rows) >= maxRows {
+			return nil, errors.New(TooManySpreadsheetRows)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// xlsxWorkbook is the list of worksheets in xl/workbook.xml
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is the list of parts referenced by xl/workbook.xml
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxSharedStrings is the list of strings shared across worksheets in xl/sharedStrings.xml
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is a plain or a rich text. Rich text is split into runs that are joined together
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// xlsxWorksheet is the list of rows of a single worksheet
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R            string   `xml:"r,attr"`
+			T            string   `xml:"t,attr"`
+			V            string   `xml:"v"`
+			InlineString xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// String ...
+//
+// returns the plain text or the joined runs of the rich text
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, v := range t.Runs {
+		sb.WriteString(v.T)
+	}
+	return sb.String()
+}
+
+// readXLSX ...
+//
+// reads the cells of the first worksheet of the selected xlsx file as they are displayed without formatting.
+// Formulas return their cached value and dates return their serial number.
+func readXLSX(content []byte, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", InvalidSpreadsheet, err)
+	}
+
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		parts[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	sheetPath, err := firstXLSXWorksheet(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if f, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := readXLSXPart(f, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetPart, ok := parts[sheetPath]
+	if !ok {
+		return nil, errors.New(InvalidSpreadsheet)
+	}
+
+	var worksheet xlsxWorksheet
+	if err := readXLSXPart(sheetPart, &worksheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(worksheet.Rows))
+	for _, row := range worksheet.Rows {
+		// rows without a row number follow the previous row
+		rowIndex := len(rows)
+		if row.R > 0 {
+			rowIndex = row.R - 1
+		}
+		// the row number is checked before the rows in between are added so that a single row with a large row
+		// number cannot allocate the whole sheet
+		if rowIndex >= maxRows {
+			return nil, errors.New(TooManySpreadsheetRows)
+		}
+		for len(rows) < rowIndex {
+			rows = append(rows, []string{})
+		}
+
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			columnIndex := len(cells)
+			if len(cell.R) > 0 {
+				columnIndex, err = xlsxColumnIndex(cell.R)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for len(cells) < columnIndex {
+				cells = append(cells, "")
+			}
+
+			var value string
+			switch cell.T {
+			case "s":
+				sharedStringIndex, err := strconv.Atoi(strings.TrimSpace(cell.V))
+				if err != nil || sharedStringIndex < 0 || sharedStringIndex >= len(sharedStrings.Items) {
+					return nil, errors.New(InvalidSpreadsheet)
+				}
+				value = sharedStrings.Items[sharedStringIndex].String()
+			case "inlineStr":
+				value = cell.InlineString.String()
+			case "b":
+				value = strconv.FormatBool(cell.V == "1")
+			default:
+				value = cell.V
+			}
+
+			if columnIndex < len(cells) {
+				cells[columnIndex] = value
+			} else {
+				cells = append(cells, value)
+			}
+		}
+
+		if rowIndex < len(rows) {
+			rows[rowIndex] = cells
+		} else {
+			rows = append(rows, cells)
+		}
+	}
+	return rows, nil
+}
+
+// firstXLSXWorksheet ...
+//
+// returns the path of the first worksheet in the workbook. Falls back to the default worksheet path when the
+// workbook does not reference its worksheets.
+func firstXLSXWorksheet(parts map[string]*zip.File) (string, error) {
+	defaultSheetPath := "xl/worksheets/sheet1.xml"
+
+	workbookPart, ok := parts["xl/workbook.xml"]
+	if !ok {
+		return defaultSheetPath, nil
+	}
+	var workbook xlsxWorkbook
+	if err := readXLSXPart(workbookPart, &workbook); err != nil {
+		return "", err
+	}
+
+	relationshipsPart, ok := parts["xl/_rels/workbook.xml.rels"]
+	if !ok || len(workbook.Sheets) == 0 {
+		return defaultSheetPath, nil
+	}
+	var relationships xlsxRelationships
+	if err := readXLSXPart(relationshipsPart, &relationships); err != nil {
+		return "", err
+	}
+
+	for _, v := range relationships.Relationships {
+		if v.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(v.Target, "/") {
+			return strings.TrimPrefix(v.Target, "/"), nil
+		}
+		return path.Join("xl", v.Target), nil
+	}
+	return defaultSheetPath, nil
+}
+
+// readXLSXPart ...
+//
+// decodes the selected xml part of the xlsx file. Parts larger than the max part size are rejected so that a
+// small compressed file cannot exhaust the memory.
+func readXLSXPart(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxSpreadsheetPartSize {
+		return errors.New(InvalidSpreadsheet)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%s: %w", InvalidSpreadsheet, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxSpreadsheetPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", InvalidSpreadsheet, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex ...
+//
+// returns the zero based column index of the selected cell reference, eg 0 for A1 and 27 for AB3
+func xlsxColumnIndex(cellReference string) (int, error) {
+	columnIndex := 0
+	for _, r := range strings.ToUpper(cellReference) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		columnIndex = columnIndex*26 + int(r-'A'+1)
+		if columnIndex > 16384 {
+			return 0, errors.New(InvalidSpreadsheet)
+		}
+	}
+	if columnIndex == 0 {
+		return 0, errors.New(InvalidSpreadsheet)
+	}
+	return columnIndex - 1, nil
+}
+
+// SpreadsheetWriter ...
+//
+// SpreadsheetWriter streams rows into a csv file or into the first worksheet of an xlsx file. Values that are an
+// int, int64 or float64 are written as numbers and the remaining values are written as text. Close must be called
+// once every row is written.
+type SpreadsheetWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewSpreadsheetWriter ...
+//
+// NewSpreadsheetWriter returns the writer that streams rows of the selected file type into the selected writer
+func NewSpreadsheetWriter(fileType string, w io.Writer) (SpreadsheetWriter, error) {
+	switch fileType {
+	case SpreadsheetFileTypeCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case SpreadsheetFileTypeXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, errors.New(UnsupportedSpreadsheet)
+}
+
+// csvWriter streams rows into a csv file
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+// WriteRow ...
+//
+// writes the selected values as a single record
+func (w *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatSpreadsheetValue(v)
+	}
+	return w.writer.Write(record)
+}
+
+// Close ...
+//
+// flushes the buffered records into the underlying writer
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// xlsxStaticParts are the parts of the xlsx file that do not depend on the rows. The worksheet is streamed after them.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// xlsxWriter streams rows into the first worksheet of an xlsx file. Text is written as inline strings so that the
+// rows do not have to be held in memory to build the shared strings.
+type xlsxWriter struct {
+	archive  *zip.Writer
+	sheet    io.Writer
+	rowCount int
+	buf      bytes.Buffer
+}
+
+// newXLSXWriter ...
+//
+// writes the static parts of the xlsx file and starts the worksheet
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, v := range xlsxStaticParts {
+		part, err := archive.Create(v.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(part, v.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+// WriteRow ...
+//
+// writes the selected values as the next row of the worksheet. Nil values are left as empty cells.
+func (w *xlsxWriter) WriteRow(values []interface{}) error {
+	w.rowCount++
+	w.buf.Reset()
+	fmt.Fprintf(&w.buf, `<row r="%d">`, w.rowCount)
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		cellReference := xlsxColumnName(i) + strconv.Itoa(w.rowCount)
+		switch value := v.(type) {
+		case int, int64:
+			fmt.Fprintf(&w.buf, `<c r="%s"><v>%d</v></c>`, cellReference, value)
+		case float64:
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return errors.New(InvalidSpreadsheet)
+			}
+			fmt.Fprintf(&w.buf, `<c r="%s"><v>%s</v></c>`, cellReference, strconv.FormatFloat(value, 'f', -1, 64))
+		default:
+			fmt.Fprintf(&w.buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, cellReference)
+			if err := xml.EscapeText(&w.buf, []byte(formatSpreadsheetValue(value))); err != nil {
+				return err
+			}
+			w.buf.WriteString(`</t></is></c>`)
+		}
+	}
+	w.buf.WriteString(`</row>`)
+
+	_, err := w.sheet.Write(w.buf.Bytes())
+	return err
+}
+
+// Close ...
+//
+// ends the worksheet and writes the central directory of the xlsx file
+func (w *xlsxWriter) Close() error {
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// formatSpreadsheetValue ...
+//
+// returns the selected value as it is written into a single cell
+func formatSpreadsheetValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// xlsxColumnName ...
+//
+// returns the column name of the selected zero based column index, eg A for 0 and AB for 27
+func xlsxColumnName(columnIndex int) string {
+	var name []byte
+	for columnIndex++; columnIndex > 0; columnIndex = (columnIndex - 1) / 26 {
+		name = append([]byte{byte('A' + (columnIndex-1)%26)}, name...)
+	}
+	return string(name)
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DetectSpreadsheetFileType(t *testing.T) {
+
+	fileType, err := DetectSpreadsheetFileType("assets.CSV", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, SpreadsheetFileTypeCSV, fileType)
+
+	fileType, err = DetectSpreadsheetFileType("assets.xlsx", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, SpreadsheetFileTypeXLSX, fileType)
+
+	fileType, err = DetectSpreadsheetFileType("blob", []byte("PK\x03\x04"))
+	assert.NoError(t, err)
+	assert.Equal(t, SpreadsheetFileTypeXLSX, fileType)
+
+	_, err = DetectSpreadsheetFileType("assets.xls", nil)
+	assert.Error(t, err)
+	assert.Equal(t, UnsupportedSpreadsheet, err.Error())
+}
+
+func Test_ReadSpreadsheet_CSV(t *testing.T) {
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeCSV, []byte("\ufeffName,Price\nHammer,12.50\n\"Drill, cordless\",99\n"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Name", "Price"}, {"Hammer", "12.50"}, {"Drill, cordless", "99"}}, rows)
+}
+
+func Test_ReadSpreadsheet_CSVWithSemicolons(t *testing.T) {
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeCSV, []byte("Name;Price\nHammer;12,50\n"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Name", "Price"}, {"Hammer", "12,50"}}, rows)
+}
+
+func Test_ReadSpreadsheet_InvalidCSV(t *testing.T) {
+
+	_, err := ReadSpreadsheet(SpreadsheetFileTypeCSV, []byte("Name,Price\n\"Hammer,12.50\n"), 0)
+	assert.Error(t, err)
+}
+
+func Test_ReadSpreadsheet_XLSX(t *testing.T) {
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Assets" sheetId="1" r:id="rId2"/><sheet name="Other" sheetId="2" r:id="rId1"/></sheets>
+		</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+			<Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/>
+		</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<si><t>Name</t></si><si><t>Price</t></si><si><r><t>Ham</t></r><r><t>mer</t></r></si>
+		</sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="inlineStr"><is><t>Wrong sheet</t></is></c></row>
+		</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+			<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>12.5</v></c></row>
+			<row r="4"><c r="B4" t="inlineStr"><is><t>Drill</t></is></c><c r="C4" t="b"><v>1</v></c></row>
+		</sheetData></worksheet>`,
+	}
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeXLSX, buildXLSX(t, parts), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Name", "Price"}, {"Hammer", "", "12.5"}, {}, {"", "Drill", "true"}}, rows)
+}
+
+func Test_ReadSpreadsheet_InvalidXLSX(t *testing.T) {
+
+	_, err := ReadSpreadsheet(SpreadsheetFileTypeXLSX, []byte("Name,Price"), 0)
+	assert.Error(t, err)
+}
+
+func Test_ReadSpreadsheet_XLSXWithLargeRowNumber(t *testing.T) {
+
+	sheet := func(rowNumber string) []byte {
+		return buildXLSX(t, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+				<sheets><sheet name="Assets" sheetId="1" r:id="rId1"/></sheets>
+			</workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+				<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+			</Relationships>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+				<row r="1"><c r="A1" t="inlineStr"><is><t>Name</t></is></c></row>
+				<row r="` + rowNumber + `"><c r="A1" t="inlineStr"><is><t>Hammer</t></is></c></row>
+			</sheetData></worksheet>`,
+		})
+	}
+
+	// rows past the end of an excel worksheet are rejected before any row is added
+	_, err := ReadSpreadsheet(SpreadsheetFileTypeXLSX, sheet("2000000000"), 0)
+	assert.Error(t, err)
+	assert.Equal(t, TooManySpreadsheetRows, err.Error())
+
+	_, err = ReadSpreadsheet(SpreadsheetFileTypeXLSX, sheet("5002"), 5001)
+	assert.Error(t, err)
+	assert.Equal(t, TooManySpreadsheetRows, err.Error())
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeXLSX, sheet("5001"), 5001)
+	assert.NoError(t, err)
+	assert.Equal(t, 5001, len(rows))
+}
+
+func Test_ReadSpreadsheet_CSVWithTooManyRows(t *testing.T) {
+
+	_, err := ReadSpreadsheet(SpreadsheetFileTypeCSV, []byte("Name\nHammer\nDrill\n"), 2)
+	assert.Error(t, err)
+	assert.Equal(t, TooManySpreadsheetRows, err.Error())
+}
+
+// buildXLSX ...
+//
+// returns the xlsx file with the selected parts
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func Test_SpreadsheetWriter_CSV(t *testing.T) {
+
+	var buf bytes.Buffer
+	w, err := NewSpreadsheetWriter(SpreadsheetFileTypeCSV, &buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow([]interface{}{"Name", "Price", "Quantity"}))
+	assert.NoError(t, w.WriteRow([]interface{}{"Hammer, claw", 12.5, 2}))
+	assert.NoError(t, w.Close())
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeCSV, buf.Bytes(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Name", "Price", "Quantity"}, {"Hammer, claw", "12.5", "2"}}, rows)
+}
+
+func Test_SpreadsheetWriter_XLSX(t *testing.T) {
+
+	var buf bytes.Buffer
+	w, err := NewSpreadsheetWriter(SpreadsheetFileTypeXLSX, &buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow([]interface{}{"Name", "Price", "Quantity"}))
+	assert.NoError(t, w.WriteRow([]interface{}{" Hammer <claw> & nails", 12.5, 2}))
+	assert.NoError(t, w.WriteRow([]interface{}{"Drill", nil, int64(1)}))
+	assert.NoError(t, w.Close())
+
+	fileType, err := DetectSpreadsheetFileType("inventories", buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, SpreadsheetFileTypeXLSX, fileType)
+
+	rows, err := ReadSpreadsheet(SpreadsheetFileTypeXLSX, buf.Bytes(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Name", "Price", "Quantity"}, {" Hammer <claw> & nails", "12.5", "2"}, {"Drill", "", "1"}}, rows)
+}
+
+func Test_SpreadsheetWriter_UnsupportedFileType(t *testing.T) {
+
+	var buf bytes.Buffer
+	_, err := NewSpreadsheetWriter("ods", &buf)
+	assert.Error(t, err)
+}
+
+func Test_xlsxColumnName(t *testing.T) {
+
+	for columnIndex, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, xlsxColumnName(columnIndex))
+		index, err := xlsxColumnIndex(name + "1")
+		assert.NoError(t, err)
+		assert.Equal(t, columnIndex, index)
+	}
+}
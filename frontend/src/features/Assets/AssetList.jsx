@@ -1,6 +1,7 @@
 import { useEffect, useState } from 'react';
 
 import { useDispatch, useSelector } from 'react-redux';
+import { useSearchParams } from 'react-router-dom';
 
 import { Stack } from '@mui/material';
 import { ConfirmationBoxModal } from '@common/utils';
@@ -11,6 +12,7 @@ import AssetListContent from '@features/Assets/AssetListContent/AssetListContent
 
 export default function AssetList() {
   const dispatch = useDispatch();
+  const [searchParams] = useSearchParams();
   const { loading, inventories = [] } = useSelector((state) => state.inventory);
 
   const [options, setOptions] = useState([]);
@@ -48,9 +50,12 @@ export default function AssetList() {
     }
   }, [loading]);
 
+  // printed labels of storage locations link to the list filtered by the selected location
+  const storageLocationID = searchParams.get('storageLocationID');
+
   useEffect(() => {
-    dispatch(inventoryActions.getAllInventoriesForUser());
-  }, []);
+    dispatch(inventoryActions.getAllInventoriesForUser(storageLocationID ? { storageLocationID } : undefined));
+  }, [storageLocationID]);
 
   return (
     <Stack flexGrow="1" spacing={2} data-tour="assets-0">
@@ -13,8 +13,13 @@ export function* fetchAllInventoriesForUser(action) {
     const USER_ID = localStorage.getItem('userID');
 
     if (action.payload) {
-      const { since } = action.payload;
-      params.append('since', since);
+      const { since, storageLocationID } = action.payload;
+      if (since) {
+        params.append('since', since);
+      }
+      if (storageLocationID) {
+        params.append('storageLocationID', storageLocationID);
+      }
     }
 
     const response = yield call(instance.get, `${BASEURL}/profile/${USER_ID}/inventories?${params.toString()}`);
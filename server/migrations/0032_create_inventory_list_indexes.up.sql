@@ -0,0 +1,11 @@
+-- File: 0032_create_inventory_list_indexes.up.sql
+-- Description: Create indexes to support pagination, sorting and filtering of the inventory list
+
+SET search_path TO community, public;
+
+CREATE INDEX IF NOT EXISTS inventory_created_by_updated_at_idx ON community.inventory (created_by, updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS inventory_created_by_name_idx ON community.inventory (created_by, name, id);
+CREATE INDEX IF NOT EXISTS inventory_created_by_price_idx ON community.inventory (created_by, price, id);
+CREATE INDEX IF NOT EXISTS inventory_storage_location_id_idx ON community.inventory (storage_location_id);
+CREATE INDEX IF NOT EXISTS category_item_item_id_idx ON community.category_item (item_id, category_id);
+CREATE INDEX IF NOT EXISTS maintenance_item_item_id_idx ON community.maintenance_item (item_id, maintenance_plan_id);
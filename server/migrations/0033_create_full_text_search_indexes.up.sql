@@ -0,0 +1,40 @@
+-- File: 0033_create_full_text_search_indexes.up.sql
+-- Description: Create full text search vectors and indexes for inventory, notes, categories and maintenance plans.
+-- Note:- sku and barcode use the simple dictionary so that codes are not stemmed --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(sku, '') || ' ' || COALESCE(barcode, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+ALTER TABLE community.notes
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+ALTER TABLE community.category
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+ALTER TABLE community.maintenance_plan
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS inventory_search_vector_idx ON community.inventory USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS notes_search_vector_idx ON community.notes USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS category_search_vector_idx ON community.category USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS maintenance_plan_search_vector_idx ON community.maintenance_plan USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS inventory_sharable_groups_idx ON community.inventory USING GIN (sharable_groups);
+CREATE INDEX IF NOT EXISTS notes_sharable_groups_idx ON community.notes USING GIN (sharable_groups);
+CREATE INDEX IF NOT EXISTS category_sharable_groups_idx ON community.category USING GIN (sharable_groups);
+CREATE INDEX IF NOT EXISTS maintenance_plan_sharable_groups_idx ON community.maintenance_plan USING GIN (sharable_groups);
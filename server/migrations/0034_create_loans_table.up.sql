@@ -0,0 +1,57 @@
+-- File: 0034_create_loans_table.up.sql
+-- Description: Create the loans and loan items tables. Used to check out assets to a borrower and check them back in.
+-- Note:- an asset can only be part of a single open loan at any given time --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.loans
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    borrower_name       VARCHAR(100)                 NOT NULL,
+    borrower_email      VARCHAR(100),
+    borrower_id         UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    due_date            TIMESTAMP WITH TIME ZONE     NOT NULL,
+    checked_out_at      TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    notes               VARCHAR(500),
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE loans IS 'assets that are checked out to a borrower';
+
+ALTER TABLE community.loans
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.loans TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.loans TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.loans TO community_admin;
+
+CREATE TABLE IF NOT EXISTS community.loan_items
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    loan_id             UUID                         NOT NULL REFERENCES loans (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    item_id             UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    checked_in_at       TIMESTAMP WITH TIME ZONE,
+    checked_in_by       UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    condition_notes     VARCHAR(500),
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE loan_items IS 'consists of assets that belong to a specific loan and their check in details';
+
+CREATE UNIQUE INDEX IF NOT EXISTS loan_items_open_item_id_idx ON community.loan_items (item_id) WHERE checked_in_at IS NULL;
+CREATE INDEX IF NOT EXISTS loan_items_loan_id_idx ON community.loan_items (loan_id);
+
+ALTER TABLE community.loan_items
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.loan_items TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.loan_items TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.loan_items TO community_admin;
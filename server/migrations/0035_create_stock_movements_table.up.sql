@@ -0,0 +1,176 @@
+-- File: 0035_create_stock_movements_table.up.sql
+-- Description: Create the stock movements ledger and the low stock alert table. The quantity of each inventory
+-- is derived from the sum of all its stock movements. Inventories that drop below their reorder point raise a low stock alert.
+-- Note:- updated_by for low stock alert is not forced to use the UUID because the system can make this change automatically --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory
+ADD COLUMN IF NOT EXISTS reorder_point INT CHECK (reorder_point >= 0);
+
+CREATE TABLE IF NOT EXISTS community.stock_movements
+(
+    id                          UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id                     UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    movement_type               VARCHAR(20)                  NOT NULL CHECK (movement_type IN ('consume', 'restock', 'adjustment', 'transfer')),
+    quantity_change             INT                          NOT NULL DEFAULT 0,
+    reason                      VARCHAR(500),
+    from_storage_location_id    UUID                         REFERENCES storage_locations (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    to_storage_location_id      UUID                         REFERENCES storage_locations (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    created_at                  TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by                  UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups             UUID[]
+);
+
+COMMENT ON TABLE stock_movements IS 'ledger of every consume, restock, adjustment and transfer of an inventory. Rows are never updated.';
+
+CREATE INDEX IF NOT EXISTS stock_movements_item_id_created_at_idx ON community.stock_movements (item_id, created_at DESC);
+
+ALTER TABLE community.stock_movements
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT ON community.stock_movements TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.stock_movements TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.stock_movements TO community_admin;
+
+CREATE TABLE IF NOT EXISTS community.low_stock_alert
+(
+    id                      UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id                 UUID                         UNIQUE REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name                    VARCHAR(100),
+    quantity                INT                          NOT NULL DEFAULT 0,
+    reorder_point           INT                          NOT NULL DEFAULT 0,
+    is_read                 BOOLEAN                               DEFAULT false,
+    updated_by              TEXT,
+    updated_at              TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    sharable_groups         UUID[]
+);
+
+COMMENT ON TABLE low_stock_alert IS 'table to support list of inventories that are below their reorder point. Derieved from the inventory table.';
+
+ALTER TABLE community.low_stock_alert
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.low_stock_alert TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.low_stock_alert TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.low_stock_alert TO community_admin;
+
+--
+-- opening balance for all existing inventories so that the ledger matches the current quantity --
+--
+INSERT INTO community.stock_movements (item_id, movement_type, quantity_change, reason, to_storage_location_id, created_at, created_by, sharable_groups)
+SELECT
+    inv.id,
+    'adjustment',
+    COALESCE(inv.quantity, 0),
+    'opening balance',
+    inv.storage_location_id,
+    NOW(),
+    inv.created_by,
+    inv.sharable_groups
+FROM community.inventory inv
+WHERE COALESCE(inv.quantity, 0) <> 0;
+
+--
+-- utility fn used to derive the inventory quantity from the ledger --
+-- transfers move the inventory to the destination storage location --
+--
+DROP FUNCTION IF EXISTS community.sync_inventory_quantity_from_stock_movements_fn() CASCADE;
+CREATE FUNCTION community.sync_inventory_quantity_from_stock_movements_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+    UPDATE community.inventory inv
+    SET
+        quantity = (SELECT COALESCE(SUM(sm.quantity_change), 0) FROM community.stock_movements sm WHERE sm.item_id = NEW.item_id),
+        storage_location_id = COALESCE(NEW.to_storage_location_id, inv.storage_location_id),
+        location = COALESCE((SELECT sl.location FROM community.storage_locations sl WHERE sl.id = NEW.to_storage_location_id), inv.location)
+    WHERE inv.id = NEW.item_id;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS sync_inventory_quantity_from_stock_movements_trigger ON community.stock_movements;
+CREATE TRIGGER sync_inventory_quantity_from_stock_movements_trigger
+    AFTER INSERT
+        ON community.stock_movements
+    FOR EACH ROW
+EXECUTE FUNCTION community.sync_inventory_quantity_from_stock_movements_fn();
+
+--
+-- utility fn used to record an adjustment in the ledger when the quantity is written directly --
+-- eg, UpdateAsset, UpdateInventory, AddInventory and bulk uploads --
+--
+DROP FUNCTION IF EXISTS community.record_stock_movement_on_quantity_change_fn() CASCADE;
+CREATE FUNCTION community.record_stock_movement_on_quantity_change_fn()
+    RETURNS trigger AS
+$$
+DECLARE
+    ledger_quantity INT;
+BEGIN
+    SELECT COALESCE(SUM(sm.quantity_change), 0) INTO ledger_quantity
+    FROM community.stock_movements sm
+    WHERE sm.item_id = NEW.id;
+
+    IF COALESCE(NEW.quantity, 0) <> ledger_quantity THEN
+        INSERT INTO community.stock_movements (item_id, movement_type, quantity_change, reason, created_at, created_by, sharable_groups)
+        VALUES (
+            NEW.id,
+            'adjustment',
+            COALESCE(NEW.quantity, 0) - ledger_quantity,
+            CASE WHEN TG_OP = 'INSERT' THEN 'opening balance' ELSE 'manual adjustment' END,
+            NOW(),
+            NEW.updated_by,
+            NEW.sharable_groups
+        );
+    END IF;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_stock_movement_on_quantity_change_trigger ON community.inventory;
+CREATE TRIGGER record_stock_movement_on_quantity_change_trigger
+    AFTER INSERT OR UPDATE OF quantity
+        ON community.inventory
+    FOR EACH ROW
+EXECUTE FUNCTION community.record_stock_movement_on_quantity_change_fn();
+
+--
+-- utility fn used to raise or clear the low stock alert --
+--
+DROP FUNCTION IF EXISTS community.update_low_stock_alert_fn() CASCADE;
+CREATE FUNCTION community.update_low_stock_alert_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.reorder_point IS NOT NULL AND COALESCE(NEW.quantity, 0) < NEW.reorder_point THEN
+        INSERT INTO community.low_stock_alert (item_id, name, quantity, reorder_point, is_read, updated_by, updated_at, sharable_groups)
+        VALUES (NEW.id, NEW.name, COALESCE(NEW.quantity, 0), NEW.reorder_point, false, NEW.updated_by::TEXT, NOW(), NEW.sharable_groups)
+        ON CONFLICT (item_id) DO UPDATE
+        SET
+            is_read = CASE
+                WHEN community.low_stock_alert.quantity IS DISTINCT FROM EXCLUDED.quantity
+                THEN false
+                ELSE community.low_stock_alert.is_read
+            END,
+            name = EXCLUDED.name,
+            quantity = EXCLUDED.quantity,
+            reorder_point = EXCLUDED.reorder_point,
+            sharable_groups = EXCLUDED.sharable_groups,
+            updated_at = NOW();
+    ELSE
+        DELETE FROM community.low_stock_alert lsa WHERE lsa.item_id = NEW.id;
+    END IF;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_low_stock_alert_trigger ON community.inventory;
+CREATE TRIGGER update_low_stock_alert_trigger
+    AFTER INSERT OR UPDATE OF quantity, reorder_point
+        ON community.inventory
+    FOR EACH ROW
+EXECUTE FUNCTION community.update_low_stock_alert_fn();
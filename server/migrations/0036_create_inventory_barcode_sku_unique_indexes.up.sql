@@ -0,0 +1,38 @@
+-- File: 0036_create_inventory_barcode_sku_unique_indexes.up.sql
+-- Description: Enforce unique barcode and sku for each owner. Used by the scanner lookup to resolve a single asset.
+-- Note:- existing duplicates are not changed. The migration fails with the list of duplicates so that each owner can resolve them --
+
+SET search_path TO community, public;
+
+--
+-- barcodes and skus are printed on labels and referenced outside the app, so duplicates must be resolved by the owner --
+--
+DO
+$$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(FORMAT('owner %s has %s %L on assets %s', d.created_by, d.field, d.value, d.ids), E'\n' ORDER BY d.created_by, d.field, d.value)
+    INTO conflicts
+    FROM (
+        SELECT inv.created_by, 'barcode' AS field, inv.barcode AS value, string_agg(inv.id::TEXT, ', ' ORDER BY inv.created_at, inv.id) AS ids
+        FROM community.inventory inv
+        WHERE inv.barcode IS NOT NULL AND inv.barcode <> ''
+        GROUP BY inv.created_by, inv.barcode
+        HAVING COUNT(*) > 1
+        UNION ALL
+        SELECT inv.created_by, 'sku' AS field, inv.sku AS value, string_agg(inv.id::TEXT, ', ' ORDER BY inv.created_at, inv.id) AS ids
+        FROM community.inventory inv
+        WHERE inv.sku IS NOT NULL AND inv.sku <> ''
+        GROUP BY inv.created_by, inv.sku
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'unable to create unique barcode and sku indexes. resolve the duplicate barcodes and skus and run the migration again:%', E'\n' || conflicts;
+    END IF;
+END;
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_created_by_barcode_unique_idx ON community.inventory (created_by, barcode) WHERE barcode IS NOT NULL AND barcode <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_created_by_sku_unique_idx ON community.inventory (created_by, sku) WHERE sku IS NOT NULL AND sku <> '';
@@ -0,0 +1,30 @@
+-- File: 0037_create_inventory_revisions_table.up.sql
+-- Description: Create the inventory revisions table. Each row is the field level diff of a single change made to an inventory.
+-- Note:- changes are stored as { "column": { "before": value, "after": value } } --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.inventory_revisions
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    revision_number     BIGSERIAL                    NOT NULL,
+    item_id             UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    action              VARCHAR(20)                  NOT NULL CHECK (action IN ('update', 'image', 'revert')),
+    changes             JSONB                        NOT NULL DEFAULT '{}'::JSONB,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_revisions IS 'field level history of every change made to an inventory. Used to view and revert prior revisions.';
+
+CREATE INDEX IF NOT EXISTS inventory_revisions_item_id_revision_number_idx ON community.inventory_revisions (item_id, revision_number DESC);
+
+ALTER TABLE community.inventory_revisions
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT ON community.inventory_revisions TO community_public;
+GRANT USAGE, SELECT ON SEQUENCE community.inventory_revisions_revision_number_seq TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_revisions TO community_test;
+GRANT USAGE, SELECT ON SEQUENCE community.inventory_revisions_revision_number_seq TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_revisions TO community_admin;
@@ -0,0 +1,151 @@
+-- File: 0038_create_soft_delete_trash.up.sql
+-- Description: Soft delete support for inventory, category, maintenance plan and notes. Deleted rows are moved to the trash
+-- by setting deleted_at and are permanently removed by the purge job once the retention period has passed.
+-- Note:- associations in category_item and maintenance_item are retained so that restoring a row restores them as well --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE community.category ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE community.maintenance_plan ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE community.notes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS inventory_deleted_at_idx ON community.inventory (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS category_deleted_at_idx ON community.category (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS maintenance_plan_deleted_at_idx ON community.maintenance_plan (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS notes_deleted_at_idx ON community.notes (deleted_at) WHERE deleted_at IS NOT NULL;
+
+--
+-- assets in the trash do not hold on to their barcode or sku. restoring an asset whose barcode or sku has been reused is rejected. --
+--
+DROP INDEX IF EXISTS community.inventory_created_by_barcode_unique_idx;
+DROP INDEX IF EXISTS community.inventory_created_by_sku_unique_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_created_by_barcode_unique_idx ON community.inventory (created_by, barcode) WHERE barcode IS NOT NULL AND barcode <> '' AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_created_by_sku_unique_idx ON community.inventory (created_by, sku) WHERE sku IS NOT NULL AND sku <> '' AND deleted_at IS NULL;
+
+--
+-- recent activities record moving to and restoring from the trash instead of a generic update --
+--
+CREATE OR REPLACE FUNCTION community.update_recent_activities_categories_fn()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO community.recent_activities (activity_id, type, title, custom_action, created_by, created_at, updated_by,
+                                             updated_at, sharable_groups)
+    VALUES (new.id, 'C', new.name,
+            CASE
+                WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL THEN 'moved to trash'
+                WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL THEN 'restored'
+                ELSE 'updated'
+            END,
+            new.created_by, new.created_at, new.updated_by, new.updated_at, new.sharable_groups);
+    RETURN new;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+CREATE OR REPLACE FUNCTION community.update_recent_activities_maintenance_plan_fn()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO community.recent_activities (activity_id, type, title, custom_action, created_by, created_at, updated_by,
+                                             updated_at, sharable_groups)
+    VALUES (new.id, 'M', new.name,
+            CASE
+                WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL THEN 'moved to trash'
+                WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL THEN 'restored'
+                ELSE 'updated'
+            END,
+            new.created_by, new.created_at, new.updated_by, new.updated_at, new.sharable_groups);
+    RETURN new;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+CREATE OR REPLACE FUNCTION community.update_recent_activities_asset_fn()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO community.recent_activities (activity_id, type, title, custom_action, created_by, created_at, updated_by,
+                                             updated_at, sharable_groups)
+    VALUES (new.id, 'A', new.name,
+            CASE
+                WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL THEN 'moved to trash'
+                WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL THEN 'restored'
+                ELSE 'updated'
+            END,
+            new.created_by, new.created_at, new.updated_by, new.updated_at, new.sharable_groups);
+    RETURN new;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+--
+-- maintenance plans in the trash do not raise maintenance alerts --
+--
+CREATE OR REPLACE FUNCTION community.populate_maintenance_alerts()
+RETURNS void AS
+$$
+BEGIN
+    INSERT INTO community.maintenance_alert (maintenance_plan_id, name, type, plan_due, is_read, updated_by, updated_at, sharable_groups)
+    SELECT
+        mp.id,
+        mp.name,
+        mp.plan_type,
+        mp.plan_due,
+        false,
+        'system',
+        NOW(),
+        mp.sharable_groups
+    FROM community.maintenance_plan mp
+    WHERE mp.plan_due::DATE BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
+    AND mp.deleted_at IS NULL
+     ON CONFLICT (maintenance_plan_id) DO UPDATE
+    SET
+        plan_due = EXCLUDED.plan_due,
+        updated_at = NOW();
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION community.update_maintenance_alert_function_on_plan_due_change_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+INSERT INTO community.maintenance_alert (maintenance_plan_id, name, type, plan_due, is_read, updated_by, updated_at, sharable_groups)
+    SELECT
+        mp.id,
+        mp.name,
+        mp.plan_type,
+        mp.plan_due,
+        false,
+        NEW.updated_by::TEXT,
+        NOW(),
+        mp.sharable_groups
+    FROM community.maintenance_plan mp
+    WHERE mp.plan_due::DATE BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
+    AND mp.deleted_at IS NULL
+     ON CONFLICT (maintenance_plan_id) DO UPDATE
+    SET
+        is_read = CASE
+            WHEN community.maintenance_alert.plan_due IS DISTINCT FROM EXCLUDED.plan_due
+            THEN false
+            ELSE community.maintenance_alert.is_read
+        END,
+        plan_due = EXCLUDED.plan_due,
+        updated_at = NOW();
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+--
+-- Utility function used by the purge job to permanently remove rows that have been in the trash --
+-- longer than the retention period. Associations are removed through the existing cascades. --
+--
+CREATE OR REPLACE FUNCTION community.purge_deleted_items(retention_days INT DEFAULT 30)
+RETURNS void AS
+$$
+BEGIN
+    DELETE FROM community.inventory WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.category WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.maintenance_plan WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.notes WHERE deleted_at < NOW() - make_interval(days => retention_days);
+END;
+$$ LANGUAGE plpgsql;
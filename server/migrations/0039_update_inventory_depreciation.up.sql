@@ -0,0 +1,11 @@
+-- File: 0039_update_inventory_depreciation.up.sql
+-- Description: Adds the purchase date, useful life, salvage value and depreciation method to inventory. Used to derive the book value
+-- and the depreciation schedule of each asset.
+-- Note:- assets without a purchase date, useful life or depreciation method are not depreciated and retain their price as book value --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS purchase_date DATE;
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS useful_life_years INT CHECK (useful_life_years > 0);
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS salvage_value DECIMAL(10, 4) NOT NULL DEFAULT 0.00 CHECK (salvage_value >= 0);
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS depreciation_method VARCHAR(30) CHECK (depreciation_method IN ('straight_line', 'declining_balance'));
@@ -0,0 +1,30 @@
+-- File: 0040_create_inventory_attachments_table.up.sql
+-- Description: Create the inventory attachments table. Each row is the metadata of a single document attached to an inventory.
+-- Note:- the document itself is stored in the app bucket under object_key --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.inventory_attachments
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id             UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    attachment_type     VARCHAR(20)                  NOT NULL CHECK (attachment_type IN ('receipt', 'manual', 'warranty', 'invoice', 'other')),
+    file_name           VARCHAR(500)                 NOT NULL,
+    content_type        VARCHAR(255)                 NOT NULL DEFAULT 'application/octet-stream',
+    size_in_bytes       BIGINT                       NOT NULL CHECK (size_in_bytes >= 0),
+    object_key          TEXT                         NOT NULL UNIQUE,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_attachments IS 'metadata of documents such as receipts, manuals and warranty cards attached to an inventory.';
+
+CREATE INDEX IF NOT EXISTS inventory_attachments_item_id_idx ON community.inventory_attachments (item_id, created_at DESC);
+
+ALTER TABLE community.inventory_attachments
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, DELETE ON community.inventory_attachments TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_attachments TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_attachments TO community_admin;
@@ -0,0 +1,37 @@
+-- File: 0041_create_category_attributes_table.up.sql
+-- Description: Create the category attributes table. Each row is a typed custom field that the assets of the category can hold.
+-- Values are stored per asset in inventory.custom_attributes keyed by the name of the attribute.
+-- Note:- changing the type of an attribute does not revalidate the values that are already stored --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.category_attributes
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    category_id         UUID                         NOT NULL REFERENCES category (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name                VARCHAR(100)                 NOT NULL CHECK (name ~ '^[a-z][a-z0-9_]*$'),
+    label               VARCHAR(100),
+    field_type          VARCHAR(20)                  NOT NULL CHECK (field_type IN ('text', 'number', 'date', 'enum', 'boolean')),
+    is_required         BOOLEAN                      NOT NULL DEFAULT false,
+    options             TEXT[]                       NOT NULL DEFAULT '{}',
+    sort_order          INT                          NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[],
+    UNIQUE (category_id, name)
+);
+
+COMMENT ON TABLE category_attributes IS 'typed custom fields defined by a category. Used to validate the custom attributes of the assets in the category.';
+
+ALTER TABLE community.category_attributes
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.category_attributes TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.category_attributes TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.category_attributes TO community_admin;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS custom_attributes JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+CREATE INDEX IF NOT EXISTS inventory_custom_attributes_idx ON community.inventory USING GIN (custom_attributes);
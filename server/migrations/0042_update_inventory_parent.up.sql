@@ -0,0 +1,11 @@
+-- File: 0042_update_inventory_parent.up.sql
+-- Description: Parent child relationship between inventories. Used to model kits and components, such as a toolbox inside a van.
+-- Note:- cycles are rejected by the api before the parent is saved --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES community.inventory (id) ON UPDATE CASCADE ON DELETE SET NULL;
+ALTER TABLE community.inventory DROP CONSTRAINT IF EXISTS inventory_parent_id_check;
+ALTER TABLE community.inventory ADD CONSTRAINT inventory_parent_id_check CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS inventory_parent_id_idx ON community.inventory (parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- File: 0043_update_inventory_revisions_merge.up.sql
+-- Description: Allow merging of duplicate inventories to be recorded in the revision history of the surviving inventory.
+-- Note:- the duplicate inventory is removed once it is merged, so only the surviving inventory holds the merge revision --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory_revisions DROP CONSTRAINT IF EXISTS inventory_revisions_action_check;
+ALTER TABLE community.inventory_revisions ADD CONSTRAINT inventory_revisions_action_check CHECK (action IN ('update', 'image', 'revert', 'merge'));
@@ -0,0 +1,58 @@
+-- File: 0044_create_tags_table.up.sql
+-- Description: Create the tags and tag items tables. Tags are lightweight colored labels that can be attached to
+-- assets, notes and maintenance plans. Each tag item links a tag to exactly one of them.
+-- Note:- tag names are unique for each owner regardless of case --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.tags
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    name                VARCHAR(50)                  NOT NULL,
+    color               VARCHAR(10),
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE tags IS 'lightweight colored labels that can be attached to assets, notes and maintenance plans';
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_created_by_name_unique_idx ON community.tags (created_by, LOWER(name));
+
+ALTER TABLE community.tags
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.tags TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.tags TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.tags TO community_admin;
+
+CREATE TABLE IF NOT EXISTS community.tag_items
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    tag_id              UUID                         NOT NULL REFERENCES tags (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    item_id             UUID                         REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    note_id             UUID                         REFERENCES notes (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    maintenance_plan_id UUID                         REFERENCES maintenance_plan (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[],
+    CHECK (num_nonnulls(item_id, note_id, maintenance_plan_id) = 1)
+);
+
+COMMENT ON TABLE tag_items IS 'links a tag to a single asset, note or maintenance plan';
+
+CREATE UNIQUE INDEX IF NOT EXISTS tag_items_tag_id_item_id_unique_idx ON community.tag_items (tag_id, item_id) WHERE item_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS tag_items_tag_id_note_id_unique_idx ON community.tag_items (tag_id, note_id) WHERE note_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS tag_items_tag_id_maintenance_plan_id_unique_idx ON community.tag_items (tag_id, maintenance_plan_id) WHERE maintenance_plan_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tag_items_item_id_idx ON community.tag_items (item_id) WHERE item_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tag_items_note_id_idx ON community.tag_items (note_id) WHERE note_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tag_items_maintenance_plan_id_idx ON community.tag_items (maintenance_plan_id) WHERE maintenance_plan_id IS NOT NULL;
+
+ALTER TABLE community.tag_items
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.tag_items TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.tag_items TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.tag_items TO community_admin;
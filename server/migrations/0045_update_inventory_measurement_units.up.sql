@@ -0,0 +1,28 @@
+-- File: 0045_update_inventory_measurement_units.up.sql
+-- Description: Store the dimensions and weights of each inventory with an explicit unit. Every dimension of an inventory
+-- shares the dimension unit and every weight shares the weight unit. Values are converted on read into the unit system
+-- selected in the profile of the user.
+-- Note:- existing dimensions and weights were stored without a unit and are assumed to be in centimeters and kilograms --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.profiles ADD COLUMN IF NOT EXISTS unit_system VARCHAR(10) NOT NULL DEFAULT 'metric';
+ALTER TABLE community.profiles DROP CONSTRAINT IF EXISTS profiles_unit_system_check;
+ALTER TABLE community.profiles ADD CONSTRAINT profiles_unit_system_check CHECK (unit_system IN ('metric', 'imperial'));
+
+ALTER TABLE community.inventory ALTER COLUMN max_weight TYPE NUMERIC USING max_weight::NUMERIC;
+ALTER TABLE community.inventory ALTER COLUMN min_weight TYPE NUMERIC USING min_weight::NUMERIC;
+ALTER TABLE community.inventory ALTER COLUMN max_height TYPE NUMERIC USING max_height::NUMERIC;
+ALTER TABLE community.inventory ALTER COLUMN min_height TYPE NUMERIC USING min_height::NUMERIC;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS length NUMERIC DEFAULT 0;
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS width NUMERIC DEFAULT 0;
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS height NUMERIC DEFAULT 0;
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS weight NUMERIC DEFAULT 0;
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS dimension_unit VARCHAR(5) NOT NULL DEFAULT 'cm';
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS weight_unit VARCHAR(5) NOT NULL DEFAULT 'kg';
+
+ALTER TABLE community.inventory DROP CONSTRAINT IF EXISTS inventory_dimension_unit_check;
+ALTER TABLE community.inventory ADD CONSTRAINT inventory_dimension_unit_check CHECK (dimension_unit IN ('mm', 'cm', 'm', 'in', 'ft'));
+ALTER TABLE community.inventory DROP CONSTRAINT IF EXISTS inventory_weight_unit_check;
+ALTER TABLE community.inventory ADD CONSTRAINT inventory_weight_unit_check CHECK (weight_unit IN ('g', 'kg', 'oz', 'lb'));
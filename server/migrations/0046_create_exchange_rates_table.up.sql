@@ -0,0 +1,41 @@
+-- File: 0046_create_exchange_rates_table.up.sql
+-- Description: Store the currency of each inventory price along with a locally maintained table of exchange rates.
+-- Each exchange rate converts one unit of the from currency into the to currency starting on the effective date.
+-- Reports and summaries convert prices into the base currency of the profile with the rate on the purchase date.
+-- Note:- existing prices were stored without a currency and are assumed to be in US dollars --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.profiles ADD COLUMN IF NOT EXISTS base_currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE community.profiles DROP CONSTRAINT IF EXISTS profiles_base_currency_check;
+ALTER TABLE community.profiles ADD CONSTRAINT profiles_base_currency_check CHECK (base_currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE community.inventory DROP CONSTRAINT IF EXISTS inventory_currency_check;
+ALTER TABLE community.inventory ADD CONSTRAINT inventory_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE TABLE IF NOT EXISTS community.exchange_rates
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    from_currency       VARCHAR(3)                   NOT NULL CHECK (from_currency ~ '^[A-Z]{3}$'),
+    to_currency         VARCHAR(3)                   NOT NULL CHECK (to_currency ~ '^[A-Z]{3}$'),
+    rate                NUMERIC                      NOT NULL CHECK (rate > 0),
+    effective_date      DATE                         NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[],
+    CHECK (from_currency <> to_currency)
+);
+
+COMMENT ON TABLE exchange_rates IS 'locally maintained exchange rates used to convert prices into the base currency of the profile';
+
+CREATE UNIQUE INDEX IF NOT EXISTS exchange_rates_created_by_pair_effective_date_unique_idx ON community.exchange_rates (created_by, from_currency, to_currency, effective_date);
+
+ALTER TABLE community.exchange_rates
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.exchange_rates TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.exchange_rates TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.exchange_rates TO community_admin;
@@ -0,0 +1,63 @@
+-- File: 0047_create_inventory_expiry.up.sql
+-- Description: Track the expiry date of consumables. Each inventory can have a single expiry date and can also be
+-- split into batches, eg lots of batteries bought on different days, where each batch has its own expiry date.
+-- Inventories and batches that are about to expire raise an expiry alert that is returned with the notifications.
+-- Note:- expiry alerts depend on the current date and are refreshed when the notifications are retrieved --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory ADD COLUMN IF NOT EXISTS expiry_date DATE;
+
+CREATE INDEX IF NOT EXISTS inventory_expiry_date_idx ON community.inventory (expiry_date) WHERE expiry_date IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS community.inventory_batches
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id             UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    lot_number          VARCHAR(100)                 NOT NULL,
+    quantity            INT                          NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    expiry_date         DATE                         NOT NULL,
+    notes               VARCHAR(250),
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_batches IS 'batches of an inventory that share the same lot number and expiry date';
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_batches_item_id_lot_number_unique_idx ON community.inventory_batches (item_id, LOWER(lot_number));
+CREATE INDEX IF NOT EXISTS inventory_batches_expiry_date_idx ON community.inventory_batches (expiry_date);
+
+ALTER TABLE community.inventory_batches
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_batches TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_batches TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_batches TO community_admin;
+
+CREATE TABLE IF NOT EXISTS community.expiry_alert
+(
+    id                      UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id                 UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    batch_id                UUID                         REFERENCES inventory_batches (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name                    VARCHAR(210),
+    expiry_date             DATE                         NOT NULL,
+    is_read                 BOOLEAN                               DEFAULT false,
+    updated_by              TEXT,
+    updated_at              TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    sharable_groups         UUID[]
+);
+
+COMMENT ON TABLE expiry_alert IS 'table to support list of inventories and batches that are about to expire. Derieved from the inventory and inventory batches tables.';
+
+CREATE UNIQUE INDEX IF NOT EXISTS expiry_alert_item_id_unique_idx ON community.expiry_alert (item_id) WHERE batch_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS expiry_alert_batch_id_unique_idx ON community.expiry_alert (batch_id) WHERE batch_id IS NOT NULL;
+
+ALTER TABLE community.expiry_alert
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.expiry_alert TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.expiry_alert TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.expiry_alert TO community_admin;
@@ -0,0 +1,120 @@
+-- File: 0048_create_inventory_units.up.sql
+-- Description: Track the serialized units of an inventory. Each unit has its own serial number, status and optional
+-- storage location. The quantity of an inventory with units is derived from the units that are not lost or retired.
+-- Loans and maintenance plans can target a single unit instead of the whole inventory.
+-- Note:- a direct write to the quantity of an inventory with units is replaced with the derived quantity --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.inventory_units
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    item_id             UUID                         NOT NULL REFERENCES inventory (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    serial_number       VARCHAR(100)                 NOT NULL,
+    status              VARCHAR(20)                  NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'in_use', 'in_repair', 'broken', 'lost', 'retired')),
+    storage_location_id UUID                         REFERENCES storage_locations (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    notes               VARCHAR(500),
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_units IS 'serialized units of an inventory. The quantity of the inventory is derived from its units.';
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_units_item_id_serial_number_unique_idx ON community.inventory_units (item_id, LOWER(serial_number));
+CREATE INDEX IF NOT EXISTS inventory_units_serial_number_idx ON community.inventory_units (LOWER(serial_number));
+
+ALTER TABLE community.inventory_units
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_units TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_units TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_units TO community_admin;
+
+--
+-- loans and maintenance plans can target a single unit of an inventory --
+-- an inventory can be part of a single open loan, or each of its units can be part of a single open loan --
+--
+ALTER TABLE community.loan_items ADD COLUMN IF NOT EXISTS unit_id UUID REFERENCES inventory_units (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS community.loan_items_open_item_id_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS loan_items_open_item_id_idx ON community.loan_items (item_id) WHERE checked_in_at IS NULL AND unit_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS loan_items_open_unit_id_idx ON community.loan_items (unit_id) WHERE checked_in_at IS NULL AND unit_id IS NOT NULL;
+
+ALTER TABLE community.maintenance_item ADD COLUMN IF NOT EXISTS unit_id UUID REFERENCES inventory_units (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS maintenance_item_unit_id_idx ON community.maintenance_item (unit_id) WHERE unit_id IS NOT NULL;
+
+--
+-- utility fn used to replace the quantity of an inventory with the quantity derived from its units --
+-- inventories without units keep the quantity that is written to them --
+--
+DROP FUNCTION IF EXISTS community.derive_inventory_quantity_from_units_fn() CASCADE;
+CREATE FUNCTION community.derive_inventory_quantity_from_units_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF EXISTS (SELECT 1 FROM community.inventory_units iu WHERE iu.item_id = NEW.id) THEN
+        NEW.quantity := (
+            SELECT COUNT(*) FROM community.inventory_units iu
+            WHERE iu.item_id = NEW.id
+            AND iu.status NOT IN ('lost', 'retired')
+        );
+    END IF;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS derive_inventory_quantity_from_units_trigger ON community.inventory;
+CREATE TRIGGER derive_inventory_quantity_from_units_trigger
+    BEFORE UPDATE OF quantity
+        ON community.inventory
+    FOR EACH ROW
+EXECUTE FUNCTION community.derive_inventory_quantity_from_units_fn();
+
+--
+-- utility fn used to refresh the quantity of an inventory when its units change --
+-- the quantity change is recorded in the stock ledger and raises the low stock alert through the inventory triggers --
+--
+DROP FUNCTION IF EXISTS community.sync_inventory_quantity_from_units_fn() CASCADE;
+CREATE FUNCTION community.sync_inventory_quantity_from_units_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE community.inventory inv
+        SET
+            quantity = (
+                SELECT COUNT(*) FROM community.inventory_units iu
+                WHERE iu.item_id = OLD.item_id
+                AND iu.status NOT IN ('lost', 'retired')
+            ),
+            updated_by = COALESCE(CASE WHEN TG_OP = 'UPDATE' THEN NEW.updated_by END, inv.updated_by)
+        WHERE inv.id = OLD.item_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND (TG_OP = 'INSERT' OR NEW.item_id IS DISTINCT FROM OLD.item_id) THEN
+        UPDATE community.inventory inv
+        SET
+            quantity = (
+                SELECT COUNT(*) FROM community.inventory_units iu
+                WHERE iu.item_id = NEW.item_id
+                AND iu.status NOT IN ('lost', 'retired')
+            ),
+            updated_by = COALESCE(NEW.updated_by, inv.updated_by)
+        WHERE inv.id = NEW.item_id;
+    END IF;
+
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS sync_inventory_quantity_from_units_trigger ON community.inventory_units;
+CREATE TRIGGER sync_inventory_quantity_from_units_trigger
+    AFTER INSERT OR UPDATE OF item_id, status OR DELETE
+        ON community.inventory_units
+    FOR EACH ROW
+EXECUTE FUNCTION community.sync_inventory_quantity_from_units_fn();
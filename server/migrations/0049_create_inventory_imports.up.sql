@@ -0,0 +1,41 @@
+-- File: 0049_create_inventory_imports.up.sql
+-- Description: Create the inventory imports table. Each row is a csv or xlsx file uploaded to add assets in bulk. The
+-- cells of the file are validated with the column mapping and the rows with errors or warnings are stored as the report.
+-- The import stays pending until it is committed, which adds the assets of the rows without errors.
+-- Note:- issues are stored as [ { "row": 2, "errors": [ { "field", "column", "message" } ], "warnings": [] } ] --
+-- Note:- cells are cleared once the import is committed --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.inventory_imports
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    file_name           VARCHAR(255)                 NOT NULL,
+    file_type           VARCHAR(10)                  NOT NULL CHECK (file_type IN ('csv', 'xlsx')),
+    status              VARCHAR(20)                  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'committed')),
+    headers             TEXT[]                       NOT NULL DEFAULT '{}',
+    cells               JSONB                        NOT NULL DEFAULT '[]'::JSONB,
+    first_row           INT                          NOT NULL DEFAULT 2,
+    mapping             JSONB                        NOT NULL DEFAULT '{}'::JSONB,
+    issues              JSONB                        NOT NULL DEFAULT '[]'::JSONB,
+    total_rows          INT                          NOT NULL DEFAULT 0,
+    valid_rows          INT                          NOT NULL DEFAULT 0,
+    imported_rows       INT                          NOT NULL DEFAULT 0,
+    committed_at        TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_imports IS 'csv and xlsx files uploaded to add assets in bulk alongside the validation report of each row';
+
+CREATE INDEX IF NOT EXISTS inventory_imports_created_by_created_at_idx ON community.inventory_imports (created_by, created_at DESC);
+
+ALTER TABLE community.inventory_imports
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_imports TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_imports TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_imports TO community_admin;
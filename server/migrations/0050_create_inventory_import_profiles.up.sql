@@ -0,0 +1,34 @@
+-- File: 0050_create_inventory_import_profiles.up.sql
+-- Description: Add the source of each inventory import and create the inventory import profiles table. The source is
+-- the name of the tool that the uploaded file is exported from, eg snipeit or homebox, and selects the headers that are
+-- used to derive the column mapping. Each profile is a column mapping saved by the user to reuse for later imports.
+-- Note:- mapping is stored as { "name": "Item Name", "categories": "Category" } --
+-- Note:- profile names are unique for each owner regardless of case --
+
+SET search_path TO community, public;
+
+ALTER TABLE community.inventory_imports ADD COLUMN IF NOT EXISTS source VARCHAR(30) NOT NULL DEFAULT 'fleetwise';
+
+CREATE TABLE IF NOT EXISTS community.inventory_import_profiles
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    name                VARCHAR(100)                 NOT NULL,
+    source              VARCHAR(30)                  NOT NULL DEFAULT 'fleetwise',
+    mapping             JSONB                        NOT NULL DEFAULT '{}'::JSONB,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE inventory_import_profiles IS 'column mappings saved by the user to reuse when csv and xlsx files of assets are imported';
+
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_import_profiles_created_by_name_unique_idx ON community.inventory_import_profiles (created_by, LOWER(name));
+
+ALTER TABLE community.inventory_import_profiles
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_import_profiles TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.inventory_import_profiles TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.inventory_import_profiles TO community_admin;
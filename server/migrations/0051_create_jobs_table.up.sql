@@ -0,0 +1,47 @@
+-- File: 0051_create_jobs_table.up.sql
+-- Description: Create the jobs table used as the queue of long running operations, eg imports, exports and label
+-- sheets. Workers within the api claim queued jobs with FOR UPDATE SKIP LOCKED so that each job is only run by a
+-- single worker. Running jobs are kept alive by the worker through locked_at; jobs whose lock has expired are claimed
+-- again. Failed jobs are queued again with run_at pushed back until max_attempts is reached.
+-- Note:- status is one of queued, running, succeeded, failed or cancelled --
+-- Note:- progress is the percent of the job that is completed --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.jobs
+(
+    id                  UUID PRIMARY KEY             NOT NULL DEFAULT gen_random_uuid(),
+    job_type            VARCHAR(50)                  NOT NULL,
+    status              VARCHAR(20)                  NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    payload             JSONB                        NOT NULL DEFAULT '{}'::JSONB,
+    result              JSONB,
+    progress            INT                          NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    progress_message    VARCHAR(255),
+    attempts            INT                          NOT NULL DEFAULT 0,
+    max_attempts        INT                          NOT NULL DEFAULT 3 CHECK (max_attempts > 0),
+    last_error          TEXT,
+    cancel_requested    BOOLEAN                      NOT NULL DEFAULT FALSE,
+    run_at              TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    locked_at           TIMESTAMP WITH TIME ZONE,
+    locked_by           VARCHAR(100),
+    started_at          TIMESTAMP WITH TIME ZONE,
+    finished_at         TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    created_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    updated_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW(),
+    updated_by          UUID                         REFERENCES profiles (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sharable_groups     UUID[]
+);
+
+COMMENT ON TABLE jobs IS 'queue of long running operations that are run by the workers of the api';
+
+CREATE INDEX IF NOT EXISTS jobs_queued_run_at_idx ON community.jobs (run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS jobs_running_locked_at_idx ON community.jobs (locked_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS jobs_created_by_created_at_idx ON community.jobs (created_by, created_at DESC);
+
+ALTER TABLE community.jobs
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.jobs TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.jobs TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.jobs TO community_admin;
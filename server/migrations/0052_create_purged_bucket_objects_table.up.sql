@@ -0,0 +1,43 @@
+-- File: 0052_create_purged_bucket_objects_table.up.sql
+-- Description: Records the bucket objects of the assets that are permanently removed by the purge job. The image and the
+-- attachments of an asset are stored in the app bucket and are not removed by the cascades of the db.
+-- Note:- the api removes the recorded objects from the bucket after each purge and deletes the rows once removed --
+
+SET search_path TO community, public;
+
+CREATE TABLE IF NOT EXISTS community.purged_bucket_objects
+(
+    object_key          TEXT PRIMARY KEY             NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE     NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE purged_bucket_objects IS 'bucket objects of purged assets that are yet to be removed from the app bucket.';
+
+ALTER TABLE community.purged_bucket_objects
+    OWNER TO community_admin;
+
+GRANT SELECT, INSERT, DELETE ON community.purged_bucket_objects TO community_public;
+GRANT SELECT, INSERT, UPDATE, DELETE ON community.purged_bucket_objects TO community_test;
+GRANT ALL PRIVILEGES ON TABLE community.purged_bucket_objects TO community_admin;
+
+--
+-- the image of each purged asset is stored under the id of the asset and each attachment under its object key --
+--
+CREATE OR REPLACE FUNCTION community.purge_deleted_items(retention_days INT DEFAULT 30)
+RETURNS void AS
+$$
+BEGIN
+    INSERT INTO community.purged_bucket_objects (object_key)
+    SELECT inv.id::TEXT FROM community.inventory inv WHERE inv.deleted_at < NOW() - make_interval(days => retention_days)
+    UNION
+    SELECT ia.object_key FROM community.inventory_attachments ia
+    JOIN community.inventory inv ON inv.id = ia.item_id
+    WHERE inv.deleted_at < NOW() - make_interval(days => retention_days)
+    ON CONFLICT (object_key) DO NOTHING;
+
+    DELETE FROM community.inventory WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.category WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.maintenance_plan WHERE deleted_at < NOW() - make_interval(days => retention_days);
+    DELETE FROM community.notes WHERE deleted_at < NOW() - make_interval(days => retention_days);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,11 @@
+-- File: 0053_create_inventory_duplicate_indexes.up.sql
+-- Description: Create trigram and normalized code indexes to find duplicate assets in the db.
+-- Note:- pg_trgm lowercases the name on its own, barcode and sku are compared trimmed and lowercased --
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public;
+
+SET search_path TO community, public;
+
+CREATE INDEX IF NOT EXISTS inventory_name_trgm_idx ON community.inventory USING GIN (name gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS inventory_lower_barcode_idx ON community.inventory (LOWER(TRIM(barcode))) WHERE barcode IS NOT NULL AND barcode <> '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS inventory_lower_sku_idx ON community.inventory (LOWER(TRIM(sku))) WHERE sku IS NOT NULL AND sku <> '' AND deleted_at IS NULL;
@@ -0,0 +1,64 @@
+-- File: 0054_update_stock_ledger_triggers_for_account_restore.up.sql
+-- Description: Skip the stock ledger triggers while an account backup is restored. The restored assets already hold
+-- the quantity and location that the restored ledger leads up to, so the ledger is restored as is.
+-- Note:- the restore sets community.restoring_account_backup to on for its own transaction only --
+
+SET search_path TO community, public;
+
+--
+-- utility fn used to derive the inventory quantity from the ledger --
+-- transfers move the inventory to the destination storage location --
+--
+CREATE OR REPLACE FUNCTION community.sync_inventory_quantity_from_stock_movements_fn()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF current_setting('community.restoring_account_backup', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+
+    UPDATE community.inventory inv
+    SET
+        quantity = (SELECT COALESCE(SUM(sm.quantity_change), 0) FROM community.stock_movements sm WHERE sm.item_id = NEW.item_id),
+        storage_location_id = COALESCE(NEW.to_storage_location_id, inv.storage_location_id),
+        location = COALESCE((SELECT sl.location FROM community.storage_locations sl WHERE sl.id = NEW.to_storage_location_id), inv.location)
+    WHERE inv.id = NEW.item_id;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+--
+-- utility fn used to record an adjustment in the ledger when the quantity is written directly --
+-- eg, UpdateAsset, UpdateInventory, AddInventory and bulk uploads --
+--
+CREATE OR REPLACE FUNCTION community.record_stock_movement_on_quantity_change_fn()
+    RETURNS trigger AS
+$$
+DECLARE
+    ledger_quantity INT;
+BEGIN
+    IF current_setting('community.restoring_account_backup', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT COALESCE(SUM(sm.quantity_change), 0) INTO ledger_quantity
+    FROM community.stock_movements sm
+    WHERE sm.item_id = NEW.id;
+
+    IF COALESCE(NEW.quantity, 0) <> ledger_quantity THEN
+        INSERT INTO community.stock_movements (item_id, movement_type, quantity_change, reason, created_at, created_by, sharable_groups)
+        VALUES (
+            NEW.id,
+            'adjustment',
+            COALESCE(NEW.quantity, 0) - ledger_quantity,
+            CASE WHEN TG_OP = 'INSERT' THEN 'opening balance' ELSE 'manual adjustment' END,
+            NOW(),
+            NEW.updated_by,
+            NEW.sharable_groups
+        );
+    END IF;
+
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
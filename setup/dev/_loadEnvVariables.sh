@@ -32,6 +32,9 @@ MINIO_APP_LOCALHOST_URL=localhost:9000
 MINIO_APP_BUCKET_NAME="images"
 MINIO_APP_BUCKET_LOCATION="us-east-1"
 
+# number of days deleted items are kept in the trash before they are purged
+TRASH_RETENTION_DAYS=30
+
 # general users
 CLIENT_USER="community_public"
 CLIENT_PASSWORD="password"
@@ -46,6 +49,9 @@ COMMUNITY_TEST_USER="community_test"
 # UI localhost uri
 REACT_APP_LOCALHOST_URL=http://localhost:8087
 REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL=ws://localhost:8087
+
+# web application uri that the printed labels link to
+WEB_APPLICATION_URL=http://localhost:5173
 DATABASE_DOCKER_CONTAINER_NAME="mashed-backend-1"
 DATABASE_DOCKER_CONTAINER_IP_ADDRESS="localhost"
 DATABASE_DOCKER_CONTAINER_PORT=8089
@@ -67,6 +73,8 @@ MINIO_APP_BUCKET_NAME=$MINIO_APP_BUCKET_NAME
 MINIO_APP_BUCKET_LOCATION=$MINIO_APP_BUCKET_LOCATION
 MINIO_APP_LOCALHOST_URL=$MINIO_APP_LOCALHOST_URL
 
+TRASH_RETENTION_DAYS=$TRASH_RETENTION_DAYS
+
 DATABASE_DOCKER_CONTAINER_NAME=$DATABASE_DOCKER_CONTAINER_NAME
 DATABASE_DOCKER_CONTAINER_PORT=$DATABASE_DOCKER_CONTAINER_PORT
 DATABASE_DOCKER_CONTAINER_IP_ADDRESS=$DATABASE_DOCKER_CONTAINER_IP_ADDRESS
@@ -83,6 +91,7 @@ SEND_GRID_USER_EMAIL_ADDRESS=$SEND_GRID_USER_EMAIL_ADDRESS
 
 REACT_APP_LOCALHOST_URL=$REACT_APP_LOCALHOST_URL
 REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL=$REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL
+WEB_APPLICATION_URL=$WEB_APPLICATION_URL
 
 DEBUG=$DEBUG
 EOF
@@ -36,6 +36,9 @@ COMMUNITY_TEST_USER="community_test"
 REACT_APP_LOCALHOST_URL=http://localhost:8087
 REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL=ws://localhost:8087
 
+# web application uri that the printed labels link to
+WEB_APPLICATION_URL=http://localhost:5173
+
 DATABASE_DOCKER_CONTAINER_NAME="mashed-backend-1"
 DATABASE_DOCKER_CONTAINER_IP_ADDRESS="localhost"
 DATABASE_DOCKER_CONTAINER_PORT=8089
@@ -61,5 +64,6 @@ REACT_APP_LOCALHOST_URL=$REACT_APP_LOCALHOST_URL
 DATABASE_DOCKER_CONTAINER_PORT=$DATABASE_DOCKER_CONTAINER_PORT
 GEOCODING_MAP_API_KEY=$GEOCODING_MAP_API_KEY
 REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL=$REACT_APP_LOCALHOST_URL_SOCKET_BASE_URL
+WEB_APPLICATION_URL=$WEB_APPLICATION_URL
 EOF
 echo "finished compiling required variables."
\ No newline at end of file
@@ -89,8 +89,8 @@ VALUES ('Dog food',
         '6 pounds of food bought from tractor supply',
         96.00,
         'HIDDEN',
-        'barcode#1123928',
-        'sku#123456734',
+        'barcode#1123929',
+        'sku#123456735',
         1,
         'Walmart',
         'Utility Closet',
@@ -12,4 +12,4 @@ PGPASSWORD=home psql \
         'refresh-alerts'::text, 
         '0 16 * * *'::text, 
         'SELECT community.populate_maintenance_alerts();'::text
-      );"
\ No newline at end of file
+      );"